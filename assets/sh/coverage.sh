@@ -0,0 +1,42 @@
+#!/bin/bash
+# Coverage gate of the financial paths (postings, transfers, fees and interest)
+# usage: ./assets/sh/coverage.sh (from the project root)
+
+set -e
+
+profile=${COVER_PROFILE:-coverage.out}
+
+# minimal statement coverage per file (percent)
+gates="internal/core/service/posting.go:85
+internal/core/service/fee.go:90
+internal/core/service/interest.go:75"
+
+# minimal statement coverage of the service package (percent)
+service_gate=80
+
+go test -count=1 -coverprofile=$profile ./internal/core/service/
+
+failed=0
+
+check(){
+    local name=$1 got=$2 min=$3
+    if awk -v g=$got -v m=$min 'BEGIN{exit !(g < m)}'; then
+        echo "FAIL $name coverage $got% < $min%"
+        failed=1
+    else
+        echo "ok   $name coverage $got% >= $min%"
+    fi
+}
+
+for gate in $gates; do
+    file=${gate%%:*}
+    min=${gate##*:}
+    got=$(awk -v f="$file" 'NR>1 && index($1, f":") { split($0, a, " "); total+=a[2]; if (a[3] > 0) covered+=a[2] }
+                            END { if (total == 0) print 0; else printf "%.1f", 100*covered/total }' $profile)
+    check $file $got $min
+done
+
+got=$(go tool cover -func=$profile | awk '/^total:/ { sub("%", "", $3); print $3 }')
+check internal/core/service $got $service_gate
+
+exit $failed
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"time"
+	"bytes"
+	"errors"
+	"context"
+	"strings"
+	"testing"
+	"net/http"
+	"encoding/json"
+	"net/http/httptest"
+
+	"github.com/go-account/internal/adapter/api"
+	"github.com/go-account/internal/adapter/memory"
+	"github.com/go-account/internal/core/service"
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/auth"
+
+	"github.com/eliezerraj/go-core/coreJson"
+	"github.com/gorilla/mux"
+)
+
+const testTenant = "tenant-1"
+
+// the service over a memory repository with a checking product and the accounts ACC-1 (credit of 100) and ACC-2 of P-1
+func newTestService(t *testing.T) *service.WorkerService {
+	t.Helper()
+	ctx := auth.WithTenantBypass(context.Background())
+	workerService := service.NewWorkerService(memory.NewMemoryRepository())
+
+	_, err := workerService.AddAccountProduct(ctx, &model.AccountProduct{ProductID: "CHECKING", Type: service.ProductChecking, Currencies: []string{"BRL"}, TenantID: testTenant})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, accountID := range []string{"ACC-1", "ACC-2"} {
+		if _, err := workerService.AddAccount(ctx, &model.Account{AccountID: accountID, PersonID: "P-1", ProductID: "CHECKING", TenantID: testTenant}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := workerService.AddPosting(ctx, &model.AccountStatement{AccountID: "ACC-1", Type: service.StatementCredit, Currency: "BRL", Amount: 100}); err != nil {
+		t.Fatal(err)
+	}
+	return workerService
+}
+
+// the error of a handler is written with the status of the APIError (as the error middleware),
+// the token is the one of a principal of the test tenant
+func handleError(fn func(http.ResponseWriter, *http.Request) error) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer token-test" {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		err := fn(rw, req.WithContext(auth.WithPrincipal(req.Context(), &model.Principal{Subject: "user-test", TenantID: testTenant})))
+		if err == nil {
+			return
+		}
+		var apiError *coreJson.APIError
+		if !errors.As(err, &apiError) {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		rw.WriteHeader(apiError.StatusCode)
+		json.NewEncoder(rw).Encode(apiError)
+	}
+}
+
+// the HTTP client over the routes used by accountctl
+func newTestHttpClient(t *testing.T, workerService *service.WorkerService) *HttpClient {
+	t.Helper()
+	h := api.NewHttpRouters(workerService, 5)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/v1/accounts", handleError(h.AddAccount)).Methods(http.MethodPost)
+	router.HandleFunc("/v1/accounts/{id}", handleError(h.GetAccount)).Methods(http.MethodGet)
+	router.HandleFunc("/v1/accounts/{id}", handleError(h.PatchAccount)).Methods(http.MethodPatch)
+	router.HandleFunc("/v1/accounts/{id}", handleError(h.DeleteAccount)).Methods(http.MethodDelete)
+	router.HandleFunc("/v1/accounts/{id}/reconciliation", handleError(h.ReconcileAccount)).Methods(http.MethodGet)
+	router.HandleFunc("/v1/accounts/{id}/statements", handleError(h.ListAccountStatement)).Methods(http.MethodGet)
+	router.HandleFunc("/v1/persons/{id}/accounts", handleError(h.ListAccountPerPerson)).Methods(http.MethodGet)
+	router.HandleFunc("/posting", handleError(h.AddPosting)).Methods(http.MethodPost)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return &HttpClient{baseURL: server.URL, token: "token-test", client: server.Client()}
+}
+
+func TestRunCommand(t *testing.T) {
+	today := time.Now().UTC().Format("2006-01-02")
+
+	tests := []struct {
+		name		string
+		output		string
+		args		[]string
+		contains	string
+		err			string
+	}{
+		{"create", "table", []string{"create", "--person", "P-2", "--product", "CHECKING", "--account", "ACC-3"}, "ACC-3", ""},
+		{"create unknown product", "table", []string{"create", "--person", "P-2", "--product", "LOAN", "--account", "ACC-4"}, "", "product"},
+		{"get", "json", []string{"get", "ACC-1"}, `"account_id": "ACC-1"`, ""},
+		{"get unknown", "table", []string{"get", "ACC-9"}, "", "not found"},
+		{"get without account", "table", []string{"get"}, "", "account_id not informed"},
+		{"list", "csv", []string{"list", "P-1"}, "ACC-2,P-1,CHECKING,ACTIVE", ""},
+		{"block", "table", []string{"block", "ACC-2"}, "BLOCKED", ""},
+		{"adjust blocked", "table", []string{"adjust", "ACC-2", "BRL", "10"}, "", "blocked"},
+		{"unblock", "csv", []string{"unblock", "ACC-2"}, "ACC-2,P-1,CHECKING,ACTIVE", ""},
+		{"block unknown", "table", []string{"block", "ACC-9"}, "", "not found"},
+		{"block without account", "table", []string{"block"}, "", "account_id not informed"},
+		{"adjust credit", "table", []string{"adjust", "ACC-1", "BRL", "10.5", "--transaction", "ADJ-1"}, "balance BRL 110.50", ""},
+		{"adjust debit", "table", []string{"adjust", "ACC-1", "BRL", "-0.5"}, "DEBIT", ""},
+		{"adjust invalid amount", "table", []string{"adjust", "ACC-1", "BRL", "ten"}, "", "invalid"},
+		{"adjust insufficient funds", "table", []string{"adjust", "ACC-2", "BRL", "-1"}, "", "insufficient"},
+		{"reconcile", "table", []string{"reconcile", "ACC-1"}, "110.00", ""},
+		{"export", "csv", []string{"export", "ACC-1", "--from", today, "--to", today}, "ID,ACCOUNT_ID,TYPE", ""},
+		{"export before the statements", "json", []string{"export", "ACC-1", "--to", "2020-01-01"}, "[]", ""},
+		{"export bad date", "table", []string{"export", "ACC-1", "--from", "01-01-2026"}, "", "bad request"},
+		{"close", "table", []string{"close", "ACC-3"}, "ACC-3", ""},
+		{"close twice", "table", []string{"close", "ACC-3"}, "", "not found"},
+		{"unknown command", "table", []string{"freeze", "ACC-1"}, "", "not supported"},
+	}
+
+	backends := map[string]func(t *testing.T) accountClient {
+		"http": func(t *testing.T) accountClient { return newTestHttpClient(t, newTestService(t)) },
+		"db": func(t *testing.T) accountClient { return &DatabaseClient{workerService: newTestService(t), principal: &model.Principal{Subject: "accountctl", TenantID: testTenant}} },
+	}
+
+	for backend, newClient := range backends {
+		t.Run(backend, func(t *testing.T) {
+			client := newClient(t)
+			for _, tt := range tests {
+				t.Run(tt.name, func(t *testing.T) {
+					stdout := bytes.Buffer{}
+					err := runCommand(context.Background(), client, options{output: tt.output, tenant: testTenant}, tt.args, &stdout)
+					if tt.err != "" {
+						if err == nil || !strings.Contains(strings.ToLower(err.Error()), tt.err) {
+							t.Fatalf("err %v want %q", err, tt.err)
+						}
+						return
+					}
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !strings.Contains(stdout.String(), tt.contains) {
+						t.Errorf("output %q want %q", stdout.String(), tt.contains)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestRunCommandNotReconciled(t *testing.T) {
+	client := &staticClient{reconciliation: []model.Reconciliation{{AccountID: "ACC-1", Currency: "BRL", Balance: 10, StatementTotal: 9, Statements: 1, Difference: 1}}}
+
+	stdout := bytes.Buffer{}
+	err := runCommand(context.Background(), client, options{output: "table"}, []string{"reconcile", "ACC-1"}, &stdout)
+	if err == nil || !strings.Contains(err.Error(), "not reconciled") {
+		t.Errorf("err %v want not reconciled", err)
+	}
+	if !strings.Contains(stdout.String(), "false") {
+		t.Errorf("output %q without the reconciliation", stdout.String())
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name	string
+		args	[]string
+		err		string
+	}{
+		{"without command", []string{}, "command not informed"},
+		{"unknown output", []string{"--output", "yaml", "get", "ACC-1"}, "output"},
+		{"unknown backend", []string{"--backend", "grpc", "get", "ACC-1"}, "backend"},
+		{"http without url", []string{"--backend", "http", "get", "ACC-1"}, "ACCOUNTCTL_URL"},
+		{"db without tenant", []string{"--backend", "db", "get", "ACC-1"}, "--tenant"},
+	}
+
+	t.Setenv("ACCOUNTCTL_URL", "")
+	t.Setenv("ACCOUNTCTL_TENANT", "")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := run(context.Background(), tt.args, &bytes.Buffer{}, &bytes.Buffer{})
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("err %v want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+// a client with a fixed reconciliation
+type staticClient struct {
+	accountClient
+	reconciliation	[]model.Reconciliation
+}
+
+func (c *staticClient) ReconcileAccount(ctx context.Context, accountID string) (*[]model.Reconciliation, error) {
+	return &c.reconciliation, nil
+}
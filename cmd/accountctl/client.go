@@ -0,0 +1,174 @@
+package main
+
+import(
+	"io"
+	"os"
+	"fmt"
+	"time"
+	"bytes"
+	"errors"
+	"context"
+	"strings"
+	"net/url"
+	"net/http"
+	"encoding/json"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/infra/security"
+)
+
+// About the operations of accountctl, over the HTTP API or over the database
+type accountClient interface {
+	CreateAccount(ctx context.Context, account *model.Account) (*model.Account, error)
+	GetAccount(ctx context.Context, accountID string) (*model.Account, error)
+	ListAccount(ctx context.Context, personID string) (*[]model.Account, error)
+	SetAccountStatus(ctx context.Context, accountID string, status string) (*model.Account, error)
+	CloseAccount(ctx context.Context, accountID string) (*model.Account, error)
+	PostAdjustment(ctx context.Context, accountStatement *model.AccountStatement) (*model.MovimentAccount, error)
+	ReconcileAccount(ctx context.Context, accountID string) (*[]model.Reconciliation, error)
+	ListAccountStatement(ctx context.Context, accountID string, from time.Time, to time.Time) (*[]model.AccountStatement, error)
+}
+
+// About the client of the HTTP API, authenticated by a bearer token or by an api key
+type HttpClient struct {
+	baseURL		string
+	token		string
+	apiKey		string
+	client		*http.Client
+}
+
+// About create the HTTP client from ACCOUNTCTL_URL and ACCOUNTCTL_TOKEN or ACCOUNTCTL_API_KEY
+func newHttpClient() (*HttpClient, error) {
+	if os.Getenv("ACCOUNTCTL_URL") == "" {
+		return nil, errors.New("ACCOUNTCTL_URL not informed")
+	}
+	return &HttpClient{	baseURL: strings.TrimRight(os.Getenv("ACCOUNTCTL_URL"), "/"),
+						token: os.Getenv("ACCOUNTCTL_TOKEN"),
+						apiKey: os.Getenv("ACCOUNTCTL_API_KEY"),
+						client: &http.Client{},
+					}, nil
+}
+
+// About call a route of the API and decode the response into res, an error status returns the message of the API
+func (c *HttpClient) do(ctx context.Context, method string, path string, body interface{}, res interface{}) error {
+	childLogger.Info().Str("func","do").Str("method", method).Str("path", path).Send()
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL + path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer " + c.token)
+	}
+	if c.apiKey != "" {
+		req.Header.Set(security.ApiKeyHeader, c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiError := struct {
+			Msg		string `json:"msg"`
+		}{}
+		payload, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(payload, &apiError) != nil || apiError.Msg == "" {
+			apiError.Msg = strings.TrimSpace(string(payload))
+		}
+		return fmt.Errorf("%s %s: %d %s", method, path, resp.StatusCode, apiError.Msg)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(res)
+}
+
+func (c *HttpClient) CreateAccount(ctx context.Context, account *model.Account) (*model.Account, error) {
+	res := model.Account{}
+	if err := c.do(ctx, http.MethodPost, "/v1/accounts", account, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *HttpClient) GetAccount(ctx context.Context, accountID string) (*model.Account, error) {
+	res := model.Account{}
+	if err := c.do(ctx, http.MethodGet, "/v1/accounts/" + url.PathEscape(accountID), nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *HttpClient) ListAccount(ctx context.Context, personID string) (*[]model.Account, error) {
+	res := []model.Account{}
+	if err := c.do(ctx, http.MethodGet, "/v1/persons/" + url.PathEscape(personID) + "/accounts", nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *HttpClient) SetAccountStatus(ctx context.Context, accountID string, status string) (*model.Account, error) {
+	res := model.Account{}
+	if err := c.do(ctx, http.MethodPatch, "/v1/accounts/" + url.PathEscape(accountID), &model.AccountPatch{Status: &status}, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *HttpClient) CloseAccount(ctx context.Context, accountID string) (*model.Account, error) {
+	res := model.Account{}
+	if err := c.do(ctx, http.MethodDelete, "/v1/accounts/" + url.PathEscape(accountID), nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *HttpClient) PostAdjustment(ctx context.Context, accountStatement *model.AccountStatement) (*model.MovimentAccount, error) {
+	res := model.MovimentAccount{}
+	if err := c.do(ctx, http.MethodPost, "/posting", accountStatement, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *HttpClient) ReconcileAccount(ctx context.Context, accountID string) (*[]model.Reconciliation, error) {
+	res := []model.Reconciliation{}
+	if err := c.do(ctx, http.MethodGet, "/v1/accounts/" + url.PathEscape(accountID) + "/reconciliation", nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *HttpClient) ListAccountStatement(ctx context.Context, accountID string, from time.Time, to time.Time) (*[]model.AccountStatement, error) {
+	// the API takes the days, the last one included
+	query := url.Values{}
+	if !from.IsZero() {
+		query.Set("from", from.Format("2006-01-02"))
+	}
+	if !to.IsZero() {
+		query.Set("to", to.AddDate(0, 0, -1).Format("2006-01-02"))
+	}
+	path := "/v1/accounts/" + url.PathEscape(accountID) + "/statements"
+	if len(query) > 0 {
+		path = path + "?" + query.Encode()
+	}
+
+	res := []model.AccountStatement{}
+	if err := c.do(ctx, http.MethodGet, path, nil, &res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import(
+	"fmt"
+	"time"
+	"context"
+
+	"github.com/go-account/internal/infra/configuration"
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/auth"
+	"github.com/go-account/internal/core/port"
+	"github.com/go-account/internal/core/service"
+	"github.com/go-account/internal/core/validator"
+	"github.com/go-account/internal/adapter/database"
+	go_core_pg "github.com/eliezerraj/go-core/database/pg"
+)
+
+// About the client over the database, it runs the same service (rules, fees, events) of the application
+type DatabaseClient struct {
+	workerService		*service.WorkerService
+	databasePGServer	*go_core_pg.DatabasePGServer
+	principal			*model.Principal
+}
+
+// About open the database with the env vars of the application (GetDatabaseEnv and ACCOUNT_PERSISTENCE),
+// the operations are recorded as the accountctl user and restricted to the tenant
+func newDatabaseClient(ctx context.Context, tenantID string) (*DatabaseClient, error) {
+	databaseConfig := configuration.GetDatabaseEnv()
+	eventStoreConfig := configuration.GetEventStoreEnv()
+
+	var databasePGServer go_core_pg.DatabasePGServer
+	databasePGServer, err := databasePGServer.NewDatabasePGServer(ctx, databaseConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	workerRepository := database.NewWorkerRepository(&databasePGServer)
+	if err := workerRepository.CheckSchemaVersion(ctx); err != nil {
+		databasePGServer.CloseConnection()
+		return nil, err
+	}
+
+	var repository port.Repository
+	switch eventStoreConfig.Mode {
+	case "state":
+		repository = workerRepository
+	case "eventstore":
+		repository = database.NewEventStoreRepository(workerRepository, eventStoreConfig.SnapshotInterval)
+	default:
+		databasePGServer.CloseConnection()
+		return nil, fmt.Errorf("unknown ACCOUNT_PERSISTENCE %s", eventStoreConfig.Mode)
+	}
+
+	workerService := service.NewWorkerService(repository)
+	workerService.SetEventSource("accountctl")
+
+	return &DatabaseClient{	workerService: workerService,
+							databasePGServer: &databasePGServer,
+							principal: &model.Principal{Subject: "accountctl", TenantID: tenantID},
+						}, nil
+}
+
+// About close the database connections
+func (c *DatabaseClient) Close() {
+	if c.databasePGServer != nil {
+		c.databasePGServer.CloseConnection()
+	}
+}
+
+func (c *DatabaseClient) context(ctx context.Context) context.Context {
+	return auth.WithPrincipal(ctx, c.principal)
+}
+
+func (c *DatabaseClient) CreateAccount(ctx context.Context, account *model.Account) (*model.Account, error) {
+	ctx = c.context(ctx)
+
+	tenantID, err := auth.ResolveTenant(ctx, account.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	account.TenantID = tenantID
+	if err := validator.Validate(account); err != nil {
+		return nil, err
+	}
+	account.UserLastUpdate = auth.UserLastUpdate(ctx)
+
+	return c.workerService.AddAccount(ctx, account)
+}
+
+func (c *DatabaseClient) GetAccount(ctx context.Context, accountID string) (*model.Account, error) {
+	return c.workerService.GetAccount(c.context(ctx), &model.Account{AccountID: accountID})
+}
+
+func (c *DatabaseClient) ListAccount(ctx context.Context, personID string) (*[]model.Account, error) {
+	return c.workerService.ListAccountPerPerson(c.context(ctx), &model.Account{PersonID: personID})
+}
+
+func (c *DatabaseClient) SetAccountStatus(ctx context.Context, accountID string, status string) (*model.Account, error) {
+	ctx = c.context(ctx)
+
+	accountPatch := model.AccountPatch{AccountID: accountID, Status: &status}
+	if err := validator.Validate(&accountPatch); err != nil {
+		return nil, err
+	}
+	accountPatch.UserLastUpdate = auth.UserLastUpdate(ctx)
+
+	return c.workerService.PatchAccount(ctx, &accountPatch)
+}
+
+func (c *DatabaseClient) CloseAccount(ctx context.Context, accountID string) (*model.Account, error) {
+	return c.workerService.DeleteAccount(c.context(ctx), &model.Account{AccountID: accountID})
+}
+
+func (c *DatabaseClient) PostAdjustment(ctx context.Context, accountStatement *model.AccountStatement) (*model.MovimentAccount, error) {
+	if err := validator.Validate(accountStatement); err != nil {
+		return nil, err
+	}
+	return c.workerService.AddPosting(c.context(ctx), accountStatement)
+}
+
+func (c *DatabaseClient) ReconcileAccount(ctx context.Context, accountID string) (*[]model.Reconciliation, error) {
+	return c.workerService.ReconcileAccount(c.context(ctx), &model.Account{AccountID: accountID})
+}
+
+func (c *DatabaseClient) ListAccountStatement(ctx context.Context, accountID string, from time.Time, to time.Time) (*[]model.AccountStatement, error) {
+	return c.workerService.ListAccountStatement(c.context(ctx), &model.Account{AccountID: accountID}, from, to)
+}
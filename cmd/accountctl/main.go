@@ -0,0 +1,305 @@
+package main
+
+import(
+	"io"
+	"os"
+	"fmt"
+	"flag"
+	"time"
+	"errors"
+	"context"
+	"strconv"
+	"os/signal"
+	"syscall"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+)
+
+var(
+	logLevel = 	zerolog.ErrorLevel // the output is for the operator, only the errors are logged
+	childLogger = log.With().Str("component","go-account").Str("package", "accountctl").Logger()
+)
+
+const usage = `accountctl - account operations for the support staff
+
+usage: accountctl [flags] <command> [arguments]
+
+commands:
+  create --person <person_id> --product <product_id> [--account <account_id>]
+  get <account_id>
+  list <person_id>
+  block <account_id>
+  unblock <account_id>
+  close <account_id>
+  adjust <account_id> <currency> <amount> [--transaction <transaction_id>]
+  reconcile <account_id>
+  export <account_id> [--from yyyy-mm-dd] [--to yyyy-mm-dd] [--file <path>]
+
+flags (the db backend needs the --tenant):
+`
+
+// About the global flags, before the command
+type options struct {
+	backend		string
+	output		string
+	tenant		string
+	timeout		time.Duration
+}
+
+// About main
+func main(){
+	zerolog.SetGlobalLevel(logLevel)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if err := run(ctx, os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintf(os.Stderr, "accountctl: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// About parse the global flags, open the backend and run the command
+func run(ctx context.Context, args []string, stdout io.Writer, stderr io.Writer) error {
+	opt := options{}
+
+	flags := flag.NewFlagSet("accountctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&opt.backend, "backend", envOrDefault("ACCOUNTCTL_BACKEND", "http"), "http (ACCOUNTCTL_URL) or db (DB_HOST, DB_PORT, DB_NAME and the pod secrets)")
+	flags.StringVar(&opt.output, "output", "table", "json, table or csv")
+	flags.StringVar(&opt.tenant, "tenant", os.Getenv("ACCOUNTCTL_TENANT"), "tenant of the operation")
+	flags.DurationVar(&opt.timeout, "timeout", 30 * time.Second, "timeout of the command")
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("command not informed")
+	}
+	if opt.output != "json" && opt.output != "table" && opt.output != "csv" {
+		return fmt.Errorf("output %q not supported (json, table or csv)", opt.output)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opt.timeout)
+	defer cancel()
+
+	var client accountClient
+	switch opt.backend {
+	case "http":
+		httpClient, err := newHttpClient()
+		if err != nil {
+			return err
+		}
+		client = httpClient
+	case "db":
+		// over the database there is no principal to restrict the operations, the tenant is mandatory
+		if opt.tenant == "" {
+			return errors.New("the db backend needs the --tenant (or ACCOUNTCTL_TENANT)")
+		}
+		databaseClient, err := newDatabaseClient(ctx, opt.tenant)
+		if err != nil {
+			return err
+		}
+		defer databaseClient.Close()
+		client = databaseClient
+	default:
+		return fmt.Errorf("backend %q not supported (http or db)", opt.backend)
+	}
+
+	return runCommand(ctx, client, opt, flags.Args(), stdout)
+}
+
+// About run a command over a backend and write its result
+func runCommand(ctx context.Context, client accountClient, opt options, args []string, stdout io.Writer) error {
+	childLogger.Info().Str("func","runCommand").Strs("args", args).Send()
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "create":
+		flags := flag.NewFlagSet("create", flag.ContinueOnError)
+		account := model.Account{TenantID: opt.tenant}
+		flags.StringVar(&account.PersonID, "person", "", "person_id")
+		flags.StringVar(&account.ProductID, "product", "", "product_id")
+		flags.StringVar(&account.AccountID, "account", "", "account_id (default generated by the number format of the tenant)")
+		if err := flags.Parse(args); err != nil {
+			return err
+		}
+		res, err := client.CreateAccount(ctx, &account)
+		if err != nil {
+			return err
+		}
+		return writeAccounts(stdout, opt.output, []model.Account{*res})
+
+	case "get":
+		accountID, err := argument(args, "account_id")
+		if err != nil {
+			return err
+		}
+		res, err := client.GetAccount(ctx, accountID)
+		if err != nil {
+			return err
+		}
+		return writeAccounts(stdout, opt.output, []model.Account{*res})
+
+	case "list":
+		personID, err := argument(args, "person_id")
+		if err != nil {
+			return err
+		}
+		res, err := client.ListAccount(ctx, personID)
+		if err != nil {
+			return err
+		}
+		return writeAccounts(stdout, opt.output, *res)
+
+	case "block", "unblock":
+		accountID, err := argument(args, "account_id")
+		if err != nil {
+			return err
+		}
+		// a blocked account refuses the postings and the transfers, unblock makes it active again
+		status := "BLOCKED"
+		if command == "unblock" {
+			status = "ACTIVE"
+		}
+		res, err := client.SetAccountStatus(ctx, accountID, status)
+		if err != nil {
+			return err
+		}
+		return writeAccounts(stdout, opt.output, []model.Account{*res})
+
+	case "close":
+		accountID, err := argument(args, "account_id")
+		if err != nil {
+			return err
+		}
+		res, err := client.CloseAccount(ctx, accountID)
+		if err != nil {
+			return err
+		}
+		return writeAccounts(stdout, opt.output, []model.Account{*res})
+
+	case "adjust":
+		if len(args) < 3 {
+			return errors.New("adjust needs <account_id> <currency> <amount>")
+		}
+		amount, err := strconv.ParseFloat(args[2], 64)
+		if err != nil || amount == 0 {
+			return fmt.Errorf("amount %q invalid", args[2])
+		}
+		// a positive amount is a credit, a negative amount a debit
+		accountStatement := model.AccountStatement{AccountID: args[0], Currency: args[1], Amount: amount, Type: "CREDIT"}
+		if amount < 0 {
+			accountStatement.Type = "DEBIT"
+		}
+		flags := flag.NewFlagSet("adjust", flag.ContinueOnError)
+		transactionID := flags.String("transaction", "", "transaction_id, an adjustment is posted only once per transaction")
+		if err := flags.Parse(args[3:]); err != nil {
+			return err
+		}
+		if *transactionID != "" {
+			accountStatement.TransactionID = transactionID
+		}
+		res, err := client.PostAdjustment(ctx, &accountStatement)
+		if err != nil {
+			return err
+		}
+		return writeMoviment(stdout, opt.output, res)
+
+	case "reconcile":
+		accountID, err := argument(args, "account_id")
+		if err != nil {
+			return err
+		}
+		res, err := client.ReconcileAccount(ctx, accountID)
+		if err != nil {
+			return err
+		}
+		if err := writeReconciliations(stdout, opt.output, *res); err != nil {
+			return err
+		}
+		for _, reconciliation := range *res {
+			if !reconciliation.Reconciled {
+				return fmt.Errorf("account %s not reconciled in %s (difference %.2f)", accountID, reconciliation.Currency, reconciliation.Difference)
+			}
+		}
+		return nil
+
+	case "export":
+		accountID, err := argument(args, "account_id")
+		if err != nil {
+			return err
+		}
+		flags := flag.NewFlagSet("export", flag.ContinueOnError)
+		varFrom := flags.String("from", "", "first day (yyyy-mm-dd)")
+		varTo := flags.String("to", "", "last day, included (yyyy-mm-dd)")
+		file := flags.String("file", "", "write the statements into a file instead of the stdout")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		from, to, err := parsePeriod(*varFrom, *varTo)
+		if err != nil {
+			return err
+		}
+		res, err := client.ListAccountStatement(ctx, accountID, from, to)
+		if err != nil {
+			return err
+		}
+		if *file == "" {
+			return writeStatements(stdout, opt.output, *res)
+		}
+		f, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		if err := writeStatements(f, opt.output, *res); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+
+	default:
+		return fmt.Errorf("command %q not supported", command)
+	}
+}
+
+// About the first positional argument of a command
+func argument(args []string, name string) (string, error) {
+	if len(args) == 0 || args[0] == "" {
+		return "", fmt.Errorf("%s not informed", name)
+	}
+	return args[0], nil
+}
+
+// About the period [from, to) of the days informed, the last day is included (zero when not informed)
+func parsePeriod(varFrom string, varTo string) (time.Time, time.Time, error) {
+	var from, to time.Time
+	var err error
+	if varFrom != "" {
+		if from, err = time.Parse("2006-01-02", varFrom); err != nil {
+			return from, to, fmt.Errorf("from %q: %w", varFrom, erro.ErrBadRequest)
+		}
+	}
+	if varTo != "" {
+		if to, err = time.Parse("2006-01-02", varTo); err != nil {
+			return from, to, fmt.Errorf("to %q: %w", varTo, erro.ErrBadRequest)
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+	return from, to, nil
+}
+
+func envOrDefault(key string, value string) string {
+	if os.Getenv(key) != "" {
+		return os.Getenv(key)
+	}
+	return value
+}
@@ -0,0 +1,117 @@
+package main
+
+import(
+	"io"
+	"fmt"
+	"time"
+	"strings"
+	"encoding/csv"
+	"encoding/json"
+	"text/tabwriter"
+
+	"github.com/go-account/internal/core/model"
+)
+
+// About write a result as json (the value as it is) or as a table/csv of the columns
+func write(w io.Writer, output string, value interface{}, header []string, rows [][]string) error {
+	switch output {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case "csv":
+		writer := csv.NewWriter(w)
+		writer.Write(header)
+		writer.WriteAll(rows)
+		return writer.Error()
+	default:
+		writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, strings.Join(header, "\t"))
+		for _, row := range rows {
+			fmt.Fprintln(writer, strings.Join(row, "\t"))
+		}
+		return writer.Flush()
+	}
+}
+
+func writeAccounts(w io.Writer, output string, list_account []model.Account) error {
+	rows := [][]string{}
+	for _, account := range list_account {
+		rows = append(rows, []string{	account.AccountID,
+										account.PersonID,
+										account.ProductID,
+										account.Status,
+										account.TenantID,
+										formatTime(account.CreatedAt),
+									})
+	}
+	return write(w, output, list_account, []string{"ACCOUNT_ID", "PERSON_ID", "PRODUCT_ID", "STATUS", "TENANT_ID", "CREATED_AT"}, rows)
+}
+
+func writeMoviment(w io.Writer, output string, movimentAccount *model.MovimentAccount) error {
+	rows := [][]string{}
+	if movimentAccount.AccountStatement != nil {
+		for _, accountStatement := range *movimentAccount.AccountStatement {
+			rows = append(rows, statementRow(accountStatement))
+		}
+	}
+	if err := write(w, output, movimentAccount, statementHeader, rows); err != nil {
+		return err
+	}
+	// the balance after the adjustment, the json already has it
+	if output == "table" && movimentAccount.AccountBalance != nil {
+		fmt.Fprintf(w, "\nbalance %s %s\n", movimentAccount.AccountBalance.Currency, formatAmount(movimentAccount.AccountBalance.Amount))
+	}
+	return nil
+}
+
+func writeReconciliations(w io.Writer, output string, list_reconciliation []model.Reconciliation) error {
+	rows := [][]string{}
+	for _, reconciliation := range list_reconciliation {
+		rows = append(rows, []string{	reconciliation.AccountID,
+										reconciliation.Currency,
+										formatAmount(reconciliation.Balance),
+										formatAmount(reconciliation.StatementTotal),
+										fmt.Sprintf("%d", reconciliation.Statements),
+										formatAmount(reconciliation.Difference),
+										fmt.Sprintf("%t", reconciliation.Reconciled),
+									})
+	}
+	return write(w, output, list_reconciliation, []string{"ACCOUNT_ID", "CURRENCY", "BALANCE", "STATEMENT_TOTAL", "STATEMENTS", "DIFFERENCE", "RECONCILED"}, rows)
+}
+
+var statementHeader = []string{"ID", "ACCOUNT_ID", "TYPE", "CHARGED_AT", "CURRENCY", "AMOUNT", "TRANSACTION_ID"}
+
+func statementRow(accountStatement model.AccountStatement) []string {
+	transactionID := ""
+	if accountStatement.TransactionID != nil {
+		transactionID = *accountStatement.TransactionID
+	}
+	return []string{	fmt.Sprintf("%d", accountStatement.ID),
+						accountStatement.AccountID,
+						accountStatement.Type,
+						formatTime(accountStatement.ChargedAt),
+						accountStatement.Currency,
+						formatAmount(accountStatement.Amount),
+						transactionID,
+					}
+}
+
+func writeStatements(w io.Writer, output string, list_statement []model.AccountStatement) error {
+	rows := [][]string{}
+	for _, accountStatement := range list_statement {
+		rows = append(rows, statementRow(accountStatement))
+	}
+	return write(w, output, list_statement, statementHeader, rows)
+}
+
+func formatAmount(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
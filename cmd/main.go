@@ -12,10 +12,19 @@ import(
 
 	"github.com/go-account/internal/infra/configuration"
 	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/auth"
 	"github.com/go-account/internal/core/service"
+	"github.com/go-account/internal/core/port"
 	"github.com/go-account/internal/infra/server"
+	"github.com/go-account/internal/infra/security"
+	"github.com/go-account/internal/infra/ratelimit"
+	"github.com/go-account/internal/infra/event"
+	"github.com/go-account/internal/infra/webhook"
 	"github.com/go-account/internal/adapter/api"
 	"github.com/go-account/internal/adapter/database"
+	grpc_adapter "github.com/go-account/internal/adapter/grpc"
+	"github.com/go-account/internal/adapter/graph"
 	go_core_pg "github.com/eliezerraj/go-core/database/pg"  
 )
 
@@ -36,11 +45,33 @@ func init(){
 	infoPod, server := configuration.GetInfoPod()
 	configOTEL 		:= configuration.GetOtelEnv()
 	databaseConfig 	:= configuration.GetDatabaseEnv()
+	interestJob 	:= configuration.GetInterestJobEnv()
+	feeJob 			:= configuration.GetFeeJobEnv()
+	securityConfig 	:= configuration.GetSecurityEnv()
+	tlsConfig 		:= configuration.GetTlsEnv()
+	rateLimitConfig := configuration.GetRateLimitEnv()
+	graphQLConfig 	:= configuration.GetGraphQLEnv()
+	outboxConfig 	:= configuration.GetOutboxEnv()
+	webhookConfig 	:= configuration.GetWebhookEnv()
+	consumerConfig 	:= configuration.GetConsumerEnv()
+	eventStoreConfig := configuration.GetEventStoreEnv()
+	migrationConfig := configuration.GetMigrationEnv()
 
 	appServer.InfoPod = &infoPod
 	appServer.Server = &server
 	appServer.ConfigOTEL = &configOTEL
 	appServer.DatabaseConfig = &databaseConfig
+	appServer.InterestJob = &interestJob
+	appServer.FeeJob = &feeJob
+	appServer.SecurityConfig = &securityConfig
+	appServer.TlsConfig = &tlsConfig
+	appServer.RateLimitConfig = &rateLimitConfig
+	appServer.GraphQLConfig = &graphQLConfig
+	appServer.OutboxConfig = &outboxConfig
+	appServer.WebhookConfig = &webhookConfig
+	appServer.ConsumerConfig = &consumerConfig
+	appServer.EventStoreConfig = &eventStoreConfig
+	appServer.MigrationConfig = &migrationConfig
 }
 
 // About main
@@ -70,11 +101,132 @@ func main (){
 	}
 
 	// wire
-	database := database.NewWorkerRepository(&databasePGServer)
-	workerService := service.NewWorkerService(database)
+	workerRepository := database.NewWorkerRepository(&databasePGServer)
+
+	// schema migrations, the migrate subcommand exits after applying them
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(ctx, workerRepository, os.Args[2:]); err != nil {
+			childLogger.Error().Err(err).Msg("fatal error migrate")
+			os.Exit(1)
+		}
+		return
+	}
+	if appServer.MigrationConfig.MigrateOnStartup {
+		if _, err := workerRepository.MigrateUp(ctx); err != nil {
+			childLogger.Error().Err(err).Msg("fatal error apply the migrations")
+			panic(err)
+		}
+	}
+	if appServer.MigrationConfig.SchemaCheck {
+		if err := workerRepository.CheckSchemaVersion(ctx); err != nil {
+			childLogger.Error().Err(err).Msg("fatal error check the schema version")
+			panic(err)
+		}
+	}
+
+	var repository port.Repository
+	switch appServer.EventStoreConfig.Mode {
+	case "state":
+		repository = workerRepository
+	case "eventstore":
+		repository = database.NewEventStoreRepository(workerRepository, appServer.EventStoreConfig.SnapshotInterval)
+	default:
+		childLogger.Error().Str("mode", appServer.EventStoreConfig.Mode).Msg("fatal error unknown ACCOUNT_PERSISTENCE")
+		panic("unknown ACCOUNT_PERSISTENCE " + appServer.EventStoreConfig.Mode)
+	}
+	workerService := service.NewWorkerService(repository)
+	workerService.SetEventSource(appServer.InfoPod.PodName)
+	workerService.SetWebhookAllowHttp(appServer.WebhookConfig.AllowHttp)
 	httpRouters := api.NewHttpRouters(workerService, time.Duration(appServer.Server.CtxTimeout))
+	graphServer, err := graph.NewGraphServer(workerService, time.Duration(appServer.Server.CtxTimeout), appServer.GraphQLConfig)
+	if err != nil {
+		childLogger.Error().Err(err).Msg("fatal error create graphql schema")
+		panic(err)
+	}
 	httpServer := server.NewHttpAppServer(appServer.Server)
 
+	authMiddleware, err := security.NewAuthMiddleware(ctx, appServer.SecurityConfig)
+	if err != nil {
+		childLogger.Error().Err(err).Msg("fatal error load security config")
+		panic(err)
+	}
+	authMiddleware.SetRoleResolver(func(ctx context.Context, tenantID string, subject string) (string, error) {
+		// the role is read within the tenant of the principal, before it is put into the request
+		ctx = auth.WithPrincipal(ctx, &model.Principal{Subject: subject, TenantID: tenantID})
+		res, err := workerService.GetTenantRole(ctx, &model.TenantRole{TenantID: tenantID, Subject: subject})
+		if err == erro.ErrNotFound {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+		return res.Role, nil
+	})
+	authMiddleware.SetApiKeyVerifier(workerService.VerifyApiKey)
+
+	var tlsReloader *security.TlsReloader
+	if appServer.TlsConfig.Enabled {
+		tlsReloader, err = security.NewTlsReloader(appServer.TlsConfig)
+		if err != nil {
+			childLogger.Error().Err(err).Msg("fatal error load tls certificates")
+			panic(err)
+		}
+	}
+
+	// rate limit, the postgres store shares the buckets between the replicas
+	var rateLimitStore ratelimit.Store
+	if appServer.RateLimitConfig.Store == "postgres" {
+		rateLimitStore = ratelimit.StoreFunc(func(ctx context.Context, key string, rate float64, burst int) (ratelimit.Result, error) {
+			tokens, allowed, err := workerRepository.TakeRateLimitToken(ctx, key, rate, burst)
+			return ratelimit.Result{Allowed: allowed, Remaining: tokens}, err
+		})
+	}
+	rateLimiter := ratelimit.NewRateLimiter(appServer.RateLimitConfig, rateLimitStore)
+
+	// start interest accrual job
+	if appServer.InterestJob.Interval > 0 {
+		go workerService.InterestAccrualJob(ctx, time.Duration(appServer.InterestJob.Interval) * time.Second)
+	}
+
+	// start maintenance fee job
+	if appServer.FeeJob.Interval > 0 {
+		go workerService.MaintenanceFeeJob(ctx, time.Duration(appServer.FeeJob.Interval) * time.Second)
+	}
+
+	// start outbox relay
+	if appServer.OutboxConfig.RelayInterval > 0 {
+		publisher, err := event.NewPublisher(ctx, appServer.OutboxConfig)
+		if err != nil {
+			childLogger.Error().Err(err).Msg("fatal error create event publisher")
+			panic(err)
+		}
+		defer publisher.Close()
+		go workerService.OutboxRelayJob(ctx, publisher, time.Duration(appServer.OutboxConfig.RelayInterval) * time.Second, appServer.OutboxConfig)
+	}
+
+	// start webhook dispatch
+	if appServer.WebhookConfig.DispatchInterval > 0 {
+		go workerService.WebhookDispatchJob(ctx, webhook.NewHttpSender(appServer.WebhookConfig.AllowHttp), time.Duration(appServer.WebhookConfig.DispatchInterval) * time.Second, appServer.WebhookConfig)
+	}
+
+	// start posting consumer
+	if appServer.ConsumerConfig.Consumer != "" {
+		consumer, err := event.NewConsumer(ctx, appServer.ConsumerConfig)
+		if err != nil {
+			childLogger.Error().Err(err).Msg("fatal error create event consumer")
+			panic(err)
+		}
+		defer consumer.Close()
+		go workerService.ConsumePostingJob(ctx, appServer.ConsumerConfig.Name, consumer, time.Duration(appServer.ConsumerConfig.RetryInterval) * time.Second, appServer.ConsumerConfig.MaxAttempts)
+	}
+
+	// start grpc server
+	if appServer.Server.GrpcPort > 0 {
+		accountServer := grpc_adapter.NewAccountServer(workerService, time.Duration(appServer.Server.CtxTimeout))
+		grpcServer := server.NewGrpcAppServer(appServer.Server)
+		go grpcServer.StartGrpcAppServer(ctx, accountServer, authMiddleware, tlsReloader, rateLimiter)
+	}
+
 	// start server
-	httpServer.StartHttpAppServer(ctx, &httpRouters, &appServer)
+	httpServer.StartHttpAppServer(ctx, &httpRouters, graphServer, authMiddleware, tlsReloader, rateLimiter, &appServer)
 }
\ No newline at end of file
@@ -0,0 +1,53 @@
+package main
+
+import(
+	"fmt"
+	"context"
+	"strconv"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/adapter/database"
+)
+
+// About the migrate subcommand: migrate up | migrate down [steps] | migrate status
+func runMigrate(ctx context.Context, workerRepository *database.WorkerRepository, args []string) error {
+	childLogger.Info().Str("func","runMigrate").Strs("args", args).Send()
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	var list_migration *[]model.SchemaMigration
+	var err error
+	switch command {
+	case "up":
+		list_migration, err = workerRepository.MigrateUp(ctx)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil {
+				return fmt.Errorf("migrate down steps %q invalid", args[1])
+			}
+		}
+		list_migration, err = workerRepository.MigrateDown(ctx, steps)
+	case "status":
+		list_migration, err = workerRepository.ListSchemaMigration(ctx)
+	default:
+		return fmt.Errorf("migrate %q not supported (up, down [steps] or status)", command)
+	}
+
+	if list_migration != nil {
+		for _, schemaMigration := range *list_migration {
+			status := "pending"
+			if command != "status" {
+				status = command
+			} else if schemaMigration.AppliedAt != nil {
+				status = "applied " + schemaMigration.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d %-30s %s\n", schemaMigration.Version, schemaMigration.Name, status)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"fmt"
+	"time"
+	"context"
+	"net/http"
+	"encoding/json"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/validator"
+	"github.com/go-account/internal/core/auth"
+	"github.com/gorilla/mux"
+)
+
+// About add (or replace) the account number format of a tenant
+func (h *HttpRouters) AddAccountNumberFormat(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","AddAccountNumberFormat").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.AddAccountNumberFormat")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	// prepare body
+	accountNumberFormat := model.AccountNumberFormat{}
+	err := json.NewDecoder(req.Body).Decode(&accountNumberFormat)
+    if err != nil {
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+    }
+	defer req.Body.Close()
+
+	// the tenant comes from the caller identity
+	accountNumberFormat.TenantID, err = auth.ResolveTenant(ctx, accountNumberFormat.TenantID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	err = validator.Validate(&accountNumberFormat)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	// call service
+	res, err := h.workerService.AddAccountNumberFormat(ctx, &accountNumberFormat)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About get the account number format of a tenant
+func (h *HttpRouters) GetAccountNumberFormat(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","GetAccountNumberFormat").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.GetAccountNumberFormat")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	//parameters
+	vars := mux.Vars(req)
+
+	// the tenant comes from the caller identity
+	tenantID, err := auth.ResolveTenant(ctx, vars["id"])
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	accountNumberFormat := model.AccountNumberFormat{}
+	accountNumberFormat.TenantID = tenantID
+
+	// call service
+	res, err := h.workerService.GetAccountNumberFormat(ctx, &accountNumberFormat)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
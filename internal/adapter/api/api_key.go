@@ -0,0 +1,139 @@
+package api
+
+import (
+	"fmt"
+	"time"
+	"context"
+	"net/http"
+	"encoding/json"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/validator"
+	"github.com/go-account/internal/core/auth"
+	"github.com/gorilla/mux"
+)
+
+// About create an api key
+func (h *HttpRouters) AddApiKey(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","AddApiKey").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.AddApiKey")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	// prepare body
+	apiKey := model.ApiKey{}
+	err := json.NewDecoder(req.Body).Decode(&apiKey)
+    if err != nil {
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+    }
+	defer req.Body.Close()
+
+	// the tenant comes from the caller identity
+	apiKey.TenantID, err = auth.ResolveTenant(ctx, apiKey.TenantID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	err = validator.Validate(&apiKey)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	apiKey.UserLastUpdate = auth.UserLastUpdate(ctx)
+
+	// call service
+	res, err := h.workerService.AddApiKey(ctx, &apiKey)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About rotate or revoke an api key
+func (h *HttpRouters) updateApiKey(rw http.ResponseWriter, req *http.Request, name string, rotate bool) error {
+	childLogger.Info().Str("func",name).Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api." + name)
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	//parameters
+	vars := mux.Vars(req)
+
+	// the tenant comes from the caller identity
+	tenantID, err := auth.ResolveTenant(ctx, "")
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	apiKey := model.ApiKey{}
+	apiKey.KeyID = vars["id"]
+	apiKey.TenantID = tenantID
+	apiKey.UserLastUpdate = auth.UserLastUpdate(ctx)
+
+	// call service
+	var res *model.ApiKey
+	if rotate {
+		res, err = h.workerService.RotateApiKey(ctx, &apiKey)
+	} else {
+		res, err = h.workerService.RevokeApiKey(ctx, &apiKey)
+	}
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About rotate an api key (a new key is returned, the old one stops working)
+func (h *HttpRouters) RotateApiKey(rw http.ResponseWriter, req *http.Request) error {
+	return h.updateApiKey(rw, req, "RotateApiKey", true)
+}
+
+// About revoke an api key
+func (h *HttpRouters) RevokeApiKey(rw http.ResponseWriter, req *http.Request) error {
+	return h.updateApiKey(rw, req, "RevokeApiKey", false)
+}
+
+// About list all api keys of a tenant
+func (h *HttpRouters) ListApiKey(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListApiKey").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.ListApiKey")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	//parameters
+	vars := mux.Vars(req)
+
+	// the tenant comes from the caller identity
+	tenantID, err := auth.ResolveTenant(ctx, vars["id"])
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	apiKey := model.ApiKey{}
+	apiKey.TenantID = tenantID
+
+	// call service
+	res, err := h.workerService.ListApiKey(ctx, &apiKey)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
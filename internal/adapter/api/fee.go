@@ -0,0 +1,42 @@
+package api
+
+import (
+	"fmt"
+	"time"
+	"context"
+	"net/http"
+
+	"github.com/go-account/internal/core/erro"
+	"github.com/gorilla/mux"
+)
+
+// About charge the maintenance fee of a month (yyyy-mm)
+func (h *HttpRouters) MaintenanceFee(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","MaintenanceFee").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// the run goes over every balance, so it is not bound to the request timeout
+	ctx, cancel := context.WithCancel(req.Context())
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.MaintenanceFee")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	//parameters
+	vars := mux.Vars(req)
+	varMonth := vars["month"]
+
+	period, err := time.Parse("2006-01", varMonth)
+	if err != nil {
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+	}
+
+	// call service
+	res, err := h.workerService.MaintenanceFee(ctx, period)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
@@ -0,0 +1,366 @@
+package api
+
+import (
+	"time"
+	"context"
+	"strings"
+	"testing"
+	"net/http"
+	"encoding/json"
+	"net/http/httptest"
+
+	"github.com/go-account/internal/adapter/memory"
+	"github.com/go-account/internal/core/service"
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/auth"
+
+	"github.com/gorilla/mux"
+)
+
+const testTenant = "tenant-1"
+
+type failSender struct{}
+
+func (f *failSender) Send(ctx context.Context, url string, header map[string]string, body []byte) (int, error) {
+	return http.StatusServiceUnavailable, nil
+}
+
+// the error of a handler is written by the error middleware of the server
+func handleError(fn func(http.ResponseWriter, *http.Request) error) http.HandlerFunc {
+	return MiddleWareErrorHandler(fn)
+}
+
+// the routes of the server (internal/infra/server) without the security middlewares
+func newTestMux(h *HttpRouters) *mux.Router {
+	router := mux.NewRouter()
+	router.HandleFunc("/health", h.Health).Methods(http.MethodGet)
+	router.HandleFunc("/live", h.Live).Methods(http.MethodGet)
+	router.HandleFunc("/openapi.json", h.OpenApi).Methods(http.MethodGet)
+	router.HandleFunc("/header", handleError(h.Header)).Methods(http.MethodGet)
+	router.HandleFunc("/context", h.Context).Methods(http.MethodGet)
+	router.HandleFunc("/stat", h.Stat).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/accounts", handleError(h.AddAccount)).Methods(http.MethodPost)
+	router.HandleFunc("/v1/accounts/{id}", handleError(h.GetAccount)).Methods(http.MethodGet)
+	router.HandleFunc("/v1/accounts/{id}", handleError(h.PatchAccount)).Methods(http.MethodPatch)
+	router.HandleFunc("/v1/accounts/{id}", handleError(h.DeleteAccount)).Methods(http.MethodDelete)
+	router.HandleFunc("/v1/accounts/pk/{id}", handleError(h.GetAccountId)).Methods(http.MethodGet)
+	router.HandleFunc("/v1/persons/{id}/accounts", handleError(h.ListAccountPerPerson)).Methods(http.MethodGet)
+	router.HandleFunc("/v1/accounts/{id}/reconciliation", handleError(h.ReconcileAccount)).Methods(http.MethodGet)
+	router.HandleFunc("/v1/accounts/{id}/statements", handleError(h.ListAccountStatement)).Methods(http.MethodGet)
+
+	router.HandleFunc("/v1/webhooks", handleError(h.AddWebhookSubscription)).Methods(http.MethodPost)
+	router.HandleFunc("/v1/webhooks", handleError(h.ListWebhookSubscription)).Methods(http.MethodGet)
+	router.HandleFunc("/v1/webhooks/{id}", handleError(h.GetWebhookSubscription)).Methods(http.MethodGet)
+	router.HandleFunc("/v1/webhooks/{id}", handleError(h.DisableWebhookSubscription)).Methods(http.MethodDelete)
+	router.HandleFunc("/v1/webhooks/{id}/deliveries", handleError(h.ListWebhookDelivery)).Methods(http.MethodGet)
+	router.HandleFunc("/v1/webhooks/{id}/deliveries/{delivery_id}/retry", handleError(h.RetryWebhookDelivery)).Methods(http.MethodPost)
+
+	router.HandleFunc("/add", handleError(h.AddAccount)).Methods(http.MethodPost)
+	router.HandleFunc("/get/{id}", handleError(h.GetAccount)).Methods(http.MethodGet)
+	router.HandleFunc("/getId/{id}", handleError(h.GetAccountId)).Methods(http.MethodGet)
+	router.HandleFunc("/update/{id}", handleError(h.UpdateAccount)).Methods(http.MethodPost)
+	router.HandleFunc("/delete", handleError(h.DeleteAccount)).Methods(http.MethodPost)
+	router.HandleFunc("/delete/{id}", handleError(h.DeleteAccount)).Methods(http.MethodPost)
+	router.HandleFunc("/list/{id}", handleError(h.ListAccountPerPerson)).Methods(http.MethodGet)
+	router.HandleFunc("/interestAccrual/{date}", handleError(h.InterestAccrual)).Methods(http.MethodPost)
+	router.HandleFunc("/maintenanceFee/{month}", handleError(h.MaintenanceFee)).Methods(http.MethodPost)
+	router.HandleFunc("/posting", handleError(h.AddPosting)).Methods(http.MethodPost)
+	router.HandleFunc("/transfer", handleError(h.Transfer)).Methods(http.MethodPost)
+	router.HandleFunc("/feePreview", handleError(h.PreviewFee)).Methods(http.MethodPost)
+	router.HandleFunc("/product", handleError(h.AddAccountProduct)).Methods(http.MethodPost)
+	router.HandleFunc("/product/{tenant}/{id}", handleError(h.GetAccountProduct)).Methods(http.MethodGet)
+	router.HandleFunc("/listProduct/{id}", handleError(h.ListAccountProduct)).Methods(http.MethodGet)
+	router.HandleFunc("/accountNumberFormat", handleError(h.AddAccountNumberFormat)).Methods(http.MethodPost)
+	router.HandleFunc("/accountNumberFormat/{id}", handleError(h.GetAccountNumberFormat)).Methods(http.MethodGet)
+	router.HandleFunc("/tenantRole", handleError(h.AddTenantRole)).Methods(http.MethodPost)
+	router.HandleFunc("/tenantRole/{id}", handleError(h.ListTenantRole)).Methods(http.MethodGet)
+	router.HandleFunc("/apiKey", handleError(h.AddApiKey)).Methods(http.MethodPost)
+	router.HandleFunc("/apiKey/{id}/rotate", handleError(h.RotateApiKey)).Methods(http.MethodPost)
+	router.HandleFunc("/apiKey/{id}/revoke", handleError(h.RevokeApiKey)).Methods(http.MethodPost)
+	router.HandleFunc("/listApiKey/{id}", handleError(h.ListApiKey)).Methods(http.MethodGet)
+	return router
+}
+
+// the ids created by the seed
+type testSeed struct {
+	subscriptionID	string
+	disableID		string
+	deliveryID		string
+	rotateKeyID		string
+	revokeKeyID		string
+}
+
+// a service over the memory repository with a product, accounts, webhooks (one dead delivery) and api keys
+func newTestRouters(t *testing.T, ctxTimeout time.Duration) (*HttpRouters, *memory.MemoryRepository, testSeed) {
+	t.Helper()
+	ctx := auth.WithTenantBypass(context.Background())
+	repository := memory.NewMemoryRepository()
+	workerService := service.NewWorkerService(repository)
+	seed := testSeed{}
+
+	_, err := workerService.AddAccountProduct(ctx, &model.AccountProduct{ProductID: "CHECKING", Type: service.ProductChecking, Currencies: []string{"BRL"}, Overdraft: 100, TenantID: testTenant})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res_subscription, err := workerService.AddWebhookSubscription(ctx, &model.WebhookSubscription{TenantID: testTenant, URL: "https://hook.example.com/a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed.subscriptionID = res_subscription.SubscriptionID
+	res_subscription, err = workerService.AddWebhookSubscription(ctx, &model.WebhookSubscription{TenantID: testTenant, URL: "https://hook.example.com/b", EventTypes: []string{"go-account.account.closed.v1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed.disableID = res_subscription.SubscriptionID
+
+	for _, accountID := range []string{"ACC-1", "ACC-2", "ACC-D1", "ACC-D2", "ACC-D3"} {
+		_, err := workerService.AddAccount(ctx, &model.Account{AccountID: accountID, PersonID: "P-1", ProductID: "CHECKING", TenantID: testTenant})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := workerService.AddPosting(ctx, &model.AccountStatement{AccountID: "ACC-1", Type: service.StatementCredit, Currency: "BRL", Amount: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the first delivery is dead after a single attempt
+	if _, err := workerService.WebhookDispatch(ctx, &failSender{}, &model.WebhookConfig{BatchSize: 1, MaxAttempts: 1, Timeout: 1}); err != nil {
+		t.Fatal(err)
+	}
+	list_delivery, err := workerService.ListWebhookDelivery(ctx, &model.WebhookSubscription{SubscriptionID: seed.subscriptionID}, service.DeliveryDead)
+	if err != nil || len(*list_delivery) != 1 {
+		t.Fatalf("dead delivery %v %v", list_delivery, err)
+	}
+	seed.deliveryID = jsonNumber((*list_delivery)[0].ID)
+
+	for _, keyID := range []*string{&seed.rotateKeyID, &seed.revokeKeyID} {
+		res_apiKey, err := workerService.AddApiKey(ctx, &model.ApiKey{TenantID: testTenant, Name: "batch", Scopes: []string{"account:read"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		*keyID = res_apiKey.KeyID
+	}
+
+	httpRouters := NewHttpRouters(workerService, ctxTimeout)
+	return &httpRouters, repository, seed
+}
+
+func jsonNumber(id int64) string {
+	b, _ := json.Marshal(id)
+	return string(b)
+}
+
+type routeTest struct {
+	name		string
+	method		string
+	route		string
+	target		string
+	body		string
+	ctx			context.Context
+	status		int
+}
+
+// the cases of every route, the order matters (the cases change the seed)
+func routeTests(seed testSeed) []routeTest {
+	other := auth.WithPrincipal(context.Background(), &model.Principal{Subject: "user-b", TenantID: "tenant-2", Scopes: []string{"account:read"}})
+	reader := auth.WithPrincipal(context.Background(), &model.Principal{Subject: "user-a", TenantID: testTenant, Scopes: []string{"account:read"}})
+
+	return []routeTest{
+		{"health", http.MethodGet, "/health", "/health", "", nil, http.StatusOK},
+		{"live", http.MethodGet, "/live", "/live", "", nil, http.StatusOK},
+		{"openapi", http.MethodGet, "/openapi.json", "/openapi.json", "", nil, http.StatusOK},
+		{"header", http.MethodGet, "/header", "/header", "", nil, http.StatusOK},
+		{"context", http.MethodGet, "/context", "/context", "", nil, http.StatusOK},
+		{"stat", http.MethodGet, "/stat", "/stat", "", nil, http.StatusOK},
+
+		{"add account", http.MethodPost, "/v1/accounts", "/v1/accounts", `{"account_id":"ACC-3","person_id":"P-3","product_id":"CHECKING","tenant_id":"tenant-1"}`, nil, http.StatusOK},
+		{"add account bad json", http.MethodPost, "/v1/accounts", "/v1/accounts", `{`, nil, http.StatusBadRequest},
+		{"add account without person", http.MethodPost, "/v1/accounts", "/v1/accounts", `{"account_id":"ACC-4","product_id":"CHECKING","tenant_id":"tenant-1"}`, nil, http.StatusBadRequest},
+		{"add account unknown product", http.MethodPost, "/v1/accounts", "/v1/accounts", `{"account_id":"ACC-4","person_id":"P-4","product_id":"LOAN","tenant_id":"tenant-1"}`, nil, http.StatusBadRequest},
+		{"add account other tenant", http.MethodPost, "/v1/accounts", "/v1/accounts", `{"account_id":"ACC-4","person_id":"P-4","product_id":"CHECKING","tenant_id":"tenant-1"}`, other, http.StatusForbidden},
+		{"get account", http.MethodGet, "/v1/accounts/{id}", "/v1/accounts/ACC-1", "", nil, http.StatusOK},
+		{"get account unknown", http.MethodGet, "/v1/accounts/{id}", "/v1/accounts/ACC-9", "", nil, http.StatusNotFound},
+		{"get account other tenant", http.MethodGet, "/v1/accounts/{id}", "/v1/accounts/ACC-1", "", other, http.StatusNotFound},
+		{"patch account", http.MethodPatch, "/v1/accounts/{id}", "/v1/accounts/ACC-1", `{"person_id":"P-9"}`, nil, http.StatusOK},
+		{"patch account read only", http.MethodPatch, "/v1/accounts/{id}", "/v1/accounts/ACC-1", `{"product_id":"X"}`, nil, http.StatusBadRequest},
+		{"patch account unknown", http.MethodPatch, "/v1/accounts/{id}", "/v1/accounts/ACC-9", `{"person_id":"P-9"}`, nil, http.StatusNotFound},
+		{"delete account", http.MethodDelete, "/v1/accounts/{id}", "/v1/accounts/ACC-D1", "", nil, http.StatusOK},
+		{"delete account unknown", http.MethodDelete, "/v1/accounts/{id}", "/v1/accounts/ACC-D1", "", nil, http.StatusNotFound},
+		{"get account pk", http.MethodGet, "/v1/accounts/pk/{id}", "/v1/accounts/pk/1", "", nil, http.StatusOK},
+		{"get account pk not a number", http.MethodGet, "/v1/accounts/pk/{id}", "/v1/accounts/pk/x", "", nil, http.StatusBadRequest},
+		{"get account pk unknown", http.MethodGet, "/v1/accounts/pk/{id}", "/v1/accounts/pk/99", "", nil, http.StatusNotFound},
+		{"list person accounts", http.MethodGet, "/v1/persons/{id}/accounts", "/v1/persons/P-1/accounts", "", nil, http.StatusOK},
+		{"reconcile account", http.MethodGet, "/v1/accounts/{id}/reconciliation", "/v1/accounts/ACC-1/reconciliation", "", nil, http.StatusOK},
+		{"reconcile account unknown", http.MethodGet, "/v1/accounts/{id}/reconciliation", "/v1/accounts/ACC-9/reconciliation", "", nil, http.StatusNotFound},
+		{"reconcile account other tenant", http.MethodGet, "/v1/accounts/{id}/reconciliation", "/v1/accounts/ACC-1/reconciliation", "", other, http.StatusNotFound},
+		{"list statements", http.MethodGet, "/v1/accounts/{id}/statements", "/v1/accounts/ACC-1/statements?from=2026-01-01", "", nil, http.StatusOK},
+		{"list statements bad date", http.MethodGet, "/v1/accounts/{id}/statements", "/v1/accounts/ACC-1/statements?to=31-01-2026", "", nil, http.StatusBadRequest},
+		{"list statements inverted period", http.MethodGet, "/v1/accounts/{id}/statements", "/v1/accounts/ACC-1/statements?from=2026-02-01&to=2026-01-01", "", nil, http.StatusBadRequest},
+		{"list statements unknown", http.MethodGet, "/v1/accounts/{id}/statements", "/v1/accounts/ACC-9/statements", "", nil, http.StatusNotFound},
+
+		{"add webhook", http.MethodPost, "/v1/webhooks", "/v1/webhooks", `{"url":"https://hook.example.com/c","tenant_id":"tenant-1"}`, nil, http.StatusOK},
+		{"add webhook invalid url", http.MethodPost, "/v1/webhooks", "/v1/webhooks", `{"url":"hook","tenant_id":"tenant-1"}`, nil, http.StatusBadRequest},
+		{"list webhooks", http.MethodGet, "/v1/webhooks", "/v1/webhooks?tenant_id=tenant-1", "", nil, http.StatusOK},
+		{"get webhook", http.MethodGet, "/v1/webhooks/{id}", "/v1/webhooks/" + seed.subscriptionID, "", nil, http.StatusOK},
+		{"get webhook unknown", http.MethodGet, "/v1/webhooks/{id}", "/v1/webhooks/unknown", "", nil, http.StatusNotFound},
+		{"list deliveries", http.MethodGet, "/v1/webhooks/{id}/deliveries", "/v1/webhooks/" + seed.subscriptionID + "/deliveries?status=DEAD", "", nil, http.StatusOK},
+		{"list deliveries unknown status", http.MethodGet, "/v1/webhooks/{id}/deliveries", "/v1/webhooks/" + seed.subscriptionID + "/deliveries?status=LOST", "", nil, http.StatusBadRequest},
+		{"retry delivery", http.MethodPost, "/v1/webhooks/{id}/deliveries/{delivery_id}/retry", "/v1/webhooks/" + seed.subscriptionID + "/deliveries/" + seed.deliveryID + "/retry", "", nil, http.StatusOK},
+		{"retry delivery not dead", http.MethodPost, "/v1/webhooks/{id}/deliveries/{delivery_id}/retry", "/v1/webhooks/" + seed.subscriptionID + "/deliveries/" + seed.deliveryID + "/retry", "", nil, http.StatusConflict},
+		{"retry delivery not a number", http.MethodPost, "/v1/webhooks/{id}/deliveries/{delivery_id}/retry", "/v1/webhooks/" + seed.subscriptionID + "/deliveries/x/retry", "", nil, http.StatusBadRequest},
+		{"disable webhook", http.MethodDelete, "/v1/webhooks/{id}", "/v1/webhooks/" + seed.disableID, "", nil, http.StatusOK},
+		{"disable webhook twice", http.MethodDelete, "/v1/webhooks/{id}", "/v1/webhooks/" + seed.disableID, "", nil, http.StatusConflict},
+
+		{"legacy add account", http.MethodPost, "/add", "/add", `{"account_id":"ACC-5","person_id":"P-5","product_id":"CHECKING","tenant_id":"tenant-1"}`, nil, http.StatusOK},
+		{"legacy get account", http.MethodGet, "/get/{id}", "/get/ACC-1", "", nil, http.StatusOK},
+		{"legacy get account pk", http.MethodGet, "/getId/{id}", "/getId/1", "", nil, http.StatusOK},
+		{"legacy update account", http.MethodPost, "/update/{id}", "/update/ACC-1", `{"person_id":"P-1","tenant_id":"tenant-1"}`, nil, http.StatusOK},
+		{"legacy update account bad json", http.MethodPost, "/update/{id}", "/update/ACC-1", `[`, nil, http.StatusBadRequest},
+		{"legacy delete account body", http.MethodPost, "/delete", "/delete", `{"account_id":"ACC-D2"}`, nil, http.StatusOK},
+		{"legacy delete account without id", http.MethodPost, "/delete", "/delete", `{}`, nil, http.StatusBadRequest},
+		{"legacy delete account", http.MethodPost, "/delete/{id}", "/delete/ACC-D3", "", nil, http.StatusOK},
+		{"legacy list person accounts", http.MethodGet, "/list/{id}", "/list/P-1", "", nil, http.StatusOK},
+
+		{"interest accrual", http.MethodPost, "/interestAccrual/{date}", "/interestAccrual/2026-01-31", "", nil, http.StatusOK},
+		{"interest accrual bad date", http.MethodPost, "/interestAccrual/{date}", "/interestAccrual/31-01-2026", "", nil, http.StatusBadRequest},
+		{"maintenance fee", http.MethodPost, "/maintenanceFee/{month}", "/maintenanceFee/2026-01", "", nil, http.StatusOK},
+		{"maintenance fee bad month", http.MethodPost, "/maintenanceFee/{month}", "/maintenanceFee/01-2026", "", nil, http.StatusBadRequest},
+		{"posting", http.MethodPost, "/posting", "/posting", `{"account_id":"ACC-1","type_charge":"CREDIT","currency":"BRL","amount":10}`, nil, http.StatusOK},
+		{"posting negative credit", http.MethodPost, "/posting", "/posting", `{"account_id":"ACC-1","type_charge":"CREDIT","currency":"BRL","amount":-10}`, nil, http.StatusConflict},
+		{"posting insufficient funds", http.MethodPost, "/posting", "/posting", `{"account_id":"ACC-2","type_charge":"DEBIT","currency":"BRL","amount":-1000}`, nil, http.StatusConflict},
+		{"posting currency out of the product", http.MethodPost, "/posting", "/posting", `{"account_id":"ACC-1","type_charge":"CREDIT","currency":"USD","amount":10}`, nil, http.StatusConflict},
+		{"posting unknown type", http.MethodPost, "/posting", "/posting", `{"account_id":"ACC-1","type_charge":"FEE","currency":"BRL","amount":10}`, nil, http.StatusBadRequest},
+		{"posting unknown account", http.MethodPost, "/posting", "/posting", `{"account_id":"ACC-9","type_charge":"CREDIT","currency":"BRL","amount":10}`, nil, http.StatusNotFound},
+		{"transfer", http.MethodPost, "/transfer", "/transfer", `{"account_from":{"account_id":"ACC-1"},"account_to":{"account_id":"ACC-2"},"currency":"BRL","amount":1}`, nil, http.StatusOK},
+		{"transfer same account", http.MethodPost, "/transfer", "/transfer", `{"account_from":{"account_id":"ACC-1"},"account_to":{"account_id":"ACC-1"},"currency":"BRL","amount":1}`, nil, http.StatusConflict},
+		{"transfer without amount", http.MethodPost, "/transfer", "/transfer", `{"account_from":{"account_id":"ACC-1"},"account_to":{"account_id":"ACC-2"},"currency":"BRL"}`, nil, http.StatusBadRequest},
+		{"fee preview", http.MethodPost, "/feePreview", "/feePreview", `{"tenant_id":"tenant-1","operation":"POSTING","currency":"BRL","amount":10}`, nil, http.StatusOK},
+		{"fee preview unknown operation", http.MethodPost, "/feePreview", "/feePreview", `{"tenant_id":"tenant-1","operation":"REFUND","currency":"BRL","amount":10}`, nil, http.StatusBadRequest},
+
+		{"add product", http.MethodPost, "/product", "/product", `{"product_id":"WALLET","type_product":"WALLET","currencies":["BRL"],"tenant_id":"tenant-1"}`, nil, http.StatusOK},
+		{"add product unknown type", http.MethodPost, "/product", "/product", `{"product_id":"LOAN","type_product":"LOAN","currencies":["BRL"],"tenant_id":"tenant-1"}`, nil, http.StatusBadRequest},
+		{"get product", http.MethodGet, "/product/{tenant}/{id}", "/product/tenant-1/CHECKING", "", nil, http.StatusOK},
+		{"get product unknown", http.MethodGet, "/product/{tenant}/{id}", "/product/tenant-1/LOAN", "", nil, http.StatusNotFound},
+		{"get product other tenant", http.MethodGet, "/product/{tenant}/{id}", "/product/tenant-1/CHECKING", "", other, http.StatusForbidden},
+		{"list products", http.MethodGet, "/listProduct/{id}", "/listProduct/tenant-1", "", nil, http.StatusOK},
+		{"add account number format", http.MethodPost, "/accountNumberFormat", "/accountNumberFormat", `{"tenant_id":"tenant-2","prefix":"ACC","sequence_length":6,"check_digit":"LUHN"}`, nil, http.StatusOK},
+		{"add account number format without sequence", http.MethodPost, "/accountNumberFormat", "/accountNumberFormat", `{"tenant_id":"tenant-2","prefix":"ACC"}`, nil, http.StatusBadRequest},
+		{"get account number format", http.MethodGet, "/accountNumberFormat/{id}", "/accountNumberFormat/tenant-2", "", nil, http.StatusOK},
+		{"get account number format unknown", http.MethodGet, "/accountNumberFormat/{id}", "/accountNumberFormat/tenant-9", "", nil, http.StatusNotFound},
+		{"add tenant role", http.MethodPost, "/tenantRole", "/tenantRole", `{"tenant_id":"tenant-1","subject":"user-a","role":"admin"}`, nil, http.StatusOK},
+		{"add tenant role unknown role", http.MethodPost, "/tenantRole", "/tenantRole", `{"tenant_id":"tenant-1","subject":"user-a","role":"root"}`, nil, http.StatusBadRequest},
+		{"list tenant roles", http.MethodGet, "/tenantRole/{id}", "/tenantRole/tenant-1", "", nil, http.StatusOK},
+		{"add api key", http.MethodPost, "/apiKey", "/apiKey", `{"tenant_id":"tenant-1","name":"batch","scopes":["account:read"]}`, nil, http.StatusOK},
+		{"add api key over the caller scopes", http.MethodPost, "/apiKey", "/apiKey", `{"name":"batch","scopes":["account:write"]}`, reader, http.StatusForbidden},
+		{"rotate api key", http.MethodPost, "/apiKey/{id}/rotate", "/apiKey/" + seed.rotateKeyID + "/rotate", "", nil, http.StatusOK},
+		{"rotate api key unknown", http.MethodPost, "/apiKey/{id}/rotate", "/apiKey/unknown/rotate", "", nil, http.StatusNotFound},
+		{"revoke api key", http.MethodPost, "/apiKey/{id}/revoke", "/apiKey/" + seed.revokeKeyID + "/revoke", "", nil, http.StatusOK},
+		{"revoke api key twice", http.MethodPost, "/apiKey/{id}/revoke", "/apiKey/" + seed.revokeKeyID + "/revoke", "", nil, http.StatusConflict},
+		{"list api keys", http.MethodGet, "/listApiKey/{id}", "/listApiKey/tenant-1", "", nil, http.StatusOK},
+	}
+}
+
+func newRouteRequest(tt routeTest) *http.Request {
+	req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+	if tt.method == http.MethodPatch {
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+	}
+	// without a principal the request is the one of the authentication disabled (all the tenants)
+	if tt.ctx != nil {
+		req = req.WithContext(tt.ctx)
+	} else {
+		req = req.WithContext(auth.WithTenantBypass(req.Context()))
+	}
+	return req
+}
+
+func TestHttpRouters(t *testing.T) {
+	httpRouters, _, seed := newTestRouters(t, 5)
+	router := newTestMux(httpRouters)
+
+	for _, tt := range routeTests(seed) {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			router.ServeHTTP(rw, newRouteRequest(tt))
+			if rw.Code != tt.status {
+				t.Errorf("%s %s status %d want %d: %s", tt.method, tt.target, rw.Code, tt.status, rw.Body.String())
+			}
+		})
+	}
+}
+
+// every route of the OpenAPI document served by the HttpRouters has a test case (the graphql and the
+// root routes are served by other packages)
+func TestHttpRoutersCoverOpenApi(t *testing.T) {
+	spec := struct {
+		Paths	map[string]map[string]json.RawMessage `json:"paths"`
+	}{}
+	if err := json.Unmarshal(openApiSpec, &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	covered := map[string]bool{}
+	for _, tt := range routeTests(testSeed{}) {
+		covered[strings.ToLower(tt.method) + " " + tt.route] = true
+	}
+	for path, operations := range spec.Paths {
+		if path == "/" || path == "/info" || path == "/graphql" {
+			continue
+		}
+		for method := range operations {
+			if !covered[method + " " + path] {
+				t.Errorf("route %s %s has no test case", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
+
+// a request whose deadline is gone returns a timeout on every route that calls the service
+func TestHttpRoutersDeadline(t *testing.T) {
+	httpRouters, _, seed := newTestRouters(t, 5)
+	router := newTestMux(httpRouters)
+
+	ctx, cancel := context.WithDeadline(auth.WithTenantBypass(context.Background()), time.Now().Add(-time.Second))
+	defer cancel()
+
+	for _, tt := range routeTests(seed) {
+		// only the cases that reach the service (the others fail before it or do not call it)
+		if tt.status != http.StatusOK || tt.ctx != nil || tt.route == "/health" || tt.route == "/live" ||
+			tt.route == "/openapi.json" || tt.route == "/header" || tt.route == "/context" || tt.route == "/stat" {
+			continue
+		}
+		t.Run(tt.name, func(t *testing.T) {
+			tt.ctx = ctx
+			rw := httptest.NewRecorder()
+			router.ServeHTTP(rw, newRouteRequest(tt))
+			if rw.Code != http.StatusGatewayTimeout {
+				t.Errorf("%s %s status %d want %d: %s", tt.method, tt.target, rw.Code, http.StatusGatewayTimeout, rw.Body.String())
+			}
+		})
+	}
+}
+
+// the handler timeout answers while the repository is still locked by another transaction
+func TestHttpRoutersTimeout(t *testing.T) {
+	httpRouters, repository, _ := newTestRouters(t, 1)
+	router := newTestMux(httpRouters)
+
+	tx, err := repository.StartTx(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(context.Background())
+
+	start := time.Now()
+	rw := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/accounts", strings.NewReader(`{"account_id":"ACC-3","person_id":"P-3","product_id":"CHECKING","tenant_id":"tenant-1"}`))
+	router.ServeHTTP(rw, req.WithContext(auth.WithTenantBypass(req.Context())))
+
+	if rw.Code != http.StatusGatewayTimeout {
+		t.Errorf("status %d want %d: %s", rw.Code, http.StatusGatewayTimeout, rw.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed > 3 * time.Second {
+		t.Errorf("the timeout took %v", elapsed)
+	}
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"fmt"
+	"time"
+	"context"
+	"net/http"
+
+	"github.com/go-account/internal/core/erro"
+	"github.com/gorilla/mux"
+)
+
+// About run the interest accrual for a date (yyyy-mm-dd)
+func (h *HttpRouters) InterestAccrual(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","InterestAccrual").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	// the run goes over every balance, so it is not bound to the request timeout
+	ctx, cancel := context.WithCancel(req.Context())
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.InterestAccrual")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	//parameters
+	vars := mux.Vars(req)
+	varDate := vars["date"]
+
+	accrualDate, err := time.Parse("2006-01-02", varDate)
+	if err != nil {
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+	}
+
+	// call service
+	res, err := h.workerService.InterestAccrual(ctx, accrualDate)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
@@ -0,0 +1,19 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// About the OpenAPI document of every route, it must be updated with the routes (see server router_test)
+//
+//go:embed openapi.json
+var openApiSpec []byte
+
+// About serve the OpenAPI document
+func (h *HttpRouters) OpenApi(rw http.ResponseWriter, req *http.Request) {
+	childLogger.Info().Str("func","OpenApi").Send()
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Write(openApiSpec)
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"fmt"
+	"time"
+	"context"
+	"net/http"
+	"encoding/json"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/validator"
+	"github.com/go-account/internal/core/auth"
+)
+
+// About post a credit or debit into an account
+func (h *HttpRouters) AddPosting(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","AddPosting").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.AddPosting")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	// prepare body
+	accountStatement := model.AccountStatement{}
+	err := json.NewDecoder(req.Body).Decode(&accountStatement)
+    if err != nil {
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+    }
+	defer req.Body.Close()
+
+	err = validator.Validate(&accountStatement)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	// create channel for async result
+	resCh := make(chan result, 1)
+
+	// run async call
+	go func() {
+		res, err := h.workerService.AddPosting(ctx, &accountStatement)
+		resCh <- result{data: res, err: err}
+	}()
+
+	// wait for either: context timeout or service result
+	select {
+	case <-ctx.Done():
+		childLogger.Error().Str("trace_id", trace_id).Msg("AddPosting timeout or cancelled")
+		return h.ErrorHandler(trace_id, ctx.Err())
+
+	case r := <-resCh:
+		if r.err != nil {
+			return h.ErrorHandler(trace_id, r.err)
+		}
+		return core_json.WriteJSON(rw, http.StatusOK, r.data)
+	}
+}
+
+// About transfer an amount between two accounts
+func (h *HttpRouters) Transfer(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","Transfer").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.Transfer")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	// prepare body
+	transfer := model.Transfer{}
+	err := json.NewDecoder(req.Body).Decode(&transfer)
+    if err != nil {
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+    }
+	defer req.Body.Close()
+
+	err = validator.Validate(&transfer)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	// create channel for async result
+	resCh := make(chan result, 1)
+
+	// run async call
+	go func() {
+		res, err := h.workerService.Transfer(ctx, &transfer)
+		resCh <- result{data: res, err: err}
+	}()
+
+	// wait for either: context timeout or service result
+	select {
+	case <-ctx.Done():
+		childLogger.Error().Str("trace_id", trace_id).Msg("Transfer timeout or cancelled")
+		return h.ErrorHandler(trace_id, ctx.Err())
+
+	case r := <-resCh:
+		if r.err != nil {
+			return h.ErrorHandler(trace_id, r.err)
+		}
+		return core_json.WriteJSON(rw, http.StatusOK, r.data)
+	}
+}
+
+// About show the fee of an operation before commit it
+func (h *HttpRouters) PreviewFee(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","PreviewFee").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.PreviewFee")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	// prepare body
+	feePreview := model.FeePreview{}
+	err := json.NewDecoder(req.Body).Decode(&feePreview)
+    if err != nil {
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+    }
+	defer req.Body.Close()
+
+	// the tenant comes from the caller identity
+	feePreview.TenantID, err = auth.ResolveTenant(ctx, feePreview.TenantID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	err = validator.Validate(&feePreview)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	// call service
+	res, err := h.workerService.PreviewFee(ctx, &feePreview)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"fmt"
+	"time"
+	"context"
+	"net/http"
+	"encoding/json"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/validator"
+	"github.com/go-account/internal/core/auth"
+	"github.com/gorilla/mux"
+)
+
+// About add (or replace) an account product
+func (h *HttpRouters) AddAccountProduct(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","AddAccountProduct").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.AddAccountProduct")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	// prepare body
+	accountProduct := model.AccountProduct{}
+	err := json.NewDecoder(req.Body).Decode(&accountProduct)
+    if err != nil {
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+    }
+	defer req.Body.Close()
+
+	// the tenant comes from the caller identity
+	accountProduct.TenantID, err = auth.ResolveTenant(ctx, accountProduct.TenantID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	err = validator.Validate(&accountProduct)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	// call service
+	res, err := h.workerService.AddAccountProduct(ctx, &accountProduct)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About get an account product of a tenant
+func (h *HttpRouters) GetAccountProduct(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","GetAccountProduct").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.GetAccountProduct")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	//parameters
+	vars := mux.Vars(req)
+
+	// the tenant comes from the caller identity
+	tenantID, err := auth.ResolveTenant(ctx, vars["tenant"])
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	accountProduct := model.AccountProduct{}
+	accountProduct.TenantID = tenantID
+	accountProduct.ProductID = vars["id"]
+
+	// call service
+	res, err := h.workerService.GetAccountProduct(ctx, &accountProduct)
+	if err == erro.ErrProduct {
+		return h.ErrorHandler(trace_id, erro.ErrNotFound)
+	}
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About list all account products of a tenant
+func (h *HttpRouters) ListAccountProduct(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListAccountProduct").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.ListAccountProduct")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	//parameters
+	vars := mux.Vars(req)
+
+	// the tenant comes from the caller identity
+	tenantID, err := auth.ResolveTenant(ctx, vars["id"])
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	accountProduct := model.AccountProduct{}
+	accountProduct.TenantID = tenantID
+
+	// call service
+	res, err := h.workerService.ListAccountProduct(ctx, &accountProduct)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"fmt"
+	"time"
+	"context"
+	"net/http"
+	"encoding/json"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/validator"
+	"github.com/go-account/internal/core/auth"
+	"github.com/gorilla/mux"
+)
+
+// About add (or replace) the role of a subject into a tenant
+func (h *HttpRouters) AddTenantRole(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","AddTenantRole").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.AddTenantRole")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	// prepare body
+	tenantRole := model.TenantRole{}
+	err := json.NewDecoder(req.Body).Decode(&tenantRole)
+    if err != nil {
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+    }
+	defer req.Body.Close()
+
+	// the tenant comes from the caller identity
+	tenantRole.TenantID, err = auth.ResolveTenant(ctx, tenantRole.TenantID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	err = validator.Validate(&tenantRole)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	tenantRole.UserLastUpdate = auth.UserLastUpdate(ctx)
+
+	// call service
+	res, err := h.workerService.AddTenantRole(ctx, &tenantRole)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About list all roles of a tenant
+func (h *HttpRouters) ListTenantRole(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListTenantRole").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.ListTenantRole")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	//parameters
+	vars := mux.Vars(req)
+
+	// the tenant comes from the caller identity
+	tenantID, err := auth.ResolveTenant(ctx, vars["id"])
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	tenantRole := model.TenantRole{}
+	tenantRole.TenantID = tenantID
+
+	// call service
+	res, err := h.workerService.ListTenantRole(ctx, &tenantRole)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
@@ -9,11 +9,15 @@ import (
 	"reflect"	
 	"encoding/json"
 	"strings"
+	"errors"
+	"sort"
 
 	"github.com/rs/zerolog/log"
 	"github.com/go-account/internal/core/service"
 	"github.com/go-account/internal/core/model"
 	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/validator"
+	"github.com/go-account/internal/core/auth"
 	go_core_observ "github.com/eliezerraj/go-core/observability"
 	go_core_tools "github.com/eliezerraj/go-core/tools"
 
@@ -89,28 +93,72 @@ func (h *HttpRouters) Stat(rw http.ResponseWriter, req *http.Request) {
 	json.NewEncoder(rw).Encode(res)
 }
 
+// About the error of a handler, a payload validation also lists each failing field
+type APIError struct {
+	coreJson.APIError
+	Fields	[]erro.FieldError `json:"fields,omitempty"`
+}
+
+func (e *APIError) Unwrap() error {
+	return &e.APIError
+}
+
+// About write the error of a handler as json with the status of its APIError (500 for any other error)
+func MiddleWareErrorHandler(fn func(http.ResponseWriter, *http.Request) error) http.HandlerFunc {
+	return func(rw http.ResponseWriter, req *http.Request) {
+		err := fn(rw, req)
+		if err == nil {
+			return
+		}
+		var apiError *APIError
+		if errors.As(err, &apiError) {
+			core_json.WriteJSON(rw, apiError.StatusCode, apiError)
+			return
+		}
+		var coreApiError *coreJson.APIError
+		if errors.As(err, &coreApiError) {
+			core_json.WriteJSON(rw, coreApiError.StatusCode, coreApiError)
+			return
+		}
+		core_json.WriteJSON(rw, http.StatusInternalServerError, core_apiError.NewAPIError(err, "", http.StatusInternalServerError))
+	}
+}
+
 // About handle error
-func (h *HttpRouters) ErrorHandler(trace_id string, err error) *coreJson.APIError {
+func (h *HttpRouters) ErrorHandler(trace_id string, err error) *APIError {
 	if strings.Contains(err.Error(), "context deadline exceeded") {
     	err = erro.ErrTimeout
 	} 
+	var validationError *erro.ValidationError
+	if errors.As(err, &validationError) {
+		return &APIError{	APIError: core_apiError.NewAPIError(err, trace_id, http.StatusBadRequest),
+							Fields: validationError.Fields }
+	}
 	switch err {
 	case erro.ErrUpdate:
 		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusInternalServerError)
 	case erro.ErrTransInvalid:
 		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusConflict)
-	case erro.ErrInvalidAmount:
+	case erro.ErrInvalidAmount, erro.ErrInsufficientFunds, erro.ErrCurrency, erro.ErrAccountBlocked:
 		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusConflict)	
-	case erro.ErrBadRequest:
+	case erro.ErrBadRequest, erro.ErrDayCount, erro.ErrProduct, erro.ErrAccountNumber:
 		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusBadRequest)
 	case erro.ErrNotFound:
 		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusNotFound)
 	case erro.ErrTimeout:
 		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusGatewayTimeout)
+	case erro.ErrUnauthorized:
+		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusUnauthorized)
+	case erro.ErrHTTPForbiden:
+		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusForbidden)
+	case erro.ErrRateLimit:
+		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusTooManyRequests)
+	case erro.ErrMediaType:
+		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusUnsupportedMediaType)
 	default:
 		core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusInternalServerError)
 	}
-	return &core_apiError
+	return &APIError{APIError: core_apiError}
 }
 
 // About add an account
@@ -134,6 +182,18 @@ func (h *HttpRouters) AddAccount(rw http.ResponseWriter, req *http.Request) erro
     }
 	defer req.Body.Close()
 
+	// the tenant comes from the caller identity
+	account.TenantID, err = auth.ResolveTenant(ctx, account.TenantID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	err = validator.Validate(&account)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	account.UserLastUpdate = auth.UserLastUpdate(ctx)
+
 	//call service
 	/*res, err := h.workerService.AddAccount(ctx, &account)
 	if err != nil {
@@ -300,6 +360,18 @@ func (h *HttpRouters) UpdateAccount(rw http.ResponseWriter, req *http.Request) e
 	varID := vars["id"]
 	account.AccountID = varID
 
+	// the tenant comes from the caller identity
+	account.TenantID, err = auth.ResolveTenant(ctx, account.TenantID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	err = validator.Validate(&account)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	account.UserLastUpdate = auth.UserLastUpdate(ctx)
+
 	// call service
 	/*res, err := h.workerService.UpdateAccount(ctx, &account)
 	if err != nil {
@@ -332,6 +404,122 @@ func (h *HttpRouters) UpdateAccount(rw http.ResponseWriter, req *http.Request) e
 	}	
 }
 
+// About update only the fields informed in a json merge patch (RFC 7396) and return the updated account
+func (h *HttpRouters) PatchAccount(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","PatchAccount").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.PatchAccount")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	mediaType, _, _ := strings.Cut(req.Header.Get("Content-Type"), ";")
+	mediaType = strings.TrimSpace(mediaType)
+	if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		return h.ErrorHandler(trace_id, erro.ErrMediaType)
+	}
+
+	//parameters
+	vars := mux.Vars(req)
+	accountPatch, err := decodeAccountPatch(req, vars["id"])
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	// the tenant comes from the caller identity
+	accountPatch.TenantID, err = auth.ResolveTenant(ctx, accountPatch.TenantID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	err = validator.Validate(accountPatch)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	accountPatch.UserLastUpdate = auth.UserLastUpdate(ctx)
+
+	// create channel for async result
+	resCh := make(chan result, 1)
+
+	// run async call
+	go func() {
+		res, err := h.workerService.PatchAccount(ctx, accountPatch)
+		resCh <- result{data: res, err: err}
+	}()
+
+	// wait for either: context timeout or service result
+	select {
+	case <-ctx.Done():
+		childLogger.Error().Str("trace_id", trace_id).Msg("PatchAccount timeout or cancelled")
+		return h.ErrorHandler(trace_id, ctx.Err())
+
+	case r := <-resCh:
+		if r.err != nil {
+			return h.ErrorHandler(trace_id, r.err)
+		}
+		return core_json.WriteJSON(rw, http.StatusOK, r.data)
+	}	
+}
+
+// About read a merge patch body, a member set to null removes the field so it is refused for the required fields,
+// the account_id, tenant_id may be informed (they must not change) and any other member is read only
+func decodeAccountPatch(req *http.Request, accountID string) (*model.AccountPatch, error) {
+	members := map[string]json.RawMessage{}
+	err := json.NewDecoder(req.Body).Decode(&members)
+	if err != nil {
+		return nil, erro.ErrBadRequest
+	}
+	defer req.Body.Close()
+
+	accountPatch := model.AccountPatch{AccountID: accountID}
+	fields := []erro.FieldError{}
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := members[name]
+		isNull := string(value) == "null"
+		switch name {
+		case "person_id":
+			var personID string
+			if isNull || json.Unmarshal(value, &personID) != nil || personID == "" {
+				fields = append(fields, erro.FieldError{Field: name, Rule: "required", Message: "is required"})
+				continue
+			}
+			accountPatch.PersonID = &personID
+		case "status":
+			var status string
+			if isNull || json.Unmarshal(value, &status) != nil || status == "" {
+				fields = append(fields, erro.FieldError{Field: name, Rule: "required", Message: "is required"})
+				continue
+			}
+			accountPatch.Status = &status
+		case "tenant_id":
+			if !isNull && json.Unmarshal(value, &accountPatch.TenantID) != nil {
+				fields = append(fields, erro.FieldError{Field: name, Rule: "type", Message: "must be a string"})
+			}
+		case "account_id":
+			var id string
+			if isNull || json.Unmarshal(value, &id) != nil || id != accountID {
+				fields = append(fields, erro.FieldError{Field: name, Rule: "readonly", Message: "can not be changed"})
+			}
+		default:
+			fields = append(fields, erro.FieldError{Field: name, Rule: "readonly", Message: "can not be changed"})
+		}
+	}
+	if len(fields) > 0 {
+		return nil, &erro.ValidationError{Fields: fields}
+	}
+
+	return &accountPatch, nil
+}
+
 // About delete an account
 func (h *HttpRouters) DeleteAccount(rw http.ResponseWriter, req *http.Request) error {
 	childLogger.Info().Str("func","DeleteAccount").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
@@ -350,6 +538,15 @@ func (h *HttpRouters) DeleteAccount(rw http.ResponseWriter, req *http.Request) e
 	varID := vars["id"]
 	account.AccountID = varID
 
+	// the legacy /delete route has no path variable, the account_id comes in the body
+	if varID == "" {
+		err := json.NewDecoder(req.Body).Decode(&account)
+		if err != nil || account.AccountID == "" {
+			return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+		}
+		defer req.Body.Close()
+	}
+
 	// call service
 	res, err := h.workerService.DeleteAccount(ctx, &account)
 	if err != nil {
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"fmt"
+	"errors"
+	"context"
+	"testing"
+	"strings"
+	"net/http"
+	"encoding/json"
+	"net/http/httptest"
+
+	"github.com/go-account/internal/core/erro"
+)
+
+func TestDecodeAccountPatch(t *testing.T) {
+	tests := []struct {
+		name		string
+		body		string
+		personID	string
+		fields		int
+	}{
+		{"empty patch", `{}`, "", 0},
+		{"person", `{"person_id":"P-2"}`, "P-2", 0},
+		{"same account_id", `{"account_id":"ACC-1","person_id":"P-2"}`, "P-2", 0},
+		{"null person", `{"person_id":null}`, "", 1},
+		{"status", `{"status":"BLOCKED"}`, "", 0},
+		{"null status", `{"status":null}`, "", 1},
+		{"other account_id", `{"account_id":"ACC-2"}`, "", 1},
+		{"read only fields", `{"product_id":"X","created_at":"2026-01-01"}`, "", 2},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPatch, "/v1/accounts/ACC-1", strings.NewReader(tt.body))
+		accountPatch, err := decodeAccountPatch(req, "ACC-1")
+
+		if tt.fields > 0 {
+			var validationError *erro.ValidationError
+			if !errors.As(err, &validationError) || len(validationError.Fields) != tt.fields {
+				t.Errorf("%s: err %v, want %d fields", tt.name, err, tt.fields)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if tt.personID == "" && accountPatch.PersonID != nil {
+			t.Errorf("%s: person_id should not be informed", tt.name)
+		}
+		if tt.personID != "" && (accountPatch.PersonID == nil || *accountPatch.PersonID != tt.personID) {
+			t.Errorf("%s: person_id %v, want %s", tt.name, accountPatch.PersonID, tt.personID)
+		}
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	h := NewHttpRouters(nil, 1)
+	tests := []struct {
+		err		error
+		status	int
+	}{
+		{&erro.ValidationError{Fields: []erro.FieldError{{Field: "person_id", Rule: "required"}}}, http.StatusBadRequest},
+		{erro.ErrUpdate, http.StatusInternalServerError},
+		{erro.ErrTransInvalid, http.StatusConflict},
+		{erro.ErrInvalidAmount, http.StatusConflict},
+		{erro.ErrInsufficientFunds, http.StatusConflict},
+		{erro.ErrCurrency, http.StatusConflict},
+		{erro.ErrAccountBlocked, http.StatusConflict},
+		{erro.ErrBadRequest, http.StatusBadRequest},
+		{erro.ErrDayCount, http.StatusBadRequest},
+		{erro.ErrProduct, http.StatusBadRequest},
+		{erro.ErrAccountNumber, http.StatusBadRequest},
+		{erro.ErrNotFound, http.StatusNotFound},
+		{erro.ErrTimeout, http.StatusGatewayTimeout},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout},
+		{fmt.Errorf("query: %w", context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{erro.ErrUnauthorized, http.StatusUnauthorized},
+		{erro.ErrHTTPForbiden, http.StatusForbidden},
+		{erro.ErrRateLimit, http.StatusTooManyRequests},
+		{erro.ErrMediaType, http.StatusUnsupportedMediaType},
+		{erro.ErrInsert, http.StatusInternalServerError},
+		{errors.New("connection refused"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		apiError := h.ErrorHandler("trace-1", tt.err)
+		if apiError.StatusCode != tt.status {
+			t.Errorf("%v: status %d want %d", tt.err, apiError.StatusCode, tt.status)
+		}
+	}
+}
+
+func TestMiddleWareErrorHandler(t *testing.T) {
+	h := NewHttpRouters(nil, 1)
+	tests := []struct {
+		name	string
+		err		error
+		status	int
+		fields	int
+	}{
+		{"validation", h.ErrorHandler("trace-1", &erro.ValidationError{Fields: []erro.FieldError{{Field: "person_id", Rule: "required", Message: "is required"}, {Field: "currency", Rule: "currency", Message: "must be an ISO 4217 currency code"}}}), http.StatusBadRequest, 2},
+		{"not found", h.ErrorHandler("trace-1", erro.ErrNotFound), http.StatusNotFound, 0},
+		{"not an APIError", errors.New("connection refused"), http.StatusInternalServerError, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			MiddleWareErrorHandler(func(http.ResponseWriter, *http.Request) error { return tt.err })(rw, httptest.NewRequest(http.MethodGet, "/", nil))
+			if rw.Code != tt.status {
+				t.Errorf("status %d want %d", rw.Code, tt.status)
+			}
+			body := struct {
+				Fields	[]erro.FieldError `json:"fields"`
+			}{}
+			if err := json.NewDecoder(rw.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if len(body.Fields) != tt.fields {
+				t.Errorf("fields %+v want %d", body.Fields, tt.fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"fmt"
+	"time"
+	"context"
+	"net/http"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+	"github.com/gorilla/mux"
+)
+
+// About reconcile the balances of an account with its statements
+func (h *HttpRouters) ReconcileAccount(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ReconcileAccount").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.ReconcileAccount")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	//parameters
+	vars := mux.Vars(req)
+	account := model.Account{AccountID: vars["id"]}
+
+	// call service
+	res, err := h.workerService.ReconcileAccount(ctx, &account)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About list the statements of an account into a period, ?from=yyyy-mm-dd&to=yyyy-mm-dd (both days included, optional)
+func (h *HttpRouters) ListAccountStatement(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListAccountStatement").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.ListAccountStatement")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	//parameters
+	vars := mux.Vars(req)
+	account := model.Account{AccountID: vars["id"]}
+
+	var from, to time.Time
+	var err error
+	if varFrom := req.URL.Query().Get("from"); varFrom != "" {
+		from, err = time.Parse("2006-01-02", varFrom)
+		if err != nil {
+			return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+		}
+	}
+	if varTo := req.URL.Query().Get("to"); varTo != "" {
+		to, err = time.Parse("2006-01-02", varTo)
+		if err != nil {
+			return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+		}
+		// the service period is [from, to), the last day is included
+		to = to.AddDate(0, 0, 1)
+	}
+
+	// call service
+	res, err := h.workerService.ListAccountStatement(ctx, &account, from, to)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"fmt"
+	"time"
+	"context"
+	"strconv"
+	"net/http"
+	"encoding/json"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/validator"
+	"github.com/go-account/internal/core/auth"
+	"github.com/go-account/internal/core/service"
+	"github.com/gorilla/mux"
+)
+
+// About create a webhook subscription
+func (h *HttpRouters) AddWebhookSubscription(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","AddWebhookSubscription").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.AddWebhookSubscription")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	// prepare body
+	webhookSubscription := model.WebhookSubscription{}
+	err := json.NewDecoder(req.Body).Decode(&webhookSubscription)
+    if err != nil {
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+    }
+	defer req.Body.Close()
+
+	// the tenant comes from the caller identity
+	webhookSubscription.TenantID, err = auth.ResolveTenant(ctx, webhookSubscription.TenantID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	err = validator.Validate(&webhookSubscription)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+	webhookSubscription.UserLastUpdate = auth.UserLastUpdate(ctx)
+
+	// call service
+	res, err := h.workerService.AddWebhookSubscription(ctx, &webhookSubscription)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About list all webhook subscriptions of the tenant
+func (h *HttpRouters) ListWebhookSubscription(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListWebhookSubscription").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.ListWebhookSubscription")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	// the tenant comes from the caller identity
+	tenantID, err := auth.ResolveTenant(ctx, req.URL.Query().Get("tenant_id"))
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	webhookSubscription := model.WebhookSubscription{}
+	webhookSubscription.TenantID = tenantID
+
+	// call service
+	res, err := h.workerService.ListWebhookSubscription(ctx, &webhookSubscription)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About get a webhook subscription
+func (h *HttpRouters) GetWebhookSubscription(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","GetWebhookSubscription").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.GetWebhookSubscription")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	//parameters
+	vars := mux.Vars(req)
+
+	// the tenant comes from the caller identity
+	tenantID, err := auth.ResolveTenant(ctx, "")
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	webhookSubscription := model.WebhookSubscription{}
+	webhookSubscription.SubscriptionID = vars["id"]
+	webhookSubscription.TenantID = tenantID
+
+	// call service
+	res, err := h.workerService.GetWebhookSubscription(ctx, &webhookSubscription)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About disable a webhook subscription (the pending deliveries are canceled)
+func (h *HttpRouters) DisableWebhookSubscription(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","DisableWebhookSubscription").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.DisableWebhookSubscription")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	//parameters
+	vars := mux.Vars(req)
+
+	// the tenant comes from the caller identity
+	tenantID, err := auth.ResolveTenant(ctx, "")
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	webhookSubscription := model.WebhookSubscription{}
+	webhookSubscription.SubscriptionID = vars["id"]
+	webhookSubscription.TenantID = tenantID
+	webhookSubscription.UserLastUpdate = auth.UserLastUpdate(ctx)
+
+	// call service
+	res, err := h.workerService.DisableWebhookSubscription(ctx, &webhookSubscription)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About list the last deliveries of a subscription with their attempts, ?status= filters them
+func (h *HttpRouters) ListWebhookDelivery(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","ListWebhookDelivery").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.ListWebhookDelivery")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	//parameters
+	vars := mux.Vars(req)
+	status := req.URL.Query().Get("status")
+	switch status {
+	case "", service.DeliveryPending, service.DeliveryDelivered, service.DeliveryDead, service.DeliveryCanceled:
+	default:
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+	}
+
+	// the tenant comes from the caller identity
+	tenantID, err := auth.ResolveTenant(ctx, "")
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	webhookSubscription := model.WebhookSubscription{}
+	webhookSubscription.SubscriptionID = vars["id"]
+	webhookSubscription.TenantID = tenantID
+
+	// call service
+	res, err := h.workerService.ListWebhookDelivery(ctx, &webhookSubscription, status)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, res)
+}
+
+// About send a dead delivery back to the queue
+func (h *HttpRouters) RetryWebhookDelivery(rw http.ResponseWriter, req *http.Request) error {
+	childLogger.Info().Str("func","RetryWebhookDelivery").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), h.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.api.RetryWebhookDelivery")
+	defer span.End()
+	trace_id := fmt.Sprintf("%v", ctx.Value("trace-request-id"))
+
+	//parameters
+	vars := mux.Vars(req)
+	deliveryID, err := strconv.ParseInt(vars["delivery_id"], 10, 64)
+	if err != nil {
+		return h.ErrorHandler(trace_id, erro.ErrBadRequest)
+	}
+
+	// the tenant comes from the caller identity
+	tenantID, err := auth.ResolveTenant(ctx, "")
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	webhookSubscription := model.WebhookSubscription{}
+	webhookSubscription.SubscriptionID = vars["id"]
+	webhookSubscription.TenantID = tenantID
+
+	// call service
+	err = h.workerService.RetryWebhookDelivery(ctx, &webhookSubscription, deliveryID)
+	if err != nil {
+		return h.ErrorHandler(trace_id, err)
+	}
+
+	return core_json.WriteJSON(rw, http.StatusOK, model.WebhookDelivery{	ID: deliveryID,
+																				SubscriptionID: webhookSubscription.SubscriptionID,
+																				Status: service.DeliveryPending })
+}
@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"time"
+	"errors"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
+	"github.com/go-account/internal/core/erro"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// About create or replace the account number format of a tenant (the sequence is kept)
+func (w WorkerRepository) AddAccountNumberFormat(ctx context.Context, tx port.Tx, accountNumberFormat *model.AccountNumberFormat) (*model.AccountNumberFormat, error){
+	childLogger.Info().Str("func","AddAccountNumberFormat").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddAccountNumberFormat")
+	defer span.End()
+
+	//Prepare
+	var id int
+	accountNumberFormat.CreatedAt = time.Now()
+
+	// Query Execute
+	query := `INSERT INTO account_number_format (tenant_id,
+												prefix,
+												sequence_length,
+												check_digit,
+												iban,
+												country_code,
+												last_sequence,
+												created_at)
+				VALUES($1, $2, $3, $4, $5, $6, 0, $7)
+				ON CONFLICT (tenant_id) DO UPDATE
+				SET prefix = excluded.prefix,
+					sequence_length = excluded.sequence_length,
+					check_digit = excluded.check_digit,
+					iban = excluded.iban,
+					country_code = excluded.country_code,
+					updated_at = excluded.created_at
+				RETURNING id, last_sequence`
+
+	row := pgxTx(tx).QueryRow(ctx, query,	accountNumberFormat.TenantID,
+									accountNumberFormat.Prefix,
+									accountNumberFormat.SequenceLength,
+									accountNumberFormat.CheckDigit,
+									accountNumberFormat.Iban,
+									accountNumberFormat.CountryCode,
+									accountNumberFormat.CreatedAt)
+	if err := row.Scan(&id, &accountNumberFormat.LastSequence); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Set PK
+	accountNumberFormat.ID = id
+	return accountNumberFormat , nil
+}
+
+// About get the account number format of a tenant
+func (w WorkerRepository) GetAccountNumberFormat(ctx context.Context, accountNumberFormat *model.AccountNumberFormat) (*model.AccountNumberFormat, error){
+	childLogger.Info().Str("func","GetAccountNumberFormat").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetAccountNumberFormat")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_accountNumberFormat := model.AccountNumberFormat{}
+
+	// Query and Execute
+	query := `SELECT 	id,
+						tenant_id,
+						prefix,
+						sequence_length,
+						check_digit,
+						iban,
+						country_code,
+						last_sequence,
+						created_at,
+						updated_at
+				FROM account_number_format
+				WHERE tenant_id = $1`
+
+	rows, err := conn.Query(ctx, query, accountNumberFormat.TenantID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+    if err := rows.Err(); err != nil {
+		childLogger.Error().Err(err).Msg("fatal error closing rows")
+        return nil, errors.New(err.Error())
+    }
+
+	for rows.Next() {
+		err := rows.Scan( 	&res_accountNumberFormat.ID,
+							&res_accountNumberFormat.TenantID,
+							&res_accountNumberFormat.Prefix,
+							&res_accountNumberFormat.SequenceLength,
+							&res_accountNumberFormat.CheckDigit,
+							&res_accountNumberFormat.Iban,
+							&res_accountNumberFormat.CountryCode,
+							&res_accountNumberFormat.LastSequence,
+							&res_accountNumberFormat.CreatedAt,
+							&res_accountNumberFormat.UpdatedAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		return &res_accountNumberFormat, nil
+	}
+
+	return nil, erro.ErrNotFound
+}
+
+// About get the next account number sequence of a tenant (the row stays locked until the end of the transaction)
+func (w WorkerRepository) NextAccountNumberSequence(ctx context.Context, tx port.Tx, accountNumberFormat *model.AccountNumberFormat) (int64, error){
+	childLogger.Info().Str("func","NextAccountNumberSequence").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.NextAccountNumberSequence")
+	defer span.End()
+
+	// Query Execute
+	query := `UPDATE account_number_format
+				set last_sequence = last_sequence + 1
+				where tenant_id = $1
+				RETURNING last_sequence`
+
+	var sequence int64
+	row := pgxTx(tx).QueryRow(ctx, query, accountNumberFormat.TenantID)
+	if err := row.Scan(&sequence); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, erro.ErrNotFound
+		}
+		return 0, errors.New(err.Error())
+	}
+
+	accountNumberFormat.LastSequence = sequence
+	return sequence, nil
+}
@@ -0,0 +1,257 @@
+package database
+
+import (
+	"context"
+	"time"
+	"errors"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/auth"
+)
+
+// About create an api key (only the hash of the key is stored)
+func (w WorkerRepository) AddApiKey(ctx context.Context, tx port.Tx, apiKey *model.ApiKey) (*model.ApiKey, error){
+	childLogger.Info().Str("func","AddApiKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddApiKey")
+	defer span.End()
+
+	//Prepare
+	var id int
+	apiKey.CreatedAt = time.Now()
+
+	// Query Execute
+	query := `INSERT INTO api_key (	key_id,
+									tenant_id,
+									name,
+									key_hash,
+									scopes,
+									role,
+									status,
+									expires_at,
+									user_last_update,
+									created_at)
+				VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+
+	row := pgxTx(tx).QueryRow(ctx, query,	apiKey.KeyID,
+									apiKey.TenantID,
+									apiKey.Name,
+									apiKey.KeyHash,
+									apiKey.Scopes,
+									apiKey.Role,
+									apiKey.Status,
+									apiKey.ExpiresAt,
+									apiKey.UserLastUpdate,
+									apiKey.CreatedAt)
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Set PK
+	apiKey.ID = id
+	return apiKey , nil
+}
+
+// About get an api key by the key id (the lookup of the authentication is not scoped, there is no principal yet)
+func (w WorkerRepository) GetApiKey(ctx context.Context, apiKey *model.ApiKey) (*model.ApiKey, error){
+	childLogger.Info().Str("func","GetApiKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetApiKey")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_apiKey := model.ApiKey{}
+
+	// Query and Execute
+	query := `SELECT 	id,
+						key_id,
+						tenant_id,
+						name,
+						key_hash,
+						scopes,
+						coalesce(role,''),
+						status,
+						expires_at,
+						last_used_at,
+						user_last_update,
+						created_at,
+						updated_at
+				FROM api_key
+				WHERE key_id = $1
+				and ($3 or tenant_id = $2)`
+
+	rows, err := conn.Query(ctx, query, apiKey.KeyID, auth.TenantID(ctx), auth.TenantBypass(ctx))
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+    if err := rows.Err(); err != nil {
+		childLogger.Error().Err(err).Msg("fatal error closing rows")
+        return nil, errors.New(err.Error())
+    }
+
+	for rows.Next() {
+		err := rows.Scan( 	&res_apiKey.ID,
+							&res_apiKey.KeyID,
+							&res_apiKey.TenantID,
+							&res_apiKey.Name,
+							&res_apiKey.KeyHash,
+							&res_apiKey.Scopes,
+							&res_apiKey.Role,
+							&res_apiKey.Status,
+							&res_apiKey.ExpiresAt,
+							&res_apiKey.LastUsedAt,
+							&res_apiKey.UserLastUpdate,
+							&res_apiKey.CreatedAt,
+							&res_apiKey.UpdatedAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		return &res_apiKey, nil
+	}
+
+	return nil, erro.ErrNotFound
+}
+
+// About list all api keys of a tenant
+func (w WorkerRepository) ListApiKey(ctx context.Context, apiKey *model.ApiKey) (*[]model.ApiKey, error){
+	childLogger.Info().Str("func","ListApiKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListApiKey")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_apiKey := model.ApiKey{}
+	res_apiKey_list := []model.ApiKey{}
+
+	// Query and Execute
+	query := `SELECT 	id,
+						key_id,
+						tenant_id,
+						name,
+						scopes,
+						coalesce(role,''),
+						status,
+						expires_at,
+						last_used_at,
+						user_last_update,
+						created_at,
+						updated_at
+				FROM api_key
+				WHERE tenant_id = $1
+				order by id desc`
+
+	rows, err := conn.Query(ctx, query, apiKey.TenantID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+    if err := rows.Err(); err != nil {
+		childLogger.Error().Err(err).Msg("fatal error closing rows")
+        return nil, errors.New(err.Error())
+    }
+
+	for rows.Next() {
+		err := rows.Scan( 	&res_apiKey.ID,
+							&res_apiKey.KeyID,
+							&res_apiKey.TenantID,
+							&res_apiKey.Name,
+							&res_apiKey.Scopes,
+							&res_apiKey.Role,
+							&res_apiKey.Status,
+							&res_apiKey.ExpiresAt,
+							&res_apiKey.LastUsedAt,
+							&res_apiKey.UserLastUpdate,
+							&res_apiKey.CreatedAt,
+							&res_apiKey.UpdatedAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res_apiKey_list = append(res_apiKey_list, res_apiKey)
+	}
+
+	return &res_apiKey_list, nil
+}
+
+// About update the hash (rotation) and the status (revocation) of an api key
+func (w WorkerRepository) UpdateApiKey(ctx context.Context, tx port.Tx, apiKey *model.ApiKey) (int64, error){
+	childLogger.Info().Str("func","UpdateApiKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.UpdateApiKey")
+	defer span.End()
+
+	// Prepare
+	updateAt := time.Now()
+	apiKey.UpdatedAt = &updateAt
+
+	//Query Execute
+	query := `Update api_key
+				set key_hash = $1,
+					status = $2,
+					updated_at = $3,
+					user_last_update = $4
+				where key_id = $5
+				and tenant_id = $6`
+
+	row, err := pgxTx(tx).Exec(ctx, query, apiKey.KeyHash,
+									apiKey.Status,
+									apiKey.UpdatedAt,
+									apiKey.UserLastUpdate,
+									apiKey.KeyID,
+									apiKey.TenantID)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+
+	return row.RowsAffected() , nil
+}
+
+// About record the last use of an api key (at most once a minute per key)
+func (w WorkerRepository) UpdateApiKeyLastUsed(ctx context.Context, apiKey *model.ApiKey) (int64, error){
+	childLogger.Debug().Str("func","UpdateApiKeyLastUsed").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.UpdateApiKeyLastUsed")
+	defer span.End()
+
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	//Query Execute
+	query := `Update api_key
+				set last_used_at = $1
+				where key_id = $2
+				and (last_used_at is null or last_used_at < $1::timestamptz - interval '1 minute')`
+
+	row, err := conn.Exec(ctx, query, time.Now(), apiKey.KeyID)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+
+	return row.RowsAffected() , nil
+}
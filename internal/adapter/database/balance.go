@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/auth"
+)
+
+// About list the balances (one per currency) of an account
+func (w WorkerRepository) ListAccountBalance(ctx context.Context, account *model.Account) (*[]model.AccountBalance, error){
+	childLogger.Info().Str("func","ListAccountBalance").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListAccountBalance")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_accountBalance := model.AccountBalance{}
+	res_accountBalance_list := []model.AccountBalance{}
+
+	// Query and Execute
+	query := `SELECT 	ab.id,
+						a.account_id,
+						ab.fk_account_id,
+						ab.currency,
+						ab.amount,
+						ab.tenant_id,
+						ab.created_at,
+						ab.updated_at
+				FROM account_balance ab
+				JOIN account a on a.id = ab.fk_account_id
+				WHERE a.account_id = $1
+				and ($3 or a.tenant_id = $2)
+				order by ab.currency`
+
+	rows, err := conn.Query(ctx, query, account.AccountID, auth.TenantID(ctx), auth.TenantBypass(ctx))
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+    if err := rows.Err(); err != nil {
+		childLogger.Error().Err(err).Msg("fatal error closing rows")
+        return nil, errors.New(err.Error())
+    }
+
+	for rows.Next() {
+		err := rows.Scan( 	&res_accountBalance.ID,
+							&res_accountBalance.AccountID,
+							&res_accountBalance.FkAccountID,
+							&res_accountBalance.Currency,
+							&res_accountBalance.Amount,
+							&res_accountBalance.TenantID,
+							&res_accountBalance.CreatedAt,
+							&res_accountBalance.UpdatedAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res_accountBalance_list = append(res_accountBalance_list, res_accountBalance)
+	}
+
+	return &res_accountBalance_list, nil
+}
@@ -0,0 +1,202 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/auth"
+)
+
+// About list the balances of many accounts in one query (batch loaders), the result is keyed by account_id
+func (w WorkerRepository) ListAccountBalanceByAccountIDs(ctx context.Context, accountIDs []string) (map[string][]model.AccountBalance, error){
+	childLogger.Info().Str("func","ListAccountBalanceByAccountIDs").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("keys", len(accountIDs)).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListAccountBalanceByAccountIDs")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute
+	query := `SELECT 	ab.id,
+						a.account_id,
+						ab.fk_account_id,
+						ab.currency,
+						ab.amount,
+						ab.tenant_id,
+						ab.created_at,
+						ab.updated_at
+				FROM account_balance ab
+				JOIN account a on a.id = ab.fk_account_id
+				WHERE a.account_id = any($1)
+				and ($3 or a.tenant_id = $2)
+				order by a.account_id, ab.currency`
+
+	rows, err := conn.Query(ctx, query, accountIDs, auth.TenantID(ctx), auth.TenantBypass(ctx))
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	res := map[string][]model.AccountBalance{}
+	for rows.Next() {
+		accountBalance := model.AccountBalance{}
+		err := rows.Scan( 	&accountBalance.ID,
+							&accountBalance.AccountID,
+							&accountBalance.FkAccountID,
+							&accountBalance.Currency,
+							&accountBalance.Amount,
+							&accountBalance.TenantID,
+							&accountBalance.CreatedAt,
+							&accountBalance.UpdatedAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res[accountBalance.AccountID] = append(res[accountBalance.AccountID], accountBalance)
+	}
+    if err := rows.Err(); err != nil {
+        return nil, errors.New(err.Error())
+    }
+
+	return res, nil
+}
+
+// About list the last statements of many accounts in one query (batch loaders), the result is keyed by account_id
+func (w WorkerRepository) ListAccountStatementByAccountIDs(ctx context.Context, accountIDs []string, last int) (map[string][]model.AccountStatement, error){
+	childLogger.Info().Str("func","ListAccountStatementByAccountIDs").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("keys", len(accountIDs)).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListAccountStatementByAccountIDs")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute, the last n statements per account
+	query := `SELECT id, account_id, fk_account_id, type_charge, charged_at, currency, amount, tenant_id, transaction_id
+				FROM (	SELECT 	s.id,
+								a.account_id,
+								s.fk_account_id,
+								s.type_charge,
+								s.charged_at,
+								s.currency,
+								s.amount,
+								s.tenant_id,
+								s.transaction_id,
+								row_number() over (partition by s.fk_account_id order by s.charged_at desc, s.id desc) as rn
+						FROM account_statement s
+						JOIN account a on a.id = s.fk_account_id
+						WHERE a.account_id = any($1)
+						and ($4 or a.tenant_id = $2) ) st
+				WHERE rn <= $3
+				order by account_id, charged_at desc, id desc`
+
+	rows, err := conn.Query(ctx, query, accountIDs, auth.TenantID(ctx), last, auth.TenantBypass(ctx))
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	res := map[string][]model.AccountStatement{}
+	for rows.Next() {
+		accountStatement := model.AccountStatement{}
+		err := rows.Scan( 	&accountStatement.ID,
+							&accountStatement.AccountID,
+							&accountStatement.FkAccountID,
+							&accountStatement.Type,
+							&accountStatement.ChargedAt,
+							&accountStatement.Currency,
+							&accountStatement.Amount,
+							&accountStatement.TenantID,
+							&accountStatement.TransactionID,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res[accountStatement.AccountID] = append(res[accountStatement.AccountID], accountStatement)
+	}
+    if err := rows.Err(); err != nil {
+        return nil, errors.New(err.Error())
+    }
+
+	return res, nil
+}
+
+// About list the last transfers (sent or received) of many accounts in one query (batch loaders), the result is keyed by account_id
+func (w WorkerRepository) ListTransferByAccountIDs(ctx context.Context, accountIDs []string, last int) (map[string][]model.Transfer, error){
+	childLogger.Info().Str("func","ListTransferByAccountIDs").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("keys", len(accountIDs)).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListTransferByAccountIDs")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute, the last n transfers per account
+	query := `SELECT owner, id, account_from, account_to, type_charge, status, currency, amount, transfer_at
+				FROM (	SELECT 	a.account_id as owner,
+								t.id,
+								af.account_id as account_from,
+								at.account_id as account_to,
+								t.type_charge,
+								t.status,
+								t.currency,
+								t.amount,
+								t.transfer_at,
+								row_number() over (partition by a.id order by t.transfer_at desc, t.id desc) as rn
+						FROM account a
+						JOIN transfer_moviment t on t.fk_account_id_from = a.id or t.fk_account_id_to = a.id
+						JOIN account af on af.id = t.fk_account_id_from
+						JOIN account at on at.id = t.fk_account_id_to
+						WHERE a.account_id = any($1)
+						and ($4 or a.tenant_id = $2) ) tr
+				WHERE rn <= $3
+				order by owner, transfer_at desc, id desc`
+
+	rows, err := conn.Query(ctx, query, accountIDs, auth.TenantID(ctx), last, auth.TenantBypass(ctx))
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	res := map[string][]model.Transfer{}
+	for rows.Next() {
+		var owner string
+		transfer := model.Transfer{}
+		err := rows.Scan( 	&owner,
+							&transfer.ID,
+							&transfer.AccountFrom.AccountID,
+							&transfer.AccountTo.AccountID,
+							&transfer.Type,
+							&transfer.Status,
+							&transfer.Currency,
+							&transfer.Amount,
+							&transfer.TransferAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res[owner] = append(res[owner], transfer)
+	}
+    if err := rows.Err(); err != nil {
+        return nil, errors.New(err.Error())
+    }
+
+	return res, nil
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
+)
+
+// About record a consumed message, it returns false when the key was already consumed (duplicate)
+func (w WorkerRepository) AddConsumedMessage(ctx context.Context, tx port.Tx, consumedMessage *model.ConsumedMessage) (bool, error){
+	childLogger.Info().Str("func","AddConsumedMessage").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("message_key", consumedMessage.MessageKey).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddConsumedMessage")
+	defer span.End()
+
+	// Query Execute
+	query := `INSERT INTO consumed_message (	consumer,
+												message_key,
+												topic,
+												partition,
+												message_offset,
+												status,
+												error)
+				VALUES($1, $2, $3, $4, $5, $6, $7)
+				ON CONFLICT (consumer, message_key) DO NOTHING`
+
+	row, err := pgxTx(tx).Exec(ctx, query,	consumedMessage.Consumer,
+									consumedMessage.MessageKey,
+									consumedMessage.Topic,
+									consumedMessage.Partition,
+									consumedMessage.Offset,
+									consumedMessage.Status,
+									consumedMessage.Error)
+	if err != nil {
+		return false, errors.New(err.Error())
+	}
+
+	return row.RowsAffected() == 1, nil
+}
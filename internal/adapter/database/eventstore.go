@@ -0,0 +1,527 @@
+package database
+
+import (
+	"time"
+	"context"
+	"errors"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/auth"
+	"github.com/go-account/internal/core/aggregate"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// About the namespace of the advisory locks of the account streams
+const streamLockNamespace = 7305002
+
+// About the queries that run on a transaction or on a connection
+type querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+// About the event store mode, the account and its balances are rebuilt from the account stream
+// (from the last snapshot). The writes of the account aggregate append an event and update the
+// state tables (read model) in the same transaction, all the other methods are the WorkerRepository ones
+type EventStoreRepository struct {
+	*WorkerRepository
+	snapshotInterval	int
+}
+
+// Initialize repository
+func NewEventStoreRepository(workerRepository *WorkerRepository, snapshotInterval int) *EventStoreRepository{
+	childLogger.Info().Str("func","NewEventStoreRepository").Int("snapshotInterval", snapshotInterval).Send()
+
+	return &EventStoreRepository{
+		WorkerRepository: workerRepository,
+		snapshotInterval: snapshotInterval,
+	}
+}
+
+// About load the state of an account from the last snapshot and the events after it
+func (e EventStoreRepository) loadState(ctx context.Context, q querier, aggregateID string) (*aggregate.AccountState, error){
+	// Snapshot
+	var accountSnapshot *model.AccountSnapshot
+	rows, err := q.Query(ctx, `SELECT aggregate_id, tenant_id, version, state, created_at
+								FROM account_snapshot
+								WHERE aggregate_id = $1`, aggregateID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	for rows.Next() {
+		res_snapshot := model.AccountSnapshot{}
+		if err := rows.Scan(&res_snapshot.AggregateID,
+							&res_snapshot.TenantID,
+							&res_snapshot.Version,
+							&res_snapshot.State,
+							&res_snapshot.CreatedAt); err != nil {
+			rows.Close()
+			return nil, errors.New(err.Error())
+		}
+		accountSnapshot = &res_snapshot
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	version := 0
+	if accountSnapshot != nil {
+		version = accountSnapshot.Version
+	}
+
+	// Events after the snapshot
+	rows, err = q.Query(ctx, `SELECT id, aggregate_id, tenant_id, version, event_type, data, created_at
+								FROM account_event_stream
+								WHERE aggregate_id = $1
+								and version > $2
+								order by version`, aggregateID, version)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	list_event := []model.StreamEvent{}
+	for rows.Next() {
+		res_event := model.StreamEvent{}
+		if err := rows.Scan(&res_event.ID,
+							&res_event.AggregateID,
+							&res_event.TenantID,
+							&res_event.Version,
+							&res_event.EventType,
+							&res_event.Data,
+							&res_event.CreatedAt); err != nil {
+			return nil, errors.New(err.Error())
+		}
+		list_event = append(list_event, res_event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return aggregate.Rebuild(accountSnapshot, list_event)
+}
+
+// About lock the stream of an account until the end of the transaction and load its state
+func (e EventStoreRepository) lockStream(ctx context.Context, tx port.Tx, aggregateID string) (*aggregate.AccountState, error){
+	if _, err := pgxTx(tx).Exec(ctx, `SELECT pg_advisory_xact_lock($1, hashtext($2))`, streamLockNamespace, aggregateID); err != nil {
+		return nil, errors.New(err.Error())
+	}
+	return e.loadState(ctx, pgxTx(tx), aggregateID)
+}
+
+// About lock and load the state of an account. An account created before the event store mode has
+// no stream, it is imported from the state tables
+func (e EventStoreRepository) lockState(ctx context.Context, tx port.Tx, aggregateID string) (*aggregate.AccountState, error){
+	accountState, err := e.lockStream(ctx, tx, aggregateID)
+	if err != nil {
+		return nil, err
+	}
+	if accountState.Version > 0 {
+		return accountState, nil
+	}
+
+	// Import the account (if it exists) with the current balances
+	res_account := model.Account{}
+	err = pgxTx(tx).QueryRow(ctx, `SELECT id,
+								account_id,
+								person_id,
+								created_at,
+								updated_at,
+								tenant_id,
+								user_last_update,
+								coalesce(product_id,''),
+								status
+							FROM account
+							WHERE account_id = $1`, aggregateID).Scan(	&res_account.ID,
+																		&res_account.AccountID,
+																		&res_account.PersonID,
+																		&res_account.CreatedAt,
+																		&res_account.UpdatedAt,
+																		&res_account.TenantID,
+																		&res_account.UserLastUpdate,
+																		&res_account.ProductID,
+																		&res_account.Status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return accountState, nil
+		}
+		return nil, errors.New(err.Error())
+	}
+
+	rows, err := pgxTx(tx).Query(ctx, `SELECT 	id,
+										fk_account_id,
+										currency,
+										amount,
+										tenant_id,
+										created_at,
+										updated_at
+								FROM account_balance
+								WHERE fk_account_id = $1
+								order by currency`, res_account.ID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	list_balance := []model.AccountBalance{}
+	for rows.Next() {
+		res_accountBalance := model.AccountBalance{AccountID: res_account.AccountID}
+		if err := rows.Scan(&res_accountBalance.ID,
+							&res_accountBalance.FkAccountID,
+							&res_accountBalance.Currency,
+							&res_accountBalance.Amount,
+							&res_accountBalance.TenantID,
+							&res_accountBalance.CreatedAt,
+							&res_accountBalance.UpdatedAt); err != nil {
+			rows.Close()
+			return nil, errors.New(err.Error())
+		}
+		list_balance = append(list_balance, res_accountBalance)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	childLogger.Info().Str("account_id", aggregateID).Int("balances", len(list_balance)).Msg("import the account into the event store")
+
+	if err := e.openStream(ctx, tx, accountState, &res_account); err != nil {
+		return nil, err
+	}
+	for _, accountBalance := range list_balance {
+		if err := e.appendEvent(ctx, tx, accountState, aggregate.BalanceOpened, aggregate.BalanceOpenedData{Balance: accountBalance}); err != nil {
+			return nil, err
+		}
+	}
+	return accountState, nil
+}
+
+// About start the stream of an account, AccountOpened
+func (e EventStoreRepository) openStream(ctx context.Context, tx port.Tx, accountState *aggregate.AccountState, account *model.Account) error{
+	streamEvent, err := aggregate.NewStreamEvent(account.AccountID, account.TenantID, accountState.Version + 1, aggregate.AccountOpened, aggregate.AccountOpenedData{Account: *account})
+	if err != nil {
+		return err
+	}
+	return e.insertEvent(ctx, tx, accountState, streamEvent)
+}
+
+// About append the next event of the stream of an opened account
+func (e EventStoreRepository) appendEvent(ctx context.Context, tx port.Tx, accountState *aggregate.AccountState, eventType string, data interface{}) error{
+	streamEvent, err := aggregate.NewStreamEvent(accountState.Account.AccountID, accountState.Account.TenantID, accountState.Version + 1, eventType, data)
+	if err != nil {
+		return err
+	}
+	return e.insertEvent(ctx, tx, accountState, streamEvent)
+}
+
+// About store an event, it is applied into the state first (so an event not allowed is never stored)
+// and a snapshot is written each snapshot interval
+func (e EventStoreRepository) insertEvent(ctx context.Context, tx port.Tx, accountState *aggregate.AccountState, streamEvent *model.StreamEvent) error{
+	childLogger.Info().Str("func","insertEvent").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("event_type", streamEvent.EventType).Send()
+
+	if err := accountState.Apply(streamEvent); err != nil {
+		return err
+	}
+	streamEvent.CreatedAt = time.Now()
+
+	query := `INSERT INTO account_event_stream (aggregate_id,
+												tenant_id,
+												version,
+												event_type,
+												data,
+												created_at)
+				VALUES($1, $2, $3, $4, $5, $6) RETURNING id`
+
+	row := pgxTx(tx).QueryRow(ctx, query,	streamEvent.AggregateID,
+									streamEvent.TenantID,
+									streamEvent.Version,
+									streamEvent.EventType,
+									streamEvent.Data,
+									streamEvent.CreatedAt)
+	if err := row.Scan(&streamEvent.ID); err != nil {
+		return errors.New(err.Error())
+	}
+
+	if e.snapshotInterval <= 0 || accountState.Version % e.snapshotInterval != 0 {
+		return nil
+	}
+
+	accountSnapshot, err := accountState.Snapshot()
+	if err != nil {
+		return err
+	}
+	query = `INSERT INTO account_snapshot (aggregate_id, tenant_id, version, state, created_at)
+				VALUES($1, $2, $3, $4, $5)
+				ON CONFLICT (aggregate_id) DO UPDATE
+				set version = excluded.version,
+					state = excluded.state,
+					created_at = excluded.created_at`
+
+	_, err = pgxTx(tx).Exec(ctx, query,	accountSnapshot.AggregateID,
+									accountSnapshot.TenantID,
+									accountSnapshot.Version,
+									accountSnapshot.State,
+									streamEvent.CreatedAt)
+	if err != nil {
+		return errors.New(err.Error())
+	}
+	return nil
+}
+
+// About create an account, the stream starts with AccountOpened
+func (e EventStoreRepository) AddAccount(ctx context.Context, tx port.Tx, account *model.Account) (*model.Account, error){
+	childLogger.Info().Str("func","AddAccount").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "eventstore.AddAccount")
+	defer span.End()
+
+	res, err := e.WorkerRepository.AddAccount(ctx, tx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	accountState, err := e.lockStream(ctx, tx, res.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.openStream(ctx, tx, accountState, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// About open an account balance, BalanceOpened
+func (e EventStoreRepository) AddAccountBalance(ctx context.Context, tx port.Tx, accountBalance *model.AccountBalance) (*model.AccountBalance, error){
+	childLogger.Info().Str("func","AddAccountBalance").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "eventstore.AddAccountBalance")
+	defer span.End()
+
+	accountState, err := e.lockState(ctx, tx, accountBalance.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if accountState.Version == 0 {
+		return nil, erro.ErrNotFound
+	}
+
+	res, err := e.WorkerRepository.AddAccountBalance(ctx, tx, accountBalance)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.appendEvent(ctx, tx, accountState, aggregate.BalanceOpened, aggregate.BalanceOpenedData{Balance: *res}); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About update the informed fields of an account, AccountUpdated
+func (e EventStoreRepository) PatchAccount(ctx context.Context, tx port.Tx, accountPatch *model.AccountPatch) (*model.Account, error){
+	childLogger.Info().Str("func","PatchAccount").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "eventstore.PatchAccount")
+	defer span.End()
+
+	accountState, err := e.lockState(ctx, tx, accountPatch.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := e.WorkerRepository.PatchAccount(ctx, tx, accountPatch)
+	if err != nil {
+		return nil, err
+	}
+
+	err = e.appendEvent(ctx, tx, accountState, aggregate.AccountUpdated, aggregate.AccountUpdatedData{	PersonID: res.PersonID,
+																										Status: res.Status,
+																										UpdatedAt: *res.UpdatedAt,
+																										UserLastUpdate: res.UserLastUpdate })
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About delete an account, AccountClosed (the stream is kept)
+func (e EventStoreRepository) DeleteAccount(ctx context.Context, tx port.Tx, account *model.Account) (bool, error){
+	childLogger.Info().Str("func","DeleteAccount").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "eventstore.DeleteAccount")
+	defer span.End()
+
+	accountState, err := e.lockState(ctx, tx, account.AccountID)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := e.WorkerRepository.DeleteAccount(ctx, tx, account)
+	if err != nil {
+		return false, err
+	}
+
+	if accountState.Version > 0 && !accountState.Closed {
+		if err := e.appendEvent(ctx, tx, accountState, aggregate.AccountClosed, aggregate.AccountClosedData{ClosedAt: time.Now()}); err != nil {
+			return false, err
+		}
+	}
+	return res, nil
+}
+
+// About add an amount into an account balance, BalancePosted
+func (e EventStoreRepository) UpdateAccountBalanceAmount(ctx context.Context, tx port.Tx, accountBalance *model.AccountBalance) (int64, error){
+	childLogger.Info().Str("func","UpdateAccountBalanceAmount").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "eventstore.UpdateAccountBalanceAmount")
+	defer span.End()
+
+	// the stream of the balance
+	var aggregateID, currency string
+	err := pgxTx(tx).QueryRow(ctx, `SELECT a.account_id, ab.currency
+								FROM account_balance ab
+								JOIN account a on a.id = ab.fk_account_id
+								WHERE ab.id = $1`, accountBalance.ID).Scan(&aggregateID, &currency)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, errors.New(err.Error())
+	}
+
+	accountState, err := e.lockState(ctx, tx, aggregateID)
+	if err != nil {
+		return 0, err
+	}
+
+	res, err := e.WorkerRepository.UpdateAccountBalanceAmount(ctx, tx, accountBalance)
+	if err != nil {
+		return 0, err
+	}
+
+	err = e.appendEvent(ctx, tx, accountState, aggregate.BalancePosted, aggregate.BalancePostedData{	BalanceID: accountBalance.ID,
+																										Currency: currency,
+																										Amount: accountBalance.Amount,
+																										TransactionID: accountBalance.TransactionID,
+																										PostedAt: *accountBalance.UpdatedAt })
+	if err != nil {
+		return 0, err
+	}
+	return res, nil
+}
+
+// About get an account rebuilt from its stream, an account without stream is read from the state tables
+func (e EventStoreRepository) GetAccount(ctx context.Context, account *model.Account) (*model.Account, error){
+	childLogger.Info().Str("func","GetAccount").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "eventstore.GetAccount")
+	defer span.End()
+
+	accountState, err := e.readState(ctx, account.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if accountState.Version == 0 {
+		return e.WorkerRepository.GetAccount(ctx, account)
+	}
+	if accountState.Closed {
+		return nil, erro.ErrNotFound
+	}
+
+	return &accountState.Account, nil
+}
+
+// About lock the stream of an account until the end of the transaction and get the account rebuilt from it
+func (e EventStoreRepository) GetAccountForUpdate(ctx context.Context, tx port.Tx, account *model.Account) (*model.Account, error){
+	childLogger.Info().Str("func","GetAccountForUpdate").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "eventstore.GetAccountForUpdate")
+	defer span.End()
+
+	accountState, err := e.lockState(ctx, tx, account.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if accountState.Version == 0 || accountState.Closed {
+		return nil, erro.ErrNotFound
+	}
+	if !auth.TenantBypass(ctx) && accountState.Account.TenantID != auth.TenantID(ctx) {
+		return nil, erro.ErrNotFound
+	}
+
+	res_account := accountState.Account
+	return &res_account, nil
+}
+
+// About list the balances of an account rebuilt from its stream
+func (e EventStoreRepository) ListAccountBalance(ctx context.Context, account *model.Account) (*[]model.AccountBalance, error){
+	childLogger.Info().Str("func","ListAccountBalance").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "eventstore.ListAccountBalance")
+	defer span.End()
+
+	accountState, err := e.readState(ctx, account.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if accountState.Version == 0 {
+		return e.WorkerRepository.ListAccountBalance(ctx, account)
+	}
+
+	res_accountBalance_list := []model.AccountBalance{}
+	if !accountState.Closed {
+		res_accountBalance_list = append(res_accountBalance_list, accountState.Balances...)
+	}
+	return &res_accountBalance_list, nil
+}
+
+// About get an account balance and lock it until the end of the transaction, the amount is the one of the stream
+func (e EventStoreRepository) GetAccountBalanceForUpdate(ctx context.Context, tx port.Tx, accountBalance *model.AccountBalance) (*model.AccountBalance, error){
+	childLogger.Info().Str("func","GetAccountBalanceForUpdate").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "eventstore.GetAccountBalanceForUpdate")
+	defer span.End()
+
+	res, err := e.WorkerRepository.GetAccountBalanceForUpdate(ctx, tx, accountBalance)
+	if err != nil {
+		return nil, err
+	}
+
+	accountState, err := e.lockState(ctx, tx, res.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if stream_balance := accountState.Balance(res.Currency); stream_balance != nil {
+		res.Amount = stream_balance.Amount
+	}
+	return res, nil
+}
+
+// About load the state of an account on a connection, a stream of another tenant is not found
+func (e EventStoreRepository) readState(ctx context.Context, aggregateID string) (*aggregate.AccountState, error){
+	// db connection
+	conn, err := e.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer e.DatabasePGServer.Release(conn)
+
+	accountState, err := e.loadState(ctx, conn, aggregateID)
+	if err != nil {
+		return nil, err
+	}
+	if accountState.Version > 0 && !auth.TenantBypass(ctx) && accountState.Account.TenantID != auth.TenantID(ctx) {
+		return nil, erro.ErrNotFound
+	}
+	return accountState, nil
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+)
+
+// About get the fee schedule of a tenant for an operation and currency (the account product schedule has precedence over the tenant one)
+func (w WorkerRepository) GetFeeSchedule(ctx context.Context, feeSchedule *model.FeeSchedule) (*model.FeeSchedule, error){
+	childLogger.Info().Str("func","GetFeeSchedule").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetFeeSchedule")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_feeSchedule := model.FeeSchedule{}
+
+	// Query and Execute
+	query := `SELECT 	id,
+						tenant_id,
+						coalesce(product_id,''),
+						operation,
+						method,
+						currency,
+						amount,
+						percentage,
+						min_fee,
+						max_fee,
+						tiers
+				FROM fee_schedule
+				WHERE tenant_id = $1
+				and operation = $2
+				and currency = $3
+				and (product_id = $4 or product_id is null)
+				order by product_id nulls last
+				limit 1`
+
+	rows, err := conn.Query(ctx, query, feeSchedule.TenantID, feeSchedule.Operation, feeSchedule.Currency, feeSchedule.ProductID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+    if err := rows.Err(); err != nil {
+		childLogger.Error().Err(err).Msg("fatal error closing rows")
+        return nil, errors.New(err.Error())
+    }
+
+	for rows.Next() {
+		err := rows.Scan( 	&res_feeSchedule.ID,
+							&res_feeSchedule.TenantID,
+							&res_feeSchedule.ProductID,
+							&res_feeSchedule.Operation,
+							&res_feeSchedule.Method,
+							&res_feeSchedule.Currency,
+							&res_feeSchedule.Amount,
+							&res_feeSchedule.Percentage,
+							&res_feeSchedule.MinFee,
+							&res_feeSchedule.MaxFee,
+							&res_feeSchedule.Tiers,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		return &res_feeSchedule, nil
+	}
+
+	return nil, erro.ErrNotFound
+}
+
+// About list the balances that have a MAINTENANCE fee schedule (of the account product or of the tenant) in their currency
+func (w WorkerRepository) ListMaintenanceBalance(ctx context.Context) (*[]model.MaintenanceBalance, error){
+	childLogger.Info().Str("func","ListMaintenanceBalance").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListMaintenanceBalance")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_maintenance := model.MaintenanceBalance{}
+	res_maintenance_list := []model.MaintenanceBalance{}
+
+	// Query and Execute
+	query := `SELECT 	ab.id,
+						ab.fk_account_id,
+						a.account_id,
+						coalesce(a.product_id,''),
+						ab.currency,
+						ab.amount,
+						a.tenant_id
+				FROM account_balance ab
+				JOIN account a on a.id = ab.fk_account_id
+				WHERE exists (SELECT 1
+								FROM fee_schedule fs
+								WHERE fs.tenant_id = a.tenant_id
+								and fs.operation = 'MAINTENANCE'
+								and fs.currency = ab.currency
+								and (fs.product_id = a.product_id or fs.product_id is null))
+				order by ab.id`
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+    if err := rows.Err(); err != nil {
+		childLogger.Error().Err(err).Msg("fatal error closing rows")
+        return nil, errors.New(err.Error())
+    }
+
+	for rows.Next() {
+		err := rows.Scan( 	&res_maintenance.FkAccountBalanceID,
+							&res_maintenance.FkAccountID,
+							&res_maintenance.AccountID,
+							&res_maintenance.ProductID,
+							&res_maintenance.Currency,
+							&res_maintenance.Balance,
+							&res_maintenance.TenantID,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res_maintenance_list = append(res_maintenance_list, res_maintenance)
+	}
+
+	return &res_maintenance_list, nil
+}
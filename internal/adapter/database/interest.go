@@ -0,0 +1,269 @@
+package database
+
+import (
+	"context"
+	"time"
+	"errors"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// About list all positive balances that have an interest rate configured for the account product
+func (w WorkerRepository) ListInterestBearingBalance(ctx context.Context) (*[]model.InterestAccrual, error){
+	childLogger.Info().Str("func","ListInterestBearingBalance").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListInterestBearingBalance")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_accrual := model.InterestAccrual{}
+	res_accrual_list := []model.InterestAccrual{}
+
+	// Query and Execute
+	query := `SELECT 	ab.id,
+						ab.fk_account_id,
+						a.account_id,
+						ab.currency,
+						ab.amount,
+						ir.rate,
+						ir.day_count,
+						a.tenant_id
+				FROM account_balance ab
+				JOIN account a on a.id = ab.fk_account_id
+				JOIN interest_rate ir on ir.product_id = a.product_id
+									 and ir.tenant_id = a.tenant_id
+				WHERE ab.amount > 0
+				order by ab.id`
+
+	rows, err := conn.Query(ctx, query)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+    if err := rows.Err(); err != nil {
+		childLogger.Error().Err(err).Msg("fatal error closing rows")
+        return nil, errors.New(err.Error())
+    }
+
+	for rows.Next() {
+		err := rows.Scan( 	&res_accrual.FkAccountBalanceID,
+							&res_accrual.FkAccountID,
+							&res_accrual.AccountID,
+							&res_accrual.Currency,
+							&res_accrual.Balance,
+							&res_accrual.Rate,
+							&res_accrual.DayCount,
+							&res_accrual.TenantID,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res_accrual_list = append(res_accrual_list, res_accrual)
+	}
+
+	return &res_accrual_list, nil
+}
+
+// About add a daily interest accrual (a second run for the same day is ignored)
+func (w WorkerRepository) AddInterestAccrual(ctx context.Context, tx port.Tx, interestAccrual *model.InterestAccrual) (bool, error){
+	childLogger.Info().Str("func","AddInterestAccrual").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddInterestAccrual")
+	defer span.End()
+
+	// Query Execute
+	query := `INSERT INTO account_interest_accrual (fk_account_balance_id,
+													accrual_date,
+													balance,
+													rate,
+													day_count,
+													amount,
+													tenant_id)
+				VALUES($1, $2, $3, $4, $5, $6, $7)
+				ON CONFLICT (fk_account_balance_id, accrual_date) DO NOTHING`
+
+	row, err := pgxTx(tx).Exec(ctx, query, interestAccrual.FkAccountBalanceID,
+									interestAccrual.AccrualDate,
+									interestAccrual.Balance,
+									interestAccrual.Rate,
+									interestAccrual.DayCount,
+									interestAccrual.Amount,
+									interestAccrual.TenantID)
+	if err != nil {
+		return false, errors.New(err.Error())
+	}
+
+	return row.RowsAffected() == 1, nil
+}
+
+// About mark as capitalized all pending accruals before a date and return their amounts
+func (w WorkerRepository) CapitalizeInterestAccrual(ctx context.Context, tx port.Tx, interestAccrual *model.InterestAccrual, until time.Time) ([]float64, error){
+	childLogger.Info().Str("func","CapitalizeInterestAccrual").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.CapitalizeInterestAccrual")
+	defer span.End()
+
+	// Prepare
+	capitalizedAt := time.Now()
+	interestAccrual.CapitalizedAt = &capitalizedAt
+
+	// Query Execute (the update holds the rows lock, so a concurrent run can not capitalize them twice)
+	query := `UPDATE account_interest_accrual
+				set capitalized_at = $1
+				where fk_account_balance_id = $2
+				and accrual_date < $3
+				and capitalized_at is null
+				RETURNING amount`
+
+	rows, err := pgxTx(tx).Query(ctx, query, interestAccrual.CapitalizedAt,
+									interestAccrual.FkAccountBalanceID,
+									until)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	amounts := []float64{}
+	for rows.Next() {
+		var amount float64
+		if err := rows.Scan(&amount); err != nil {
+			return nil, errors.New(err.Error())
+		}
+		amounts = append(amounts, amount)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return amounts, nil
+}
+
+// About add an account statement
+func (w WorkerRepository) AddAccountStatement(ctx context.Context, tx port.Tx, accountStatement *model.AccountStatement) (*model.AccountStatement, error){
+	childLogger.Info().Str("func","AddAccountStatement").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddAccountStatement")
+	defer span.End()
+
+	//Prepare
+	var id int
+	if accountStatement.ChargedAt.IsZero() {
+		accountStatement.ChargedAt = time.Now()
+	}
+
+	// Query Execute
+	query := `INSERT INTO account_statement (fk_account_id,
+											type_charge,
+											charged_at,
+											currency,
+											amount,
+											tenant_id,
+											transaction_id)
+				VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+	row := pgxTx(tx).QueryRow(ctx, query,	accountStatement.FkAccountID,
+									accountStatement.Type,
+									accountStatement.ChargedAt,
+									accountStatement.Currency,
+									accountStatement.Amount,
+									accountStatement.TenantID,
+									accountStatement.TransactionID)
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Set PK
+	accountStatement.ID = id
+	return accountStatement , nil
+}
+
+// About add a maintenance fee statement, it returns false when the account already has a statement with its transaction id
+func (w WorkerRepository) AddAccountStatementOnce(ctx context.Context, tx port.Tx, accountStatement *model.AccountStatement) (bool, error){
+	childLogger.Info().Str("func","AddAccountStatementOnce").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddAccountStatementOnce")
+	defer span.End()
+
+	//Prepare
+	var id int
+	if accountStatement.ChargedAt.IsZero() {
+		accountStatement.ChargedAt = time.Now()
+	}
+
+	// Query Execute (the conflict target is the unique index of the maintenance fees, migration 0011)
+	query := `INSERT INTO account_statement (fk_account_id,
+											type_charge,
+											charged_at,
+											currency,
+											amount,
+											tenant_id,
+											transaction_id)
+				VALUES($1, $2, $3, $4, $5, $6, $7)
+				ON CONFLICT (fk_account_id, transaction_id) WHERE transaction_id LIKE 'FEE-MAINTENANCE-%' DO NOTHING
+				RETURNING id`
+
+	row := pgxTx(tx).QueryRow(ctx, query,	accountStatement.FkAccountID,
+									accountStatement.Type,
+									accountStatement.ChargedAt,
+									accountStatement.Currency,
+									accountStatement.Amount,
+									accountStatement.TenantID,
+									accountStatement.TransactionID)
+	if err := row.Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, errors.New(err.Error())
+	}
+
+	// Set PK
+	accountStatement.ID = id
+	return true, nil
+}
+
+// About add an amount into an account balance
+func (w WorkerRepository) UpdateAccountBalanceAmount(ctx context.Context, tx port.Tx, accountBalance *model.AccountBalance) (int64, error){
+	childLogger.Info().Str("func","UpdateAccountBalanceAmount").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.UpdateAccountBalanceAmount")
+	defer span.End()
+
+	// Prepare
+	updateAt := time.Now()
+	accountBalance.UpdatedAt = &updateAt
+
+	//Query Execute
+	query := `Update account_balance
+				set amount = amount + $1,
+					updated_at = $2,
+					transaction_id = $3
+				where id = $4 `
+
+	row, err := pgxTx(tx).Exec(ctx, query, accountBalance.Amount,
+									accountBalance.UpdatedAt,
+									accountBalance.TransactionID,
+									accountBalance.ID)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+
+	childLogger.Debug().Int("rowsAffected : ",int(row.RowsAffected())).Msg("")
+
+	return row.RowsAffected() , nil
+}
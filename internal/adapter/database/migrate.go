@@ -0,0 +1,317 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"embed"
+	"regexp"
+	"errors"
+	"context"
+	"strconv"
+	"io/fs"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/auth"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// About the versioned schema migrations, embedded into the binary: <version>_<name>.up.sql and
+// <version>_<name>.down.sql, each one is applied in its own transaction
+//go:embed migrations/*.sql
+var migrationFS embed.FS
+
+// About the advisory lock of the migrations, only one replica migrates at a time (the others wait)
+const schemaMigrationLock = 7305002
+
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type migration struct {
+	version	int
+	name	string
+	up		string
+	down	string
+}
+
+// About load the embedded migrations ordered by version, both up and down are required
+func loadMigrations() ([]migration, error){
+	files, err := fs.ReadDir(migrationFS, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := map[int]*migration{}
+	for _, file := range files {
+		match := migrationFile.FindStringSubmatch(file.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s invalid name", file.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		sql, err := fs.ReadFile(migrationFS, "migrations/" + file.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := migrations[version]
+		if !ok {
+			m = &migration{version: version, name: match[2]}
+			migrations[version] = m
+		}
+		if m.name != match[2] {
+			return nil, fmt.Errorf("migration version %d has two names %s and %s", version, m.name, match[2])
+		}
+		if match[3] == "up" {
+			m.up = string(sql)
+		} else {
+			m.down = string(sql)
+		}
+	}
+
+	res_migration_list := []migration{}
+	for _, m := range migrations {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs the up and down files", m.version, m.name)
+		}
+		res_migration_list = append(res_migration_list, *m)
+	}
+	sort.Slice(res_migration_list, func(i, j int) bool { return res_migration_list[i].version < res_migration_list[j].version })
+
+	return res_migration_list, nil
+}
+
+// About take the migration lock on a connection and create the schema_migrations table,
+// the returned func unlocks and releases the connection. The migrations see all the tenants (backfills)
+func (w WorkerRepository) lockMigration(ctx context.Context) (*pgxpool.Conn, func(), error){
+	conn, err := w.acquire(auth.WithTenantBypass(ctx))
+	if err != nil {
+		return nil, nil, errors.New(err.Error())
+	}
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, schemaMigrationLock); err != nil {
+		w.DatabasePGServer.Release(conn)
+		return nil, nil, errors.New(err.Error())
+	}
+	unlock := func() {
+		conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, schemaMigrationLock)
+		w.DatabasePGServer.Release(conn)
+	}
+
+	query := `CREATE TABLE IF NOT EXISTS schema_migrations (	version		integer PRIMARY KEY,
+																name		varchar(200) NOT NULL,
+																applied_at	timestamptz NOT NULL DEFAULT now())`
+	if _, err := conn.Exec(ctx, query); err != nil {
+		unlock()
+		return nil, nil, errors.New(err.Error())
+	}
+
+	return conn, unlock, nil
+}
+
+// About the applied versions of the schema_migrations table (empty when the table does not exist)
+func appliedMigrations(ctx context.Context, conn *pgxpool.Conn) (map[int]model.SchemaMigration, error){
+	res_applied := map[int]model.SchemaMigration{}
+
+	var exists bool
+	if err := conn.QueryRow(ctx, `SELECT to_regclass('schema_migrations') is not null`).Scan(&exists); err != nil {
+		return nil, errors.New(err.Error())
+	}
+	if !exists {
+		return res_applied, nil
+	}
+
+	rows, err := conn.Query(ctx, `SELECT version, name, applied_at FROM schema_migrations order by version`)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		schemaMigration := model.SchemaMigration{}
+		if err := rows.Scan(&schemaMigration.Version, &schemaMigration.Name, &schemaMigration.AppliedAt); err != nil {
+			return nil, errors.New(err.Error())
+		}
+		res_applied[schemaMigration.Version] = schemaMigration
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return res_applied, nil
+}
+
+// About apply the pending migrations in order, it returns the applied ones
+func (w WorkerRepository) MigrateUp(ctx context.Context) (*[]model.SchemaMigration, error){
+	childLogger.Info().Str("func","MigrateUp").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.MigrateUp")
+	defer span.End()
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, unlock, err := w.lockMigration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	res_migration_list := []model.SchemaMigration{}
+	for _, m := range migrations {
+		if _, ok := applied[m.version]; ok {
+			continue
+		}
+		childLogger.Info().Int("version", m.version).Str("name", m.name).Msg("applying migration")
+
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return &res_migration_list, errors.New(err.Error())
+		}
+		if _, err := tx.Exec(ctx, m.up); err != nil {
+			tx.Rollback(ctx)
+			return &res_migration_list, fmt.Errorf("migration %d_%s: %v", m.version, m.name, err)
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES($1, $2)`, m.version, m.name); err != nil {
+			tx.Rollback(ctx)
+			return &res_migration_list, errors.New(err.Error())
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return &res_migration_list, errors.New(err.Error())
+		}
+
+		res_migration_list = append(res_migration_list, model.SchemaMigration{Version: m.version, Name: m.name})
+	}
+
+	return &res_migration_list, nil
+}
+
+// About revert the last applied migrations (steps), it returns the reverted ones
+func (w WorkerRepository) MigrateDown(ctx context.Context, steps int) (*[]model.SchemaMigration, error){
+	childLogger.Info().Str("func","MigrateDown").Int("steps", steps).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.MigrateDown")
+	defer span.End()
+
+	if steps <= 0 {
+		return nil, erro.ErrBadRequest
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, unlock, err := w.lockMigration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	res_migration_list := []model.SchemaMigration{}
+	for i := len(migrations) - 1; i >= 0 && len(res_migration_list) < steps; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.version]; !ok {
+			continue
+		}
+		childLogger.Info().Int("version", m.version).Str("name", m.name).Msg("reverting migration")
+
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return &res_migration_list, errors.New(err.Error())
+		}
+		if _, err := tx.Exec(ctx, m.down); err != nil {
+			tx.Rollback(ctx)
+			return &res_migration_list, fmt.Errorf("migration %d_%s: %v", m.version, m.name, err)
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, m.version); err != nil {
+			tx.Rollback(ctx)
+			return &res_migration_list, errors.New(err.Error())
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return &res_migration_list, errors.New(err.Error())
+		}
+
+		res_migration_list = append(res_migration_list, model.SchemaMigration{Version: m.version, Name: m.name})
+	}
+
+	return &res_migration_list, nil
+}
+
+// About list the embedded migrations with the time they were applied (nil when pending)
+func (w WorkerRepository) ListSchemaMigration(ctx context.Context) (*[]model.SchemaMigration, error){
+	childLogger.Info().Str("func","ListSchemaMigration").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListSchemaMigration")
+	defer span.End()
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := w.acquire(auth.WithTenantBypass(ctx))
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	applied, err := appliedMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	res_migration_list := []model.SchemaMigration{}
+	for _, m := range migrations {
+		schemaMigration := model.SchemaMigration{Version: m.version, Name: m.name}
+		if a, ok := applied[m.version]; ok {
+			schemaMigration.AppliedAt = a.AppliedAt
+		}
+		res_migration_list = append(res_migration_list, schemaMigration)
+	}
+
+	return &res_migration_list, nil
+}
+
+// About check the schema has all the embedded migrations, a newer schema (applied by a newer
+// version of the service) is accepted
+func (w WorkerRepository) CheckSchemaVersion(ctx context.Context) error{
+	childLogger.Info().Str("func","CheckSchemaVersion").Send()
+
+	list_migration, err := w.ListSchemaMigration(ctx)
+	if err != nil {
+		return err
+	}
+
+	applied := 0
+	for _, schemaMigration := range *list_migration {
+		if schemaMigration.AppliedAt != nil {
+			applied++
+		}
+	}
+	// a database of go-account-migration-worker (no schema_migrations yet) is adopted by migrate up
+	if applied == 0 && len(*list_migration) > 0 {
+		return fmt.Errorf("%w: no migration applied, run go-account migrate up once to adopt the database", erro.ErrSchemaBehind)
+	}
+	for _, schemaMigration := range *list_migration {
+		if schemaMigration.AppliedAt == nil {
+			return fmt.Errorf("%w: migration %d_%s not applied", erro.ErrSchemaBehind, schemaMigration.Version, schemaMigration.Name)
+		}
+	}
+
+	return nil
+}
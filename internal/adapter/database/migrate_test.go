@@ -0,0 +1,125 @@
+package database
+
+import (
+	"errors"
+	"context"
+	"testing"
+
+	"github.com/go-account/internal/core/erro"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no migrations embedded")
+	}
+
+	// the versions are sequential, a gap means a file missing
+	for i, m := range migrations {
+		if m.version != i + 1 {
+			t.Errorf("migration %d_%s want version %d", m.version, m.name, i + 1)
+		}
+		if m.up == "" || m.down == "" {
+			t.Errorf("migration %d_%s without up or down", m.version, m.name)
+		}
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	repository := newTestRepository(t)
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := len(migrations)
+
+	tests := []struct {
+		name		string
+		run			func() (int, error)
+		changed		int
+		applied		int
+		checkErr	error
+	}{
+		{"up to date", func() (int, error) { res, err := repository.MigrateUp(ctx); return len(*res), err }, 0, latest, nil},
+		{"down one step", func() (int, error) { res, err := repository.MigrateDown(ctx, 1); return len(*res), err }, 1, latest - 1, erro.ErrSchemaBehind},
+		{"down all", func() (int, error) { res, err := repository.MigrateDown(ctx, latest); return len(*res), err }, latest - 1, 0, erro.ErrSchemaBehind},
+		{"down without migrations", func() (int, error) { res, err := repository.MigrateDown(ctx, 1); return len(*res), err }, 0, 0, erro.ErrSchemaBehind},
+		{"up all", func() (int, error) { res, err := repository.MigrateUp(ctx); return len(*res), err }, latest, latest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed, err := tt.run()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if changed != tt.changed {
+				t.Errorf("changed %d want %d", changed, tt.changed)
+			}
+
+			list_migration, err := repository.ListSchemaMigration(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			applied := 0
+			for _, schemaMigration := range *list_migration {
+				if schemaMigration.AppliedAt != nil {
+					applied++
+				}
+			}
+			if applied != tt.applied {
+				t.Errorf("applied %d want %d", applied, tt.applied)
+			}
+			if err := repository.CheckSchemaVersion(ctx); !errors.Is(err, tt.checkErr) {
+				t.Errorf("check schema err %v want %v", err, tt.checkErr)
+			}
+		})
+	}
+
+	if _, err := repository.MigrateDown(ctx, 0); err != erro.ErrBadRequest {
+		t.Errorf("down 0 steps err %v", err)
+	}
+}
+
+// the baseline down keeps the tables of go-account-migration-worker and their rows, only the product_id
+// column is removed, and the up adopts the existing tables
+func TestMigrateBaseline(t *testing.T) {
+	ctx := context.Background()
+	repository := newTestRepository(t)
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := testAdminConn.Exec(ctx, `INSERT INTO account (account_id, person_id, tenant_id, product_id) VALUES ('ACC-1', 'P-1', 'tenant-1', 'CHECKING')`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repository.MigrateDown(ctx, len(migrations)); err != nil {
+		t.Fatal(err)
+	}
+	var accounts, productColumns int
+	query := `SELECT (SELECT count(*) FROM account),
+					(SELECT count(*) FROM information_schema.columns WHERE table_name = 'account' and column_name = 'product_id')`
+	if err := testAdminConn.QueryRow(ctx, query).Scan(&accounts, &productColumns); err != nil {
+		t.Fatal(err)
+	}
+	if accounts != 1 || productColumns != 0 {
+		t.Errorf("after down accounts %d product_id columns %d", accounts, productColumns)
+	}
+
+	if _, err := repository.MigrateUp(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := testAdminConn.QueryRow(ctx, query).Scan(&accounts, &productColumns); err != nil {
+		t.Fatal(err)
+	}
+	if accounts != 1 || productColumns != 1 {
+		t.Errorf("after up accounts %d product_id columns %d", accounts, productColumns)
+	}
+}
@@ -0,0 +1,14 @@
+-- Only what the baseline added is reverted, the tables of go-account-migration-worker
+-- (account, account_balance, account_statement and transfer_moviment) and their data are kept
+DROP TABLE IF EXISTS api_key;
+DROP TABLE IF EXISTS tenant_role;
+DROP TABLE IF EXISTS account_number_format;
+DROP TABLE IF EXISTS fee_schedule;
+DROP TABLE IF EXISTS account_interest_accrual;
+DROP TABLE IF EXISTS interest_rate;
+DROP TABLE IF EXISTS account_product;
+
+DROP INDEX IF EXISTS account_statement_account_idx;
+DROP INDEX IF EXISTS account_person_idx;
+
+ALTER TABLE account DROP COLUMN IF EXISTS product_id;
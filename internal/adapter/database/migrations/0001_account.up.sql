@@ -0,0 +1,145 @@
+-- Baseline of the account service. The tables account, account_balance, account_statement and
+-- transfer_moviment were created by go-account-migration-worker on the databases older than the
+-- embedded migrations, so they are created IF NOT EXISTS with the columns of that schema and the
+-- columns added by the service come with ADD COLUMN IF NOT EXISTS (an existing database is adopted).
+-- The other tables (products, fees, interest, account numbers, roles and api keys) are new.
+
+CREATE TABLE IF NOT EXISTS account (
+	id					serial PRIMARY KEY,
+	account_id			varchar(50) NOT NULL UNIQUE,
+	person_id			varchar(50) NOT NULL,
+	created_at			timestamptz NOT NULL DEFAULT now(),
+	updated_at			timestamptz NULL,
+	user_last_update	varchar(100) NULL,
+	tenant_id			varchar(50) NOT NULL
+);
+
+ALTER TABLE account ADD COLUMN IF NOT EXISTS product_id varchar(50) NULL;
+
+CREATE INDEX IF NOT EXISTS account_person_idx ON account (person_id);
+
+CREATE TABLE IF NOT EXISTS account_balance (
+	id					serial PRIMARY KEY,
+	fk_account_id		integer NOT NULL REFERENCES account(id) ON DELETE CASCADE,
+	currency			varchar(10) NOT NULL,
+	amount				numeric(20,2) NOT NULL DEFAULT 0,
+	tenant_id			varchar(50) NOT NULL,
+	user_last_update	varchar(100) NULL,
+	transaction_id		varchar(100) NULL,
+	created_at			timestamptz NOT NULL DEFAULT now(),
+	updated_at			timestamptz NULL,
+	UNIQUE (fk_account_id, currency)
+);
+
+CREATE TABLE IF NOT EXISTS account_statement (
+	id					serial PRIMARY KEY,
+	fk_account_id		integer NOT NULL REFERENCES account(id) ON DELETE CASCADE,
+	type_charge			varchar(20) NOT NULL,
+	charged_at			timestamptz NOT NULL DEFAULT now(),
+	currency			varchar(10) NOT NULL,
+	amount				numeric(20,2) NOT NULL,
+	tenant_id			varchar(50) NOT NULL,
+	transaction_id		varchar(100) NULL
+);
+
+CREATE INDEX IF NOT EXISTS account_statement_account_idx ON account_statement (fk_account_id, charged_at DESC);
+
+CREATE TABLE IF NOT EXISTS transfer_moviment (
+	id					serial PRIMARY KEY,
+	fk_account_id_from	integer NOT NULL REFERENCES account(id) ON DELETE CASCADE,
+	fk_account_id_to	integer NOT NULL REFERENCES account(id) ON DELETE CASCADE,
+	type_charge			varchar(20) NOT NULL,
+	status				varchar(20) NOT NULL,
+	currency			varchar(10) NOT NULL,
+	amount				numeric(20,2) NOT NULL,
+	transfer_at			timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS account_product (
+	id					serial PRIMARY KEY,
+	product_id			varchar(50) NOT NULL,
+	type_product		varchar(20) NOT NULL,
+	description			varchar(200) NULL,
+	currencies			text[] NOT NULL,
+	overdraft			numeric(20,2) NOT NULL DEFAULT 0,
+	tenant_id			varchar(50) NOT NULL,
+	created_at			timestamptz NOT NULL DEFAULT now(),
+	updated_at			timestamptz NULL,
+	UNIQUE (product_id, tenant_id)
+);
+
+CREATE TABLE IF NOT EXISTS interest_rate (
+	id					serial PRIMARY KEY,
+	product_id			varchar(50) NOT NULL,
+	rate				numeric(10,6) NOT NULL,
+	day_count			varchar(10) NOT NULL,
+	tenant_id			varchar(50) NOT NULL,
+	UNIQUE (product_id, tenant_id)
+);
+
+CREATE TABLE IF NOT EXISTS account_interest_accrual (
+	id						serial PRIMARY KEY,
+	fk_account_balance_id	integer NOT NULL REFERENCES account_balance(id) ON DELETE CASCADE,
+	accrual_date			date NOT NULL,
+	balance					numeric(20,2) NOT NULL,
+	rate					numeric(10,6) NOT NULL,
+	day_count				varchar(10) NOT NULL,
+	amount					numeric(20,6) NOT NULL,
+	tenant_id				varchar(50) NOT NULL,
+	capitalized_at			timestamptz NULL,
+	UNIQUE (fk_account_balance_id, accrual_date)
+);
+
+CREATE TABLE IF NOT EXISTS fee_schedule (
+	id					serial PRIMARY KEY,
+	tenant_id			varchar(50) NOT NULL,
+	product_id			varchar(50) NULL,
+	operation			varchar(20) NOT NULL,
+	method				varchar(20) NOT NULL,
+	currency			varchar(10) NOT NULL,
+	amount				numeric(20,2) NOT NULL DEFAULT 0,
+	percentage			numeric(10,4) NOT NULL DEFAULT 0,
+	min_fee				numeric(20,2) NOT NULL DEFAULT 0,
+	max_fee				numeric(20,2) NOT NULL DEFAULT 0,
+	tiers				jsonb NOT NULL DEFAULT '[]'
+);
+
+CREATE TABLE IF NOT EXISTS account_number_format (
+	id					serial PRIMARY KEY,
+	tenant_id			varchar(50) NOT NULL UNIQUE,
+	prefix				varchar(10) NOT NULL DEFAULT '',
+	sequence_length		integer NOT NULL,
+	check_digit			varchar(10) NOT NULL DEFAULT 'NONE',
+	iban				boolean NOT NULL DEFAULT false,
+	country_code		varchar(2) NOT NULL DEFAULT '',
+	last_sequence		bigint NOT NULL DEFAULT 0,
+	created_at			timestamptz NOT NULL DEFAULT now(),
+	updated_at			timestamptz NULL
+);
+
+CREATE TABLE IF NOT EXISTS tenant_role (
+	id					serial PRIMARY KEY,
+	tenant_id			varchar(50) NOT NULL,
+	subject				varchar(100) NOT NULL,
+	role				varchar(20) NOT NULL,
+	user_last_update	varchar(100) NULL,
+	created_at			timestamptz NOT NULL DEFAULT now(),
+	updated_at			timestamptz NULL,
+	UNIQUE (tenant_id, subject)
+);
+
+CREATE TABLE IF NOT EXISTS api_key (
+	id					serial PRIMARY KEY,
+	key_id				varchar(50) NOT NULL UNIQUE,
+	tenant_id			varchar(50) NOT NULL,
+	name				varchar(100) NOT NULL,
+	key_hash			varchar(128) NOT NULL,
+	scopes				text[] NOT NULL,
+	role				varchar(20) NULL,
+	status				varchar(20) NOT NULL,
+	expires_at			timestamptz NULL,
+	last_used_at		timestamptz NULL,
+	user_last_update	varchar(100) NULL,
+	created_at			timestamptz NOT NULL DEFAULT now(),
+	updated_at			timestamptz NULL
+);
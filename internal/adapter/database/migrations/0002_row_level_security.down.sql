@@ -0,0 +1,21 @@
+DO $$
+DECLARE
+	t text;
+BEGIN
+	FOREACH t IN ARRAY ARRAY['account',
+							'account_balance',
+							'account_statement',
+							'account_product',
+							'account_number_format',
+							'account_interest_accrual',
+							'fee_schedule',
+							'interest_rate',
+							'tenant_role',
+							'api_key']
+	LOOP
+		EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', t);
+		EXECUTE format('ALTER TABLE %I NO FORCE ROW LEVEL SECURITY', t);
+		EXECUTE format('ALTER TABLE %I DISABLE ROW LEVEL SECURITY', t);
+	END LOOP;
+END
+$$;
@@ -0,0 +1,32 @@
+-- Row level security by tenant (defense in depth)
+-- The application sets app.tenant_id from the caller identity: SET LOCAL inside the transactions
+-- (set_config(..., true)) and per connection acquire for the reads. An empty app.tenant_id
+-- (background jobs, auth disabled) is not filtered.
+-- FORCE is needed because the application user is the owner of the tables.
+
+DO $$
+DECLARE
+	t text;
+BEGIN
+	FOREACH t IN ARRAY ARRAY['account',
+							'account_balance',
+							'account_statement',
+							'account_product',
+							'account_number_format',
+							'account_interest_accrual',
+							'fee_schedule',
+							'interest_rate',
+							'tenant_role',
+							'api_key']
+	LOOP
+		EXECUTE format('ALTER TABLE %I ENABLE ROW LEVEL SECURITY', t);
+		EXECUTE format('ALTER TABLE %I FORCE ROW LEVEL SECURITY', t);
+		EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', t);
+		EXECUTE format($p$CREATE POLICY tenant_isolation ON %I
+							USING (coalesce(current_setting('app.tenant_id', true), '') = ''
+									OR tenant_id = current_setting('app.tenant_id', true))
+							WITH CHECK (coalesce(current_setting('app.tenant_id', true), '') = ''
+									OR tenant_id = current_setting('app.tenant_id', true))$p$, t);
+	END LOOP;
+END
+$$;
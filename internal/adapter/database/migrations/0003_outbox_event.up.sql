@@ -0,0 +1,14 @@
+-- Domain events written in the same transaction of the account mutations, published by the outbox relay
+CREATE TABLE IF NOT EXISTS outbox_event (
+	id				bigserial PRIMARY KEY,
+	aggregate_id	varchar(50) NOT NULL,
+	tenant_id		varchar(50) NOT NULL,
+	event_type		varchar(100) NOT NULL,
+	payload			jsonb NOT NULL,
+	created_at		timestamptz NOT NULL DEFAULT now(),
+	published_at	timestamptz NULL,
+	attempts		integer NOT NULL DEFAULT 0,
+	last_error		text NULL
+);
+
+CREATE INDEX IF NOT EXISTS outbox_event_pending_idx ON outbox_event (id) WHERE published_at IS NULL;
@@ -0,0 +1,47 @@
+-- Webhook subscriptions of a tenant, the secret signs the payloads (HMAC-SHA256)
+CREATE TABLE IF NOT EXISTS webhook_subscription (
+	id					serial PRIMARY KEY,
+	subscription_id		varchar(50) NOT NULL UNIQUE,
+	tenant_id			varchar(50) NOT NULL,
+	url					varchar(500) NOT NULL,
+	event_types			text[] NOT NULL DEFAULT '{}',
+	secret				varchar(100) NOT NULL,
+	status				varchar(20) NOT NULL,
+	user_last_update	varchar(100) NULL,
+	created_at			timestamptz NOT NULL,
+	updated_at			timestamptz NULL
+);
+
+CREATE INDEX IF NOT EXISTS webhook_subscription_tenant_idx ON webhook_subscription (tenant_id) WHERE status = 'ACTIVE';
+
+-- One delivery per subscription and event, written in the transaction of the event (outbox)
+CREATE TABLE IF NOT EXISTS webhook_delivery (
+	id					bigserial PRIMARY KEY,
+	fk_subscription_id	integer NOT NULL REFERENCES webhook_subscription(id),
+	outbox_event_id		bigint NOT NULL,
+	event_type			varchar(100) NOT NULL,
+	payload				jsonb NOT NULL,
+	status				varchar(20) NOT NULL DEFAULT 'PENDING',
+	attempts			integer NOT NULL DEFAULT 0,
+	next_attempt_at		timestamptz NOT NULL DEFAULT now(),
+	last_status_code	integer NULL,
+	last_error			text NULL,
+	created_at			timestamptz NOT NULL DEFAULT now(),
+	delivered_at		timestamptz NULL,
+	UNIQUE (fk_subscription_id, outbox_event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_pending_idx ON webhook_delivery (next_attempt_at) WHERE status = 'PENDING';
+
+-- History of the delivery attempts
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempt (
+	id					bigserial PRIMARY KEY,
+	fk_delivery_id		bigint NOT NULL REFERENCES webhook_delivery(id),
+	attempt				integer NOT NULL,
+	attempted_at		timestamptz NOT NULL,
+	status_code			integer NULL,
+	error				text NULL,
+	duration_ms			bigint NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_attempt_delivery_idx ON webhook_delivery_attempt (fk_delivery_id);
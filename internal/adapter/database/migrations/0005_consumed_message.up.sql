@@ -0,0 +1,12 @@
+-- Messages consumed by the inbound consumers (idempotency on the message key), written in the transaction of the posting
+CREATE TABLE IF NOT EXISTS consumed_message (
+	consumer		varchar(100) NOT NULL,
+	message_key		varchar(200) NOT NULL,
+	topic			varchar(200) NULL,
+	partition		integer NULL,
+	message_offset	bigint NULL,
+	status			varchar(20) NOT NULL,
+	error			text NULL,
+	created_at		timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY (consumer, message_key)
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS account_snapshot;
+DROP TABLE IF EXISTS account_event_stream;
+DROP FUNCTION IF EXISTS account_event_stream_append_only();
@@ -0,0 +1,50 @@
+-- Event store of the account aggregate (ACCOUNT_PERSISTENCE=eventstore), append only. The account and
+-- account_balance tables are kept as the read model, written in the same transaction of the events
+CREATE TABLE IF NOT EXISTS account_event_stream (
+	id				bigserial PRIMARY KEY,
+	aggregate_id	varchar(50) NOT NULL,
+	tenant_id		varchar(50) NOT NULL,
+	version			integer NOT NULL,
+	event_type		varchar(100) NOT NULL,
+	data			jsonb NOT NULL,
+	created_at		timestamptz NOT NULL DEFAULT now(),
+	UNIQUE (aggregate_id, version)
+);
+
+-- The last snapshot of an aggregate (each SNAPSHOT_INTERVAL events), the state is rebuilt from it
+CREATE TABLE IF NOT EXISTS account_snapshot (
+	aggregate_id	varchar(50) PRIMARY KEY,
+	tenant_id		varchar(50) NOT NULL,
+	version			integer NOT NULL,
+	state			jsonb NOT NULL,
+	created_at		timestamptz NOT NULL DEFAULT now()
+);
+
+-- The stream is never changed
+CREATE OR REPLACE FUNCTION account_event_stream_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'account_event_stream is append only';
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS account_event_stream_append_only ON account_event_stream;
+CREATE TRIGGER account_event_stream_append_only BEFORE UPDATE OR DELETE ON account_event_stream
+	FOR EACH ROW EXECUTE FUNCTION account_event_stream_append_only();
+
+ALTER TABLE account_event_stream ENABLE ROW LEVEL SECURITY;
+ALTER TABLE account_event_stream FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON account_event_stream;
+CREATE POLICY tenant_isolation ON account_event_stream
+	USING (coalesce(current_setting('app.tenant_id', true), '') = ''
+			OR tenant_id = current_setting('app.tenant_id', true))
+	WITH CHECK (coalesce(current_setting('app.tenant_id', true), '') = ''
+			OR tenant_id = current_setting('app.tenant_id', true));
+
+ALTER TABLE account_snapshot ENABLE ROW LEVEL SECURITY;
+ALTER TABLE account_snapshot FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON account_snapshot;
+CREATE POLICY tenant_isolation ON account_snapshot
+	USING (coalesce(current_setting('app.tenant_id', true), '') = ''
+			OR tenant_id = current_setting('app.tenant_id', true))
+	WITH CHECK (coalesce(current_setting('app.tenant_id', true), '') = ''
+			OR tenant_id = current_setting('app.tenant_id', true));
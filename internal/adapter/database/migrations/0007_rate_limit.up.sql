@@ -0,0 +1,7 @@
+-- Token buckets of the rate limit shared by the replicas (RATE_LIMIT_STORE=postgres)
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_bucket (
+	bucket_key	varchar(200) PRIMARY KEY,
+	tokens		float8 NOT NULL,
+	allowed		boolean NOT NULL,
+	updated_at	timestamptz NOT NULL
+);
@@ -0,0 +1,26 @@
+DO $$
+DECLARE
+	t text;
+BEGIN
+	FOREACH t IN ARRAY ARRAY['account',
+							'account_balance',
+							'account_statement',
+							'account_product',
+							'account_number_format',
+							'account_interest_accrual',
+							'fee_schedule',
+							'interest_rate',
+							'tenant_role',
+							'api_key',
+							'account_event_stream',
+							'account_snapshot']
+	LOOP
+		EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', t);
+		EXECUTE format($p$CREATE POLICY tenant_isolation ON %I
+							USING (coalesce(current_setting('app.tenant_id', true), '') = ''
+									OR tenant_id = current_setting('app.tenant_id', true))
+							WITH CHECK (coalesce(current_setting('app.tenant_id', true), '') = ''
+									OR tenant_id = current_setting('app.tenant_id', true))$p$, t);
+	END LOOP;
+END
+$$;
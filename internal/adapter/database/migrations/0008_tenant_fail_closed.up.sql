@@ -0,0 +1,30 @@
+-- Row level security fails closed: an empty app.tenant_id sees no row. The application itself
+-- (jobs, api key lookup, migrations) sets app.bypass_rls = 'on' on purpose to see all the tenants,
+-- the requests always run with app.bypass_rls = 'off'
+
+DO $$
+DECLARE
+	t text;
+BEGIN
+	FOREACH t IN ARRAY ARRAY['account',
+							'account_balance',
+							'account_statement',
+							'account_product',
+							'account_number_format',
+							'account_interest_accrual',
+							'fee_schedule',
+							'interest_rate',
+							'tenant_role',
+							'api_key',
+							'account_event_stream',
+							'account_snapshot']
+	LOOP
+		EXECUTE format('DROP POLICY IF EXISTS tenant_isolation ON %I', t);
+		EXECUTE format($p$CREATE POLICY tenant_isolation ON %I
+							USING (current_setting('app.bypass_rls', true) = 'on'
+									OR tenant_id = nullif(current_setting('app.tenant_id', true), ''))
+							WITH CHECK (current_setting('app.bypass_rls', true) = 'on'
+									OR tenant_id = nullif(current_setting('app.tenant_id', true), ''))$p$, t);
+	END LOOP;
+END
+$$;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS outbox_event_parked_idx;
+DROP INDEX IF EXISTS outbox_event_pending_idx;
+CREATE INDEX IF NOT EXISTS outbox_event_pending_idx ON outbox_event (id) WHERE published_at IS NULL;
+
+ALTER TABLE outbox_event DROP COLUMN IF EXISTS parked_at;
+ALTER TABLE outbox_event DROP COLUMN IF EXISTS claimed_until;
@@ -0,0 +1,8 @@
+-- The outbox relay claims a batch with a lease (claimed_until) and publishes it out of the transaction,
+-- an event that failed OUTBOX_MAX_ATTEMPTS times is parked (parked_at) and no longer relayed
+ALTER TABLE outbox_event ADD COLUMN IF NOT EXISTS claimed_until timestamptz NULL;
+ALTER TABLE outbox_event ADD COLUMN IF NOT EXISTS parked_at timestamptz NULL;
+
+DROP INDEX IF EXISTS outbox_event_pending_idx;
+CREATE INDEX IF NOT EXISTS outbox_event_pending_idx ON outbox_event (id) WHERE published_at IS NULL AND parked_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_event_parked_idx ON outbox_event (id) WHERE parked_at IS NOT NULL;
@@ -0,0 +1,2 @@
+-- Status of an account: ACTIVE or BLOCKED (a blocked account refuses the postings and the transfers)
+ALTER TABLE account ADD COLUMN IF NOT EXISTS status varchar(20) NOT NULL DEFAULT 'ACTIVE';
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS account_statement_maintenance_fee_idx;
@@ -0,0 +1,3 @@
+-- A maintenance fee is charged once per account and month: its statement has the transaction id FEE-MAINTENANCE-<balance>-<month>
+-- and the insert of the fee does nothing on a conflict with this index (two replicas running the same month)
+CREATE UNIQUE INDEX IF NOT EXISTS account_statement_maintenance_fee_idx ON account_statement (fk_account_id, transaction_id) WHERE transaction_id LIKE 'FEE-MAINTENANCE-%';
@@ -0,0 +1,242 @@
+package database
+
+import (
+	"time"
+	"sort"
+	"context"
+	"errors"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
+)
+
+// About the advisory lock of the outbox relay, only one replica publishes at a time (keeps the order)
+const outboxRelayLock = 7305001
+
+// About add an event into the outbox, in the same transaction of the mutation
+func (w WorkerRepository) AddOutboxEvent(ctx context.Context, tx port.Tx, outboxEvent *model.OutboxEvent) (*model.OutboxEvent, error){
+	childLogger.Info().Str("func","AddOutboxEvent").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("event_type", outboxEvent.EventType).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddOutboxEvent")
+	defer span.End()
+
+	// Query Execute
+	query := `INSERT INTO outbox_event (aggregate_id,
+										tenant_id,
+										event_type,
+										payload)
+				VALUES($1, $2, $3, $4) RETURNING id, created_at`
+
+	row := pgxTx(tx).QueryRow(ctx, query,	outboxEvent.AggregateID,
+									outboxEvent.TenantID,
+									outboxEvent.EventType,
+									outboxEvent.Payload)
+	if err := row.Scan(&outboxEvent.ID, &outboxEvent.CreatedAt); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return outboxEvent, nil
+}
+
+// About claim a batch of pending events for the lease, in a short transaction of its own with the relay lock
+// (the claims are serialized). The publish runs out of it, an expired lease is claimed again (at-least-once).
+// An event waits while an earlier event of the same account is claimed (ordering per account), the parked events are skipped
+func (w WorkerRepository) ClaimOutboxEvent(ctx context.Context, limit int, lease time.Duration) (*[]model.OutboxEvent, error){
+	childLogger.Debug().Str("func","ClaimOutboxEvent").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ClaimOutboxEvent")
+	defer span.End()
+
+	// db connection
+	tx, err := w.StartTx(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer tx.Rollback(ctx)
+
+	res_outboxEvent_list := []model.OutboxEvent{}
+
+	// another replica is claiming
+	locked, err := w.TryOutboxRelayLock(ctx, tx)
+	if err != nil || !locked {
+		return &res_outboxEvent_list, err
+	}
+
+	// Query and Execute
+	query := `WITH due AS (	SELECT e.id
+							FROM outbox_event e
+							WHERE e.published_at is null
+							and e.parked_at is null
+							and (e.claimed_until is null or e.claimed_until < now())
+							and not exists (SELECT 1
+											FROM outbox_event p
+											WHERE p.aggregate_id = e.aggregate_id
+											and p.id < e.id
+											and p.published_at is null
+											and p.parked_at is null
+											and p.claimed_until >= now())
+							order by e.id
+							limit $1 )
+				UPDATE outbox_event e
+				SET claimed_until = now() + $2 * interval '1 second'
+				FROM due
+				WHERE e.id = due.id
+				RETURNING 	e.id,
+							e.aggregate_id,
+							e.tenant_id,
+							e.event_type,
+							e.payload,
+							e.created_at,
+							e.attempts,
+							e.claimed_until`
+
+	rows, err := pgxTx(tx).Query(ctx, query, limit, int(lease.Seconds()))
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	for rows.Next() {
+		outboxEvent := model.OutboxEvent{}
+		err := rows.Scan( 	&outboxEvent.ID,
+							&outboxEvent.AggregateID,
+							&outboxEvent.TenantID,
+							&outboxEvent.EventType,
+							&outboxEvent.Payload,
+							&outboxEvent.CreatedAt,
+							&outboxEvent.Attempts,
+							&outboxEvent.ClaimedUntil,
+						)
+		if err != nil {
+			rows.Close()
+			return nil, errors.New(err.Error())
+        }
+		res_outboxEvent_list = append(res_outboxEvent_list, outboxEvent)
+	}
+	rows.Close()
+    if err := rows.Err(); err != nil {
+        return nil, errors.New(err.Error())
+    }
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// RETURNING has no order, the events are published in the write order
+	sort.Slice(res_outboxEvent_list, func(i, j int) bool { return res_outboxEvent_list[i].ID < res_outboxEvent_list[j].ID })
+
+	return &res_outboxEvent_list, nil
+}
+
+// About try to take the relay lock until the end of the transaction
+func (w WorkerRepository) TryOutboxRelayLock(ctx context.Context, tx port.Tx) (bool, error){
+	var locked bool
+	if err := pgxTx(tx).QueryRow(ctx, `SELECT pg_try_advisory_xact_lock($1)`, outboxRelayLock).Scan(&locked); err != nil {
+		return false, errors.New(err.Error())
+	}
+	return locked, nil
+}
+
+// About list the events not published yet (nor parked), in the order they were written
+func (w WorkerRepository) ListPendingOutboxEvent(ctx context.Context, tx port.Tx, limit int) (*[]model.OutboxEvent, error){
+	childLogger.Info().Str("func","ListPendingOutboxEvent").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.ListPendingOutboxEvent")
+	defer span.End()
+
+	// Query and Execute
+	query := `SELECT 	id,
+						aggregate_id,
+						tenant_id,
+						event_type,
+						payload,
+						created_at,
+						attempts
+				FROM outbox_event
+				WHERE published_at is null
+				and parked_at is null
+				order by id
+				limit $1`
+
+	rows, err := pgxTx(tx).Query(ctx, query, limit)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	res_outboxEvent_list := []model.OutboxEvent{}
+	for rows.Next() {
+		outboxEvent := model.OutboxEvent{}
+		err := rows.Scan( 	&outboxEvent.ID,
+							&outboxEvent.AggregateID,
+							&outboxEvent.TenantID,
+							&outboxEvent.EventType,
+							&outboxEvent.Payload,
+							&outboxEvent.CreatedAt,
+							&outboxEvent.Attempts,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res_outboxEvent_list = append(res_outboxEvent_list, outboxEvent)
+	}
+    if err := rows.Err(); err != nil {
+        return nil, errors.New(err.Error())
+    }
+
+	return &res_outboxEvent_list, nil
+}
+
+// About mark the events as published
+func (w WorkerRepository) MarkOutboxEventPublished(ctx context.Context, tx port.Tx, ids []int64) (int64, error){
+	childLogger.Info().Str("func","MarkOutboxEventPublished").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("events", len(ids)).Send()
+
+	query := `UPDATE outbox_event
+				SET published_at = now(),
+					attempts = attempts + 1,
+					last_error = null,
+					claimed_until = null
+				WHERE id = any($1)`
+
+	row, err := pgxTx(tx).Exec(ctx, query, ids)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	return row.RowsAffected(), nil
+}
+
+// About record a failed publish attempt of an event, it is parked when it reached the max attempts (0 never parks)
+func (w WorkerRepository) MarkOutboxEventFailed(ctx context.Context, tx port.Tx, id int64, lastError string, maxAttempts int) (int64, error){
+	childLogger.Info().Str("func","MarkOutboxEventFailed").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int64("id", id).Send()
+
+	query := `UPDATE outbox_event
+				SET attempts = attempts + 1,
+					last_error = $2,
+					claimed_until = null,
+					parked_at = CASE WHEN $3 > 0 and attempts + 1 >= $3 THEN now() ELSE null END
+				WHERE id = $1`
+
+	row, err := pgxTx(tx).Exec(ctx, query, id, lastError, maxAttempts)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	return row.RowsAffected(), nil
+}
+
+// About give back the claim of the events not published (waiting behind a failure of the same account)
+func (w WorkerRepository) ReleaseOutboxEvent(ctx context.Context, tx port.Tx, ids []int64) (int64, error){
+	childLogger.Info().Str("func","ReleaseOutboxEvent").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("events", len(ids)).Send()
+
+	query := `UPDATE outbox_event
+				SET claimed_until = null
+				WHERE id = any($1)
+				and published_at is null`
+
+	row, err := pgxTx(tx).Exec(ctx, query, ids)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+	return row.RowsAffected(), nil
+}
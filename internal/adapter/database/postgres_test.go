@@ -0,0 +1,210 @@
+package database
+
+import (
+	"os"
+	"fmt"
+	"net"
+	"time"
+	"context"
+	"testing"
+	"os/exec"
+	"strconv"
+	"path/filepath"
+
+	"github.com/go-account/internal/core/port"
+
+	go_core_pg "github.com/eliezerraj/go-core/database/pg"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// About the throwaway Postgres of the repository tests, started by TestMain with initdb/pg_ctl
+// (PATH or /usr/lib/postgresql/*/bin). The tests are skipped when it is not available.
+// The application connects with a role that is not superuser, so the row level security is applied.
+const (
+	testDatabaseName	= "account"
+	testDatabaseUser	= "account_app"
+	testDatabasePass	= "account_app"
+)
+
+var (
+	testRepository	*WorkerRepository
+	testAdminConn	*pgx.Conn
+	testSkipReason	string
+)
+
+func TestMain(m *testing.M) {
+	stop, err := startTestPostgres()
+	if err != nil {
+		testSkipReason = err.Error()
+	}
+
+	code := m.Run()
+
+	if stop != nil {
+		stop()
+	}
+	os.Exit(code)
+}
+
+// About the repository on the throwaway Postgres, with all the tables empty
+func newTestRepository(t *testing.T) *WorkerRepository {
+	t.Helper()
+
+	if testRepository == nil {
+		t.Skipf("postgres not available: %s", testSkipReason)
+	}
+
+	// the admin connection is superuser, so the truncate is not filtered by the tenant
+	query := `TRUNCATE account, account_product, interest_rate, fee_schedule, account_number_format,
+						tenant_role, api_key, outbox_event, webhook_subscription, consumed_message,
+						account_event_stream, account_snapshot, rate_limit_bucket
+				RESTART IDENTITY CASCADE`
+	if _, err := testAdminConn.Exec(context.Background(), query); err != nil {
+		t.Fatal(err)
+	}
+
+	return testRepository
+}
+
+// About run fn into a transaction of the repository, it commits when fn succeeds
+func inTestTx(t *testing.T, ctx context.Context, repository *WorkerRepository, fn func(tx port.Tx) error) error {
+	t.Helper()
+
+	tx, err := repository.StartTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback(ctx)
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// About find a postgres binary in the PATH or in the debian layout
+func findPostgresBin(name string) (string, error) {
+	if path, err := exec.LookPath(name); err == nil {
+		return path, nil
+	}
+	matches, _ := filepath.Glob(filepath.Join("/usr/lib/postgresql", "*", "bin", name))
+	if len(matches) > 0 {
+		return matches[len(matches)-1], nil
+	}
+	return "", fmt.Errorf("%s not found", name)
+}
+
+// About a free local tcp port
+func freeTestPort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// About initdb into a temp dir, start it, create the application role and database and migrate it
+func startTestPostgres() (func(), error) {
+	if os.Getenv("ACCOUNT_SKIP_DB_TEST") != "" {
+		return nil, fmt.Errorf("ACCOUNT_SKIP_DB_TEST is set")
+	}
+	if os.Geteuid() == 0 {
+		return nil, fmt.Errorf("postgres does not run as root")
+	}
+	initdb, err := findPostgresBin("initdb")
+	if err != nil {
+		return nil, err
+	}
+	pgCtl, err := findPostgresBin("pg_ctl")
+	if err != nil {
+		return nil, err
+	}
+	port, err := freeTestPort()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "go-account-pg-")
+	if err != nil {
+		return nil, err
+	}
+	dataDir := filepath.Join(dir, "data")
+
+	out, err := exec.Command(initdb, "-D", dataDir, "-U", "postgres", "--auth=trust", "--no-sync", "-E", "UTF8").CombinedOutput()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("initdb: %v %s", err, out)
+	}
+
+	options := fmt.Sprintf("-p %d -k %s -c listen_addresses=127.0.0.1 -c fsync=off", port, dir)
+	out, err = exec.Command(pgCtl, "-D", dataDir, "-o", options, "-l", filepath.Join(dir, "postgres.log"), "-w", "start").CombinedOutput()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("pg_ctl start: %v %s", err, out)
+	}
+
+	stop := func() {
+		if testAdminConn != nil {
+			testAdminConn.Close(context.Background())
+		}
+		if testRepository != nil {
+			testRepository.DatabasePGServer.CloseConnection()
+		}
+		exec.Command(pgCtl, "-D", dataDir, "-m", "immediate", "stop").Run()
+		os.RemoveAll(dir)
+	}
+
+	if err := setupTestDatabase(port); err != nil {
+		stop()
+		testRepository = nil
+		return nil, err
+	}
+
+	return stop, nil
+}
+
+// About create the role and database, open the repository and apply the migrations
+func setupTestDatabase(port int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 60 * time.Second)
+	defer cancel()
+
+	adminConn, err := pgx.Connect(ctx, fmt.Sprintf("postgres://postgres@127.0.0.1:%d/postgres?sslmode=disable", port))
+	if err != nil {
+		return err
+	}
+	if _, err := adminConn.Exec(ctx, fmt.Sprintf("CREATE ROLE %s LOGIN PASSWORD '%s' NOSUPERUSER NOBYPASSRLS", testDatabaseUser, testDatabasePass)); err != nil {
+		adminConn.Close(ctx)
+		return err
+	}
+	if _, err := adminConn.Exec(ctx, fmt.Sprintf("CREATE DATABASE %s OWNER %s", testDatabaseName, testDatabaseUser)); err != nil {
+		adminConn.Close(ctx)
+		return err
+	}
+	adminConn.Close(ctx)
+
+	testAdminConn, err = pgx.Connect(ctx, fmt.Sprintf("postgres://postgres@127.0.0.1:%d/%s?sslmode=disable", port, testDatabaseName))
+	if err != nil {
+		return err
+	}
+
+	var databasePGServer go_core_pg.DatabasePGServer
+	databasePGServer, err = databasePGServer.NewDatabasePGServer(ctx, go_core_pg.DatabaseConfig{	Host: "127.0.0.1",
+																								Port: strconv.Itoa(port),
+																								DatabaseName: testDatabaseName,
+																								User: testDatabaseUser,
+																								Password: testDatabasePass,
+																								DbMax_Connection: 5,
+																							})
+	if err != nil {
+		return err
+	}
+	testRepository = NewWorkerRepository(&databasePGServer)
+
+	// the schema is created by the embedded migrations, as the application role (owner of the tables)
+	if _, err := testRepository.MigrateUp(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"time"
+	"errors"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
+	"github.com/go-account/internal/core/erro"
+)
+
+// About get an account balance per currency and lock it until the end of the transaction
+func (w WorkerRepository) GetAccountBalanceForUpdate(ctx context.Context, tx port.Tx, accountBalance *model.AccountBalance) (*model.AccountBalance, error){
+	childLogger.Info().Str("func","GetAccountBalanceForUpdate").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetAccountBalanceForUpdate")
+	defer span.End()
+
+	// Prepare
+	res_accountBalance := model.AccountBalance{}
+
+	// Query and Execute
+	query := `SELECT 	ab.id,
+						a.account_id,
+						ab.fk_account_id,
+						ab.currency,
+						ab.amount,
+						ab.tenant_id,
+						ab.created_at,
+						ab.updated_at
+				FROM account_balance ab
+				JOIN account a on a.id = ab.fk_account_id
+				WHERE ab.fk_account_id = $1
+				and ab.currency = $2
+				FOR UPDATE OF ab`
+
+	rows, err := pgxTx(tx).Query(ctx, query, accountBalance.FkAccountID, accountBalance.Currency)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+    if err := rows.Err(); err != nil {
+		childLogger.Error().Err(err).Msg("fatal error closing rows")
+        return nil, errors.New(err.Error())
+    }
+
+	for rows.Next() {
+		err := rows.Scan( 	&res_accountBalance.ID,
+							&res_accountBalance.AccountID,
+							&res_accountBalance.FkAccountID,
+							&res_accountBalance.Currency,
+							&res_accountBalance.Amount,
+							&res_accountBalance.TenantID,
+							&res_accountBalance.CreatedAt,
+							&res_accountBalance.UpdatedAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		return &res_accountBalance, nil
+	}
+
+	return nil, erro.ErrNotFound
+}
+
+// About add a transfer
+func (w WorkerRepository) AddTransfer(ctx context.Context, tx port.Tx, transfer *model.Transfer) (*model.Transfer, error){
+	childLogger.Info().Str("func","AddTransfer").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddTransfer")
+	defer span.End()
+
+	//Prepare
+	var id int
+	transfer.TransferAt = time.Now()
+
+	// Query Execute
+	query := `INSERT INTO transfer_moviment (fk_account_id_from,
+											fk_account_id_to,
+											type_charge,
+											status,
+											currency,
+											amount,
+											transfer_at)
+				VALUES($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+	row := pgxTx(tx).QueryRow(ctx, query,	transfer.AccountFrom.FkAccountID,
+									transfer.AccountTo.FkAccountID,
+									transfer.Type,
+									transfer.Status,
+									transfer.Currency,
+									transfer.Amount,
+									transfer.TransferAt)
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Set PK
+	transfer.ID = id
+	return transfer , nil
+}
@@ -0,0 +1,446 @@
+package database
+
+import (
+	"context"
+	"time"
+	"errors"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
+	"github.com/go-account/internal/core/erro"
+)
+
+// About create or replace an account product
+func (w WorkerRepository) AddAccountProduct(ctx context.Context, tx port.Tx, accountProduct *model.AccountProduct) (*model.AccountProduct, error){
+	childLogger.Info().Str("func","AddAccountProduct").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddAccountProduct")
+	defer span.End()
+
+	//Prepare
+	var id int
+	accountProduct.CreatedAt = time.Now()
+
+	// Query Execute
+	query := `INSERT INTO account_product (	product_id,
+											type_product,
+											description,
+											currencies,
+											overdraft,
+											tenant_id,
+											created_at)
+				VALUES($1, $2, $3, $4, $5, $6, $7)
+				ON CONFLICT (product_id, tenant_id) DO UPDATE
+				SET type_product = excluded.type_product,
+					description = excluded.description,
+					currencies = excluded.currencies,
+					overdraft = excluded.overdraft,
+					updated_at = excluded.created_at
+				RETURNING id`
+
+	row := pgxTx(tx).QueryRow(ctx, query,	accountProduct.ProductID,
+									accountProduct.Type,
+									accountProduct.Description,
+									accountProduct.Currencies,
+									accountProduct.Overdraft,
+									accountProduct.TenantID,
+									accountProduct.CreatedAt)
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Set PK
+	accountProduct.ID = id
+	return accountProduct , nil
+}
+
+// About get an account product of a tenant
+func (w WorkerRepository) GetAccountProduct(ctx context.Context, accountProduct *model.AccountProduct) (*model.AccountProduct, error){
+	childLogger.Info().Str("func","GetAccountProduct").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetAccountProduct")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_accountProduct := model.AccountProduct{}
+
+	// Query and Execute
+	query := `SELECT 	id,
+						product_id,
+						type_product,
+						description,
+						currencies,
+						overdraft,
+						tenant_id,
+						created_at,
+						updated_at
+				FROM account_product
+				WHERE product_id = $1
+				and tenant_id = $2`
+
+	rows, err := conn.Query(ctx, query, accountProduct.ProductID, accountProduct.TenantID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+    if err := rows.Err(); err != nil {
+		childLogger.Error().Err(err).Msg("fatal error closing rows")
+        return nil, errors.New(err.Error())
+    }
+
+	for rows.Next() {
+		err := rows.Scan( 	&res_accountProduct.ID,
+							&res_accountProduct.ProductID,
+							&res_accountProduct.Type,
+							&res_accountProduct.Description,
+							&res_accountProduct.Currencies,
+							&res_accountProduct.Overdraft,
+							&res_accountProduct.TenantID,
+							&res_accountProduct.CreatedAt,
+							&res_accountProduct.UpdatedAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		return &res_accountProduct, nil
+	}
+
+	return nil, erro.ErrNotFound
+}
+
+// About list all account products of a tenant
+func (w WorkerRepository) ListAccountProduct(ctx context.Context, accountProduct *model.AccountProduct) (*[]model.AccountProduct, error){
+	childLogger.Info().Str("func","ListAccountProduct").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListAccountProduct")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_accountProduct := model.AccountProduct{}
+	res_accountProduct_list := []model.AccountProduct{}
+
+	// Query and Execute
+	query := `SELECT 	id,
+						product_id,
+						type_product,
+						description,
+						currencies,
+						overdraft,
+						tenant_id,
+						created_at,
+						updated_at
+				FROM account_product
+				WHERE tenant_id = $1
+				order by product_id`
+
+	rows, err := conn.Query(ctx, query, accountProduct.TenantID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+    if err := rows.Err(); err != nil {
+		childLogger.Error().Err(err).Msg("fatal error closing rows")
+        return nil, errors.New(err.Error())
+    }
+
+	for rows.Next() {
+		err := rows.Scan( 	&res_accountProduct.ID,
+							&res_accountProduct.ProductID,
+							&res_accountProduct.Type,
+							&res_accountProduct.Description,
+							&res_accountProduct.Currencies,
+							&res_accountProduct.Overdraft,
+							&res_accountProduct.TenantID,
+							&res_accountProduct.CreatedAt,
+							&res_accountProduct.UpdatedAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res_accountProduct_list = append(res_accountProduct_list, res_accountProduct)
+	}
+
+	return &res_accountProduct_list, nil
+}
+
+// About create or replace the interest rate of an account product
+func (w WorkerRepository) AddInterestRate(ctx context.Context, tx port.Tx, interestRate *model.InterestRate) (*model.InterestRate, error){
+	childLogger.Info().Str("func","AddInterestRate").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddInterestRate")
+	defer span.End()
+
+	//Prepare
+	var id int
+
+	// Query Execute
+	query := `INSERT INTO interest_rate (	product_id,
+											rate,
+											day_count,
+											tenant_id)
+				VALUES($1, $2, $3, $4)
+				ON CONFLICT (product_id, tenant_id) DO UPDATE
+				SET rate = excluded.rate,
+					day_count = excluded.day_count
+				RETURNING id`
+
+	row := pgxTx(tx).QueryRow(ctx, query,	interestRate.ProductID,
+									interestRate.Rate,
+									interestRate.DayCount,
+									interestRate.TenantID)
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Set PK
+	interestRate.ID = id
+	return interestRate , nil
+}
+
+// About get the interest rate of an account product
+func (w WorkerRepository) GetInterestRate(ctx context.Context, interestRate *model.InterestRate) (*model.InterestRate, error){
+	childLogger.Info().Str("func","GetInterestRate").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetInterestRate")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_interestRate := model.InterestRate{}
+
+	// Query and Execute
+	query := `SELECT 	id,
+						product_id,
+						rate,
+						day_count,
+						tenant_id
+				FROM interest_rate
+				WHERE product_id = $1
+				and tenant_id = $2`
+
+	rows, err := conn.Query(ctx, query, interestRate.ProductID, interestRate.TenantID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+    if err := rows.Err(); err != nil {
+		childLogger.Error().Err(err).Msg("fatal error closing rows")
+        return nil, errors.New(err.Error())
+    }
+
+	for rows.Next() {
+		err := rows.Scan( 	&res_interestRate.ID,
+							&res_interestRate.ProductID,
+							&res_interestRate.Rate,
+							&res_interestRate.DayCount,
+							&res_interestRate.TenantID,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		return &res_interestRate, nil
+	}
+
+	return nil, erro.ErrNotFound
+}
+
+// About add a fee schedule of a tenant, an empty product_id is the schedule of the tenant
+func (w WorkerRepository) AddFeeSchedule(ctx context.Context, tx port.Tx, feeSchedule *model.FeeSchedule) (*model.FeeSchedule, error){
+	childLogger.Info().Str("func","AddFeeSchedule").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddFeeSchedule")
+	defer span.End()
+
+	//Prepare
+	var id int
+	var productID *string
+	if feeSchedule.ProductID != "" {
+		productID = &feeSchedule.ProductID
+	}
+	tiers := feeSchedule.Tiers
+	if tiers == nil {
+		tiers = []model.FeeTier{}
+	}
+
+	// Query Execute
+	query := `INSERT INTO fee_schedule (	tenant_id,
+											product_id,
+											operation,
+											method,
+											currency,
+											amount,
+											percentage,
+											min_fee,
+											max_fee,
+											tiers)
+				VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id`
+
+	row := pgxTx(tx).QueryRow(ctx, query,	feeSchedule.TenantID,
+									productID,
+									feeSchedule.Operation,
+									feeSchedule.Method,
+									feeSchedule.Currency,
+									feeSchedule.Amount,
+									feeSchedule.Percentage,
+									feeSchedule.MinFee,
+									feeSchedule.MaxFee,
+									tiers)
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Set PK
+	feeSchedule.ID = id
+	return feeSchedule , nil
+}
+
+// About delete the fee schedules bound to an account product (the schedules of the tenant are kept)
+func (w WorkerRepository) DeleteFeeScheduleProduct(ctx context.Context, tx port.Tx, feeSchedule *model.FeeSchedule) (int64, error){
+	childLogger.Info().Str("func","DeleteFeeScheduleProduct").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.DeleteFeeScheduleProduct")
+	defer span.End()
+
+	// Query Execute
+	query := `DELETE FROM fee_schedule
+				WHERE product_id = $1
+				and tenant_id = $2`
+
+	row, err := pgxTx(tx).Exec(ctx, query, feeSchedule.ProductID, feeSchedule.TenantID)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+
+	return row.RowsAffected(), nil
+}
+
+// About list the fee schedules bound to an account product
+func (w WorkerRepository) ListFeeScheduleProduct(ctx context.Context, feeSchedule *model.FeeSchedule) (*[]model.FeeSchedule, error){
+	childLogger.Info().Str("func","ListFeeScheduleProduct").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListFeeScheduleProduct")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_feeSchedule := model.FeeSchedule{}
+	res_feeSchedule_list := []model.FeeSchedule{}
+
+	// Query and Execute
+	query := `SELECT 	id,
+						tenant_id,
+						product_id,
+						operation,
+						method,
+						currency,
+						amount,
+						percentage,
+						min_fee,
+						max_fee,
+						tiers
+				FROM fee_schedule
+				WHERE product_id = $1
+				and tenant_id = $2
+				order by operation, currency`
+
+	rows, err := conn.Query(ctx, query, feeSchedule.ProductID, feeSchedule.TenantID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+    if err := rows.Err(); err != nil {
+		childLogger.Error().Err(err).Msg("fatal error closing rows")
+        return nil, errors.New(err.Error())
+    }
+
+	for rows.Next() {
+		err := rows.Scan( 	&res_feeSchedule.ID,
+							&res_feeSchedule.TenantID,
+							&res_feeSchedule.ProductID,
+							&res_feeSchedule.Operation,
+							&res_feeSchedule.Method,
+							&res_feeSchedule.Currency,
+							&res_feeSchedule.Amount,
+							&res_feeSchedule.Percentage,
+							&res_feeSchedule.MinFee,
+							&res_feeSchedule.MaxFee,
+							&res_feeSchedule.Tiers,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res_feeSchedule_list = append(res_feeSchedule_list, res_feeSchedule)
+	}
+
+	return &res_feeSchedule_list, nil
+}
+
+// About open an account balance
+func (w WorkerRepository) AddAccountBalance(ctx context.Context, tx port.Tx, accountBalance *model.AccountBalance) (*model.AccountBalance, error){
+	childLogger.Info().Str("func","AddAccountBalance").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddAccountBalance")
+	defer span.End()
+
+	//Prepare
+	var id int
+	accountBalance.CreatedAt = time.Now()
+
+	// Query Execute
+	query := `INSERT INTO account_balance (	fk_account_id,
+											currency,
+											amount,
+											tenant_id,
+											user_last_update,
+											created_at)
+				VALUES($1, $2, $3, $4, $5, $6) RETURNING id`
+
+	row := pgxTx(tx).QueryRow(ctx, query,	accountBalance.FkAccountID,
+									accountBalance.Currency,
+									accountBalance.Amount,
+									accountBalance.TenantID,
+									accountBalance.UserLastUpdate,
+									accountBalance.CreatedAt)
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Set PK
+	accountBalance.ID = id
+	return accountBalance , nil
+}
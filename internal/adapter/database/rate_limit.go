@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+	"errors"
+)
+
+// About take a token of a bucket shared by the replicas, the refill (rate tokens per second up to burst)
+// and the take are done in a single statement, it returns the tokens left and if the token was taken
+func (w WorkerRepository) TakeRateLimitToken(ctx context.Context, key string, rate float64, burst int) (float64, bool, error){
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return 0, false, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute
+	query := `INSERT INTO rate_limit_bucket AS b (bucket_key, tokens, allowed, updated_at)
+				VALUES($1, $3::float8 - 1, true, now())
+				ON CONFLICT (bucket_key) DO UPDATE
+				SET tokens = CASE WHEN least($3::float8, b.tokens + extract(epoch from now() - b.updated_at) * $2::float8) >= 1
+								THEN least($3::float8, b.tokens + extract(epoch from now() - b.updated_at) * $2::float8) - 1
+								ELSE least($3::float8, b.tokens + extract(epoch from now() - b.updated_at) * $2::float8) END,
+					allowed = least($3::float8, b.tokens + extract(epoch from now() - b.updated_at) * $2::float8) >= 1,
+					updated_at = now()
+				RETURNING tokens, allowed`
+
+	var tokens float64
+	var allowed bool
+	if err := conn.QueryRow(ctx, query, key, rate, burst).Scan(&tokens, &allowed); err != nil {
+		return 0, false, errors.New(err.Error())
+	}
+
+	return tokens, allowed, nil
+}
@@ -1,12 +1,16 @@
 package database
 
 import (
+	"fmt"
 	"context"
 	"time"
 	"errors"
+	"strings"
 	
 	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
 	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/auth"
 
 	go_core_observ "github.com/eliezerraj/go-core/observability"
 	go_core_pg "github.com/eliezerraj/go-core/database/pg"
@@ -54,7 +58,7 @@ func (w WorkerRepository) Stat(ctx context.Context) (go_core_pg.PoolStats){
 }
 
 // About create a account
-func (w WorkerRepository) AddAccount(ctx context.Context, tx pgx.Tx, account *model.Account) (*model.Account, error){
+func (w WorkerRepository) AddAccount(ctx context.Context, tx port.Tx, account *model.Account) (*model.Account, error){
 	childLogger.Info().Str("func","AddAccount").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
 
 	// trace
@@ -69,13 +73,19 @@ func (w WorkerRepository) AddAccount(ctx context.Context, tx pgx.Tx, account *mo
 	query := `INSERT INTO account ( account_id, 
 									person_id, 
 									created_at,
-									tenant_id) 
-				VALUES($1, $2, $3, $4) RETURNING id`
+									tenant_id,
+									product_id,
+									user_last_update,
+									status) 
+				VALUES($1, $2, $3, $4, $5, $6, coalesce(nullif($7, ''), 'ACTIVE')) RETURNING id`
 
-	row := tx.QueryRow(ctx, query,account.AccountID, 
+	row := pgxTx(tx).QueryRow(ctx, query,account.AccountID, 
 									account.PersonID,
 									account.CreatedAt,
-									account.TenantID)
+									account.TenantID,
+									account.ProductID,
+									account.UserLastUpdate,
+									account.Status)
 	if err := row.Scan(&id); err != nil {
 		return nil, errors.New(err.Error())
 	}
@@ -94,7 +104,7 @@ func (w WorkerRepository) GetAccount(ctx context.Context, account *model.Account
 	defer span.End()
 
 	// db connection
-	conn, err := w.DatabasePGServer.Acquire(ctx)
+	conn, err := w.acquire(ctx)
 	if err != nil {
 		return nil, errors.New(err.Error())
 	}
@@ -110,11 +120,14 @@ func (w WorkerRepository) GetAccount(ctx context.Context, account *model.Account
 					created_at, 
 					updated_at, 
 					tenant_id, 
-					user_last_update 
+					user_last_update,
+					coalesce(product_id,''),
+					status
 				FROM account 
-				WHERE account_id =$1`
+				WHERE account_id =$1
+				and ($3 or tenant_id = $2)`
 
-	rows, err := conn.Query(ctx, query, account.AccountID)
+	rows, err := conn.Query(ctx, query, account.AccountID, auth.TenantID(ctx), auth.TenantBypass(ctx))
 	if err != nil {
 		return nil, errors.New(err.Error())
 	}
@@ -132,6 +145,8 @@ func (w WorkerRepository) GetAccount(ctx context.Context, account *model.Account
 							&res_account.UpdatedAt,
 							&res_account.TenantID,
 							&res_account.UserLastUpdate,
+							&res_account.ProductID,
+							&res_account.Status,
 							)
 		if err != nil {
 			return nil, errors.New(err.Error())
@@ -142,6 +157,51 @@ func (w WorkerRepository) GetAccount(ctx context.Context, account *model.Account
 	return nil, erro.ErrNotFound
 }
 
+// About get an account and lock it until the end of the transaction
+func (w WorkerRepository) GetAccountForUpdate(ctx context.Context, tx port.Tx, account *model.Account) (*model.Account, error){
+	childLogger.Info().Str("func","GetAccountForUpdate").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetAccountForUpdate")
+	defer span.End()
+
+	// Prepare
+	res_account := model.Account{}
+
+	// Query and Execute
+	query := `SELECT id, 
+					account_id, 
+					person_id, 
+					created_at, 
+					updated_at, 
+					tenant_id, 
+					user_last_update,
+					coalesce(product_id,''),
+					status
+				FROM account 
+				WHERE account_id =$1
+				and ($3 or tenant_id = $2)
+				FOR UPDATE`
+
+	err := pgxTx(tx).QueryRow(ctx, query, account.AccountID, auth.TenantID(ctx), auth.TenantBypass(ctx)).Scan(	&res_account.ID, 
+																												&res_account.AccountID, 
+																												&res_account.PersonID, 
+																												&res_account.CreatedAt,
+																												&res_account.UpdatedAt,
+																												&res_account.TenantID,
+																												&res_account.UserLastUpdate,
+																												&res_account.ProductID,
+																												&res_account.Status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, erro.ErrNotFound
+		}
+		return nil, errors.New(err.Error())
+	}
+
+	return &res_account, nil
+}
+
 // About get an account from id (pk)
 func (w WorkerRepository) GetAccountId(ctx context.Context, account *model.Account) (*model.Account, error){
 	childLogger.Info().Str("func","GetAccountId").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
@@ -151,7 +211,7 @@ func (w WorkerRepository) GetAccountId(ctx context.Context, account *model.Accou
 	defer span.End()
 
 	// db connection
-	conn, err := w.DatabasePGServer.Acquire(ctx)
+	conn, err := w.acquire(ctx)
 	if err != nil {
 		return nil, errors.New(err.Error())
 	}
@@ -167,11 +227,14 @@ func (w WorkerRepository) GetAccountId(ctx context.Context, account *model.Accou
 					created_at, 
 					updated_at, 
 					tenant_id, 
-					user_last_update 
+					user_last_update,
+					coalesce(product_id,''),
+					status
 				FROM account 
-				WHERE id =$1`
+				WHERE id =$1
+				and ($3 or tenant_id = $2)`
 
-	rows, err := conn.Query(ctx, query, account.ID)
+	rows, err := conn.Query(ctx, query, account.ID, auth.TenantID(ctx), auth.TenantBypass(ctx))
 	if err != nil {
 		return nil, errors.New(err.Error())
 	}
@@ -189,6 +252,8 @@ func (w WorkerRepository) GetAccountId(ctx context.Context, account *model.Accou
 							&res_account.UpdatedAt,
 							&res_account.TenantID,
 							&res_account.UserLastUpdate,
+							&res_account.ProductID,
+							&res_account.Status,
 							)
 		if err != nil {
 			return nil, errors.New(err.Error())
@@ -212,7 +277,7 @@ func (w WorkerRepository) ListAccountPerPerson(ctx context.Context, account *mod
 	res_account_list := []model.Account{}
 
 	// db connection
-	conn, err := w.DatabasePGServer.Acquire(ctx)
+	conn, err := w.acquire(ctx)
 	if err != nil {
 		return nil, errors.New(err.Error())
 	}
@@ -225,11 +290,15 @@ func (w WorkerRepository) ListAccountPerPerson(ctx context.Context, account *mod
 						created_at, 
 						updated_at, 
 						user_last_update,
-						tenant_id 
+						tenant_id,
+						coalesce(product_id,''),
+						status
 						FROM account 
-						WHERE person_id =$1 order by id desc`
+						WHERE person_id =$1
+						and ($3 or tenant_id = $2)
+						order by id desc`
 
-	rows, err := conn.Query(ctx, query, account.PersonID)
+	rows, err := conn.Query(ctx, query, account.PersonID, auth.TenantID(ctx), auth.TenantBypass(ctx))
 	if err != nil {
 		return nil, errors.New(err.Error())
 	}
@@ -248,6 +317,8 @@ func (w WorkerRepository) ListAccountPerPerson(ctx context.Context, account *mod
 							&res_account.UpdatedAt,
 							&res_account.UserLastUpdate,
 							&res_account.TenantID,
+							&res_account.ProductID,
+							&res_account.Status,
 						)
 		if err != nil {
 			return nil, errors.New(err.Error())
@@ -258,56 +329,78 @@ func (w WorkerRepository) ListAccountPerPerson(ctx context.Context, account *mod
 	return &res_account_list, nil
 }
 
-// About update an account
-func (w WorkerRepository) UpdateAccount(ctx context.Context, tx pgx.Tx, account *model.Account) (int64, error){
-	childLogger.Info().Str("func","UpdateAccount").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+// About update only the informed fields of an account and return the updated account
+func (w WorkerRepository) PatchAccount(ctx context.Context, tx port.Tx, accountPatch *model.AccountPatch) (*model.Account, error){
+	childLogger.Info().Str("func","PatchAccount").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
 
 	// trace
-	span := tracerProvider.Span(ctx, "database.UpdateAccount")
+	span := tracerProvider.Span(ctx, "database.PatchAccount")
 	defer span.End()
 
-	// Prepare
-	updateAt := time.Now()
-	account.UpdatedAt = &updateAt
+	// Prepare, the set clause has only the informed fields
+	sets := []string{}
+	args := []interface{}{}
+	if accountPatch.PersonID != nil {
+		args = append(args, *accountPatch.PersonID)
+		sets = append(sets, fmt.Sprintf("person_id = $%d", len(args)))
+	}
+	if accountPatch.Status != nil {
+		args = append(args, *accountPatch.Status)
+		sets = append(sets, fmt.Sprintf("status = $%d", len(args)))
+	}
+	args = append(args, time.Now())
+	sets = append(sets, fmt.Sprintf("updated_at = $%d", len(args)))
+	args = append(args, accountPatch.UserLastUpdate)
+	sets = append(sets, fmt.Sprintf("user_last_update = $%d", len(args)))
+	args = append(args, accountPatch.AccountID, accountPatch.TenantID)
 
 	//Query Execute
 	query := `Update account
-				set person_id = $1, 
-					updated_at = $2,
-					user_last_update =$3,
-					tenant_id = $4
-				where account_id = $5 `
-
-	row, err := tx.Exec(ctx, query, account.PersonID,
-									account.UpdatedAt,
-									account.UserLastUpdate,
-									account.TenantID,
-									account.AccountID)
+				set ` + strings.Join(sets, ", ") + fmt.Sprintf(`
+				where account_id = $%d
+				and tenant_id = $%d
+				returning id, 
+					account_id, 
+					person_id, 
+					created_at, 
+					updated_at, 
+					tenant_id, 
+					user_last_update,
+					coalesce(product_id,''),
+					status`, len(args) - 1, len(args))
+
+	res_account := model.Account{}
+	err := pgxTx(tx).QueryRow(ctx, query, args...).Scan(	&res_account.ID, 
+													&res_account.AccountID, 
+													&res_account.PersonID, 
+													&res_account.CreatedAt,
+													&res_account.UpdatedAt,
+													&res_account.TenantID,
+													&res_account.UserLastUpdate,
+													&res_account.ProductID,
+													&res_account.Status)
 	if err != nil {
-		return 0, errors.New(err.Error())
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, erro.ErrUpdate
+		}
+		return nil, errors.New(err.Error())
 	}
 
-	childLogger.Debug().Int("rowsAffected : ",int(row.RowsAffected())).Msg("")
-
-	return row.RowsAffected() , nil
+	return &res_account, nil
 }
 
 // About delete an account
-func (w WorkerRepository) DeleteAccount(ctx context.Context, account *model.Account) (bool, error){
+func (w WorkerRepository) DeleteAccount(ctx context.Context, tx port.Tx, account *model.Account) (bool, error){
 	childLogger.Info().Str("func","DeleteAccount").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
 
 	span := tracerProvider.Span(ctx, "storage.DeleteAccount")	
 	defer span.End()
 
-	conn, err := w.DatabasePGServer.Acquire(ctx)
-	if err != nil {
-		return false, errors.New(err.Error())
-	}
-	defer w.DatabasePGServer.Release(conn)
-
-	query := `Delete from account where id = $1`
+	query := `Delete from account 
+				where account_id = $1
+				and ($3 or tenant_id = $2)`
 
-	_, err = conn.Exec(ctx, query, account.AccountID)
+	_, err := pgxTx(tx).Exec(ctx, query, account.AccountID, auth.TenantID(ctx), auth.TenantBypass(ctx))
 	if err != nil {
 		return false, errors.New(err.Error())
 	}
@@ -0,0 +1,781 @@
+package database
+
+import (
+	"fmt"
+	"time"
+	"context"
+	"testing"
+	"encoding/json"
+
+	"github.com/go-account/internal/core/auth"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
+)
+
+const testTenant = "tenant-1"
+
+// the context of the jobs (all the tenants)
+func bypassContext() context.Context {
+	return auth.WithTenantBypass(context.Background())
+}
+
+func tenantContext(tenantID string) context.Context {
+	return auth.WithPrincipal(context.Background(), &model.Principal{Subject: "user-test", TenantID: tenantID})
+}
+
+// About add an account with a balance on BRL, it returns the account and the balance
+func addTestAccount(t *testing.T, repository *WorkerRepository, accountID string, tenantID string, amount float64) (*model.Account, *model.AccountBalance) {
+	t.Helper()
+
+	account := model.Account{AccountID: accountID, PersonID: "P-1", TenantID: tenantID}
+	accountBalance := model.AccountBalance{Currency: "BRL", Amount: amount, TenantID: tenantID}
+	err := inTestTx(t, bypassContext(), repository, func(tx port.Tx) error {
+		if _, err := repository.AddAccount(bypassContext(), tx, &account); err != nil {
+			return err
+		}
+		accountBalance.FkAccountID = account.ID
+		_, err := repository.AddAccountBalance(bypassContext(), tx, &accountBalance)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &account, &accountBalance
+}
+
+func TestAddAccount(t *testing.T) {
+	repository := newTestRepository(t)
+	addTestAccount(t, repository, "ACC-1", testTenant, 0)
+
+	tests := []struct {
+		name		string
+		ctx			context.Context
+		account		model.Account
+		wantErr		bool
+	}{
+		{"new account", bypassContext(), model.Account{AccountID: "ACC-2", PersonID: "P-2", TenantID: testTenant}, false},
+		{"with product", bypassContext(), model.Account{AccountID: "ACC-3", PersonID: "P-2", TenantID: testTenant, ProductID: "CHECKING"}, false},
+		{"duplicated account", bypassContext(), model.Account{AccountID: "ACC-1", PersonID: "P-2", TenantID: testTenant}, true},
+		{"other tenant of the caller", tenantContext("tenant-2"), model.Account{AccountID: "ACC-4", PersonID: "P-2", TenantID: testTenant}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := inTestTx(t, tt.ctx, repository, func(tx port.Tx) error {
+				_, err := repository.AddAccount(tt.ctx, tx, &tt.account)
+				return err
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err %v want error %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			res, err := repository.GetAccount(tt.ctx, &model.Account{AccountID: tt.account.AccountID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// the status defaults to ACTIVE
+			if res.PersonID != tt.account.PersonID || res.ProductID != tt.account.ProductID || res.TenantID != testTenant || res.Status != "ACTIVE" {
+				t.Errorf("account %+v want %+v", res, tt.account)
+			}
+		})
+	}
+}
+
+func TestPatchDeleteAccount(t *testing.T) {
+	ctx := bypassContext()
+	repository := newTestRepository(t)
+	addTestAccount(t, repository, "ACC-1", testTenant, 10)
+
+	// the lock is filtered by the tenant as the reads
+	err := inTestTx(t, ctx, repository, func(tx port.Tx) error {
+		if _, err := repository.GetAccountForUpdate(tenantContext("tenant-2"), tx, &model.Account{AccountID: "ACC-1"}); err != erro.ErrNotFound {
+			return fmt.Errorf("account of another tenant locked err %v", err)
+		}
+		_, err := repository.GetAccountForUpdate(tenantContext(testTenant), tx, &model.Account{AccountID: "ACC-1"})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	personID, status := "P-9", "BLOCKED"
+	err = inTestTx(t, ctx, repository, func(tx port.Tx) error {
+		_, err := repository.PatchAccount(ctx, tx, &model.AccountPatch{AccountID: "ACC-1", PersonID: &personID, Status: &status})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := repository.GetAccount(ctx, &model.Account{AccountID: "ACC-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.PersonID != personID || res.Status != status || res.UpdatedAt == nil {
+		t.Errorf("patched account %+v", res)
+	}
+
+	// the balances are removed with the account
+	err = inTestTx(t, ctx, repository, func(tx port.Tx) error {
+		_, err := repository.DeleteAccount(ctx, tx, &model.Account{AccountID: "ACC-1"})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repository.GetAccount(ctx, &model.Account{AccountID: "ACC-1"}); err != erro.ErrNotFound {
+		t.Errorf("deleted account err %v", err)
+	}
+	var count int
+	if err := testAdminConn.QueryRow(ctx, `SELECT count(*) FROM account_balance`).Scan(&count); err != nil || count != 0 {
+		t.Errorf("balances %d err %v", count, err)
+	}
+}
+
+func TestPostingBalance(t *testing.T) {
+	ctx := bypassContext()
+	repository := newTestRepository(t)
+	account_1, balance_1 := addTestAccount(t, repository, "ACC-1", testTenant, 100)
+	account_2, balance_2 := addTestAccount(t, repository, "ACC-2", testTenant, 0)
+
+	transactionID := "TX-1"
+	tests := []struct {
+		name		string
+		from		*model.AccountBalance
+		to			*model.AccountBalance
+		amount		float64
+		wantFrom	float64
+		wantTo		float64
+	}{
+		{"transfer", balance_1, balance_2, 30.55, 69.45, 30.55},
+		{"transfer back", balance_2, balance_1, 0.55, 30, 70},
+		{"overdraft is not checked by the repository", balance_2, balance_1, 40, -10, 110},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := inTestTx(t, ctx, repository, func(tx port.Tx) error {
+				from, err := repository.GetAccountBalanceForUpdate(ctx, tx, &model.AccountBalance{FkAccountID: tt.from.FkAccountID, Currency: "BRL"})
+				if err != nil {
+					return err
+				}
+				to, err := repository.GetAccountBalanceForUpdate(ctx, tx, &model.AccountBalance{FkAccountID: tt.to.FkAccountID, Currency: "BRL"})
+				if err != nil {
+					return err
+				}
+				if _, err := repository.UpdateAccountBalanceAmount(ctx, tx, &model.AccountBalance{ID: from.ID, Amount: -tt.amount, TransactionID: &transactionID}); err != nil {
+					return err
+				}
+				if _, err := repository.UpdateAccountBalanceAmount(ctx, tx, &model.AccountBalance{ID: to.ID, Amount: tt.amount, TransactionID: &transactionID}); err != nil {
+					return err
+				}
+				for _, accountStatement := range []model.AccountStatement{	{FkAccountID: from.FkAccountID, Type: "DEBIT", Currency: "BRL", Amount: -tt.amount, TenantID: testTenant, TransactionID: &transactionID},
+																			{FkAccountID: to.FkAccountID, Type: "CREDIT", Currency: "BRL", Amount: tt.amount, TenantID: testTenant, TransactionID: &transactionID}} {
+					if _, err := repository.AddAccountStatement(ctx, tx, &accountStatement); err != nil {
+						return err
+					}
+				}
+				_, err = repository.AddTransfer(ctx, tx, &model.Transfer{AccountFrom: *from, AccountTo: *to, Type: "TRANSFER", Status: "DONE", Currency: "BRL", Amount: tt.amount})
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			res_balances, err := repository.ListAccountBalanceByAccountIDs(ctx, []string{tt.from.AccountID, tt.to.AccountID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := res_balances[tt.from.AccountID][0].Amount; got != tt.wantFrom {
+				t.Errorf("from balance %v want %v", got, tt.wantFrom)
+			}
+			if got := res_balances[tt.to.AccountID][0].Amount; got != tt.wantTo {
+				t.Errorf("to balance %v want %v", got, tt.wantTo)
+			}
+		})
+	}
+
+	res_statements, err := repository.ListAccountStatementByAccountIDs(ctx, []string{account_1.AccountID, account_2.AccountID}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res_statements[account_1.AccountID]) != 2 || len(res_statements[account_2.AccountID]) != 2 {
+		t.Errorf("statements %+v", res_statements)
+	}
+	res_transfers, err := repository.ListTransferByAccountIDs(ctx, []string{account_1.AccountID}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res_transfers[account_1.AccountID]) != 3 {
+		t.Errorf("transfers %+v", res_transfers)
+	}
+
+	if _, err := repository.ListAccountBalance(ctx, &model.Account{AccountID: "ACC-1"}); err != nil {
+		t.Error(err)
+	}
+	err = inTestTx(t, ctx, repository, func(tx port.Tx) error {
+		_, err := repository.GetAccountBalanceForUpdate(ctx, tx, &model.AccountBalance{FkAccountID: account_1.ID, Currency: "USD"})
+		return err
+	})
+	if err != erro.ErrNotFound {
+		t.Errorf("balance of an other currency err %v", err)
+	}
+}
+
+func TestListAccountStatement(t *testing.T) {
+	ctx := bypassContext()
+	repository := newTestRepository(t)
+	account, _ := addTestAccount(t, repository, "ACC-1", testTenant, 0)
+	other, _ := addTestAccount(t, repository, "ACC-2", testTenant, 0)
+
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	err := inTestTx(t, ctx, repository, func(tx port.Tx) error {
+		for _, accountStatement := range []model.AccountStatement{	{FkAccountID: account.ID, Type: "CREDIT", ChargedAt: day.Add(2 * time.Hour), Currency: "BRL", Amount: 100, TenantID: testTenant},
+																	{FkAccountID: account.ID, Type: "DEBIT", ChargedAt: day.Add(time.Hour), Currency: "BRL", Amount: -30.5, TenantID: testTenant},
+																	{FkAccountID: account.ID, Type: "CREDIT", ChargedAt: day.AddDate(0, 0, 1), Currency: "USD", Amount: 10, TenantID: testTenant},
+																	{FkAccountID: other.ID, Type: "CREDIT", ChargedAt: day.Add(time.Hour), Currency: "BRL", Amount: 5, TenantID: testTenant}} {
+			if _, err := repository.AddAccountStatement(ctx, tx, &accountStatement); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name		string
+		ctx			context.Context
+		from		time.Time
+		to			time.Time
+		amounts		[]float64
+	}{
+		{"all", ctx, time.Time{}, day.AddDate(1, 0, 0), []float64{-30.5, 100, 10}},
+		{"one day", ctx, day, day.AddDate(0, 0, 1), []float64{-30.5, 100}},
+		{"to is excluded", ctx, day, day.Add(2 * time.Hour), []float64{-30.5}},
+		{"same tenant", tenantContext(testTenant), day, day.AddDate(0, 0, 2), []float64{-30.5, 100, 10}},
+		{"other tenant", tenantContext("tenant-2"), day, day.AddDate(0, 0, 2), []float64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := repository.ListAccountStatement(tt.ctx, account, tt.from, tt.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(*res) != len(tt.amounts) {
+				t.Fatalf("statements %+v want %v", *res, tt.amounts)
+			}
+			for i, accountStatement := range *res {
+				if accountStatement.Amount != tt.amounts[i] || accountStatement.AccountID != account.AccountID {
+					t.Errorf("statement %d %+v want %v", i, accountStatement, tt.amounts[i])
+				}
+			}
+		})
+	}
+
+	res_total, err := repository.ListAccountStatementTotal(ctx, account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []model.Reconciliation{	{AccountID: "ACC-1", Currency: "BRL", StatementTotal: 69.5, Statements: 2},
+									{AccountID: "ACC-1", Currency: "USD", StatementTotal: 10, Statements: 1}}
+	if len(*res_total) != len(want) {
+		t.Fatalf("totals %+v want %+v", *res_total, want)
+	}
+	for i, total := range *res_total {
+		if total != want[i] {
+			t.Errorf("total %+v want %+v", total, want[i])
+		}
+	}
+}
+
+func TestGetFeeSchedule(t *testing.T) {
+	ctx := bypassContext()
+	repository := newTestRepository(t)
+
+	query := `INSERT INTO fee_schedule (tenant_id, product_id, operation, method, currency, amount, tiers)
+				VALUES	($1, null, 'TRANSFER', 'FLAT', 'BRL', 5, '[]'),
+						($1, 'CHECKING', 'TRANSFER', 'FLAT', 'BRL', 2, '[]'),
+						($1, null, 'POSTING', 'TIERED', 'BRL', 0, '[{"up_to":100,"amount":1}]'),
+						('tenant-2', null, 'TRANSFER', 'FLAT', 'USD', 9, '[]')`
+	if _, err := testAdminConn.Exec(ctx, query, testTenant); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name		string
+		ctx			context.Context
+		feeSchedule	model.FeeSchedule
+		amount		float64
+		err			error
+	}{
+		{"tenant schedule", ctx, model.FeeSchedule{TenantID: testTenant, Operation: "TRANSFER", Currency: "BRL"}, 5, nil},
+		{"product has precedence", ctx, model.FeeSchedule{TenantID: testTenant, ProductID: "CHECKING", Operation: "TRANSFER", Currency: "BRL"}, 2, nil},
+		{"product without schedule", ctx, model.FeeSchedule{TenantID: testTenant, ProductID: "SAVINGS", Operation: "TRANSFER", Currency: "BRL"}, 5, nil},
+		{"tiers", ctx, model.FeeSchedule{TenantID: testTenant, Operation: "POSTING", Currency: "BRL"}, 0, nil},
+		{"other currency", ctx, model.FeeSchedule{TenantID: testTenant, Operation: "TRANSFER", Currency: "USD"}, 0, erro.ErrNotFound},
+		{"hidden by the row level security", tenantContext(testTenant), model.FeeSchedule{TenantID: "tenant-2", Operation: "TRANSFER", Currency: "USD"}, 0, erro.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := repository.GetFeeSchedule(tt.ctx, &tt.feeSchedule)
+			if err != tt.err {
+				t.Fatalf("err %v want %v", err, tt.err)
+			}
+			if err == nil && res.Amount != tt.amount {
+				t.Errorf("fee schedule %+v want amount %v", res, tt.amount)
+			}
+		})
+	}
+}
+
+func TestAddInterestRateFeeSchedule(t *testing.T) {
+	ctx := bypassContext()
+	repository := newTestRepository(t)
+
+	// the rate is replaced, the schedules of the product are replaced and the one of the tenant is kept
+	for _, rate := range []float64{0.1, 0.12} {
+		err := inTestTx(t, ctx, repository, func(tx port.Tx) error {
+			if _, err := repository.AddInterestRate(ctx, tx, &model.InterestRate{ProductID: "SAVINGS", Rate: rate, DayCount: "ACT/365", TenantID: testTenant}); err != nil {
+				return err
+			}
+			if _, err := repository.DeleteFeeScheduleProduct(ctx, tx, &model.FeeSchedule{ProductID: "SAVINGS", TenantID: testTenant}); err != nil {
+				return err
+			}
+			_, err := repository.AddFeeSchedule(ctx, tx, &model.FeeSchedule{TenantID: testTenant, ProductID: "SAVINGS", Operation: "POSTING", Method: "TIERED", Currency: "BRL", Tiers: []model.FeeTier{{UpTo: 100, Amount: 1}}})
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := inTestTx(t, ctx, repository, func(tx port.Tx) error {
+		_, err := repository.AddFeeSchedule(ctx, tx, &model.FeeSchedule{TenantID: testTenant, Operation: "TRANSFER", Method: "FLAT", Currency: "BRL", Amount: 5})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res_interestRate, err := repository.GetInterestRate(ctx, &model.InterestRate{ProductID: "SAVINGS", TenantID: testTenant})
+	if err != nil || res_interestRate.Rate != 0.12 {
+		t.Errorf("interest rate %+v err %v", res_interestRate, err)
+	}
+	res_feeSchedule, err := repository.ListFeeScheduleProduct(ctx, &model.FeeSchedule{ProductID: "SAVINGS", TenantID: testTenant})
+	if err != nil || len(*res_feeSchedule) != 1 || len((*res_feeSchedule)[0].Tiers) != 1 {
+		t.Errorf("fee schedule %+v err %v", res_feeSchedule, err)
+	}
+	if _, err := repository.GetFeeSchedule(ctx, &model.FeeSchedule{TenantID: testTenant, ProductID: "SAVINGS", Operation: "TRANSFER", Currency: "BRL"}); err != nil {
+		t.Errorf("tenant schedule err %v", err)
+	}
+}
+
+func TestListMaintenanceBalance(t *testing.T) {
+	ctx := bypassContext()
+	repository := newTestRepository(t)
+
+	_, accountBalance := addTestAccount(t, repository, "ACC-M1", testTenant, 10)
+	addTestAccount(t, repository, "ACC-M2", "tenant-2", 10)
+
+	// the schedule of tenant-2 is bound to a product that ACC-M2 does not have
+	query := `INSERT INTO fee_schedule (tenant_id, product_id, operation, method, currency, amount, tiers)
+				VALUES	($1, null, 'MAINTENANCE', 'FLAT', 'BRL', 3, '[]'),
+						('tenant-2', 'CHECKING', 'MAINTENANCE', 'FLAT', 'BRL', 3, '[]')`
+	if _, err := testAdminConn.Exec(ctx, query, testTenant); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := repository.ListMaintenanceBalance(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*res) != 1 || (*res)[0].FkAccountBalanceID != accountBalance.ID || (*res)[0].AccountID != "ACC-M1" || (*res)[0].Balance != 10 {
+		t.Errorf("maintenance balances %+v", *res)
+	}
+}
+
+func TestAddAccountStatementOnce(t *testing.T) {
+	ctx := bypassContext()
+	repository := newTestRepository(t)
+	account_1, _ := addTestAccount(t, repository, "ACC-1", testTenant, 10)
+	account_2, _ := addTestAccount(t, repository, "ACC-2", testTenant, 10)
+
+	tests := []struct {
+		name			string
+		fkAccountID		int
+		transactionID	string
+		added			bool
+	}{
+		{"first", account_1.ID, "FEE-MAINTENANCE-1-2026-01", true},
+		{"next month", account_1.ID, "FEE-MAINTENANCE-1-2026-02", true},
+		{"same month again is ignored", account_1.ID, "FEE-MAINTENANCE-1-2026-01", false},
+		{"same month of an other account", account_2.ID, "FEE-MAINTENANCE-1-2026-01", true},
+		{"other transaction ids are not unique", account_1.ID, "TX-1", true},
+		{"other transaction ids again", account_1.ID, "TX-1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var added bool
+			err := inTestTx(t, ctx, repository, func(tx port.Tx) (err error) {
+				added, err = repository.AddAccountStatementOnce(ctx, tx, &model.AccountStatement{FkAccountID: tt.fkAccountID, Type: "FEE", Currency: "BRL", Amount: -3, TenantID: testTenant, TransactionID: &tt.transactionID})
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if added != tt.added {
+				t.Errorf("added %v want %v", added, tt.added)
+			}
+		})
+	}
+}
+
+func TestInterestAccrual(t *testing.T) {
+	ctx := bypassContext()
+	repository := newTestRepository(t)
+	_, balance := addTestAccount(t, repository, "ACC-1", testTenant, 36500)
+
+	day_1 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	day_2 := day_1.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name		string
+		accrualDate	time.Time
+		added		bool
+	}{
+		{"first day", day_1, true},
+		{"second day", day_2, true},
+		{"same day again is ignored", day_1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var added bool
+			err := inTestTx(t, ctx, repository, func(tx port.Tx) (err error) {
+				added, err = repository.AddInterestAccrual(ctx, tx, &model.InterestAccrual{FkAccountBalanceID: balance.ID, AccrualDate: tt.accrualDate, Balance: 36500, Rate: 0.1, DayCount: "ACT/365", Amount: 10, TenantID: testTenant})
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if added != tt.added {
+				t.Errorf("added %v want %v", added, tt.added)
+			}
+		})
+	}
+
+	// only the accruals before the date are capitalized, and only once
+	for _, want := range []int{1, 0} {
+		var amounts []float64
+		err := inTestTx(t, ctx, repository, func(tx port.Tx) (err error) {
+			amounts, err = repository.CapitalizeInterestAccrual(ctx, tx, &model.InterestAccrual{FkAccountBalanceID: balance.ID}, day_2)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(amounts) != want {
+			t.Errorf("capitalized %v want %d", amounts, want)
+		}
+	}
+}
+
+func TestOutboxEvent(t *testing.T) {
+	ctx := bypassContext()
+	repository := newTestRepository(t)
+
+	err := inTestTx(t, ctx, repository, func(tx port.Tx) error {
+		for _, aggregateID := range []string{"ACC-1", "ACC-2", "ACC-3"} {
+			if _, err := repository.AddOutboxEvent(ctx, tx, &model.OutboxEvent{AggregateID: aggregateID, TenantID: testTenant, EventType: "account.created", Payload: json.RawMessage(`{}`)}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := repository.StartTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+
+	locked, err := repository.TryOutboxRelayLock(ctx, tx)
+	if err != nil || !locked {
+		t.Fatalf("relay lock %v err %v", locked, err)
+	}
+
+	// an other replica does not take the lock
+	other_tx, err := repository.StartTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locked, err := repository.TryOutboxRelayLock(ctx, other_tx); err != nil || locked {
+		t.Errorf("second relay lock %v err %v", locked, err)
+	}
+	other_tx.Rollback(ctx)
+
+	list_event, err := repository.ListPendingOutboxEvent(ctx, tx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*list_event) != 2 || (*list_event)[0].AggregateID != "ACC-1" {
+		t.Fatalf("pending events %+v", list_event)
+	}
+	if _, err := repository.MarkOutboxEventPublished(ctx, tx, []int64{(*list_event)[0].ID, (*list_event)[1].ID}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repository.MarkOutboxEventFailed(ctx, tx, 3, "broker down", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	list_event, err = repository.ListPendingOutboxEvent(ctx, tx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*list_event) != 1 || (*list_event)[0].Attempts != 1 {
+		t.Errorf("pending events %+v", list_event)
+	}
+	tx.Rollback(ctx)
+
+	// the claimed events are leased, a second claim does not take them again
+	list_event, err = repository.ClaimOutboxEvent(ctx, 10, time.Minute)
+	if err != nil || len(*list_event) != 3 {
+		t.Fatalf("claimed events %+v err %v", list_event, err)
+	}
+	if other_list, err := repository.ClaimOutboxEvent(ctx, 10, time.Minute); err != nil || len(*other_list) != 0 {
+		t.Errorf("second claim %+v err %v", other_list, err)
+	}
+
+	// the max attempts parks the event, it is no longer pending
+	err = inTestTx(t, ctx, repository, func(tx port.Tx) error {
+		_, err := repository.MarkOutboxEventFailed(ctx, tx, (*list_event)[0].ID, "broker down", 1)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending_tx, err := repository.StartTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pending_tx.Rollback(ctx)
+	if list_event, err = repository.ListPendingOutboxEvent(ctx, pending_tx, 10); err != nil || len(*list_event) != 2 {
+		t.Errorf("pending events %+v err %v", list_event, err)
+	}
+}
+
+func TestConsumedMessage(t *testing.T) {
+	ctx := bypassContext()
+	repository := newTestRepository(t)
+
+	tests := []struct {
+		name		string
+		message		model.ConsumedMessage
+		added		bool
+	}{
+		{"first", model.ConsumedMessage{Consumer: "posting", MessageKey: "K-1", Topic: "posting", Offset: 1, Status: "DONE"}, true},
+		{"other key", model.ConsumedMessage{Consumer: "posting", MessageKey: "K-2", Topic: "posting", Offset: 2, Status: "DONE"}, true},
+		{"duplicated key", model.ConsumedMessage{Consumer: "posting", MessageKey: "K-1", Topic: "posting", Offset: 3, Status: "DONE"}, false},
+		{"same key of an other consumer", model.ConsumedMessage{Consumer: "audit", MessageKey: "K-1", Topic: "posting", Offset: 3, Status: "DONE"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var added bool
+			err := inTestTx(t, ctx, repository, func(tx port.Tx) (err error) {
+				added, err = repository.AddConsumedMessage(ctx, tx, &tt.message)
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if added != tt.added {
+				t.Errorf("added %v want %v", added, tt.added)
+			}
+		})
+	}
+}
+
+func TestTenantRoleApiKey(t *testing.T) {
+	ctx := bypassContext()
+	repository := newTestRepository(t)
+
+	for _, role := range []string{"viewer", "admin"} {
+		err := inTestTx(t, ctx, repository, func(tx port.Tx) error {
+			_, err := repository.AddTenantRole(ctx, tx, &model.TenantRole{TenantID: testTenant, Subject: "user-a", Role: role})
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	res_role, err := repository.GetTenantRole(ctx, &model.TenantRole{TenantID: testTenant, Subject: "user-a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res_role.Role != "admin" || res_role.UpdatedAt == nil {
+		t.Errorf("role %+v", res_role)
+	}
+
+	err = inTestTx(t, ctx, repository, func(tx port.Tx) error {
+		_, err := repository.AddApiKey(ctx, tx, &model.ApiKey{KeyID: "KEY-1", TenantID: testTenant, Name: "batch", KeyHash: "hash", Scopes: []string{"account:read"}, Status: "ACTIVE"})
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name	string
+		ctx		context.Context
+		keyID	string
+		err		error
+	}{
+		{"without principal", ctx, "KEY-1", nil},
+		{"same tenant", tenantContext(testTenant), "KEY-1", nil},
+		{"other tenant", tenantContext("tenant-2"), "KEY-1", erro.ErrNotFound},
+		{"unknown", ctx, "KEY-2", erro.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := repository.GetApiKey(tt.ctx, &model.ApiKey{KeyID: tt.keyID})
+			if err != tt.err {
+				t.Fatalf("err %v want %v", err, tt.err)
+			}
+			if err == nil && (res.KeyHash != "hash" || len(res.Scopes) != 1) {
+				t.Errorf("api key %+v", res)
+			}
+		})
+	}
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	ctx := bypassContext()
+	repository := newTestRepository(t)
+
+	err := inTestTx(t, ctx, repository, func(tx port.Tx) error {
+		for _, webhookSubscription := range []model.WebhookSubscription{{SubscriptionID: "SUB-1", TenantID: testTenant, URL: "http://localhost/all", Secret: "s1", Status: "ACTIVE"},
+																		{SubscriptionID: "SUB-2", TenantID: testTenant, URL: "http://localhost/closed", EventTypes: []string{"account.closed"}, Secret: "s2", Status: "ACTIVE"},
+																		{SubscriptionID: "SUB-3", TenantID: "tenant-2", URL: "http://localhost/other", Secret: "s3", Status: "ACTIVE"}} {
+			if _, err := repository.AddWebhookSubscription(ctx, tx, &webhookSubscription); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name		string
+		eventType	string
+		deliveries	int64
+	}{
+		{"subscription without filter", "account.created", 1},
+		{"filtered subscription", "account.closed", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deliveries int64
+			err := inTestTx(t, ctx, repository, func(tx port.Tx) error {
+				outboxEvent := model.OutboxEvent{AggregateID: "ACC-1", TenantID: testTenant, EventType: tt.eventType, Payload: json.RawMessage(`{}`)}
+				if _, err := repository.AddOutboxEvent(ctx, tx, &outboxEvent); err != nil {
+					return err
+				}
+				var err error
+				deliveries, err = repository.AddWebhookDelivery(ctx, tx, &outboxEvent)
+				return err
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deliveries != tt.deliveries {
+				t.Errorf("deliveries %d want %d", deliveries, tt.deliveries)
+			}
+		})
+	}
+
+	// claimed deliveries are leased, a second claim does not take them again
+	res_claim, err := repository.ClaimWebhookDelivery(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*res_claim) != 3 {
+		t.Fatalf("claim %+v", res_claim)
+	}
+	res_claim, err = repository.ClaimWebhookDelivery(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*res_claim) != 0 {
+		t.Errorf("second claim %+v", res_claim)
+	}
+}
+
+func TestRowLevelSecurity(t *testing.T) {
+	repository := newTestRepository(t)
+	addTestAccount(t, repository, "ACC-1", testTenant, 10)
+	addTestAccount(t, repository, "ACC-2", "tenant-2", 20)
+
+	tests := []struct {
+		name		string
+		ctx			context.Context
+		visible		map[string]bool
+	}{
+		{"background job sees all", bypassContext(), map[string]bool{"ACC-1": true, "ACC-2": true}},
+		{"without tenant sees none", context.Background(), map[string]bool{}},
+		{"tenant 1", tenantContext(testTenant), map[string]bool{"ACC-1": true}},
+		{"tenant 2", tenantContext("tenant-2"), map[string]bool{"ACC-2": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res_balances, err := repository.ListAccountBalanceByAccountIDs(tt.ctx, []string{"ACC-1", "ACC-2"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, accountID := range []string{"ACC-1", "ACC-2"} {
+				if (len(res_balances[accountID]) > 0) != tt.visible[accountID] {
+					t.Errorf("%s visible %v want %v", accountID, len(res_balances[accountID]) > 0, tt.visible[accountID])
+				}
+			}
+
+			// the policy holds even without the tenant filter of the query
+			conn, err := repository.acquire(tt.ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer repository.DatabasePGServer.Release(conn)
+			var count int
+			if err := conn.QueryRow(tt.ctx, `SELECT count(*) FROM account`).Scan(&count); err != nil {
+				t.Fatal(err)
+			}
+			if count != len(tt.visible) {
+				t.Errorf("accounts %d want %d", count, len(tt.visible))
+			}
+		})
+	}
+
+	// a write into an other tenant is refused by the policy
+	ctx := tenantContext(testTenant)
+	err := inTestTx(t, ctx, repository, func(tx port.Tx) error {
+		_, err := repository.AddAccount(ctx, tx, &model.Account{AccountID: "ACC-3", PersonID: "P-1", TenantID: "tenant-2"})
+		return err
+	})
+	if err == nil {
+		t.Error("insert into an other tenant was accepted")
+	}
+}
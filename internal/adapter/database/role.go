@@ -0,0 +1,167 @@
+package database
+
+import (
+	"context"
+	"time"
+	"errors"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
+	"github.com/go-account/internal/core/erro"
+)
+
+// About create or replace the role of a subject into a tenant
+func (w WorkerRepository) AddTenantRole(ctx context.Context, tx port.Tx, tenantRole *model.TenantRole) (*model.TenantRole, error){
+	childLogger.Info().Str("func","AddTenantRole").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddTenantRole")
+	defer span.End()
+
+	//Prepare
+	var id int
+	tenantRole.CreatedAt = time.Now()
+
+	// Query Execute
+	query := `INSERT INTO tenant_role (	tenant_id,
+										subject,
+										role,
+										user_last_update,
+										created_at)
+				VALUES($1, $2, $3, $4, $5)
+				ON CONFLICT (tenant_id, subject) DO UPDATE
+				SET role = excluded.role,
+					user_last_update = excluded.user_last_update,
+					updated_at = excluded.created_at
+				RETURNING id`
+
+	row := pgxTx(tx).QueryRow(ctx, query,	tenantRole.TenantID,
+									tenantRole.Subject,
+									tenantRole.Role,
+									tenantRole.UserLastUpdate,
+									tenantRole.CreatedAt)
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Set PK
+	tenantRole.ID = id
+	return tenantRole , nil
+}
+
+// About get the role of a subject into a tenant
+func (w WorkerRepository) GetTenantRole(ctx context.Context, tenantRole *model.TenantRole) (*model.TenantRole, error){
+	childLogger.Info().Str("func","GetTenantRole").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetTenantRole")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_tenantRole := model.TenantRole{}
+
+	// Query and Execute
+	query := `SELECT 	id,
+						tenant_id,
+						subject,
+						role,
+						user_last_update,
+						created_at,
+						updated_at
+				FROM tenant_role
+				WHERE tenant_id = $1
+				and subject = $2`
+
+	rows, err := conn.Query(ctx, query, tenantRole.TenantID, tenantRole.Subject)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+    if err := rows.Err(); err != nil {
+		childLogger.Error().Err(err).Msg("fatal error closing rows")
+        return nil, errors.New(err.Error())
+    }
+
+	for rows.Next() {
+		err := rows.Scan( 	&res_tenantRole.ID,
+							&res_tenantRole.TenantID,
+							&res_tenantRole.Subject,
+							&res_tenantRole.Role,
+							&res_tenantRole.UserLastUpdate,
+							&res_tenantRole.CreatedAt,
+							&res_tenantRole.UpdatedAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		return &res_tenantRole, nil
+	}
+
+	return nil, erro.ErrNotFound
+}
+
+// About list all roles of a tenant
+func (w WorkerRepository) ListTenantRole(ctx context.Context, tenantRole *model.TenantRole) (*[]model.TenantRole, error){
+	childLogger.Info().Str("func","ListTenantRole").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListTenantRole")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_tenantRole := model.TenantRole{}
+	res_tenantRole_list := []model.TenantRole{}
+
+	// Query and Execute
+	query := `SELECT 	id,
+						tenant_id,
+						subject,
+						role,
+						user_last_update,
+						created_at,
+						updated_at
+				FROM tenant_role
+				WHERE tenant_id = $1
+				order by subject`
+
+	rows, err := conn.Query(ctx, query, tenantRole.TenantID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+    if err := rows.Err(); err != nil {
+		childLogger.Error().Err(err).Msg("fatal error closing rows")
+        return nil, errors.New(err.Error())
+    }
+
+	for rows.Next() {
+		err := rows.Scan( 	&res_tenantRole.ID,
+							&res_tenantRole.TenantID,
+							&res_tenantRole.Subject,
+							&res_tenantRole.Role,
+							&res_tenantRole.UserLastUpdate,
+							&res_tenantRole.CreatedAt,
+							&res_tenantRole.UpdatedAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res_tenantRole_list = append(res_tenantRole_list, res_tenantRole)
+	}
+
+	return &res_tenantRole_list, nil
+}
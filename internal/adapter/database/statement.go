@@ -0,0 +1,127 @@
+package database
+
+import (
+	"time"
+	"context"
+	"errors"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/auth"
+)
+
+// About list the statements of an account charged into a period [from, to), in the order they were charged (exports)
+func (w WorkerRepository) ListAccountStatement(ctx context.Context, account *model.Account, from time.Time, to time.Time) (*[]model.AccountStatement, error){
+	childLogger.Info().Str("func","ListAccountStatement").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("account_id", account.AccountID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListAccountStatement")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute
+	query := `SELECT 	s.id,
+						a.account_id,
+						s.fk_account_id,
+						s.type_charge,
+						s.charged_at,
+						s.currency,
+						s.amount,
+						s.tenant_id,
+						s.transaction_id
+				FROM account_statement s
+				JOIN account a on a.id = s.fk_account_id
+				WHERE a.account_id = $1
+				and ($5 or a.tenant_id = $2)
+				and s.charged_at >= $3
+				and s.charged_at < $4
+				order by s.charged_at, s.id`
+
+	rows, err := conn.Query(ctx, query, account.AccountID, auth.TenantID(ctx), from, to, auth.TenantBypass(ctx))
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	res_accountStatement_list := []model.AccountStatement{}
+	for rows.Next() {
+		accountStatement := model.AccountStatement{}
+		err := rows.Scan( 	&accountStatement.ID,
+							&accountStatement.AccountID,
+							&accountStatement.FkAccountID,
+							&accountStatement.Type,
+							&accountStatement.ChargedAt,
+							&accountStatement.Currency,
+							&accountStatement.Amount,
+							&accountStatement.TenantID,
+							&accountStatement.TransactionID,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res_accountStatement_list = append(res_accountStatement_list, accountStatement)
+	}
+    if err := rows.Err(); err != nil {
+        return nil, errors.New(err.Error())
+    }
+
+	return &res_accountStatement_list, nil
+}
+
+// About the sum and count of the statements of an account per currency (reconciliation)
+func (w WorkerRepository) ListAccountStatementTotal(ctx context.Context, account *model.Account) (*[]model.Reconciliation, error){
+	childLogger.Info().Str("func","ListAccountStatementTotal").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("account_id", account.AccountID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListAccountStatementTotal")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute
+	query := `SELECT 	a.account_id,
+						s.currency,
+						sum(s.amount),
+						count(*)
+				FROM account_statement s
+				JOIN account a on a.id = s.fk_account_id
+				WHERE a.account_id = $1
+				and ($3 or a.tenant_id = $2)
+				group by a.account_id, s.currency
+				order by s.currency`
+
+	rows, err := conn.Query(ctx, query, account.AccountID, auth.TenantID(ctx), auth.TenantBypass(ctx))
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	res_reconciliation_list := []model.Reconciliation{}
+	for rows.Next() {
+		reconciliation := model.Reconciliation{}
+		err := rows.Scan( 	&reconciliation.AccountID,
+							&reconciliation.Currency,
+							&reconciliation.StatementTotal,
+							&reconciliation.Statements,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res_reconciliation_list = append(res_reconciliation_list, reconciliation)
+	}
+    if err := rows.Err(); err != nil {
+        return nil, errors.New(err.Error())
+    }
+
+	return &res_reconciliation_list, nil
+}
@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-account/internal/core/auth"
+	"github.com/go-account/internal/core/port"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// About the tenant of the request to be applied on the queries, the same value is set
+// into app.tenant_id, which is used by the row level security policies (defense in depth).
+// An empty tenant sees no row, app.bypass_rls is only on when the context was marked on purpose (jobs)
+const setTenantQuery = `SELECT set_config('app.tenant_id', $1, $3), set_config('app.bypass_rls', $2, $3)`
+
+// About the value of app.bypass_rls of the context
+func bypassRLS(ctx context.Context) string {
+	if auth.TenantBypass(ctx) {
+		return "on"
+	}
+	return "off"
+}
+
+// About a transaction of the pool, the connection is released when it ends
+type pgTx struct {
+	pgx.Tx
+	conn		*pgxpool.Conn
+	release		func(conn *pgxpool.Conn)
+	released	bool
+}
+
+func (t *pgTx) Commit(ctx context.Context) error {
+	defer t.end()
+	return t.Tx.Commit(ctx)
+}
+
+func (t *pgTx) Rollback(ctx context.Context) error {
+	defer t.end()
+	return t.Tx.Rollback(ctx)
+}
+
+func (t *pgTx) end() {
+	if !t.released {
+		t.released = true
+		t.release(t.conn)
+	}
+}
+
+// About the pgx transaction of a unit of work started by StartTx
+func pgxTx(tx port.Tx) pgx.Tx {
+	return tx.(pgx.Tx)
+}
+
+// About start a transaction with app.tenant_id and app.bypass_rls set as SET LOCAL (only for this transaction)
+func (w WorkerRepository) StartTx(ctx context.Context) (port.Tx, error){
+	tx, conn, err := w.DatabasePGServer.StartTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, setTenantQuery, auth.TenantID(ctx), bypassRLS(ctx), true); err != nil {
+		tx.Rollback(ctx)
+		w.DatabasePGServer.ReleaseTx(conn)
+		return nil, errors.New(err.Error())
+	}
+
+	return &pgTx{Tx: tx, conn: conn, release: w.DatabasePGServer.ReleaseTx}, nil
+}
+
+// About acquire a connection with app.tenant_id and app.bypass_rls set, it is always overwritten because the connections are reused by the pool
+func (w WorkerRepository) acquire(ctx context.Context) (*pgxpool.Conn, error){
+	conn, err := w.DatabasePGServer.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.Exec(ctx, setTenantQuery, auth.TenantID(ctx), bypassRLS(ctx), false); err != nil {
+		w.DatabasePGServer.Release(conn)
+		return nil, err
+	}
+
+	return conn, nil
+}
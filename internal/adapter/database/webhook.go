@@ -0,0 +1,535 @@
+package database
+
+import (
+	"context"
+	"time"
+	"errors"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/auth"
+)
+
+// About create a webhook subscription
+func (w WorkerRepository) AddWebhookSubscription(ctx context.Context, tx port.Tx, webhookSubscription *model.WebhookSubscription) (*model.WebhookSubscription, error){
+	childLogger.Info().Str("func","AddWebhookSubscription").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddWebhookSubscription")
+	defer span.End()
+
+	//Prepare
+	var id int
+	webhookSubscription.CreatedAt = time.Now()
+	if webhookSubscription.EventTypes == nil {
+		webhookSubscription.EventTypes = []string{}
+	}
+
+	// Query Execute
+	query := `INSERT INTO webhook_subscription (	subscription_id,
+													tenant_id,
+													url,
+													event_types,
+													secret,
+													status,
+													user_last_update,
+													created_at)
+				VALUES($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+
+	row := pgxTx(tx).QueryRow(ctx, query,	webhookSubscription.SubscriptionID,
+									webhookSubscription.TenantID,
+									webhookSubscription.URL,
+									webhookSubscription.EventTypes,
+									webhookSubscription.Secret,
+									webhookSubscription.Status,
+									webhookSubscription.UserLastUpdate,
+									webhookSubscription.CreatedAt)
+	if err := row.Scan(&id); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	// Set PK
+	webhookSubscription.ID = id
+	return webhookSubscription , nil
+}
+
+// About get a webhook subscription by the subscription id
+func (w WorkerRepository) GetWebhookSubscription(ctx context.Context, webhookSubscription *model.WebhookSubscription) (*model.WebhookSubscription, error){
+	childLogger.Info().Str("func","GetWebhookSubscription").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.GetWebhookSubscription")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_webhookSubscription := model.WebhookSubscription{}
+
+	// Query and Execute
+	query := `SELECT 	id,
+						subscription_id,
+						tenant_id,
+						url,
+						event_types,
+						status,
+						user_last_update,
+						created_at,
+						updated_at
+				FROM webhook_subscription
+				WHERE subscription_id = $1
+				and ($3 or tenant_id = $2)`
+
+	rows, err := conn.Query(ctx, query, webhookSubscription.SubscriptionID, auth.TenantID(ctx), auth.TenantBypass(ctx))
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		err := rows.Scan( 	&res_webhookSubscription.ID,
+							&res_webhookSubscription.SubscriptionID,
+							&res_webhookSubscription.TenantID,
+							&res_webhookSubscription.URL,
+							&res_webhookSubscription.EventTypes,
+							&res_webhookSubscription.Status,
+							&res_webhookSubscription.UserLastUpdate,
+							&res_webhookSubscription.CreatedAt,
+							&res_webhookSubscription.UpdatedAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		return &res_webhookSubscription, nil
+	}
+    if err := rows.Err(); err != nil {
+        return nil, errors.New(err.Error())
+    }
+
+	return nil, erro.ErrNotFound
+}
+
+// About list all webhook subscriptions of a tenant (without the secrets)
+func (w WorkerRepository) ListWebhookSubscription(ctx context.Context, webhookSubscription *model.WebhookSubscription) (*[]model.WebhookSubscription, error){
+	childLogger.Info().Str("func","ListWebhookSubscription").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListWebhookSubscription")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Prepare
+	res_webhookSubscription_list := []model.WebhookSubscription{}
+
+	// Query and Execute
+	query := `SELECT 	id,
+						subscription_id,
+						tenant_id,
+						url,
+						event_types,
+						status,
+						user_last_update,
+						created_at,
+						updated_at
+				FROM webhook_subscription
+				WHERE tenant_id = $1
+				order by id desc`
+
+	rows, err := conn.Query(ctx, query, webhookSubscription.TenantID)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		res_webhookSubscription := model.WebhookSubscription{}
+		err := rows.Scan( 	&res_webhookSubscription.ID,
+							&res_webhookSubscription.SubscriptionID,
+							&res_webhookSubscription.TenantID,
+							&res_webhookSubscription.URL,
+							&res_webhookSubscription.EventTypes,
+							&res_webhookSubscription.Status,
+							&res_webhookSubscription.UserLastUpdate,
+							&res_webhookSubscription.CreatedAt,
+							&res_webhookSubscription.UpdatedAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res_webhookSubscription_list = append(res_webhookSubscription_list, res_webhookSubscription)
+	}
+    if err := rows.Err(); err != nil {
+        return nil, errors.New(err.Error())
+    }
+
+	return &res_webhookSubscription_list, nil
+}
+
+// About update the status of a webhook subscription (disable)
+func (w WorkerRepository) UpdateWebhookSubscription(ctx context.Context, tx port.Tx, webhookSubscription *model.WebhookSubscription) (int64, error){
+	childLogger.Info().Str("func","UpdateWebhookSubscription").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.UpdateWebhookSubscription")
+	defer span.End()
+
+	// Prepare
+	updateAt := time.Now()
+	webhookSubscription.UpdatedAt = &updateAt
+
+	//Query Execute
+	query := `Update webhook_subscription
+				set status = $1,
+					updated_at = $2,
+					user_last_update = $3
+				where id = $4`
+
+	row, err := pgxTx(tx).Exec(ctx, query, webhookSubscription.Status,
+									webhookSubscription.UpdatedAt,
+									webhookSubscription.UserLastUpdate,
+									webhookSubscription.ID)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+
+	return row.RowsAffected() , nil
+}
+
+// About add a delivery of an outbox event for each active subscription of the tenant that accepts
+// the event type (no filter accepts all), in the transaction of the event
+func (w WorkerRepository) AddWebhookDelivery(ctx context.Context, tx port.Tx, outboxEvent *model.OutboxEvent) (int64, error){
+	childLogger.Info().Str("func","AddWebhookDelivery").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int64("outbox_event_id", outboxEvent.ID).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.AddWebhookDelivery")
+	defer span.End()
+
+	// Query Execute
+	query := `INSERT INTO webhook_delivery (	fk_subscription_id,
+												outbox_event_id,
+												event_type,
+												payload)
+				SELECT id, $1, $2, $3
+				FROM webhook_subscription
+				WHERE tenant_id = $4
+				and status = 'ACTIVE'
+				and (cardinality(event_types) = 0 or $2 = any(event_types))
+				ON CONFLICT DO NOTHING`
+
+	row, err := pgxTx(tx).Exec(ctx, query,	outboxEvent.ID,
+									outboxEvent.EventType,
+									outboxEvent.Payload,
+									outboxEvent.TenantID)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+
+	return row.RowsAffected() , nil
+}
+
+// About claim the due deliveries of the active subscriptions, the next attempt is moved by the lease
+// so other replicas skip them while they are being delivered
+func (w WorkerRepository) ClaimWebhookDelivery(ctx context.Context, limit int, lease time.Duration) (*[]model.WebhookDelivery, error){
+	childLogger.Debug().Str("func","ClaimWebhookDelivery").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ClaimWebhookDelivery")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute
+	query := `WITH due AS (	SELECT d.id
+							FROM webhook_delivery d
+							JOIN webhook_subscription s on s.id = d.fk_subscription_id
+							WHERE d.status = 'PENDING'
+							and d.next_attempt_at <= now()
+							and s.status = 'ACTIVE'
+							order by d.next_attempt_at
+							limit $1
+							FOR UPDATE OF d SKIP LOCKED )
+				UPDATE webhook_delivery d
+				SET next_attempt_at = now() + $2 * interval '1 second'
+				FROM due, webhook_subscription s
+				WHERE d.id = due.id
+				and s.id = d.fk_subscription_id
+				RETURNING 	d.id,
+							s.subscription_id,
+							s.url,
+							s.secret,
+							d.outbox_event_id,
+							d.event_type,
+							d.payload,
+							d.status,
+							d.attempts,
+							d.created_at`
+
+	rows, err := conn.Query(ctx, query, limit, int(lease.Seconds()))
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	res_webhookDelivery_list := []model.WebhookDelivery{}
+	for rows.Next() {
+		webhookDelivery := model.WebhookDelivery{}
+		err := rows.Scan( 	&webhookDelivery.ID,
+							&webhookDelivery.SubscriptionID,
+							&webhookDelivery.URL,
+							&webhookDelivery.Secret,
+							&webhookDelivery.OutboxEventID,
+							&webhookDelivery.EventType,
+							&webhookDelivery.Payload,
+							&webhookDelivery.Status,
+							&webhookDelivery.Attempts,
+							&webhookDelivery.CreatedAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res_webhookDelivery_list = append(res_webhookDelivery_list, webhookDelivery)
+	}
+    if err := rows.Err(); err != nil {
+        return nil, errors.New(err.Error())
+    }
+
+	return &res_webhookDelivery_list, nil
+}
+
+// About record the result of a delivery attempt (status, counters and the next attempt)
+func (w WorkerRepository) UpdateWebhookDelivery(ctx context.Context, tx port.Tx, webhookDelivery *model.WebhookDelivery) (int64, error){
+	childLogger.Info().Str("func","UpdateWebhookDelivery").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int64("id", webhookDelivery.ID).Str("status", webhookDelivery.Status).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.UpdateWebhookDelivery")
+	defer span.End()
+
+	//Query Execute
+	query := `Update webhook_delivery
+				set status = $1,
+					attempts = $2,
+					next_attempt_at = $3,
+					last_status_code = $4,
+					last_error = $5,
+					delivered_at = $6
+				where id = $7`
+
+	row, err := pgxTx(tx).Exec(ctx, query, webhookDelivery.Status,
+									webhookDelivery.Attempts,
+									webhookDelivery.NextAttemptAt,
+									webhookDelivery.LastStatusCode,
+									webhookDelivery.LastError,
+									webhookDelivery.DeliveredAt,
+									webhookDelivery.ID)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+
+	return row.RowsAffected() , nil
+}
+
+// About cancel the pending deliveries of a subscription
+func (w WorkerRepository) CancelWebhookDelivery(ctx context.Context, tx port.Tx, webhookSubscription *model.WebhookSubscription) (int64, error){
+	childLogger.Info().Str("func","CancelWebhookDelivery").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	query := `Update webhook_delivery
+				set status = 'CANCELED'
+				where fk_subscription_id = $1
+				and status = 'PENDING'`
+
+	row, err := pgxTx(tx).Exec(ctx, query, webhookSubscription.ID)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+
+	return row.RowsAffected() , nil
+}
+
+// About add an attempt into the history of a delivery
+func (w WorkerRepository) AddWebhookDeliveryAttempt(ctx context.Context, tx port.Tx, webhookDeliveryAttempt *model.WebhookDeliveryAttempt) (*model.WebhookDeliveryAttempt, error){
+	childLogger.Info().Str("func","AddWebhookDeliveryAttempt").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Query Execute
+	query := `INSERT INTO webhook_delivery_attempt (	fk_delivery_id,
+														attempt,
+														attempted_at,
+														status_code,
+														error,
+														duration_ms)
+				VALUES($1, $2, $3, $4, $5, $6) RETURNING id`
+
+	row := pgxTx(tx).QueryRow(ctx, query,	webhookDeliveryAttempt.FkDeliveryID,
+									webhookDeliveryAttempt.Attempt,
+									webhookDeliveryAttempt.AttemptedAt,
+									webhookDeliveryAttempt.StatusCode,
+									webhookDeliveryAttempt.Error,
+									webhookDeliveryAttempt.DurationMs)
+	if err := row.Scan(&webhookDeliveryAttempt.ID); err != nil {
+		return nil, errors.New(err.Error())
+	}
+
+	return webhookDeliveryAttempt, nil
+}
+
+// About list the last deliveries of a subscription, optionally filtered by status
+func (w WorkerRepository) ListWebhookDelivery(ctx context.Context, webhookSubscription *model.WebhookSubscription, status string, limit int) (*[]model.WebhookDelivery, error){
+	childLogger.Info().Str("func","ListWebhookDelivery").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListWebhookDelivery")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute
+	query := `SELECT 	id,
+						outbox_event_id,
+						event_type,
+						payload,
+						status,
+						attempts,
+						next_attempt_at,
+						last_status_code,
+						last_error,
+						created_at,
+						delivered_at
+				FROM webhook_delivery
+				WHERE fk_subscription_id = $1
+				and ($2 = '' or status = $2)
+				order by id desc
+				limit $3`
+
+	rows, err := conn.Query(ctx, query, webhookSubscription.ID, status, limit)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	res_webhookDelivery_list := []model.WebhookDelivery{}
+	for rows.Next() {
+		webhookDelivery := model.WebhookDelivery{SubscriptionID: webhookSubscription.SubscriptionID}
+		err := rows.Scan( 	&webhookDelivery.ID,
+							&webhookDelivery.OutboxEventID,
+							&webhookDelivery.EventType,
+							&webhookDelivery.Payload,
+							&webhookDelivery.Status,
+							&webhookDelivery.Attempts,
+							&webhookDelivery.NextAttemptAt,
+							&webhookDelivery.LastStatusCode,
+							&webhookDelivery.LastError,
+							&webhookDelivery.CreatedAt,
+							&webhookDelivery.DeliveredAt,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res_webhookDelivery_list = append(res_webhookDelivery_list, webhookDelivery)
+	}
+    if err := rows.Err(); err != nil {
+        return nil, errors.New(err.Error())
+    }
+
+	return &res_webhookDelivery_list, nil
+}
+
+// About list the attempts of many deliveries in one query, the result is keyed by the delivery id
+func (w WorkerRepository) ListWebhookDeliveryAttemptByDeliveryIDs(ctx context.Context, deliveryIDs []int64) (map[int64][]model.WebhookDeliveryAttempt, error){
+	childLogger.Info().Str("func","ListWebhookDeliveryAttemptByDeliveryIDs").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int("keys", len(deliveryIDs)).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "database.ListWebhookDeliveryAttemptByDeliveryIDs")
+	defer span.End()
+
+	// db connection
+	conn, err := w.acquire(ctx)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer w.DatabasePGServer.Release(conn)
+
+	// Query and Execute
+	query := `SELECT 	id,
+						fk_delivery_id,
+						attempt,
+						attempted_at,
+						status_code,
+						error,
+						duration_ms
+				FROM webhook_delivery_attempt
+				WHERE fk_delivery_id = any($1)
+				order by fk_delivery_id, attempted_at`
+
+	rows, err := conn.Query(ctx, query, deliveryIDs)
+	if err != nil {
+		return nil, errors.New(err.Error())
+	}
+	defer rows.Close()
+
+	res := map[int64][]model.WebhookDeliveryAttempt{}
+	for rows.Next() {
+		webhookDeliveryAttempt := model.WebhookDeliveryAttempt{}
+		err := rows.Scan( 	&webhookDeliveryAttempt.ID,
+							&webhookDeliveryAttempt.FkDeliveryID,
+							&webhookDeliveryAttempt.Attempt,
+							&webhookDeliveryAttempt.AttemptedAt,
+							&webhookDeliveryAttempt.StatusCode,
+							&webhookDeliveryAttempt.Error,
+							&webhookDeliveryAttempt.DurationMs,
+						)
+		if err != nil {
+			return nil, errors.New(err.Error())
+        }
+		res[webhookDeliveryAttempt.FkDeliveryID] = append(res[webhookDeliveryAttempt.FkDeliveryID], webhookDeliveryAttempt)
+	}
+    if err := rows.Err(); err != nil {
+        return nil, errors.New(err.Error())
+    }
+
+	return res, nil
+}
+
+// About send a dead delivery back to the queue (redrive), the attempts start again
+func (w WorkerRepository) RetryWebhookDelivery(ctx context.Context, tx port.Tx, webhookSubscription *model.WebhookSubscription, deliveryID int64) (int64, error){
+	childLogger.Info().Str("func","RetryWebhookDelivery").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Int64("id", deliveryID).Send()
+
+	// trace
+	span := tracerProvider.Span(ctx, "database.RetryWebhookDelivery")
+	defer span.End()
+
+	query := `Update webhook_delivery
+				set status = 'PENDING',
+					attempts = 0,
+					next_attempt_at = now()
+				where id = $1
+				and fk_subscription_id = $2
+				and status = 'DEAD'`
+
+	row, err := pgxTx(tx).Exec(ctx, query, deliveryID, webhookSubscription.ID)
+	if err != nil {
+		return 0, errors.New(err.Error())
+	}
+
+	return row.RowsAffected() , nil
+}
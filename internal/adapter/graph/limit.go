@@ -0,0 +1,131 @@
+package graph
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/go-account/internal/core/model"
+)
+
+// About the expected size of the lists without a "last" argument (used by the cost)
+const listFactor = 10
+
+// About the lists of the schema, their children cost is multiplied by the number of items
+var listFields = map[string]bool{
+	"accountsByPerson":	true,
+	"balances":			true,
+	"statements":		true,
+	"transfers":		true,
+}
+
+// About the depth and the cost of a query, every field costs 1 plus the cost of its
+// children, multiplied by the size of the list. The introspection fields are not counted
+type analyzer struct {
+	fragments	map[string]*ast.FragmentDefinition
+	variables	map[string]interface{}
+	visiting	map[string]bool
+}
+
+// About check the depth and the cost of the operation against the limits
+func checkLimits(doc *ast.Document, operationName string, variables map[string]interface{}, graphQLConfig *model.GraphQLConfig) error {
+	a := analyzer{	fragments: map[string]*ast.FragmentDefinition{},
+					variables: variables,
+					visiting: map[string]bool{}	}
+
+	var operation *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		switch def := definition.(type) {
+		case *ast.FragmentDefinition:
+			a.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				operation = def
+			}
+		}
+	}
+	if operation == nil {
+		return nil
+	}
+
+	depth, cost := a.selectionSet(operation.SelectionSet, 1)
+	if graphQLConfig.MaxDepth > 0 && depth > graphQLConfig.MaxDepth {
+		return fmt.Errorf("query depth %d exceeds the max depth %d", depth, graphQLConfig.MaxDepth)
+	}
+	if graphQLConfig.MaxCost > 0 && cost > graphQLConfig.MaxCost {
+		return fmt.Errorf("query cost %d exceeds the max cost %d", cost, graphQLConfig.MaxCost)
+	}
+	return nil
+}
+
+// About the max depth and the sum of the cost of a selection set
+func (a *analyzer) selectionSet(selectionSet *ast.SelectionSet, depth int) (int, int) {
+	if selectionSet == nil {
+		return depth - 1, 0
+	}
+
+	maxDepth, cost := depth - 1, 0
+	for _, selection := range selectionSet.Selections {
+		var selDepth, selCost int
+		switch sel := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(sel.Name.Value, "__") {
+				continue
+			}
+			childDepth, childCost := a.selectionSet(sel.SelectionSet, depth + 1)
+			selDepth = depth
+			if childDepth > depth {
+				selDepth = childDepth
+			}
+			selCost = 1 + a.multiplier(sel) * childCost
+		case *ast.InlineFragment:
+			selDepth, selCost = a.selectionSet(sel.SelectionSet, depth)
+		case *ast.FragmentSpread:
+			fragment, ok := a.fragments[sel.Name.Value]
+			if !ok || a.visiting[sel.Name.Value] {
+				continue
+			}
+			a.visiting[sel.Name.Value] = true
+			selDepth, selCost = a.selectionSet(fragment.SelectionSet, depth)
+			a.visiting[sel.Name.Value] = false
+		}
+		if selDepth > maxDepth {
+			maxDepth = selDepth
+		}
+		cost = cost + selCost
+	}
+	return maxDepth, cost
+}
+
+// About the number of items of a list field, the "last" argument or the list factor
+func (a *analyzer) multiplier(field *ast.Field) int {
+	if !listFields[field.Name.Value] {
+		return 1
+	}
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "last" {
+			continue
+		}
+		last := listFactor
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			last, _ = strconv.Atoi(value.Value)
+		case *ast.Variable:
+			switch v := a.variables[value.Name.Value].(type) {
+			case float64:
+				last = int(v)
+			case int:
+				last = v
+			}
+		}
+		if last < 1 {
+			return 1
+		}
+		if last > maxLast {
+			return maxLast
+		}
+		return last
+	}
+	return listFactor
+}
@@ -0,0 +1,134 @@
+package graph
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-account/internal/core/model"
+)
+
+// About a per request batch loader, the keys requested by the resolvers of one level of the query
+// are collected and loaded with one call when the first thunk is resolved (avoid the N+1 queries)
+type loader[T any] struct {
+	mu		sync.Mutex
+	fetch	func(ctx context.Context, keys []string) (map[string][]T, error)
+	pending	[]string
+	cache	map[string][]T
+	errs	map[string]error
+}
+
+// About create a loader
+func newLoader[T any](fetch func(ctx context.Context, keys []string) (map[string][]T, error)) *loader[T] {
+	return &loader[T]{
+		fetch: fetch,
+		cache: map[string][]T{},
+		errs: map[string]error{},
+	}
+}
+
+// About register a key and return the thunk (graphql-go resolves it after the siblings were registered)
+func (l *loader[T]) Load(ctx context.Context, key string) func() (interface{}, error) {
+	l.mu.Lock()
+	if _, ok := l.cache[key]; !ok {
+		if _, ok := l.errs[key]; !ok {
+			l.pending = append(l.pending, key)
+		}
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if len(l.pending) > 0 {
+			l.dispatch(ctx)
+		}
+		if err, ok := l.errs[key]; ok {
+			return nil, err
+		}
+		res := l.cache[key]
+		if res == nil {
+			res = []T{}
+		}
+		return res, nil
+	}
+}
+
+// About load all the pending keys at once
+func (l *loader[T]) dispatch(ctx context.Context) {
+	keys := unique(l.pending)
+	l.pending = nil
+
+	res, err := l.fetch(ctx, keys)
+	for _, key := range keys {
+		if err != nil {
+			l.errs[key] = err
+			continue
+		}
+		l.cache[key] = res[key]
+		if l.cache[key] == nil {
+			l.cache[key] = []T{}
+		}
+	}
+}
+
+func unique(keys []string) []string {
+	seen := map[string]bool{}
+	res := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !seen[key] {
+			seen[key] = true
+			res = append(res, key)
+		}
+	}
+	return res
+}
+
+// About the loaders of a request, statements and transfers have one loader per "last" argument
+type loaders struct {
+	mu			sync.Mutex
+	balances	*loader[model.AccountBalance]
+	statements	map[int]*loader[model.AccountStatement]
+	transfers	map[int]*loader[model.Transfer]
+}
+
+type loadersKey struct{}
+
+// About attach new loaders into the ctx of a request
+func withLoaders(ctx context.Context, g *GraphServer) context.Context {
+	l := &loaders{
+		statements: map[int]*loader[model.AccountStatement]{},
+		transfers: map[int]*loader[model.Transfer]{},
+	}
+	l.balances = newLoader(g.accountReader.ListAccountBalanceByAccountIDs)
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	l, _ := ctx.Value(loadersKey{}).(*loaders)
+	return l
+}
+
+func (l *loaders) statementLoader(g *GraphServer, last int) *loader[model.AccountStatement] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.statements[last]; !ok {
+		l.statements[last] = newLoader(func(ctx context.Context, keys []string) (map[string][]model.AccountStatement, error) {
+			return g.accountReader.ListAccountStatementByAccountIDs(ctx, keys, last)
+		})
+	}
+	return l.statements[last]
+}
+
+func (l *loaders) transferLoader(g *GraphServer, last int) *loader[model.Transfer] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.transfers[last]; !ok {
+		l.transfers[last] = newLoader(func(ctx context.Context, keys []string) (map[string][]model.Transfer, error) {
+			return g.accountReader.ListTransferByAccountIDs(ctx, keys, last)
+		})
+	}
+	return l.transfers[last]
+}
@@ -0,0 +1,183 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/graphql-go/graphql"
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+)
+
+// About the default and the max of the "last" argument of statements and transfers
+const (
+	defaultLast = 10
+	maxLast 	= 100
+)
+
+// About the service calls used by the resolvers (implemented by service.WorkerService)
+type accountReader interface {
+	GetAccount(ctx context.Context, account *model.Account) (*model.Account, error)
+	ListAccountPerPerson(ctx context.Context, account *model.Account) (*[]model.Account, error)
+	ListAccountBalanceByAccountIDs(ctx context.Context, accountIDs []string) (map[string][]model.AccountBalance, error)
+	ListAccountStatementByAccountIDs(ctx context.Context, accountIDs []string, last int) (map[string][]model.AccountStatement, error)
+	ListTransferByAccountIDs(ctx context.Context, accountIDs []string, last int) (map[string][]model.Transfer, error)
+}
+
+// About the fields resolved by name (graphql-go matches the field name with the struct field name)
+func scalarFields(fields map[string]graphql.Output) graphql.Fields {
+	res := graphql.Fields{}
+	for name, output := range fields {
+		res[name] = &graphql.Field{Type: output}
+	}
+	return res
+}
+
+// About the "last" argument of a list field
+func lastArgument(p graphql.ResolveParams) int {
+	last, ok := p.Args["last"].(int)
+	if !ok {
+		return defaultLast
+	}
+	if last < 1 {
+		return 1
+	}
+	if last > maxLast {
+		return maxLast
+	}
+	return last
+}
+
+// About create the schema over account, balances, statements and transfers
+func (g *GraphServer) newSchema() (graphql.Schema, error) {
+	accountBalanceType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "AccountBalance",
+		Fields: scalarFields(map[string]graphql.Output{
+			"id":			graphql.Int,
+			"accountId":	graphql.String,
+			"currency":		graphql.String,
+			"amount":		graphql.Float,
+			"tenantId":		graphql.String,
+			"createdAt":	graphql.DateTime,
+			"updatedAt":	graphql.DateTime,
+		}),
+	})
+
+	accountStatementType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "AccountStatement",
+		Fields: scalarFields(map[string]graphql.Output{
+			"id":				graphql.Int,
+			"accountId":		graphql.String,
+			"type":				graphql.String,
+			"chargedAt":		graphql.DateTime,
+			"currency":			graphql.String,
+			"amount":			graphql.Float,
+			"tenantId":			graphql.String,
+			"transactionId":	graphql.String,
+		}),
+	})
+
+	transferFields := scalarFields(map[string]graphql.Output{
+		"id":			graphql.Int,
+		"type":			graphql.String,
+		"status":		graphql.String,
+		"currency":		graphql.String,
+		"amount":		graphql.Float,
+		"transferAt":	graphql.DateTime,
+	})
+	transferFields["accountFrom"] = &graphql.Field{
+		Type: graphql.String,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(model.Transfer).AccountFrom.AccountID, nil
+		},
+	}
+	transferFields["accountTo"] = &graphql.Field{
+		Type: graphql.String,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(model.Transfer).AccountTo.AccountID, nil
+		},
+	}
+	transferType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Transfer",
+		Fields: transferFields,
+	})
+
+	lastArgs := graphql.FieldConfigArgument{
+		"last": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultLast},
+	}
+
+	accountFields := scalarFields(map[string]graphql.Output{
+		"id":				graphql.Int,
+		"accountId":		graphql.String,
+		"personId":			graphql.String,
+		"productId":		graphql.String,
+		"status":			graphql.String,
+		"tenantId":			graphql.String,
+		"userLastUpdate":	graphql.String,
+		"createdAt":		graphql.DateTime,
+		"updatedAt":		graphql.DateTime,
+	})
+	accountFields["balances"] = &graphql.Field{
+		Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(accountBalanceType))),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			account := p.Source.(model.Account)
+			return loadersFrom(p.Context).balances.Load(p.Context, account.AccountID), nil
+		},
+	}
+	accountFields["statements"] = &graphql.Field{
+		Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(accountStatementType))),
+		Args: lastArgs,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			account := p.Source.(model.Account)
+			return loadersFrom(p.Context).statementLoader(g, lastArgument(p)).Load(p.Context, account.AccountID), nil
+		},
+	}
+	accountFields["transfers"] = &graphql.Field{
+		Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(transferType))),
+		Args: lastArgs,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			account := p.Source.(model.Account)
+			return loadersFrom(p.Context).transferLoader(g, lastArgument(p)).Load(p.Context, account.AccountID), nil
+		},
+	}
+	accountType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Account",
+		Fields: accountFields,
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"account": &graphql.Field{
+				Type: accountType,
+				Args: graphql.FieldConfigArgument{
+					"accountId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					res, err := g.accountReader.GetAccount(p.Context, &model.Account{AccountID: p.Args["accountId"].(string)})
+					if err == erro.ErrNotFound {
+						return nil, nil
+					}
+					if err != nil {
+						return nil, err
+					}
+					return *res, nil
+				},
+			},
+			"accountsByPerson": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(accountType))),
+				Args: graphql.FieldConfigArgument{
+					"personId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					res, err := g.accountReader.ListAccountPerPerson(p.Context, &model.Account{PersonID: p.Args["personId"].(string)})
+					if err != nil {
+						return nil, err
+					}
+					return *res, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
@@ -0,0 +1,115 @@
+package graph
+
+import (
+	"time"
+	"context"
+	"net/http"
+	"encoding/json"
+
+	"github.com/rs/zerolog/log"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/go-account/internal/core/service"
+	"github.com/go-account/internal/core/model"
+	go_core_observ "github.com/eliezerraj/go-core/observability"
+)
+
+var (
+	childLogger = log.With().Str("component", "go-account").Str("package", "internal.adapter.graph").Logger()
+	tracerProvider go_core_observ.TracerProvider
+)
+
+// About the graphql server, the resolvers call the WorkerService
+type GraphServer struct {
+	accountReader	accountReader
+	ctxTimeout		time.Duration
+	graphQLConfig	*model.GraphQLConfig
+	schema			graphql.Schema
+}
+
+// About a graphql request (POST body)
+type Request struct {
+	Query			string					`json:"query"`
+	OperationName	string					`json:"operationName,omitempty"`
+	Variables		map[string]interface{}	`json:"variables,omitempty"`
+}
+
+// About create the graphql server
+func NewGraphServer(workerService *service.WorkerService,
+					ctxTimeout	time.Duration,
+					graphQLConfig *model.GraphQLConfig) (*GraphServer, error) {
+	childLogger.Info().Str("func","NewGraphServer").Send()
+
+	return newGraphServer(workerService, ctxTimeout, graphQLConfig)
+}
+
+func newGraphServer(accountReader accountReader,
+					ctxTimeout	time.Duration,
+					graphQLConfig *model.GraphQLConfig) (*GraphServer, error) {
+	g := &GraphServer{
+		accountReader: accountReader,
+		ctxTimeout: ctxTimeout,
+		graphQLConfig: graphQLConfig,
+	}
+
+	schema, err := g.newSchema()
+	if err != nil {
+		return nil, err
+	}
+	g.schema = schema
+
+	return g, nil
+}
+
+// About execute a request, the errors of parse, validation and limits return no data
+func (g *GraphServer) Execute(ctx context.Context, request Request) (*graphql.Result, bool) {
+	doc, err := parser.Parse(parser.ParseParams{Source: request.Query})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}, false
+	}
+
+	validation := graphql.ValidateDocument(&g.schema, doc, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}, false
+	}
+
+	if err := checkLimits(doc, request.OperationName, request.Variables, g.graphQLConfig); err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}, false
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:			g.schema,
+		AST:			doc,
+		OperationName:	request.OperationName,
+		Args:			request.Variables,
+		Context:		withLoaders(ctx, g),
+	}), true
+}
+
+// About the graphql endpoint, the errors of the resolvers are in the body with the data
+func (g *GraphServer) Query(rw http.ResponseWriter, req *http.Request) {
+	childLogger.Info().Str("func","Query").Interface("trace-resquest-id", req.Context().Value("trace-request-id")).Send()
+
+	ctx, cancel := context.WithTimeout(req.Context(), g.ctxTimeout * time.Second)
+    defer cancel()
+
+	// trace
+	span := tracerProvider.Span(ctx, "adapter.graph.Query")
+	defer span.End()
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	request := Request{}
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil || request.Query == "" {
+		rw.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(rw).Encode(graphql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.NewFormattedError("request body must be a json with a query")}})
+		return
+	}
+
+	res, ok := g.Execute(ctx, request)
+	if !ok {
+		rw.WriteHeader(http.StatusBadRequest)
+	}
+	json.NewEncoder(rw).Encode(res)
+}
@@ -0,0 +1,174 @@
+package graph
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"encoding/json"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+)
+
+type fakeReader struct {
+	accounts	[]model.Account
+	calls		map[string]int
+	keys		map[string][]string
+}
+
+func (f *fakeReader) called(name string, keys []string) {
+	f.calls[name]++
+	f.keys[name] = keys
+}
+
+func (f *fakeReader) GetAccount(ctx context.Context, account *model.Account) (*model.Account, error) {
+	f.called("GetAccount", nil)
+	for _, a := range f.accounts {
+		if a.AccountID == account.AccountID {
+			return &a, nil
+		}
+	}
+	return nil, erro.ErrNotFound
+}
+
+func (f *fakeReader) ListAccountPerPerson(ctx context.Context, account *model.Account) (*[]model.Account, error) {
+	f.called("ListAccountPerPerson", nil)
+	return &f.accounts, nil
+}
+
+func (f *fakeReader) ListAccountBalanceByAccountIDs(ctx context.Context, accountIDs []string) (map[string][]model.AccountBalance, error) {
+	f.called("balances", accountIDs)
+	res := map[string][]model.AccountBalance{}
+	for _, id := range accountIDs {
+		res[id] = []model.AccountBalance{{AccountID: id, Currency: "BRL", Amount: 10}}
+	}
+	return res, nil
+}
+
+func (f *fakeReader) ListAccountStatementByAccountIDs(ctx context.Context, accountIDs []string, last int) (map[string][]model.AccountStatement, error) {
+	f.called("statements", accountIDs)
+	return map[string][]model.AccountStatement{accountIDs[0]: {{AccountID: accountIDs[0], Type: "CREDIT", Amount: float64(last)}}}, nil
+}
+
+func (f *fakeReader) ListTransferByAccountIDs(ctx context.Context, accountIDs []string, last int) (map[string][]model.Transfer, error) {
+	f.called("transfers", accountIDs)
+	return map[string][]model.Transfer{}, nil
+}
+
+func newTestGraphServer(t *testing.T, graphQLConfig *model.GraphQLConfig) (*GraphServer, *fakeReader) {
+	reader := &fakeReader{
+		accounts: []model.Account{{AccountID: "ACC-1", PersonID: "P-1"}, {AccountID: "ACC-2", PersonID: "P-1"}, {AccountID: "ACC-3", PersonID: "P-1"}},
+		calls: map[string]int{},
+		keys: map[string][]string{},
+	}
+	g, err := newGraphServer(reader, 1, graphQLConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g, reader
+}
+
+// the balances and statements of all the accounts of a person are loaded with one call each
+func TestExecuteBatchesLists(t *testing.T) {
+	g, reader := newTestGraphServer(t, &model.GraphQLConfig{MaxDepth: 5, MaxCost: 1000})
+
+	res, ok := g.Execute(context.Background(), Request{Query: `{
+		accountsByPerson(personId: "P-1") {
+			accountId updatedAt
+			balances { currency amount }
+			statements(last: 2) { type amount transactionId }
+			transfers { accountFrom }
+		}
+	}`})
+	if !ok || len(res.Errors) > 0 {
+		t.Fatalf("unexpected errors %v", res.Errors)
+	}
+
+	for _, name := range []string{"balances", "statements", "transfers"} {
+		if reader.calls[name] != 1 {
+			t.Errorf("%s loaded %d times, want 1", name, reader.calls[name])
+		}
+		if len(reader.keys[name]) != 3 {
+			t.Errorf("%s keys %v, want the 3 accounts", name, reader.keys[name])
+		}
+	}
+
+	data, _ := json.Marshal(res.Data)
+	if !strings.Contains(string(data), `"balances":[{"amount":10,"currency":"BRL"}]`) {
+		t.Errorf("unexpected data %s", data)
+	}
+	if !strings.Contains(string(data), `"statements":[{"amount":2,"transactionId":null,"type":"CREDIT"}]`) {
+		t.Errorf("the last argument is not used %s", data)
+	}
+}
+
+func TestExecuteAccountNotFound(t *testing.T) {
+	g, _ := newTestGraphServer(t, &model.GraphQLConfig{})
+
+	res, ok := g.Execute(context.Background(), Request{Query: `{ account(accountId: "ACC-9") { accountId } }`})
+	if !ok || len(res.Errors) > 0 {
+		t.Fatalf("unexpected errors %v", res.Errors)
+	}
+	data, _ := json.Marshal(res.Data)
+	if string(data) != `{"account":null}` {
+		t.Errorf("unexpected data %s", data)
+	}
+}
+
+func TestExecuteLimits(t *testing.T) {
+	g, reader := newTestGraphServer(t, &model.GraphQLConfig{MaxDepth: 2, MaxCost: 50})
+
+	tests := []struct {
+		name		string
+		request		Request
+		message		string
+	}{
+		{"depth", Request{Query: `{ accountsByPerson(personId: "P-1") { balances { amount } } }`}, "query depth 3 exceeds the max depth 2"},
+		{"depth in fragment", Request{Query: `query { account(accountId: "ACC-1") { ...f } } fragment f on Account { statements { amount } }`}, "query depth 3 exceeds the max depth 2"},
+		// 1 + 10 * (1 + 1 + 1)
+		{"cost", Request{Query: `{ accountsByPerson(personId: "P-1") { accountId personId tenantId } }`}, ""},
+		// 1 + 10 * (1 + 1 + 1 + 1 + 1 + 1)
+		{"cost over", Request{Query: `{ accountsByPerson(personId: "P-1") { id accountId personId tenantId productId createdAt } }`}, "query cost 61 exceeds the max cost 50"},
+		{"invalid", Request{Query: `{ account { nope } }`}, "Cannot query field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := reader.calls["ListAccountPerPerson"] + reader.calls["GetAccount"]
+			res, ok := g.Execute(context.Background(), tt.request)
+			if tt.message == "" {
+				if !ok || len(res.Errors) > 0 {
+					t.Fatalf("unexpected errors %v", res.Errors)
+				}
+				return
+			}
+			if ok || len(res.Errors) == 0 || !strings.Contains(res.Errors[0].Message, tt.message) {
+				t.Fatalf("got %v, want %q", res.Errors, tt.message)
+			}
+			if reader.calls["ListAccountPerPerson"] + reader.calls["GetAccount"] != calls {
+				t.Errorf("the query was executed")
+			}
+		})
+	}
+}
+
+func TestCheckLimitsLastArgument(t *testing.T) {
+	g, _ := newTestGraphServer(t, &model.GraphQLConfig{MaxDepth: 5, MaxCost: 30})
+
+	// 1 + 1 * (1 + 25 * 1) = 27
+	res, ok := g.Execute(context.Background(), Request{
+		Query: `query q($n: Int) { account(accountId: "ACC-1") { statements(last: $n) { amount } } }`,
+		Variables: map[string]interface{}{"n": float64(25)},
+	})
+	if !ok {
+		t.Fatalf("unexpected errors %v", res.Errors)
+	}
+
+	res, ok = g.Execute(context.Background(), Request{
+		Query: `query q($n: Int) { account(accountId: "ACC-1") { statements(last: $n) { amount } } }`,
+		Variables: map[string]interface{}{"n": float64(40)},
+	})
+	if ok {
+		t.Fatalf("the cost of last 40 must exceed the limit")
+	}
+}
@@ -0,0 +1,85 @@
+package grpc
+
+import (
+	"time"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/adapter/grpc/pb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func toString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func toAccount(account *model.Account) *pb.Account {
+	return &pb.Account{	Id: int64(account.ID),
+						AccountId: account.AccountID,
+						PersonId: account.PersonID,
+						TenantId: account.TenantID,
+						ProductId: account.ProductID,
+						UserLastUpdate: toString(account.UserLastUpdate),
+						CreatedAt: toTimestamp(&account.CreatedAt),
+						UpdatedAt: toTimestamp(account.UpdatedAt) }
+}
+
+func toAccountBalance(accountBalance *model.AccountBalance) *pb.AccountBalance {
+	if accountBalance == nil {
+		return nil
+	}
+	return &pb.AccountBalance{	Id: int64(accountBalance.ID),
+								AccountId: accountBalance.AccountID,
+								Currency: accountBalance.Currency,
+								Amount: accountBalance.Amount,
+								TenantId: accountBalance.TenantID,
+								TransactionId: toString(accountBalance.TransactionID),
+								CreatedAt: toTimestamp(&accountBalance.CreatedAt),
+								UpdatedAt: toTimestamp(accountBalance.UpdatedAt) }
+}
+
+func toAccountStatement(accountStatement *model.AccountStatement) *pb.AccountStatement {
+	return &pb.AccountStatement{	Id: int64(accountStatement.ID),
+									AccountId: accountStatement.AccountID,
+									TypeCharge: accountStatement.Type,
+									Currency: accountStatement.Currency,
+									Amount: accountStatement.Amount,
+									TenantId: accountStatement.TenantID,
+									TransactionId: toString(accountStatement.TransactionID),
+									Obs: accountStatement.Obs,
+									ChargedAt: toTimestamp(&accountStatement.ChargedAt) }
+}
+
+func toMovimentAccount(movimentAccount *model.MovimentAccount) *pb.MovimentAccount {
+	res := pb.MovimentAccount{	AccountBalance: toAccountBalance(movimentAccount.AccountBalance),
+								StatementCredit: movimentAccount.AccountBalanceStatementCredit,
+								StatementDebit: movimentAccount.AccountBalanceStatementDebit,
+								StatementTotal: movimentAccount.AccountBalanceStatementTotal }
+	if movimentAccount.AccountStatement != nil {
+		for i := range *movimentAccount.AccountStatement {
+			res.AccountStatement = append(res.AccountStatement, toAccountStatement(&(*movimentAccount.AccountStatement)[i]))
+		}
+	}
+	return &res
+}
+
+func toTransfer(transfer *model.Transfer) *pb.AccountTransfer {
+	return &pb.AccountTransfer{	Id: int64(transfer.ID),
+								AccountFrom: toAccountBalance(&transfer.AccountFrom),
+								AccountTo: toAccountBalance(&transfer.AccountTo),
+								Currency: transfer.Currency,
+								Amount: transfer.Amount,
+								TypeCharge: transfer.Type,
+								Status: transfer.Status,
+								TransferAt: toTimestamp(&transfer.TransferAt) }
+}
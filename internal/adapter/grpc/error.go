@@ -0,0 +1,49 @@
+package grpc
+
+import (
+	"errors"
+	"strings"
+	"context"
+
+	"github.com/go-account/internal/core/erro"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// About map the domain errors to the grpc status codes (the same classes of the http ErrorHandler)
+func errorStatus(err error) error {
+	// already a grpc status
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	var validationError *erro.ValidationError
+	switch {
+	case errors.As(err, &validationError):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.DeadlineExceeded), strings.Contains(err.Error(), "context deadline exceeded"):
+		return status.Error(codes.DeadlineExceeded, erro.ErrTimeout.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+
+	switch err {
+	case erro.ErrBadRequest, erro.ErrDayCount, erro.ErrProduct, erro.ErrAccountNumber, erro.ErrMediaType:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case erro.ErrInvalidAmount, erro.ErrInsufficientFunds, erro.ErrCurrency, erro.ErrTransInvalid, erro.ErrAccountBlocked:
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case erro.ErrNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case erro.ErrTimeout:
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case erro.ErrUnauthorized:
+		return status.Error(codes.Unauthenticated, err.Error())
+	case erro.ErrHTTPForbiden:
+		return status.Error(codes.PermissionDenied, err.Error())
+	case erro.ErrRateLimit:
+		return status.Error(codes.ResourceExhausted, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
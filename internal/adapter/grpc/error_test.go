@@ -0,0 +1,40 @@
+package grpc
+
+import (
+	"fmt"
+	"testing"
+	"context"
+
+	"github.com/go-account/internal/core/erro"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		err		error
+		code	codes.Code
+	}{
+		{&erro.ValidationError{Fields: []erro.FieldError{{Field: "person_id", Rule: "required"}}}, codes.InvalidArgument},
+		{erro.ErrBadRequest, codes.InvalidArgument},
+		{erro.ErrProduct, codes.InvalidArgument},
+		{erro.ErrInsufficientFunds, codes.FailedPrecondition},
+		{erro.ErrAccountBlocked, codes.FailedPrecondition},
+		{erro.ErrTransInvalid, codes.FailedPrecondition},
+		{erro.ErrNotFound, codes.NotFound},
+		{erro.ErrUnauthorized, codes.Unauthenticated},
+		{erro.ErrHTTPForbiden, codes.PermissionDenied},
+		{erro.ErrRateLimit, codes.ResourceExhausted},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+		{fmt.Errorf("query: %w", context.Canceled), codes.Canceled},
+		{status.Error(codes.Aborted, "aborted"), codes.Aborted},
+		{fmt.Errorf("database down"), codes.Internal},
+	}
+
+	for _, tt := range tests {
+		if code := status.Code(errorStatus(tt.err)); code != tt.code {
+			t.Errorf("%v: code %s, want %s", tt.err, code, tt.code)
+		}
+	}
+}
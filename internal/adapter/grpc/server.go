@@ -0,0 +1,236 @@
+package grpc
+
+import (
+	"time"
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/go-account/internal/core/service"
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/auth"
+	"github.com/go-account/internal/core/validator"
+	"github.com/go-account/internal/adapter/grpc/pb"
+	go_core_observ "github.com/eliezerraj/go-core/observability"
+)
+
+var (
+	childLogger = log.With().Str("component", "go-account").Str("package", "internal.adapter.grpc").Logger()
+	tracerProvider go_core_observ.TracerProvider
+)
+
+// About the grpc AccountService, it calls the same WorkerService methods of the http api
+type AccountServer struct {
+	pb.UnimplementedAccountServiceServer
+	workerService 	*service.WorkerService
+	ctxTimeout		time.Duration
+}
+
+// About create the grpc account server
+func NewAccountServer(workerService *service.WorkerService,
+					ctxTimeout	time.Duration) *AccountServer {
+	childLogger.Info().Str("func","NewAccountServer").Send()
+
+	return &AccountServer{
+		workerService: workerService,
+		ctxTimeout: ctxTimeout,
+	}
+}
+
+// About the ctx of a call, the deadline of the client is kept when it is shorter than the ctx timeout
+func (a *AccountServer) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, a.ctxTimeout * time.Second)
+}
+
+// About add an account
+func (a *AccountServer) AddAccount(ctx context.Context, req *pb.AddAccountRequest) (*pb.Account, error) {
+	childLogger.Info().Str("func","AddAccount").Send()
+
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.grpc.AddAccount")
+	defer span.End()
+
+	account := model.Account{	AccountID: req.GetAccountId(),
+								PersonID: req.GetPersonId(),
+								ProductID: req.GetProductId() }
+
+	// the tenant comes from the caller identity
+	tenantID, err := auth.ResolveTenant(ctx, req.GetTenantId())
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+	account.TenantID = tenantID
+
+	if err := validator.Validate(&account); err != nil {
+		return nil, errorStatus(err)
+	}
+	account.UserLastUpdate = auth.UserLastUpdate(ctx)
+
+	res, err := a.workerService.AddAccount(ctx, &account)
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+	return toAccount(res), nil
+}
+
+// About get an account
+func (a *AccountServer) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.Account, error) {
+	childLogger.Info().Str("func","GetAccount").Send()
+
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.grpc.GetAccount")
+	defer span.End()
+
+	res, err := a.workerService.GetAccount(ctx, &model.Account{AccountID: req.GetAccountId()})
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+	return toAccount(res), nil
+}
+
+// About update only the informed fields of an account
+func (a *AccountServer) UpdateAccount(ctx context.Context, req *pb.UpdateAccountRequest) (*pb.Account, error) {
+	childLogger.Info().Str("func","UpdateAccount").Send()
+
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.grpc.UpdateAccount")
+	defer span.End()
+
+	accountPatch := model.AccountPatch{	AccountID: req.GetAccountId(),
+										PersonID: req.PersonId }
+	if accountPatch.PersonID != nil && *accountPatch.PersonID == "" {
+		return nil, errorStatus(&erro.ValidationError{Fields: []erro.FieldError{{Field: "person_id", Rule: "required", Message: "is required"}}})
+	}
+	if err := validator.Validate(&accountPatch); err != nil {
+		return nil, errorStatus(err)
+	}
+	accountPatch.UserLastUpdate = auth.UserLastUpdate(ctx)
+
+	res, err := a.workerService.PatchAccount(ctx, &accountPatch)
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+	return toAccount(res), nil
+}
+
+// About delete an account
+func (a *AccountServer) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest) (*pb.Account, error) {
+	childLogger.Info().Str("func","DeleteAccount").Send()
+
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.grpc.DeleteAccount")
+	defer span.End()
+
+	res, err := a.workerService.DeleteAccount(ctx, &model.Account{AccountID: req.GetAccountId()})
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+	return toAccount(res), nil
+}
+
+// About list all person´s account
+func (a *AccountServer) ListAccountPerPerson(ctx context.Context, req *pb.ListAccountPerPersonRequest) (*pb.ListAccountPerPersonResponse, error) {
+	childLogger.Info().Str("func","ListAccountPerPerson").Send()
+
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.grpc.ListAccountPerPerson")
+	defer span.End()
+
+	res, err := a.workerService.ListAccountPerPerson(ctx, &model.Account{PersonID: req.GetPersonId()})
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+
+	response := pb.ListAccountPerPersonResponse{}
+	for i := range *res {
+		response.Accounts = append(response.Accounts, toAccount(&(*res)[i]))
+	}
+	return &response, nil
+}
+
+// About list the balances (one per currency) of an account
+func (a *AccountServer) ListAccountBalance(ctx context.Context, req *pb.ListAccountBalanceRequest) (*pb.ListAccountBalanceResponse, error) {
+	childLogger.Info().Str("func","ListAccountBalance").Send()
+
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.grpc.ListAccountBalance")
+	defer span.End()
+
+	res, err := a.workerService.ListAccountBalance(ctx, &model.Account{AccountID: req.GetAccountId()})
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+
+	response := pb.ListAccountBalanceResponse{}
+	for i := range *res {
+		response.Balances = append(response.Balances, toAccountBalance(&(*res)[i]))
+	}
+	return &response, nil
+}
+
+// About post a credit or debit into an account
+func (a *AccountServer) AddPosting(ctx context.Context, req *pb.AddPostingRequest) (*pb.MovimentAccount, error) {
+	childLogger.Info().Str("func","AddPosting").Send()
+
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.grpc.AddPosting")
+	defer span.End()
+
+	accountStatement := model.AccountStatement{	AccountID: req.GetAccountId(),
+													Type: req.GetTypeCharge(),
+													Currency: req.GetCurrency(),
+													Amount: req.GetAmount(),
+													Obs: req.GetObs() }
+	if req.GetTransactionId() != "" {
+		transactionID := req.GetTransactionId()
+		accountStatement.TransactionID = &transactionID
+	}
+	if err := validator.Validate(&accountStatement); err != nil {
+		return nil, errorStatus(err)
+	}
+
+	res, err := a.workerService.AddPosting(ctx, &accountStatement)
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+	return toMovimentAccount(res), nil
+}
+
+// About transfer an amount between two accounts
+func (a *AccountServer) Transfer(ctx context.Context, req *pb.TransferRequest) (*pb.AccountTransfer, error) {
+	childLogger.Info().Str("func","Transfer").Send()
+
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+
+	span := tracerProvider.Span(ctx, "adapter.grpc.Transfer")
+	defer span.End()
+
+	transfer := model.Transfer{	AccountFrom: model.AccountBalance{AccountID: req.GetAccountFrom()},
+								AccountTo: model.AccountBalance{AccountID: req.GetAccountTo()},
+								Currency: req.GetCurrency(),
+								Amount: req.GetAmount() }
+	if err := validator.Validate(&transfer); err != nil {
+		return nil, errorStatus(err)
+	}
+
+	res, err := a.workerService.Transfer(ctx, &transfer)
+	if err != nil {
+		return nil, errorStatus(err)
+	}
+	return toTransfer(res), nil
+}
@@ -0,0 +1,160 @@
+package memory
+
+import (
+	"sort"
+	"time"
+	"context"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
+	"github.com/go-account/internal/core/erro"
+)
+
+// About create or replace the role of a subject into a tenant
+func (m *MemoryRepository) AddTenantRole(ctx context.Context, tx port.Tx, tenantRole *model.TenantRole) (*model.TenantRole, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	tenantRole.CreatedAt = time.Now()
+	for i := range data.roles {
+		if data.roles[i].TenantID == tenantRole.TenantID && data.roles[i].Subject == tenantRole.Subject {
+			data.roles[i].Role = tenantRole.Role
+			data.roles[i].UserLastUpdate = tenantRole.UserLastUpdate
+			data.roles[i].UpdatedAt = timePtr(tenantRole.CreatedAt)
+			tenantRole.ID = data.roles[i].ID
+			return tenantRole, nil
+		}
+	}
+
+	tenantRole.ID = int(data.nextID("tenant_role"))
+	data.roles = append(data.roles, *tenantRole)
+	return tenantRole, nil
+}
+
+// About get the role of a subject into a tenant
+func (m *MemoryRepository) GetTenantRole(ctx context.Context, tenantRole *model.TenantRole) (*model.TenantRole, error){
+	var res *model.TenantRole
+	err := m.read(ctx, func(data *store) error {
+		for _, res_tenantRole := range data.roles {
+			if res_tenantRole.TenantID == tenantRole.TenantID && res_tenantRole.Subject == tenantRole.Subject && visible(ctx, res_tenantRole.TenantID) {
+				res = &res_tenantRole
+				return nil
+			}
+		}
+		return erro.ErrNotFound
+	})
+	return res, err
+}
+
+// About list all roles of a tenant
+func (m *MemoryRepository) ListTenantRole(ctx context.Context, tenantRole *model.TenantRole) (*[]model.TenantRole, error){
+	res_tenantRole_list := []model.TenantRole{}
+	err := m.read(ctx, func(data *store) error {
+		for _, res_tenantRole := range data.roles {
+			if res_tenantRole.TenantID == tenantRole.TenantID && visible(ctx, res_tenantRole.TenantID) {
+				res_tenantRole_list = append(res_tenantRole_list, res_tenantRole)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(res_tenantRole_list, func(i, j int) bool { return res_tenantRole_list[i].Subject < res_tenantRole_list[j].Subject })
+	return &res_tenantRole_list, nil
+}
+
+// About create an api key (only the hash of the key is stored)
+func (m *MemoryRepository) AddApiKey(ctx context.Context, tx port.Tx, apiKey *model.ApiKey) (*model.ApiKey, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, res_apiKey := range data.apiKeys {
+		if res_apiKey.KeyID == apiKey.KeyID {
+			return nil, ErrDuplicateKey
+		}
+	}
+
+	apiKey.CreatedAt = time.Now()
+	apiKey.ID = int(data.nextID("api_key"))
+	res_apiKey := *apiKey
+	res_apiKey.Key = ""
+	data.apiKeys = append(data.apiKeys, res_apiKey)
+	return apiKey, nil
+}
+
+// About get an api key by the key id
+func (m *MemoryRepository) GetApiKey(ctx context.Context, apiKey *model.ApiKey) (*model.ApiKey, error){
+	var res *model.ApiKey
+	err := m.read(ctx, func(data *store) error {
+		for _, res_apiKey := range data.apiKeys {
+			if res_apiKey.KeyID == apiKey.KeyID && visible(ctx, res_apiKey.TenantID) {
+				res = &res_apiKey
+				return nil
+			}
+		}
+		return erro.ErrNotFound
+	})
+	return res, err
+}
+
+// About list all api keys of a tenant (without the hashes)
+func (m *MemoryRepository) ListApiKey(ctx context.Context, apiKey *model.ApiKey) (*[]model.ApiKey, error){
+	res_apiKey_list := []model.ApiKey{}
+	err := m.read(ctx, func(data *store) error {
+		for i := len(data.apiKeys) - 1; i >= 0; i-- {
+			if data.apiKeys[i].TenantID == apiKey.TenantID && visible(ctx, data.apiKeys[i].TenantID) {
+				res_apiKey := data.apiKeys[i]
+				res_apiKey.KeyHash = ""
+				res_apiKey_list = append(res_apiKey_list, res_apiKey)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res_apiKey_list, nil
+}
+
+// About update the hash (rotation) and the status (revocation) of an api key
+func (m *MemoryRepository) UpdateApiKey(ctx context.Context, tx port.Tx, apiKey *model.ApiKey) (int64, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	apiKey.UpdatedAt = timePtr(time.Now())
+	for i := range data.apiKeys {
+		if data.apiKeys[i].KeyID == apiKey.KeyID && data.apiKeys[i].TenantID == apiKey.TenantID && visible(ctx, data.apiKeys[i].TenantID) {
+			data.apiKeys[i].KeyHash = apiKey.KeyHash
+			data.apiKeys[i].Status = apiKey.Status
+			data.apiKeys[i].UpdatedAt = apiKey.UpdatedAt
+			data.apiKeys[i].UserLastUpdate = apiKey.UserLastUpdate
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// About record the last use of an api key (at most once a minute per key)
+func (m *MemoryRepository) UpdateApiKeyLastUsed(ctx context.Context, apiKey *model.ApiKey) (int64, error){
+	var rows int64
+	err := m.write(ctx, func(data *store) error {
+		now := time.Now()
+		for i := range data.apiKeys {
+			if data.apiKeys[i].KeyID != apiKey.KeyID || !visible(ctx, data.apiKeys[i].TenantID) {
+				continue
+			}
+			if data.apiKeys[i].LastUsedAt == nil || data.apiKeys[i].LastUsedAt.Before(now.Add(-time.Minute)) {
+				data.apiKeys[i].LastUsedAt = timePtr(now)
+				rows++
+			}
+		}
+		return nil
+	})
+	return rows, err
+}
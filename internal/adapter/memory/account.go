@@ -0,0 +1,543 @@
+package memory
+
+import (
+	"sort"
+	"time"
+	"context"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
+	"github.com/go-account/internal/core/erro"
+)
+
+func (s *store) accountByID(id int) *model.Account {
+	for i := range s.accounts {
+		if s.accounts[i].ID == id {
+			return &s.accounts[i]
+		}
+	}
+	return nil
+}
+
+func (s *store) accountByAccountID(accountID string) *model.Account {
+	for i := range s.accounts {
+		if s.accounts[i].AccountID == accountID {
+			return &s.accounts[i]
+		}
+	}
+	return nil
+}
+
+// About create an account
+func (m *MemoryRepository) AddAccount(ctx context.Context, tx port.Tx, account *model.Account) (*model.Account, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return nil, err
+	}
+	if data.accountByAccountID(account.AccountID) != nil {
+		return nil, ErrDuplicateKey
+	}
+
+	// same default as the account table
+	if account.Status == "" {
+		account.Status = "ACTIVE"
+	}
+	account.CreatedAt = time.Now()
+	account.ID = int(data.nextID("account"))
+	data.accounts = append(data.accounts, *account)
+	return account, nil
+}
+
+// About get an account
+func (m *MemoryRepository) GetAccount(ctx context.Context, account *model.Account) (*model.Account, error){
+	var res *model.Account
+	err := m.read(ctx, func(data *store) error {
+		if a := data.accountByAccountID(account.AccountID); a != nil && visible(ctx, a.TenantID) {
+			res_account := *a
+			res = &res_account
+			return nil
+		}
+		return erro.ErrNotFound
+	})
+	return res, err
+}
+
+// About get an account (the transactions are serialized, so it is locked)
+func (m *MemoryRepository) GetAccountForUpdate(ctx context.Context, tx port.Tx, account *model.Account) (*model.Account, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return nil, err
+	}
+	if a := data.accountByAccountID(account.AccountID); a != nil && visible(ctx, a.TenantID) {
+		res_account := *a
+		return &res_account, nil
+	}
+	return nil, erro.ErrNotFound
+}
+
+// About get an account from id (pk)
+func (m *MemoryRepository) GetAccountId(ctx context.Context, account *model.Account) (*model.Account, error){
+	var res *model.Account
+	err := m.read(ctx, func(data *store) error {
+		if a := data.accountByID(account.ID); a != nil && visible(ctx, a.TenantID) {
+			res_account := *a
+			res = &res_account
+			return nil
+		}
+		return erro.ErrNotFound
+	})
+	return res, err
+}
+
+// About get all account per person
+func (m *MemoryRepository) ListAccountPerPerson(ctx context.Context, account *model.Account) (*[]model.Account, error){
+	res_account_list := []model.Account{}
+	err := m.read(ctx, func(data *store) error {
+		for i := len(data.accounts) - 1; i >= 0; i-- {
+			if data.accounts[i].PersonID == account.PersonID && visible(ctx, data.accounts[i].TenantID) {
+				res_account_list = append(res_account_list, data.accounts[i])
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res_account_list, nil
+}
+
+// About update only the informed fields of an account and return the updated account
+func (m *MemoryRepository) PatchAccount(ctx context.Context, tx port.Tx, accountPatch *model.AccountPatch) (*model.Account, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	a := data.accountByAccountID(accountPatch.AccountID)
+	if a == nil || a.TenantID != accountPatch.TenantID || !visible(ctx, a.TenantID) {
+		return nil, erro.ErrUpdate
+	}
+	if accountPatch.PersonID != nil {
+		a.PersonID = *accountPatch.PersonID
+	}
+	if accountPatch.Status != nil {
+		a.Status = *accountPatch.Status
+	}
+	a.UpdatedAt = timePtr(time.Now())
+	a.UserLastUpdate = accountPatch.UserLastUpdate
+
+	res_account := *a
+	return &res_account, nil
+}
+
+// About delete an account (and its balances)
+func (m *MemoryRepository) DeleteAccount(ctx context.Context, tx port.Tx, account *model.Account) (bool, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return false, err
+	}
+
+	a := data.accountByAccountID(account.AccountID)
+	if a == nil || !visible(ctx, a.TenantID) {
+		return true, nil
+	}
+	id := a.ID
+
+	accounts := data.accounts[:0:0]
+	for _, res_account := range data.accounts {
+		if res_account.ID != id {
+			accounts = append(accounts, res_account)
+		}
+	}
+	data.accounts = accounts
+
+	balances := data.balances[:0:0]
+	for _, res_balance := range data.balances {
+		if res_balance.FkAccountID != id {
+			balances = append(balances, res_balance)
+		}
+	}
+	data.balances = balances
+	return true, nil
+}
+
+// About open an account balance (one per currency)
+func (m *MemoryRepository) AddAccountBalance(ctx context.Context, tx port.Tx, accountBalance *model.AccountBalance) (*model.AccountBalance, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, res_balance := range data.balances {
+		if res_balance.FkAccountID == accountBalance.FkAccountID && res_balance.Currency == accountBalance.Currency {
+			return nil, ErrDuplicateKey
+		}
+	}
+
+	accountBalance.CreatedAt = time.Now()
+	accountBalance.ID = int(data.nextID("account_balance"))
+	data.balances = append(data.balances, *accountBalance)
+	return accountBalance, nil
+}
+
+// About the balance with the account_id of its account
+func (s *store) balanceView(accountBalance model.AccountBalance) model.AccountBalance {
+	if a := s.accountByID(accountBalance.FkAccountID); a != nil {
+		accountBalance.AccountID = a.AccountID
+	}
+	accountBalance.JwtId = nil
+	accountBalance.RequestId = nil
+	accountBalance.UserLastUpdate = nil
+	accountBalance.TransactionID = nil
+	return accountBalance
+}
+
+// About the balances of an account ordered by currency
+func (s *store) accountBalances(accountID int) []model.AccountBalance {
+	res_accountBalance_list := []model.AccountBalance{}
+	for _, res_balance := range s.balances {
+		if res_balance.FkAccountID == accountID {
+			res_accountBalance_list = append(res_accountBalance_list, s.balanceView(res_balance))
+		}
+	}
+	sort.Slice(res_accountBalance_list, func(i, j int) bool { return res_accountBalance_list[i].Currency < res_accountBalance_list[j].Currency })
+	return res_accountBalance_list
+}
+
+// About list the balances (one per currency) of an account
+func (m *MemoryRepository) ListAccountBalance(ctx context.Context, account *model.Account) (*[]model.AccountBalance, error){
+	res_accountBalance_list := []model.AccountBalance{}
+	err := m.read(ctx, func(data *store) error {
+		if a := data.accountByAccountID(account.AccountID); a != nil && visible(ctx, a.TenantID) {
+			res_accountBalance_list = data.accountBalances(a.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res_accountBalance_list, nil
+}
+
+// About get an account balance per currency (the transactions are serialized, so it is locked)
+func (m *MemoryRepository) GetAccountBalanceForUpdate(ctx context.Context, tx port.Tx, accountBalance *model.AccountBalance) (*model.AccountBalance, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, res_balance := range data.balances {
+		if res_balance.FkAccountID == accountBalance.FkAccountID && res_balance.Currency == accountBalance.Currency && visible(ctx, res_balance.TenantID) {
+			res_accountBalance := data.balanceView(res_balance)
+			return &res_accountBalance, nil
+		}
+	}
+	return nil, erro.ErrNotFound
+}
+
+// About add an amount into an account balance
+func (m *MemoryRepository) UpdateAccountBalanceAmount(ctx context.Context, tx port.Tx, accountBalance *model.AccountBalance) (int64, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	accountBalance.UpdatedAt = timePtr(time.Now())
+	for i := range data.balances {
+		if data.balances[i].ID == accountBalance.ID && visible(ctx, data.balances[i].TenantID) {
+			data.balances[i].Amount = data.balances[i].Amount + accountBalance.Amount
+			data.balances[i].UpdatedAt = accountBalance.UpdatedAt
+			data.balances[i].TransactionID = accountBalance.TransactionID
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// About add an account statement
+func (m *MemoryRepository) AddAccountStatement(ctx context.Context, tx port.Tx, accountStatement *model.AccountStatement) (*model.AccountStatement, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if accountStatement.ChargedAt.IsZero() {
+		accountStatement.ChargedAt = time.Now()
+	}
+	accountStatement.ID = int(data.nextID("account_statement"))
+	data.statements = append(data.statements, *accountStatement)
+	return accountStatement, nil
+}
+
+// About add a maintenance fee statement, it returns false when the account already has a statement with its transaction id
+func (m *MemoryRepository) AddAccountStatementOnce(ctx context.Context, tx port.Tx, accountStatement *model.AccountStatement) (bool, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, res_statement := range data.statements {
+		if res_statement.FkAccountID == accountStatement.FkAccountID &&
+			res_statement.TransactionID != nil && accountStatement.TransactionID != nil &&
+			*res_statement.TransactionID == *accountStatement.TransactionID {
+			return false, nil
+		}
+	}
+
+	if _, err := m.AddAccountStatement(ctx, tx, accountStatement); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// About add a transfer
+func (m *MemoryRepository) AddTransfer(ctx context.Context, tx port.Tx, transfer *model.Transfer) (*model.Transfer, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer.TransferAt = time.Now()
+	transfer.ID = int(data.nextID("transfer_moviment"))
+	data.transfers = append(data.transfers, *transfer)
+	return transfer, nil
+}
+
+// About list all positive balances that have an interest rate configured for the account product
+func (m *MemoryRepository) ListInterestBearingBalance(ctx context.Context) (*[]model.InterestAccrual, error){
+	res_accrual_list := []model.InterestAccrual{}
+	err := m.read(ctx, func(data *store) error {
+		for _, res_balance := range data.balances {
+			a := data.accountByID(res_balance.FkAccountID)
+			if a == nil || res_balance.Amount <= 0 || !visible(ctx, res_balance.TenantID) {
+				continue
+			}
+			for _, interestRate := range data.interestRates {
+				if interestRate.ProductID == a.ProductID && interestRate.TenantID == a.TenantID {
+					res_accrual_list = append(res_accrual_list, model.InterestAccrual{	FkAccountBalanceID: res_balance.ID,
+																						FkAccountID: a.ID,
+																						AccountID: a.AccountID,
+																						Currency: res_balance.Currency,
+																						Balance: res_balance.Amount,
+																						Rate: interestRate.Rate,
+																						DayCount: interestRate.DayCount,
+																						TenantID: a.TenantID })
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res_accrual_list, nil
+}
+
+// About add a daily interest accrual (a second run for the same day is ignored)
+func (m *MemoryRepository) AddInterestAccrual(ctx context.Context, tx port.Tx, interestAccrual *model.InterestAccrual) (bool, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return false, err
+	}
+	for _, res_accrual := range data.accruals {
+		if res_accrual.FkAccountBalanceID == interestAccrual.FkAccountBalanceID && res_accrual.AccrualDate.Equal(interestAccrual.AccrualDate) {
+			return false, nil
+		}
+	}
+
+	res_accrual := *interestAccrual
+	res_accrual.ID = int(data.nextID("account_interest_accrual"))
+	res_accrual.CapitalizedAt = nil
+	data.accruals = append(data.accruals, res_accrual)
+	return true, nil
+}
+
+// About mark as capitalized all pending accruals before a date and return their amounts
+func (m *MemoryRepository) CapitalizeInterestAccrual(ctx context.Context, tx port.Tx, interestAccrual *model.InterestAccrual, until time.Time) ([]float64, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	interestAccrual.CapitalizedAt = timePtr(time.Now())
+	amounts := []float64{}
+	for i := range data.accruals {
+		if data.accruals[i].FkAccountBalanceID == interestAccrual.FkAccountBalanceID &&
+			data.accruals[i].AccrualDate.Before(until) &&
+			data.accruals[i].CapitalizedAt == nil {
+			data.accruals[i].CapitalizedAt = interestAccrual.CapitalizedAt
+			amounts = append(amounts, data.accruals[i].Amount)
+		}
+	}
+	return amounts, nil
+}
+
+// About list the balances of many accounts (batch loaders), the result is keyed by account_id
+func (m *MemoryRepository) ListAccountBalanceByAccountIDs(ctx context.Context, accountIDs []string) (map[string][]model.AccountBalance, error){
+	res := map[string][]model.AccountBalance{}
+	err := m.read(ctx, func(data *store) error {
+		for _, accountID := range accountIDs {
+			if a := data.accountByAccountID(accountID); a != nil && visible(ctx, a.TenantID) {
+				if list := data.accountBalances(a.ID); len(list) > 0 {
+					res[accountID] = list
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About list the last statements of many accounts (batch loaders), the result is keyed by account_id
+func (m *MemoryRepository) ListAccountStatementByAccountIDs(ctx context.Context, accountIDs []string, last int) (map[string][]model.AccountStatement, error){
+	res := map[string][]model.AccountStatement{}
+	err := m.read(ctx, func(data *store) error {
+		for _, accountID := range accountIDs {
+			a := data.accountByAccountID(accountID)
+			if a == nil || !visible(ctx, a.TenantID) {
+				continue
+			}
+			list := []model.AccountStatement{}
+			for _, res_statement := range data.statements {
+				if res_statement.FkAccountID == a.ID {
+					list = append(list, model.AccountStatement{	ID: res_statement.ID,
+																AccountID: a.AccountID,
+																FkAccountID: res_statement.FkAccountID,
+																Type: res_statement.Type,
+																ChargedAt: res_statement.ChargedAt,
+																Currency: res_statement.Currency,
+																Amount: res_statement.Amount,
+																TenantID: res_statement.TenantID,
+																TransactionID: res_statement.TransactionID })
+				}
+			}
+			sort.SliceStable(list, func(i, j int) bool {
+				if !list[i].ChargedAt.Equal(list[j].ChargedAt) {
+					return list[i].ChargedAt.After(list[j].ChargedAt)
+				}
+				return list[i].ID > list[j].ID
+			})
+			if len(list) > last {
+				list = list[:last]
+			}
+			if len(list) > 0 {
+				res[accountID] = list
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About list the last transfers (sent or received) of many accounts, the result is keyed by account_id
+func (m *MemoryRepository) ListTransferByAccountIDs(ctx context.Context, accountIDs []string, last int) (map[string][]model.Transfer, error){
+	res := map[string][]model.Transfer{}
+	err := m.read(ctx, func(data *store) error {
+		for _, accountID := range accountIDs {
+			a := data.accountByAccountID(accountID)
+			if a == nil || !visible(ctx, a.TenantID) {
+				continue
+			}
+			list := []model.Transfer{}
+			for _, res_transfer := range data.transfers {
+				if res_transfer.AccountFrom.FkAccountID != a.ID && res_transfer.AccountTo.FkAccountID != a.ID {
+					continue
+				}
+				from, to := data.accountByID(res_transfer.AccountFrom.FkAccountID), data.accountByID(res_transfer.AccountTo.FkAccountID)
+				if from == nil || to == nil {
+					continue
+				}
+				list = append(list, model.Transfer{	ID: res_transfer.ID,
+													AccountFrom: model.AccountBalance{AccountID: from.AccountID},
+													AccountTo: model.AccountBalance{AccountID: to.AccountID},
+													Type: res_transfer.Type,
+													Status: res_transfer.Status,
+													Currency: res_transfer.Currency,
+													Amount: res_transfer.Amount,
+													TransferAt: res_transfer.TransferAt })
+			}
+			sort.SliceStable(list, func(i, j int) bool {
+				if !list[i].TransferAt.Equal(list[j].TransferAt) {
+					return list[i].TransferAt.After(list[j].TransferAt)
+				}
+				return list[i].ID > list[j].ID
+			})
+			if len(list) > last {
+				list = list[:last]
+			}
+			if len(list) > 0 {
+				res[accountID] = list
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About list the statements of an account charged into a period [from, to), in the order they were charged
+func (m *MemoryRepository) ListAccountStatement(ctx context.Context, account *model.Account, from time.Time, to time.Time) (*[]model.AccountStatement, error){
+	res_accountStatement_list := []model.AccountStatement{}
+	err := m.read(ctx, func(data *store) error {
+		a := data.accountByAccountID(account.AccountID)
+		if a == nil || !visible(ctx, a.TenantID) {
+			return nil
+		}
+		for _, res_statement := range data.statements {
+			if res_statement.FkAccountID == a.ID && !res_statement.ChargedAt.Before(from) && res_statement.ChargedAt.Before(to) {
+				res_statement.AccountID = a.AccountID
+				res_accountStatement_list = append(res_accountStatement_list, res_statement)
+			}
+		}
+		sort.SliceStable(res_accountStatement_list, func(i, j int) bool {
+			if !res_accountStatement_list[i].ChargedAt.Equal(res_accountStatement_list[j].ChargedAt) {
+				return res_accountStatement_list[i].ChargedAt.Before(res_accountStatement_list[j].ChargedAt)
+			}
+			return res_accountStatement_list[i].ID < res_accountStatement_list[j].ID
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res_accountStatement_list, nil
+}
+
+// About the sum and count of the statements of an account per currency
+func (m *MemoryRepository) ListAccountStatementTotal(ctx context.Context, account *model.Account) (*[]model.Reconciliation, error){
+	res_reconciliation_list := []model.Reconciliation{}
+	err := m.read(ctx, func(data *store) error {
+		a := data.accountByAccountID(account.AccountID)
+		if a == nil || !visible(ctx, a.TenantID) {
+			return nil
+		}
+		totals := map[string]*model.Reconciliation{}
+		for _, res_statement := range data.statements {
+			if res_statement.FkAccountID != a.ID {
+				continue
+			}
+			total, ok := totals[res_statement.Currency]
+			if !ok {
+				total = &model.Reconciliation{AccountID: a.AccountID, Currency: res_statement.Currency}
+				totals[res_statement.Currency] = total
+			}
+			total.StatementTotal += res_statement.Amount
+			total.Statements++
+		}
+		for _, total := range totals {
+			res_reconciliation_list = append(res_reconciliation_list, *total)
+		}
+		sort.Slice(res_reconciliation_list, func(i, j int) bool { return res_reconciliation_list[i].Currency < res_reconciliation_list[j].Currency })
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res_reconciliation_list, nil
+}
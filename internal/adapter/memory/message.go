@@ -0,0 +1,154 @@
+package memory
+
+import (
+	"time"
+	"context"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
+)
+
+// About add an event into the outbox, in the same transaction of the mutation
+func (m *MemoryRepository) AddOutboxEvent(ctx context.Context, tx port.Tx, outboxEvent *model.OutboxEvent) (*model.OutboxEvent, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	outboxEvent.ID = data.nextID("outbox_event")
+	outboxEvent.CreatedAt = time.Now()
+	data.outboxEvents = append(data.outboxEvents, *outboxEvent)
+	return outboxEvent, nil
+}
+
+// About claim a batch of pending events for the lease, the transactions are serialized. An event waits while
+// an earlier event of the same account is claimed (ordering per account), the parked events are skipped
+func (m *MemoryRepository) ClaimOutboxEvent(ctx context.Context, limit int, lease time.Duration) (*[]model.OutboxEvent, error){
+	res_outboxEvent_list := []model.OutboxEvent{}
+	err := m.write(ctx, func(data *store) error {
+		now := time.Now()
+		claimed := map[string]bool{}
+		for i := range data.outboxEvents {
+			if len(res_outboxEvent_list) == limit {
+				break
+			}
+			outboxEvent := &data.outboxEvents[i]
+			if outboxEvent.PublishedAt != nil || outboxEvent.ParkedAt != nil {
+				continue
+			}
+			if outboxEvent.ClaimedUntil != nil && !outboxEvent.ClaimedUntil.Before(now) {
+				claimed[outboxEvent.AggregateID] = true
+				continue
+			}
+			if claimed[outboxEvent.AggregateID] {
+				continue
+			}
+			outboxEvent.ClaimedUntil = timePtr(now.Add(lease))
+			res_outboxEvent_list = append(res_outboxEvent_list, *outboxEvent)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res_outboxEvent_list, nil
+}
+
+// About list the events not published yet (nor parked), in the order they were written
+func (m *MemoryRepository) ListPendingOutboxEvent(ctx context.Context, tx port.Tx, limit int) (*[]model.OutboxEvent, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	res_outboxEvent_list := []model.OutboxEvent{}
+	for _, outboxEvent := range data.outboxEvents {
+		if len(res_outboxEvent_list) == limit {
+			break
+		}
+		if outboxEvent.PublishedAt == nil && outboxEvent.ParkedAt == nil {
+			res_outboxEvent_list = append(res_outboxEvent_list, outboxEvent)
+		}
+	}
+	return &res_outboxEvent_list, nil
+}
+
+// About mark the events as published
+func (m *MemoryRepository) MarkOutboxEventPublished(ctx context.Context, tx port.Tx, ids []int64) (int64, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	var rows int64
+	publishedAt := time.Now()
+	for i := range data.outboxEvents {
+		for _, id := range ids {
+			if data.outboxEvents[i].ID == id {
+				data.outboxEvents[i].PublishedAt = &publishedAt
+				data.outboxEvents[i].Attempts++
+				data.outboxEvents[i].LastError = nil
+				data.outboxEvents[i].ClaimedUntil = nil
+				rows++
+			}
+		}
+	}
+	return rows, nil
+}
+
+// About record a failed publish attempt of an event, it is parked when it reached the max attempts (0 never parks)
+func (m *MemoryRepository) MarkOutboxEventFailed(ctx context.Context, tx port.Tx, id int64, lastError string, maxAttempts int) (int64, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return 0, err
+	}
+	for i := range data.outboxEvents {
+		if data.outboxEvents[i].ID == id {
+			data.outboxEvents[i].Attempts++
+			data.outboxEvents[i].LastError = &lastError
+			data.outboxEvents[i].ClaimedUntil = nil
+			if maxAttempts > 0 && data.outboxEvents[i].Attempts >= maxAttempts {
+				data.outboxEvents[i].ParkedAt = timePtr(time.Now())
+			}
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// About give back the claim of the events not published (waiting behind a failure of the same account)
+func (m *MemoryRepository) ReleaseOutboxEvent(ctx context.Context, tx port.Tx, ids []int64) (int64, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	var rows int64
+	for i := range data.outboxEvents {
+		for _, id := range ids {
+			if data.outboxEvents[i].ID == id && data.outboxEvents[i].PublishedAt == nil {
+				data.outboxEvents[i].ClaimedUntil = nil
+				rows++
+			}
+		}
+	}
+	return rows, nil
+}
+
+// About record a consumed message, it returns false when the key was already consumed (duplicate)
+func (m *MemoryRepository) AddConsumedMessage(ctx context.Context, tx port.Tx, consumedMessage *model.ConsumedMessage) (bool, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return false, err
+	}
+	for _, res_consumedMessage := range data.consumedMessages {
+		if res_consumedMessage.Consumer == consumedMessage.Consumer && res_consumedMessage.MessageKey == consumedMessage.MessageKey {
+			return false, nil
+		}
+	}
+
+	res_consumedMessage := *consumedMessage
+	res_consumedMessage.CreatedAt = time.Now()
+	data.consumedMessages = append(data.consumedMessages, res_consumedMessage)
+	return true, nil
+}
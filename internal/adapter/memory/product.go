@@ -0,0 +1,300 @@
+package memory
+
+import (
+	"sort"
+	"time"
+	"context"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
+	"github.com/go-account/internal/core/erro"
+)
+
+// About create or replace an account product
+func (m *MemoryRepository) AddAccountProduct(ctx context.Context, tx port.Tx, accountProduct *model.AccountProduct) (*model.AccountProduct, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	accountProduct.CreatedAt = time.Now()
+	res_accountProduct := *accountProduct
+	res_accountProduct.InterestRate = nil
+	res_accountProduct.FeeSchedule = nil
+	for i := range data.products {
+		if data.products[i].ProductID == accountProduct.ProductID && data.products[i].TenantID == accountProduct.TenantID {
+			res_accountProduct.ID = data.products[i].ID
+			res_accountProduct.CreatedAt = data.products[i].CreatedAt
+			res_accountProduct.UpdatedAt = timePtr(accountProduct.CreatedAt)
+			data.products[i] = res_accountProduct
+			accountProduct.ID = res_accountProduct.ID
+			return accountProduct, nil
+		}
+	}
+
+	res_accountProduct.ID = int(data.nextID("account_product"))
+	data.products = append(data.products, res_accountProduct)
+	accountProduct.ID = res_accountProduct.ID
+	return accountProduct, nil
+}
+
+// About get an account product of a tenant
+func (m *MemoryRepository) GetAccountProduct(ctx context.Context, accountProduct *model.AccountProduct) (*model.AccountProduct, error){
+	var res *model.AccountProduct
+	err := m.read(ctx, func(data *store) error {
+		for _, res_accountProduct := range data.products {
+			if res_accountProduct.ProductID == accountProduct.ProductID && res_accountProduct.TenantID == accountProduct.TenantID && visible(ctx, res_accountProduct.TenantID) {
+				res = &res_accountProduct
+				return nil
+			}
+		}
+		return erro.ErrNotFound
+	})
+	return res, err
+}
+
+// About list all account products of a tenant
+func (m *MemoryRepository) ListAccountProduct(ctx context.Context, accountProduct *model.AccountProduct) (*[]model.AccountProduct, error){
+	res_accountProduct_list := []model.AccountProduct{}
+	err := m.read(ctx, func(data *store) error {
+		for _, res_accountProduct := range data.products {
+			if res_accountProduct.TenantID == accountProduct.TenantID && visible(ctx, res_accountProduct.TenantID) {
+				res_accountProduct_list = append(res_accountProduct_list, res_accountProduct)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(res_accountProduct_list, func(i, j int) bool { return res_accountProduct_list[i].ProductID < res_accountProduct_list[j].ProductID })
+	return &res_accountProduct_list, nil
+}
+
+// About create or replace the interest rate of an account product out of a transaction (test setup)
+func (m *MemoryRepository) PutInterestRate(ctx context.Context, interestRate model.InterestRate) error{
+	return m.write(ctx, func(data *store) error {
+		data.putInterestRate(&interestRate)
+		return nil
+	})
+}
+
+// About create or replace the interest rate of an account product
+func (m *MemoryRepository) AddInterestRate(ctx context.Context, tx port.Tx, interestRate *model.InterestRate) (*model.InterestRate, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	data.putInterestRate(interestRate)
+	return interestRate, nil
+}
+
+func (s *store) putInterestRate(interestRate *model.InterestRate) {
+	for i := range s.interestRates {
+		if s.interestRates[i].ProductID == interestRate.ProductID && s.interestRates[i].TenantID == interestRate.TenantID {
+			interestRate.ID = s.interestRates[i].ID
+			s.interestRates[i] = *interestRate
+			return
+		}
+	}
+	interestRate.ID = int(s.nextID("interest_rate"))
+	s.interestRates = append(s.interestRates, *interestRate)
+}
+
+// About get the interest rate of an account product
+func (m *MemoryRepository) GetInterestRate(ctx context.Context, interestRate *model.InterestRate) (*model.InterestRate, error){
+	var res *model.InterestRate
+	err := m.read(ctx, func(data *store) error {
+		for _, res_interestRate := range data.interestRates {
+			if res_interestRate.ProductID == interestRate.ProductID && res_interestRate.TenantID == interestRate.TenantID && visible(ctx, res_interestRate.TenantID) {
+				res = &res_interestRate
+				return nil
+			}
+		}
+		return erro.ErrNotFound
+	})
+	return res, err
+}
+
+// About add a fee schedule of a tenant, an empty product_id is the schedule of the tenant (there is no
+// write in the service, the schedules are loaded by the schema scripts)
+func (m *MemoryRepository) PutFeeSchedule(ctx context.Context, feeSchedule model.FeeSchedule) error{
+	return m.write(ctx, func(data *store) error {
+		feeSchedule.ID = int(data.nextID("fee_schedule"))
+		data.feeSchedules = append(data.feeSchedules, feeSchedule)
+		return nil
+	})
+}
+
+// About add a fee schedule of a tenant, an empty product_id is the schedule of the tenant
+func (m *MemoryRepository) AddFeeSchedule(ctx context.Context, tx port.Tx, feeSchedule *model.FeeSchedule) (*model.FeeSchedule, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	feeSchedule.ID = int(data.nextID("fee_schedule"))
+	data.feeSchedules = append(data.feeSchedules, *feeSchedule)
+	return feeSchedule, nil
+}
+
+// About delete the fee schedules bound to an account product (the schedules of the tenant are kept)
+func (m *MemoryRepository) DeleteFeeScheduleProduct(ctx context.Context, tx port.Tx, feeSchedule *model.FeeSchedule) (int64, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	list_feeSchedule := data.feeSchedules[:0]
+	for _, res_feeSchedule := range data.feeSchedules {
+		if res_feeSchedule.ProductID != "" && res_feeSchedule.ProductID == feeSchedule.ProductID && res_feeSchedule.TenantID == feeSchedule.TenantID {
+			deleted++
+			continue
+		}
+		list_feeSchedule = append(list_feeSchedule, res_feeSchedule)
+	}
+	data.feeSchedules = list_feeSchedule
+	return deleted, nil
+}
+
+// About get the fee schedule of a tenant for an operation and currency (the account product schedule has precedence over the tenant one)
+func (m *MemoryRepository) GetFeeSchedule(ctx context.Context, feeSchedule *model.FeeSchedule) (*model.FeeSchedule, error){
+	var res *model.FeeSchedule
+	err := m.read(ctx, func(data *store) error {
+		for _, res_feeSchedule := range data.feeSchedules {
+			if res_feeSchedule.TenantID != feeSchedule.TenantID ||
+				res_feeSchedule.Operation != feeSchedule.Operation ||
+				res_feeSchedule.Currency != feeSchedule.Currency ||
+				!visible(ctx, res_feeSchedule.TenantID) {
+				continue
+			}
+			if res_feeSchedule.ProductID != "" && res_feeSchedule.ProductID != feeSchedule.ProductID {
+				continue
+			}
+			if res == nil || (res.ProductID == "" && res_feeSchedule.ProductID != "") {
+				found := res_feeSchedule
+				res = &found
+			}
+		}
+		if res == nil {
+			return erro.ErrNotFound
+		}
+		return nil
+	})
+	return res, err
+}
+
+// About list the balances that have a MAINTENANCE fee schedule (of the account product or of the tenant) in their currency
+func (m *MemoryRepository) ListMaintenanceBalance(ctx context.Context) (*[]model.MaintenanceBalance, error){
+	res_maintenance_list := []model.MaintenanceBalance{}
+	err := m.read(ctx, func(data *store) error {
+		for _, res_balance := range data.balances {
+			a := data.accountByID(res_balance.FkAccountID)
+			if a == nil || !visible(ctx, res_balance.TenantID) {
+				continue
+			}
+			for _, res_feeSchedule := range data.feeSchedules {
+				if res_feeSchedule.TenantID == a.TenantID &&
+					res_feeSchedule.Operation == "MAINTENANCE" &&
+					res_feeSchedule.Currency == res_balance.Currency &&
+					(res_feeSchedule.ProductID == "" || res_feeSchedule.ProductID == a.ProductID) {
+					res_maintenance_list = append(res_maintenance_list, model.MaintenanceBalance{	FkAccountBalanceID: res_balance.ID,
+																									FkAccountID: a.ID,
+																									AccountID: a.AccountID,
+																									ProductID: a.ProductID,
+																									Currency: res_balance.Currency,
+																									Balance: res_balance.Amount,
+																									TenantID: a.TenantID })
+					break
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res_maintenance_list, nil
+}
+
+// About list the fee schedules bound to an account product
+func (m *MemoryRepository) ListFeeScheduleProduct(ctx context.Context, feeSchedule *model.FeeSchedule) (*[]model.FeeSchedule, error){
+	res_feeSchedule_list := []model.FeeSchedule{}
+	err := m.read(ctx, func(data *store) error {
+		for _, res_feeSchedule := range data.feeSchedules {
+			if res_feeSchedule.ProductID == feeSchedule.ProductID && res_feeSchedule.TenantID == feeSchedule.TenantID && visible(ctx, res_feeSchedule.TenantID) {
+				res_feeSchedule_list = append(res_feeSchedule_list, res_feeSchedule)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(res_feeSchedule_list, func(i, j int) bool {
+		if res_feeSchedule_list[i].Operation != res_feeSchedule_list[j].Operation {
+			return res_feeSchedule_list[i].Operation < res_feeSchedule_list[j].Operation
+		}
+		return res_feeSchedule_list[i].Currency < res_feeSchedule_list[j].Currency
+	})
+	return &res_feeSchedule_list, nil
+}
+
+// About create or replace the account number format of a tenant (the sequence is kept)
+func (m *MemoryRepository) AddAccountNumberFormat(ctx context.Context, tx port.Tx, accountNumberFormat *model.AccountNumberFormat) (*model.AccountNumberFormat, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	accountNumberFormat.CreatedAt = time.Now()
+	for i := range data.numberFormats {
+		if data.numberFormats[i].TenantID == accountNumberFormat.TenantID {
+			accountNumberFormat.ID = data.numberFormats[i].ID
+			accountNumberFormat.LastSequence = data.numberFormats[i].LastSequence
+			res_accountNumberFormat := *accountNumberFormat
+			res_accountNumberFormat.CreatedAt = data.numberFormats[i].CreatedAt
+			res_accountNumberFormat.UpdatedAt = timePtr(accountNumberFormat.CreatedAt)
+			data.numberFormats[i] = res_accountNumberFormat
+			return accountNumberFormat, nil
+		}
+	}
+
+	accountNumberFormat.ID = int(data.nextID("account_number_format"))
+	accountNumberFormat.LastSequence = 0
+	data.numberFormats = append(data.numberFormats, *accountNumberFormat)
+	return accountNumberFormat, nil
+}
+
+// About get the account number format of a tenant
+func (m *MemoryRepository) GetAccountNumberFormat(ctx context.Context, accountNumberFormat *model.AccountNumberFormat) (*model.AccountNumberFormat, error){
+	var res *model.AccountNumberFormat
+	err := m.read(ctx, func(data *store) error {
+		for _, res_accountNumberFormat := range data.numberFormats {
+			if res_accountNumberFormat.TenantID == accountNumberFormat.TenantID && visible(ctx, res_accountNumberFormat.TenantID) {
+				res = &res_accountNumberFormat
+				return nil
+			}
+		}
+		return erro.ErrNotFound
+	})
+	return res, err
+}
+
+// About get the next account number sequence of a tenant
+func (m *MemoryRepository) NextAccountNumberSequence(ctx context.Context, tx port.Tx, accountNumberFormat *model.AccountNumberFormat) (int64, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return 0, err
+	}
+	for i := range data.numberFormats {
+		if data.numberFormats[i].TenantID == accountNumberFormat.TenantID && visible(ctx, data.numberFormats[i].TenantID) {
+			data.numberFormats[i].LastSequence++
+			accountNumberFormat.LastSequence = data.numberFormats[i].LastSequence
+			return accountNumberFormat.LastSequence, nil
+		}
+	}
+	return 0, erro.ErrNotFound
+}
@@ -0,0 +1,197 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"errors"
+	"time"
+
+	"github.com/go-account/internal/core/auth"
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
+
+	go_core_pg "github.com/eliezerraj/go-core/database/pg"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	childLogger = log.With().Str("component","go-account").Str("package","internal.adapter.memory").Logger()
+
+	ErrTxClosed = errors.New("transaction already closed")
+	ErrDuplicateKey = errors.New("duplicate key value violates unique constraint")
+)
+
+var _ port.Repository = (*MemoryRepository)(nil)
+
+// About the tables kept in memory
+type store struct {
+	sequence			map[string]int64
+	accounts			[]model.Account
+	balances			[]model.AccountBalance
+	statements			[]model.AccountStatement
+	transfers			[]model.Transfer
+	accruals			[]model.InterestAccrual
+	products			[]model.AccountProduct
+	interestRates		[]model.InterestRate
+	feeSchedules		[]model.FeeSchedule
+	numberFormats		[]model.AccountNumberFormat
+	roles				[]model.TenantRole
+	apiKeys				[]model.ApiKey
+	outboxEvents		[]model.OutboxEvent
+	consumedMessages	[]model.ConsumedMessage
+	subscriptions		[]model.WebhookSubscription
+	deliveries			[]model.WebhookDelivery
+	attempts			[]model.WebhookDeliveryAttempt
+}
+
+func newStore() *store {
+	return &store{sequence: map[string]int64{}}
+}
+
+// About copy the tables, a transaction works on its copy until the commit
+func (s *store) clone() *store {
+	c := *s
+	c.sequence = make(map[string]int64, len(s.sequence))
+	for k, v := range s.sequence {
+		c.sequence[k] = v
+	}
+	c.accounts = append([]model.Account(nil), s.accounts...)
+	c.balances = append([]model.AccountBalance(nil), s.balances...)
+	c.statements = append([]model.AccountStatement(nil), s.statements...)
+	c.transfers = append([]model.Transfer(nil), s.transfers...)
+	c.accruals = append([]model.InterestAccrual(nil), s.accruals...)
+	c.products = append([]model.AccountProduct(nil), s.products...)
+	c.interestRates = append([]model.InterestRate(nil), s.interestRates...)
+	c.feeSchedules = append([]model.FeeSchedule(nil), s.feeSchedules...)
+	c.numberFormats = append([]model.AccountNumberFormat(nil), s.numberFormats...)
+	c.roles = append([]model.TenantRole(nil), s.roles...)
+	c.apiKeys = append([]model.ApiKey(nil), s.apiKeys...)
+	c.outboxEvents = append([]model.OutboxEvent(nil), s.outboxEvents...)
+	c.consumedMessages = append([]model.ConsumedMessage(nil), s.consumedMessages...)
+	c.subscriptions = append([]model.WebhookSubscription(nil), s.subscriptions...)
+	c.deliveries = append([]model.WebhookDelivery(nil), s.deliveries...)
+	c.attempts = append([]model.WebhookDeliveryAttempt(nil), s.attempts...)
+	return &c
+}
+
+// About the next id of a table
+func (s *store) nextID(table string) int64 {
+	s.sequence[table]++
+	return s.sequence[table]
+}
+
+// About the in memory implementation of the repository (tests and local runs without Postgres).
+// The transactions are serialized: a transaction works on a copy of the tables that replaces them
+// on the commit, the reads out of a transaction see only the committed tables
+type MemoryRepository struct {
+	lock		chan struct{}
+	mutex		sync.Mutex
+	data		*store
+}
+
+// Initialize repository
+func NewMemoryRepository() *MemoryRepository{
+	childLogger.Info().Str("func","NewMemoryRepository").Send()
+
+	return &MemoryRepository{
+		lock:	make(chan struct{}, 1),
+		data:	newStore(),
+	}
+}
+
+// About a transaction of the memory repository
+type memoryTx struct {
+	repository	*MemoryRepository
+	data		*store
+	closed		bool
+}
+
+func (t *memoryTx) Commit(ctx context.Context) error {
+	if t.closed {
+		return ErrTxClosed
+	}
+	t.closed = true
+	t.repository.mutex.Lock()
+	t.repository.data = t.data
+	t.repository.mutex.Unlock()
+	<-t.repository.lock
+	return nil
+}
+
+func (t *memoryTx) Rollback(ctx context.Context) error {
+	if t.closed {
+		return ErrTxClosed
+	}
+	t.closed = true
+	<-t.repository.lock
+	return nil
+}
+
+// About start a transaction, it waits the running one (or the context)
+func (m *MemoryRepository) StartTx(ctx context.Context) (port.Tx, error){
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	select {
+	case m.lock <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return &memoryTx{repository: m, data: m.data.clone()}, nil
+}
+
+// About the stats of the pool, there is no pool in memory
+func (m *MemoryRepository) Stat(ctx context.Context) (go_core_pg.PoolStats){
+	return go_core_pg.PoolStats{}
+}
+
+// About the tables of a transaction
+func (m *MemoryRepository) txData(tx port.Tx) (*store, error) {
+	memTx, ok := tx.(*memoryTx)
+	if !ok || memTx.repository != m {
+		return nil, errors.New("transaction of another repository")
+	}
+	if memTx.closed {
+		return nil, ErrTxClosed
+	}
+	return memTx.data, nil
+}
+
+// About read the committed tables
+func (m *MemoryRepository) read(ctx context.Context, fn func(data *store) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return fn(m.data)
+}
+
+// About a write out of a transaction, it runs in its own transaction
+func (m *MemoryRepository) write(ctx context.Context, fn func(data *store) error) error {
+	tx, err := m.StartTx(ctx)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx.(*memoryTx).data); err != nil {
+		tx.Rollback(ctx)
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// About the row level security of the tables with tenant, an empty tenant sees no row (fail closed)
+// unless the context was marked to see all the tenants (jobs)
+func visible(ctx context.Context, tenantID string) bool {
+	if auth.TenantBypass(ctx) {
+		return true
+	}
+	return auth.TenantID(ctx) != "" && auth.TenantID(ctx) == tenantID
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"time"
+	"context"
+	"testing"
+
+	"github.com/go-account/internal/core/auth"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/model"
+)
+
+// the writes of a transaction are seen out of it only after the commit, a rollback discards them
+func Test_MemoryTx(t *testing.T){
+	ctx := auth.WithTenantBypass(context.Background())
+	repository := NewMemoryRepository()
+
+	tx, err := repository.StartTx(ctx)
+	if err != nil {
+		t.Fatalf("start tx %v", err)
+	}
+	_, err = repository.AddAccount(ctx, tx, &model.Account{AccountID: "ACC-1", PersonID: "P-1", TenantID: "tenant-1"})
+	if err != nil {
+		t.Fatalf("add account %v", err)
+	}
+	if _, err := repository.GetAccount(ctx, &model.Account{AccountID: "ACC-1"}); err != erro.ErrNotFound {
+		t.Errorf("uncommitted account read err %v want %v", err, erro.ErrNotFound)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("commit %v", err)
+	}
+	if err := tx.Commit(ctx); err != ErrTxClosed {
+		t.Errorf("second commit err %v want %v", err, ErrTxClosed)
+	}
+	if _, err := repository.GetAccount(ctx, &model.Account{AccountID: "ACC-1"}); err != nil {
+		t.Errorf("committed account read err %v", err)
+	}
+
+	tx, err = repository.StartTx(ctx)
+	if err != nil {
+		t.Fatalf("start tx %v", err)
+	}
+	_, err = repository.AddAccount(ctx, tx, &model.Account{AccountID: "ACC-2", PersonID: "P-2", TenantID: "tenant-1"})
+	if err != nil {
+		t.Fatalf("add account %v", err)
+	}
+	if err := tx.Rollback(ctx); err != nil {
+		t.Fatalf("rollback %v", err)
+	}
+	if _, err := repository.GetAccount(ctx, &model.Account{AccountID: "ACC-2"}); err != erro.ErrNotFound {
+		t.Errorf("rolled back account read err %v want %v", err, erro.ErrNotFound)
+	}
+	if _, err := repository.AddAccount(ctx, tx, &model.Account{AccountID: "ACC-3"}); err != ErrTxClosed {
+		t.Errorf("write into a closed tx err %v want %v", err, ErrTxClosed)
+	}
+}
+
+// a transaction waits the running one, up to the deadline of its context
+func Test_MemoryTxWait(t *testing.T){
+	repository := NewMemoryRepository()
+
+	tx, err := repository.StartTx(context.Background())
+	if err != nil {
+		t.Fatalf("start tx %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20 * time.Millisecond)
+	defer cancel()
+	if _, err := repository.StartTx(ctx); err != context.DeadlineExceeded {
+		t.Errorf("concurrent tx err %v want %v", err, context.DeadlineExceeded)
+	}
+
+	tx.Rollback(context.Background())
+	tx, err = repository.StartTx(context.Background())
+	if err != nil {
+		t.Fatalf("start tx after the rollback %v", err)
+	}
+	tx.Rollback(context.Background())
+}
+
+// the reads see only the rows of the tenant of the context, without a tenant no row (fail closed)
+func Test_MemoryTenant(t *testing.T){
+	ctx := auth.WithTenantBypass(context.Background())
+	repository := NewMemoryRepository()
+
+	tx, _ := repository.StartTx(ctx)
+	repository.AddAccount(ctx, tx, &model.Account{AccountID: "ACC-1", PersonID: "P-1", TenantID: "tenant-1"})
+	tx.Commit(ctx)
+
+	ctx_other := auth.WithPrincipal(ctx, &model.Principal{Subject: "user", TenantID: "tenant-2"})
+	if _, err := repository.GetAccount(ctx_other, &model.Account{AccountID: "ACC-1"}); err != erro.ErrNotFound {
+		t.Errorf("account of another tenant read err %v want %v", err, erro.ErrNotFound)
+	}
+	ctx_own := auth.WithPrincipal(ctx, &model.Principal{Subject: "user", TenantID: "tenant-1"})
+	if _, err := repository.GetAccount(ctx_own, &model.Account{AccountID: "ACC-1"}); err != nil {
+		t.Errorf("account of the tenant read err %v", err)
+	}
+	if _, err := repository.GetAccount(context.Background(), &model.Account{AccountID: "ACC-1"}); err != erro.ErrNotFound {
+		t.Errorf("account read without tenant err %v want %v", err, erro.ErrNotFound)
+	}
+	if _, err := repository.GetAccount(ctx, &model.Account{AccountID: "ACC-1"}); err != nil {
+		t.Errorf("account read by the jobs err %v", err)
+	}
+
+	tx, _ = repository.StartTx(ctx)
+	defer tx.Rollback(ctx)
+	if _, err := repository.GetAccountForUpdate(ctx_other, tx, &model.Account{AccountID: "ACC-1"}); err != erro.ErrNotFound {
+		t.Errorf("account of another tenant locked err %v want %v", err, erro.ErrNotFound)
+	}
+	if _, err := repository.GetAccountForUpdate(ctx_own, tx, &model.Account{AccountID: "ACC-1"}); err != nil {
+		t.Errorf("account of the tenant locked err %v", err)
+	}
+}
@@ -0,0 +1,305 @@
+package memory
+
+import (
+	"sort"
+	"time"
+	"context"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
+	"github.com/go-account/internal/core/erro"
+)
+
+func (s *store) subscriptionByID(id int) *model.WebhookSubscription {
+	for i := range s.subscriptions {
+		if s.subscriptions[i].ID == id {
+			return &s.subscriptions[i]
+		}
+	}
+	return nil
+}
+
+// About create a webhook subscription
+func (m *MemoryRepository) AddWebhookSubscription(ctx context.Context, tx port.Tx, webhookSubscription *model.WebhookSubscription) (*model.WebhookSubscription, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, res_webhookSubscription := range data.subscriptions {
+		if res_webhookSubscription.SubscriptionID == webhookSubscription.SubscriptionID {
+			return nil, ErrDuplicateKey
+		}
+	}
+
+	webhookSubscription.CreatedAt = time.Now()
+	if webhookSubscription.EventTypes == nil {
+		webhookSubscription.EventTypes = []string{}
+	}
+	webhookSubscription.ID = int(data.nextID("webhook_subscription"))
+	data.subscriptions = append(data.subscriptions, *webhookSubscription)
+	return webhookSubscription, nil
+}
+
+// About get a webhook subscription by the subscription id (without the secret)
+func (m *MemoryRepository) GetWebhookSubscription(ctx context.Context, webhookSubscription *model.WebhookSubscription) (*model.WebhookSubscription, error){
+	var res *model.WebhookSubscription
+	err := m.read(ctx, func(data *store) error {
+		for _, res_webhookSubscription := range data.subscriptions {
+			if res_webhookSubscription.SubscriptionID == webhookSubscription.SubscriptionID && visible(ctx, res_webhookSubscription.TenantID) {
+				res_webhookSubscription.Secret = ""
+				res = &res_webhookSubscription
+				return nil
+			}
+		}
+		return erro.ErrNotFound
+	})
+	return res, err
+}
+
+// About list all webhook subscriptions of a tenant (without the secrets)
+func (m *MemoryRepository) ListWebhookSubscription(ctx context.Context, webhookSubscription *model.WebhookSubscription) (*[]model.WebhookSubscription, error){
+	res_webhookSubscription_list := []model.WebhookSubscription{}
+	err := m.read(ctx, func(data *store) error {
+		for i := len(data.subscriptions) - 1; i >= 0; i-- {
+			if data.subscriptions[i].TenantID == webhookSubscription.TenantID {
+				res_webhookSubscription := data.subscriptions[i]
+				res_webhookSubscription.Secret = ""
+				res_webhookSubscription_list = append(res_webhookSubscription_list, res_webhookSubscription)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res_webhookSubscription_list, nil
+}
+
+// About update the status of a webhook subscription (disable)
+func (m *MemoryRepository) UpdateWebhookSubscription(ctx context.Context, tx port.Tx, webhookSubscription *model.WebhookSubscription) (int64, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	webhookSubscription.UpdatedAt = timePtr(time.Now())
+	if s := data.subscriptionByID(webhookSubscription.ID); s != nil {
+		s.Status = webhookSubscription.Status
+		s.UpdatedAt = webhookSubscription.UpdatedAt
+		s.UserLastUpdate = webhookSubscription.UserLastUpdate
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// About add a delivery of an outbox event for each active subscription of the tenant that accepts
+// the event type (no filter accepts all), in the transaction of the event
+func (m *MemoryRepository) AddWebhookDelivery(ctx context.Context, tx port.Tx, outboxEvent *model.OutboxEvent) (int64, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	var rows int64
+	now := time.Now()
+	for _, s := range data.subscriptions {
+		if s.TenantID != outboxEvent.TenantID || s.Status != "ACTIVE" || !acceptEvent(s.EventTypes, outboxEvent.EventType) {
+			continue
+		}
+		duplicate := false
+		for _, d := range data.deliveries {
+			if d.SubscriptionID == s.SubscriptionID && d.OutboxEventID == outboxEvent.ID {
+				duplicate = true
+			}
+		}
+		if duplicate {
+			continue
+		}
+		data.deliveries = append(data.deliveries, model.WebhookDelivery{	ID: data.nextID("webhook_delivery"),
+																			SubscriptionID: s.SubscriptionID,
+																			OutboxEventID: outboxEvent.ID,
+																			EventType: outboxEvent.EventType,
+																			Payload: outboxEvent.Payload,
+																			Status: "PENDING",
+																			NextAttemptAt: now,
+																			CreatedAt: now })
+		rows++
+	}
+	return rows, nil
+}
+
+func acceptEvent(eventTypes []string, eventType string) bool {
+	if len(eventTypes) == 0 {
+		return true
+	}
+	for _, t := range eventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *store) subscriptionBySubscriptionID(subscriptionID string) *model.WebhookSubscription {
+	for i := range s.subscriptions {
+		if s.subscriptions[i].SubscriptionID == subscriptionID {
+			return &s.subscriptions[i]
+		}
+	}
+	return nil
+}
+
+// About claim the due deliveries of the active subscriptions, the next attempt is moved by the lease
+func (m *MemoryRepository) ClaimWebhookDelivery(ctx context.Context, limit int, lease time.Duration) (*[]model.WebhookDelivery, error){
+	res_webhookDelivery_list := []model.WebhookDelivery{}
+	err := m.write(ctx, func(data *store) error {
+		now := time.Now()
+		due := []int{}
+		for i, d := range data.deliveries {
+			s := data.subscriptionBySubscriptionID(d.SubscriptionID)
+			if d.Status == "PENDING" && !d.NextAttemptAt.After(now) && s != nil && s.Status == "ACTIVE" {
+				due = append(due, i)
+			}
+		}
+		sort.SliceStable(due, func(i, j int) bool { return data.deliveries[due[i]].NextAttemptAt.Before(data.deliveries[due[j]].NextAttemptAt) })
+		if len(due) > limit {
+			due = due[:limit]
+		}
+		for _, i := range due {
+			s := data.subscriptionBySubscriptionID(data.deliveries[i].SubscriptionID)
+			data.deliveries[i].NextAttemptAt = now.Add(time.Duration(lease.Seconds()) * time.Second)
+			res_webhookDelivery := model.WebhookDelivery{	ID: data.deliveries[i].ID,
+															SubscriptionID: s.SubscriptionID,
+															URL: s.URL,
+															Secret: s.Secret,
+															OutboxEventID: data.deliveries[i].OutboxEventID,
+															EventType: data.deliveries[i].EventType,
+															Payload: data.deliveries[i].Payload,
+															Status: data.deliveries[i].Status,
+															Attempts: data.deliveries[i].Attempts,
+															CreatedAt: data.deliveries[i].CreatedAt }
+			res_webhookDelivery_list = append(res_webhookDelivery_list, res_webhookDelivery)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res_webhookDelivery_list, nil
+}
+
+// About record the result of a delivery attempt (status, counters and the next attempt)
+func (m *MemoryRepository) UpdateWebhookDelivery(ctx context.Context, tx port.Tx, webhookDelivery *model.WebhookDelivery) (int64, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return 0, err
+	}
+	for i := range data.deliveries {
+		if data.deliveries[i].ID == webhookDelivery.ID {
+			data.deliveries[i].Status = webhookDelivery.Status
+			data.deliveries[i].Attempts = webhookDelivery.Attempts
+			data.deliveries[i].NextAttemptAt = webhookDelivery.NextAttemptAt
+			data.deliveries[i].LastStatusCode = webhookDelivery.LastStatusCode
+			data.deliveries[i].LastError = webhookDelivery.LastError
+			data.deliveries[i].DeliveredAt = webhookDelivery.DeliveredAt
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// About cancel the pending deliveries of a subscription
+func (m *MemoryRepository) CancelWebhookDelivery(ctx context.Context, tx port.Tx, webhookSubscription *model.WebhookSubscription) (int64, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	s := data.subscriptionByID(webhookSubscription.ID)
+	if s == nil {
+		return 0, nil
+	}
+	var rows int64
+	for i := range data.deliveries {
+		if data.deliveries[i].SubscriptionID == s.SubscriptionID && data.deliveries[i].Status == "PENDING" {
+			data.deliveries[i].Status = "CANCELED"
+			rows++
+		}
+	}
+	return rows, nil
+}
+
+// About add an attempt into the history of a delivery
+func (m *MemoryRepository) AddWebhookDeliveryAttempt(ctx context.Context, tx port.Tx, webhookDeliveryAttempt *model.WebhookDeliveryAttempt) (*model.WebhookDeliveryAttempt, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookDeliveryAttempt.ID = data.nextID("webhook_delivery_attempt")
+	data.attempts = append(data.attempts, *webhookDeliveryAttempt)
+	return webhookDeliveryAttempt, nil
+}
+
+// About list the last deliveries of a subscription, optionally filtered by status
+func (m *MemoryRepository) ListWebhookDelivery(ctx context.Context, webhookSubscription *model.WebhookSubscription, status string, limit int) (*[]model.WebhookDelivery, error){
+	res_webhookDelivery_list := []model.WebhookDelivery{}
+	err := m.read(ctx, func(data *store) error {
+		s := data.subscriptionByID(webhookSubscription.ID)
+		if s == nil {
+			return nil
+		}
+		for i := len(data.deliveries) - 1; i >= 0 && len(res_webhookDelivery_list) < limit; i-- {
+			d := data.deliveries[i]
+			if d.SubscriptionID == s.SubscriptionID && (status == "" || d.Status == status) {
+				res_webhookDelivery_list = append(res_webhookDelivery_list, d)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &res_webhookDelivery_list, nil
+}
+
+// About list the attempts of many deliveries, the result is keyed by the delivery id
+func (m *MemoryRepository) ListWebhookDeliveryAttemptByDeliveryIDs(ctx context.Context, deliveryIDs []int64) (map[int64][]model.WebhookDeliveryAttempt, error){
+	res := map[int64][]model.WebhookDeliveryAttempt{}
+	err := m.read(ctx, func(data *store) error {
+		for _, id := range deliveryIDs {
+			for _, a := range data.attempts {
+				if a.FkDeliveryID == id {
+					res[id] = append(res[id], a)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About send a dead delivery back to the queue (redrive), the attempts start again
+func (m *MemoryRepository) RetryWebhookDelivery(ctx context.Context, tx port.Tx, webhookSubscription *model.WebhookSubscription, deliveryID int64) (int64, error){
+	data, err := m.txData(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	s := data.subscriptionByID(webhookSubscription.ID)
+	if s == nil {
+		return 0, nil
+	}
+	for i := range data.deliveries {
+		if data.deliveries[i].ID == deliveryID && data.deliveries[i].SubscriptionID == s.SubscriptionID && data.deliveries[i].Status == "DEAD" {
+			data.deliveries[i].Status = "PENDING"
+			data.deliveries[i].Attempts = 0
+			data.deliveries[i].NextAttemptAt = time.Now()
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
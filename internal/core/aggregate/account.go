@@ -0,0 +1,182 @@
+package aggregate
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+	"errors"
+	"encoding/json"
+
+	"github.com/go-account/internal/core/model"
+)
+
+// About the events of the account stream (the persistence of the event store mode), they are internal
+// and carry the full state change, the integration events are the CloudEvents of internal/core/event
+const (
+	AccountOpened	= "AccountOpened"
+	AccountUpdated	= "AccountUpdated"
+	AccountClosed	= "AccountClosed"
+	BalanceOpened	= "BalanceOpened"
+	BalancePosted	= "BalancePosted"
+)
+
+var ErrStream = errors.New("event not allowed for the account stream")
+
+type AccountOpenedData struct {
+	Account		model.Account	`json:"account"`
+}
+
+type AccountUpdatedData struct {
+	PersonID		string		`json:"person_id"`
+	Status			string		`json:"status,omitempty"`
+	UpdatedAt		time.Time	`json:"updated_at"`
+	UserLastUpdate	*string		`json:"user_last_update,omitempty"`
+}
+
+type AccountClosedData struct {
+	ClosedAt	time.Time	`json:"closed_at"`
+}
+
+type BalanceOpenedData struct {
+	Balance		model.AccountBalance	`json:"balance"`
+}
+
+type BalancePostedData struct {
+	BalanceID		int			`json:"balance_id"`
+	Currency		string		`json:"currency"`
+	Amount			float64		`json:"amount"`
+	TransactionID	*string		`json:"transaction_id,omitempty"`
+	PostedAt		time.Time	`json:"posted_at"`
+}
+
+// About the account aggregate rebuilt from its stream, the balances are ordered by currency
+type AccountState struct {
+	Version		int						`json:"version"`
+	Account		model.Account			`json:"account"`
+	Balances	[]model.AccountBalance	`json:"balances"`
+	Closed		bool					`json:"closed"`
+}
+
+// About create the next event of a stream
+func NewStreamEvent(aggregateID string, tenantID string, version int, eventType string, data interface{}) (*model.StreamEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	return &model.StreamEvent{	AggregateID: aggregateID,
+								TenantID: tenantID,
+								Version: version,
+								EventType: eventType,
+								Data: payload }, nil
+}
+
+func (a *AccountState) balance(currency string) *model.AccountBalance {
+	for i := range a.Balances {
+		if a.Balances[i].Currency == currency {
+			return &a.Balances[i]
+		}
+	}
+	return nil
+}
+
+// About the balance of a currency (nil when it is not opened)
+func (a *AccountState) Balance(currency string) *model.AccountBalance {
+	return a.balance(currency)
+}
+
+// About apply the next event of the stream, an event out of order or not allowed by the state is an error
+func (a *AccountState) Apply(streamEvent *model.StreamEvent) error {
+	if streamEvent.Version != a.Version + 1 {
+		return fmt.Errorf("%w: version %d after %d", ErrStream, streamEvent.Version, a.Version)
+	}
+	if a.Closed {
+		return fmt.Errorf("%w: %s on a closed account", ErrStream, streamEvent.EventType)
+	}
+	if a.Version == 0 && streamEvent.EventType != AccountOpened {
+		return fmt.Errorf("%w: %s before %s", ErrStream, streamEvent.EventType, AccountOpened)
+	}
+
+	switch streamEvent.EventType {
+	case AccountOpened:
+		if a.Version > 0 {
+			return fmt.Errorf("%w: account already opened", ErrStream)
+		}
+		data := AccountOpenedData{}
+		if err := json.Unmarshal(streamEvent.Data, &data); err != nil {
+			return err
+		}
+		a.Account = data.Account
+	case AccountUpdated:
+		data := AccountUpdatedData{}
+		if err := json.Unmarshal(streamEvent.Data, &data); err != nil {
+			return err
+		}
+		updatedAt := data.UpdatedAt
+		a.Account.PersonID = data.PersonID
+		// the events written before the account status keep the current one
+		if data.Status != "" {
+			a.Account.Status = data.Status
+		}
+		a.Account.UpdatedAt = &updatedAt
+		a.Account.UserLastUpdate = data.UserLastUpdate
+	case AccountClosed:
+		a.Closed = true
+	case BalanceOpened:
+		data := BalanceOpenedData{}
+		if err := json.Unmarshal(streamEvent.Data, &data); err != nil {
+			return err
+		}
+		if a.balance(data.Balance.Currency) != nil {
+			return fmt.Errorf("%w: balance %s already opened", ErrStream, data.Balance.Currency)
+		}
+		a.Balances = append(a.Balances, data.Balance)
+		sort.Slice(a.Balances, func(i, j int) bool { return a.Balances[i].Currency < a.Balances[j].Currency })
+	case BalancePosted:
+		data := BalancePostedData{}
+		if err := json.Unmarshal(streamEvent.Data, &data); err != nil {
+			return err
+		}
+		accountBalance := a.balance(data.Currency)
+		if accountBalance == nil {
+			return fmt.Errorf("%w: balance %s not opened", ErrStream, data.Currency)
+		}
+		postedAt := data.PostedAt
+		accountBalance.Amount = math.Round((accountBalance.Amount + data.Amount) * 100) / 100
+		accountBalance.TransactionID = data.TransactionID
+		accountBalance.UpdatedAt = &postedAt
+	default:
+		return fmt.Errorf("%w: unknown type %s", ErrStream, streamEvent.EventType)
+	}
+
+	a.Version = streamEvent.Version
+	return nil
+}
+
+// About rebuild the state from the last snapshot (optional) and the events after it
+func Rebuild(accountSnapshot *model.AccountSnapshot, list_event []model.StreamEvent) (*AccountState, error) {
+	accountState := AccountState{}
+	if accountSnapshot != nil {
+		if err := json.Unmarshal(accountSnapshot.State, &accountState); err != nil {
+			return nil, err
+		}
+	}
+	for i := range list_event {
+		if err := accountState.Apply(&list_event[i]); err != nil {
+			return nil, err
+		}
+	}
+	return &accountState, nil
+}
+
+// About the snapshot of the state
+func (a *AccountState) Snapshot() (*model.AccountSnapshot, error) {
+	state, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return &model.AccountSnapshot{	AggregateID: a.Account.AccountID,
+									TenantID: a.Account.TenantID,
+									Version: a.Version,
+									State: state }, nil
+}
@@ -0,0 +1,89 @@
+package aggregate
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-account/internal/core/model"
+)
+
+func stream(t *testing.T, events ...interface{}) []model.StreamEvent {
+	list_event := []model.StreamEvent{}
+	for i := 0; i < len(events); i += 2 {
+		streamEvent, err := NewStreamEvent("ACC-1", "T1", len(list_event) + 1, events[i].(string), events[i+1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		list_event = append(list_event, *streamEvent)
+	}
+	return list_event
+}
+
+func TestRebuild(t *testing.T) {
+	now := time.Now().UTC()
+	list_event := stream(t,
+		AccountOpened, AccountOpenedData{Account: model.Account{ID: 7, AccountID: "ACC-1", PersonID: "P-1", TenantID: "T1"}},
+		BalanceOpened, BalanceOpenedData{Balance: model.AccountBalance{ID: 1, AccountID: "ACC-1", Currency: "USD"}},
+		BalanceOpened, BalanceOpenedData{Balance: model.AccountBalance{ID: 2, AccountID: "ACC-1", Currency: "BRL"}},
+		BalancePosted, BalancePostedData{BalanceID: 2, Currency: "BRL", Amount: 100.10, PostedAt: now},
+		BalancePosted, BalancePostedData{BalanceID: 2, Currency: "BRL", Amount: -0.20, PostedAt: now},
+		AccountUpdated, AccountUpdatedData{PersonID: "P-2", UpdatedAt: now},
+	)
+
+	accountState, err := Rebuild(nil, list_event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accountState.Version != 6 || accountState.Account.PersonID != "P-2" || accountState.Account.ID != 7 {
+		t.Errorf("unexpected state %+v", accountState)
+	}
+	if len(accountState.Balances) != 2 || accountState.Balances[0].Currency != "BRL" || accountState.Balance("BRL").Amount != 99.9 {
+		t.Errorf("unexpected balances %+v", accountState.Balances)
+	}
+
+	// the snapshot and the events after it give the same state
+	accountSnapshot, err := Rebuild(nil, list_event[:3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot, err := accountSnapshot.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromSnapshot, err := Rebuild(snapshot, list_event[3:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromSnapshot.Version != 6 || fromSnapshot.Balance("BRL").Amount != 99.9 || fromSnapshot.Account.PersonID != "P-2" {
+		t.Errorf("unexpected state from the snapshot %+v", fromSnapshot)
+	}
+}
+
+func TestApplyNotAllowed(t *testing.T) {
+	opened := AccountOpenedData{Account: model.Account{AccountID: "ACC-1"}}
+	tests := []struct {
+		name	string
+		events	[]interface{}
+	}{
+		{"posting before open", []interface{}{BalancePosted, BalancePostedData{Currency: "BRL", Amount: 1}}},
+		{"balance not opened", []interface{}{AccountOpened, opened, BalancePosted, BalancePostedData{Currency: "BRL", Amount: 1}}},
+		{"balance opened twice", []interface{}{AccountOpened, opened, BalanceOpened, BalanceOpenedData{Balance: model.AccountBalance{Currency: "BRL"}}, BalanceOpened, BalanceOpenedData{Balance: model.AccountBalance{Currency: "BRL"}}}},
+		{"closed", []interface{}{AccountOpened, opened, AccountClosed, AccountClosedData{}, AccountUpdated, AccountUpdatedData{PersonID: "P-2"}}},
+		{"opened twice", []interface{}{AccountOpened, opened, AccountOpened, opened}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Rebuild(nil, stream(t, tt.events...)); !errors.Is(err, ErrStream) {
+				t.Errorf("got %v want ErrStream", err)
+			}
+		})
+	}
+
+	// a gap in the versions
+	list_event := stream(t, AccountOpened, opened, AccountClosed, AccountClosedData{})
+	list_event[1].Version = 3
+	if _, err := Rebuild(nil, list_event); !errors.Is(err, ErrStream) {
+		t.Errorf("got %v want ErrStream", err)
+	}
+}
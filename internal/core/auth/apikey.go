@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"strings"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/base64"
+)
+
+// About the api key format: ak_<key id>_<secret>, the key id is public (lookup), only the hash of the key is stored
+const apiKeyPrefix = "ak"
+
+// About generate a new api key, it returns the key id and the key (shown only once)
+func GenerateApiKey() (string, string, error) {
+	id := make([]byte, 6)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+	keyID := hex.EncodeToString(id)
+
+	key, err := GenerateApiKeySecret(keyID)
+	if err != nil {
+		return "", "", err
+	}
+	return keyID, key, nil
+}
+
+// About generate a new key for an existing key id (rotation)
+func GenerateApiKeySecret(keyID string) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + "_" + keyID + "_" + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// About the key id of an api key (false when the format is invalid)
+func ParseApiKey(key string) (string, bool) {
+	parts := strings.SplitN(strings.TrimSpace(key), "_", 3)
+	if len(parts) != 3 || parts[0] != apiKeyPrefix || parts[1] == "" || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// About the hash stored of an api key (the key has 256 bits of entropy, so a plain sha256 is enough)
+func HashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(key)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"testing"
+)
+
+func TestApiKey(t *testing.T) {
+	keyID, key, err := GenerateApiKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, ok := ParseApiKey(key)
+	if !ok || parsed != keyID {
+		t.Errorf("ParseApiKey(%s) = (%s, %v), want (%s, true)", key, parsed, ok, keyID)
+	}
+
+	rotated, err := GenerateApiKeySecret(keyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed, _ := ParseApiKey(rotated); parsed != keyID {
+		t.Errorf("rotated key id %s, want %s", parsed, keyID)
+	}
+	if HashApiKey(rotated) == HashApiKey(key) {
+		t.Errorf("rotated key must have a different hash")
+	}
+
+	for _, invalid := range []string{"", "abc", "ak__secret", "xx_123_secret", "ak_123_"} {
+		if _, ok := ParseApiKey(invalid); ok {
+			t.Errorf("ParseApiKey(%q) must be invalid", invalid)
+		}
+	}
+}
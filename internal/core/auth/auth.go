@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+)
+
+type contextKey string
+
+const principalKey contextKey = "principal"
+
+// About put the authenticated principal into the context
+func WithPrincipal(ctx context.Context, principal *model.Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// About get the authenticated principal from the context
+func PrincipalFromContext(ctx context.Context) (*model.Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(*model.Principal)
+	return principal, ok && principal != nil
+}
+
+// About check if the principal has all the scopes
+func HasScopes(principal *model.Principal, scopes ...string) bool {
+	for _, scope := range scopes {
+		found := false
+		for _, s := range principal.Scopes {
+			if s == scope {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// About the user to be recorded as user_last_update (nil when the request is not authenticated)
+func UserLastUpdate(ctx context.Context) *string {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok || principal.Subject == "" {
+		return nil
+	}
+	subject := principal.Subject
+	return &subject
+}
+
+// About the tenant of the authenticated principal ("" when the request is not authenticated)
+func TenantID(ctx context.Context) string {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return ""
+	}
+	return principal.TenantID
+}
+
+const tenantBypassKey contextKey = "tenant-bypass"
+
+// About mark the context of the application itself (jobs, authentication lookups, migrations) to see all the tenants.
+// It is never set from a request, so a context without a principal sees no tenant (fail closed)
+func WithTenantBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tenantBypassKey, true)
+}
+
+// About check if the context was marked to see all the tenants, a principal always restricts the context to its tenant
+func TenantBypass(ctx context.Context) bool {
+	if _, ok := PrincipalFromContext(ctx); ok {
+		return false
+	}
+	bypass, _ := ctx.Value(tenantBypassKey).(bool)
+	return bypass
+}
+
+// About the tenant to be used by a request, it always comes from the principal.
+// A different tenant informed by the caller is refused, without a principal (auth disabled) the informed tenant is kept
+func ResolveTenant(ctx context.Context, tenantID string) (string, error) {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return tenantID, nil
+	}
+	if principal.TenantID == "" || (tenantID != "" && tenantID != principal.TenantID) {
+		return "", erro.ErrHTTPForbiden
+	}
+	return principal.TenantID, nil
+}
+
+const clientCertificateKey contextKey = "client-certificate"
+
+// About put the verified client certificate (mTLS) into the context
+func WithClientCertificate(ctx context.Context, clientCertificate *model.ClientCertificate) context.Context {
+	return context.WithValue(ctx, clientCertificateKey, clientCertificate)
+}
+
+// About get the verified client certificate (mTLS) from the context, used for service-to-service authorization
+func ClientCertificateFromContext(ctx context.Context) (*model.ClientCertificate, bool) {
+	clientCertificate, ok := ctx.Value(clientCertificateKey).(*model.ClientCertificate)
+	return clientCertificate, ok && clientCertificate != nil
+}
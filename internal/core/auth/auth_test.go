@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"testing"
+	"context"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+)
+
+func TestResolveTenant(t *testing.T) {
+	ctx := WithPrincipal(context.Background(), &model.Principal{Subject: "user-1", TenantID: "TENANT-1"})
+
+	tests := []struct {
+		name		string
+		ctx			context.Context
+		informed	string
+		want		string
+		err			error
+	}{
+		{"from principal", ctx, "", "TENANT-1", nil},
+		{"same tenant", ctx, "TENANT-1", "TENANT-1", nil},
+		{"other tenant", ctx, "TENANT-2", "", erro.ErrHTTPForbiden},
+		{"principal without tenant", WithPrincipal(context.Background(), &model.Principal{Subject: "user-1"}), "TENANT-1", "", erro.ErrHTTPForbiden},
+		{"auth disabled", context.Background(), "TENANT-2", "TENANT-2", nil},
+	}
+
+	for _, tt := range tests {
+		got, err := ResolveTenant(tt.ctx, tt.informed)
+		if got != tt.want || err != tt.err {
+			t.Errorf("%s: got (%s, %v), want (%s, %v)", tt.name, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestTenantBypass(t *testing.T) {
+	bypass := WithTenantBypass(context.Background())
+
+	tests := []struct {
+		name	string
+		ctx		context.Context
+		want	bool
+	}{
+		{"without principal", context.Background(), false},
+		{"marked", bypass, true},
+		{"principal restricts the marked context", WithPrincipal(bypass, &model.Principal{Subject: "consumer", TenantID: "TENANT-1"}), false},
+	}
+
+	for _, tt := range tests {
+		if got := TenantBypass(tt.ctx); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package auth
+
+// About the roles of a tenant, each role has the permissions of the lower ones
+const (
+	RoleViewer		= "viewer"
+	RoleOperator	= "operator"
+	RoleAdmin		= "admin"
+)
+
+var roleLevel = map[string]int{	RoleViewer: 1,
+								RoleOperator: 2,
+								RoleAdmin: 3 }
+
+// About check if a role is valid
+func IsRole(role string) bool {
+	_, ok := roleLevel[role]
+	return ok
+}
+
+// About check if a role grants the required role
+func RoleGrants(role string, required string) bool {
+	return roleLevel[role] > 0 && roleLevel[role] >= roleLevel[required]
+}
+
+// About the highest valid role of a list ("" when none is valid)
+func HighestRole(roles ...string) string {
+	res := ""
+	for _, role := range roles {
+		if roleLevel[role] > roleLevel[res] {
+			res = role
+		}
+	}
+	return res
+}
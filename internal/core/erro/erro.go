@@ -2,6 +2,7 @@ package erro
 
 import (
 	"errors"
+	"strings"
 )
 
 var (
@@ -16,4 +17,37 @@ var (
 	ErrTransInvalid		= errors.New("transaction invalid")
 	ErrInvalidAmount	= errors.New("invalid amount for this transaction type")
 	ErrTimeout			= errors.New("timeout: context deadline exceeded")
-)
\ No newline at end of file
+	ErrDayCount			= errors.New("day count convention not supported")
+	ErrFeeMethod		= errors.New("fee method not supported")
+	ErrInsufficientFunds	= errors.New("insufficient funds")
+	ErrProduct			= errors.New("account product not found")
+	ErrCurrency			= errors.New("currency not allowed for the account product")
+	ErrAccountBlocked	= errors.New("account blocked")
+	ErrAccountNumber	= errors.New("account number invalid (check digit)")
+	ErrRateLimit		= errors.New("too many requests, rate limit exceeded")
+	ErrRateLimitStore	= errors.New("rate limit unavailable")
+	ErrMediaType		= errors.New("unsupported media type")
+	ErrSchemaBehind		= errors.New("database schema is behind the service, run the migrations")
+)
+type FieldError struct {
+	Field	string `json:"field"`
+	Rule	string `json:"rule"`
+	Message	string `json:"message"`
+}
+
+// About a payload validation error, it lists each failing field
+type ValidationError struct {
+	Fields	[]FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Field + " " + f.Message)
+	}
+	return ErrBadRequest.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrBadRequest
+}
@@ -0,0 +1,57 @@
+package event
+
+import (
+	"time"
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// About the CloudEvents spec version and the content types
+const (
+	SpecVersion			= "1.0"
+	ContentTypeJSON		= "application/json"
+	ContentTypeEvent	= "application/cloudevents+json"
+)
+
+// About a CloudEvents 1.0 envelope (structured mode), traceparent is the distributed tracing extension
+type CloudEvent struct {
+	SpecVersion		string			`json:"specversion"`
+	ID				string			`json:"id"`
+	Source			string			`json:"source"`
+	Type			string			`json:"type"`
+	Subject			string			`json:"subject,omitempty"`
+	Time			time.Time		`json:"time"`
+	DataContentType	string			`json:"datacontenttype"`
+	TraceParent		string			`json:"traceparent,omitempty"`
+	TraceState		string			`json:"tracestate,omitempty"`
+	TenantID		string			`json:"tenantid,omitempty"`
+	Data			json.RawMessage	`json:"data"`
+}
+
+// About create the envelope of a domain event, the trace context comes from the span of the ctx
+func NewCloudEvent(ctx context.Context, source string, eventType string, subject string, tenantID string, data interface{}) (*CloudEvent, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(ctx, carrier)
+
+	return &CloudEvent{
+		SpecVersion: SpecVersion,
+		ID: uuid.NewString(),
+		Source: source,
+		Type: eventType,
+		Subject: subject,
+		Time: time.Now().UTC(),
+		DataContentType: ContentTypeJSON,
+		TraceParent: carrier.Get("traceparent"),
+		TraceState: carrier.Get("tracestate"),
+		TenantID: tenantID,
+		Data: payload,
+	}, nil
+}
@@ -0,0 +1,109 @@
+package event
+
+import (
+	"os"
+	"sort"
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"encoding/json"
+
+	"github.com/go-account/internal/core/model"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// every event type must be in the registry with the fields of its data struct
+func TestRegistryCoversTypes(t *testing.T) {
+	types := map[string]interface{}{
+		TypeAccountCreated:		AccountCreatedV1{},
+		TypeAccountUpdated:		AccountUpdatedV1{},
+		TypeAccountClosed:		AccountClosedV1{},
+		TypeBalancePosted:		BalancePostedV1{},
+		TypeTransferCompleted:	TransferCompletedV1{},
+	}
+
+	file, err := os.ReadFile("registry.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := struct {
+		Events	map[string]struct {
+			Data	struct {
+				Required	[]string					`json:"required"`
+				Properties	map[string]json.RawMessage	`json:"properties"`
+			}	`json:"data"`
+		}	`json:"events"`
+	}{}
+	if err := json.Unmarshal(file, &registry); err != nil {
+		t.Fatal(err)
+	}
+	if len(registry.Events) != len(types) {
+		t.Errorf("registry has %d events, want %d", len(registry.Events), len(types))
+	}
+
+	for eventType, data := range types {
+		entry, ok := registry.Events[eventType]
+		if !ok {
+			t.Errorf("%s missing in the registry", eventType)
+			continue
+		}
+		properties, required := []string{}, []string{}
+		dataType := reflect.TypeOf(data)
+		for i := 0; i < dataType.NumField(); i++ {
+			tag := strings.Split(dataType.Field(i).Tag.Get("json"), ",")
+			properties = append(properties, tag[0])
+			if len(tag) == 1 {
+				required = append(required, tag[0])
+			}
+		}
+		registered := []string{}
+		for name := range entry.Data.Properties {
+			registered = append(registered, name)
+		}
+		sort.Strings(properties)
+		sort.Strings(registered)
+		sort.Strings(required)
+		sort.Strings(entry.Data.Required)
+		if !reflect.DeepEqual(properties, registered) {
+			t.Errorf("%s properties %v, registry %v", eventType, properties, registered)
+		}
+		if !reflect.DeepEqual(required, entry.Data.Required) {
+			t.Errorf("%s required %v, registry %v", eventType, required, entry.Data.Required)
+		}
+	}
+}
+
+func TestNewCloudEvent(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID: spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	account := model.Account{AccountID: "ACC-1", PersonID: "P-1", TenantID: "TENANT-1"}
+	cloudEvent, err := NewCloudEvent(ctx, "go-account-pod-1", TypeAccountCreated, account.AccountID, account.TenantID, NewAccountCreated(&account))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cloudEvent.SpecVersion != "1.0" || cloudEvent.ID == "" || cloudEvent.Source != "go-account-pod-1" || cloudEvent.Subject != "ACC-1" {
+		t.Errorf("unexpected envelope %+v", cloudEvent)
+	}
+	if cloudEvent.TraceParent != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("traceparent %q", cloudEvent.TraceParent)
+	}
+
+	data := AccountCreatedV1{}
+	if err := json.Unmarshal(cloudEvent.Data, &data); err != nil || data.PersonID != "P-1" {
+		t.Errorf("unexpected data %s", cloudEvent.Data)
+	}
+
+	other, _ := NewCloudEvent(context.Background(), "go-account-pod-1", TypeAccountCreated, "ACC-1", "", nil)
+	if other.ID == cloudEvent.ID || other.TraceParent != "" {
+		t.Errorf("the id must be unique and the traceparent empty without a span")
+	}
+}
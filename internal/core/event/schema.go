@@ -0,0 +1,118 @@
+package event
+
+import (
+	"time"
+
+	"github.com/go-account/internal/core/model"
+)
+
+// About the types of the domain events, the last segment is the version of the data schema
+// (a breaking change of the data is a new type, see registry.json)
+const (
+	TypeAccountCreated		= "go-account.account.created.v1"
+	TypeAccountUpdated		= "go-account.account.updated.v1"
+	TypeAccountClosed		= "go-account.account.closed.v1"
+	TypeBalancePosted		= "go-account.balance.posted.v1"
+	TypeTransferCompleted	= "go-account.transfer.completed.v1"
+)
+
+type AccountCreatedV1 struct {
+	AccountID		string		`json:"account_id"`
+	PersonID		string		`json:"person_id"`
+	ProductID		string		`json:"product_id,omitempty"`
+	TenantID		string		`json:"tenant_id"`
+	CreatedAt		time.Time	`json:"created_at"`
+	UserLastUpdate	*string		`json:"user_last_update,omitempty"`
+}
+
+type AccountUpdatedV1 struct {
+	AccountID		string		`json:"account_id"`
+	PersonID		string		`json:"person_id"`
+	ProductID		string		`json:"product_id,omitempty"`
+	Status			string		`json:"status,omitempty"`
+	TenantID		string		`json:"tenant_id"`
+	UpdatedAt		*time.Time	`json:"updated_at,omitempty"`
+	UserLastUpdate	*string		`json:"user_last_update,omitempty"`
+}
+
+type AccountClosedV1 struct {
+	AccountID		string		`json:"account_id"`
+	PersonID		string		`json:"person_id"`
+	TenantID		string		`json:"tenant_id"`
+	ClosedAt		time.Time	`json:"closed_at"`
+}
+
+type BalancePostedV1 struct {
+	AccountID		string		`json:"account_id"`
+	TenantID		string		`json:"tenant_id"`
+	Type			string		`json:"type_charge"`
+	Currency		string		`json:"currency"`
+	Amount			float64		`json:"amount"`
+	Balance			*float64	`json:"balance,omitempty"`
+	TransactionID	*string		`json:"transaction_id,omitempty"`
+	ChargedAt		time.Time	`json:"charged_at"`
+}
+
+type TransferCompletedV1 struct {
+	TransferID		int			`json:"transfer_id"`
+	AccountFrom		string		`json:"account_from"`
+	AccountTo		string		`json:"account_to"`
+	TenantID		string		`json:"tenant_id"`
+	Currency		string		`json:"currency"`
+	Amount			float64		`json:"amount"`
+	Status			string		`json:"status"`
+	TransferAt		time.Time	`json:"transfer_at"`
+}
+
+func NewAccountCreated(account *model.Account) AccountCreatedV1 {
+	return AccountCreatedV1{	AccountID: account.AccountID,
+								PersonID: account.PersonID,
+								ProductID: account.ProductID,
+								TenantID: account.TenantID,
+								CreatedAt: account.CreatedAt,
+								UserLastUpdate: account.UserLastUpdate }
+}
+
+func NewAccountUpdated(account *model.Account) AccountUpdatedV1 {
+	return AccountUpdatedV1{	AccountID: account.AccountID,
+								PersonID: account.PersonID,
+								ProductID: account.ProductID,
+								Status: account.Status,
+								TenantID: account.TenantID,
+								UpdatedAt: account.UpdatedAt,
+								UserLastUpdate: account.UserLastUpdate }
+}
+
+func NewAccountClosed(account *model.Account, closedAt time.Time) AccountClosedV1 {
+	return AccountClosedV1{	AccountID: account.AccountID,
+							PersonID: account.PersonID,
+							TenantID: account.TenantID,
+							ClosedAt: closedAt }
+}
+
+// About a statement posted into a balance, the balance is nil when the resulting amount is not known
+func NewBalancePosted(accountStatement *model.AccountStatement, accountBalance *model.AccountBalance) BalancePostedV1 {
+	balancePosted := BalancePostedV1{	AccountID: accountStatement.AccountID,
+										TenantID: accountStatement.TenantID,
+										Type: accountStatement.Type,
+										Currency: accountStatement.Currency,
+										Amount: accountStatement.Amount,
+										TransactionID: accountStatement.TransactionID,
+										ChargedAt: accountStatement.ChargedAt }
+	if accountBalance != nil {
+		balance := accountBalance.Amount
+		balancePosted.Balance = &balance
+	}
+	return balancePosted
+}
+
+func NewTransferCompleted(transfer *model.Transfer) TransferCompletedV1 {
+	return TransferCompletedV1{	TransferID: transfer.ID,
+								AccountFrom: transfer.AccountFrom.AccountID,
+								AccountTo: transfer.AccountTo.AccountID,
+								TenantID: transfer.AccountFrom.TenantID,
+								Currency: transfer.Currency,
+								Amount: transfer.Amount,
+								Status: transfer.Status,
+								TransferAt: transfer.TransferAt }
+}
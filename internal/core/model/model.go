@@ -2,6 +2,7 @@ package model
 
 import (
 	"time"
+	"encoding/json"
 	go_core_pg "github.com/eliezerraj/go-core/database/pg"
 	go_core_observ "github.com/eliezerraj/go-core/observability" 
 )
@@ -11,6 +12,17 @@ type AppServer struct {
 	Server     		*Server     				`json:"server"`
 	ConfigOTEL		*go_core_observ.ConfigOTEL	`json:"otel_config"`
 	DatabaseConfig	*go_core_pg.DatabaseConfig  `json:"database"`		
+	InterestJob		*InterestJob				`json:"interest_job"`
+	FeeJob			*FeeJob						`json:"fee_job"`
+	SecurityConfig	*SecurityConfig				`json:"security"`
+	TlsConfig		*TlsConfig					`json:"tls"`
+	RateLimitConfig	*RateLimitConfig			`json:"rate_limit"`
+	GraphQLConfig	*GraphQLConfig				`json:"graphql"`
+	OutboxConfig	*OutboxConfig				`json:"outbox"`
+	WebhookConfig	*WebhookConfig				`json:"webhook"`
+	ConsumerConfig	*ConsumerConfig				`json:"consumer"`
+	EventStoreConfig	*EventStoreConfig			`json:"event_store"`
+	MigrationConfig	*MigrationConfig			`json:"migration"`
 }
 
 type InfoPod struct {
@@ -30,6 +42,112 @@ type Server struct {
 	WriteTimeout	int `json:"writeTimeout"`
 	IdleTimeout		int `json:"idleTimeout"`
 	CtxTimeout		int `json:"ctxTimeout"`
+	GrpcPort		int `json:"grpcPort"`
+}
+
+type InterestJob struct {
+	Interval		int `json:"interval"`
+}
+
+type FeeJob struct {
+	Interval		int `json:"interval"`
+}
+
+type SecurityConfig struct {
+	AuthDisabled	bool	`json:"auth_disabled"`
+	JwksUrl			string	`json:"jwks_url,omitempty"`
+	JwksFile		string	`json:"jwks_file,omitempty"`
+	JwksRefresh		int		`json:"jwks_refresh"`
+	Issuer			string	`json:"issuer,omitempty"`
+	Audience		string	`json:"audience,omitempty"`
+	DefaultRole		string		`json:"default_role,omitempty"`
+	RoleCacheTTL	int			`json:"role_cache_ttl"`
+	CertScopes		[]string	`json:"cert_scopes,omitempty"`
+}
+
+type TlsConfig struct {
+	Enabled			bool	`json:"enabled"`
+	CertFile		string	`json:"cert_file,omitempty"`
+	KeyFile			string	`json:"key_file,omitempty"`
+	CaFile			string	`json:"ca_file,omitempty"`
+	ClientAuth		string	`json:"client_auth,omitempty"`
+}
+
+type RateLimitConfig struct {
+	Enabled			bool	`json:"enabled"`
+	ReadRate		float64	`json:"read_rate"`
+	ReadBurst		int		`json:"read_burst"`
+	WriteRate		float64	`json:"write_rate"`
+	WriteBurst		int		`json:"write_burst"`
+	Store			string	`json:"store,omitempty"`
+	FailOpen		bool	`json:"fail_open"`
+}
+
+type GraphQLConfig struct {
+	MaxDepth		int		`json:"max_depth"`
+	MaxCost			int		`json:"max_cost"`
+}
+
+type OutboxConfig struct {
+	RelayInterval	int			`json:"relay_interval"`
+	BatchSize		int			`json:"batch_size"`
+	MaxAttempts		int			`json:"max_attempts"`
+	ClaimLease		int			`json:"claim_lease"`
+	Publisher		string		`json:"publisher,omitempty"`
+	KafkaBrokers	[]string	`json:"kafka_brokers,omitempty"`
+	KafkaTopic		string		`json:"kafka_topic,omitempty"`
+	FilePath		string		`json:"file_path,omitempty"`
+}
+
+type WebhookConfig struct {
+	DispatchInterval	int		`json:"dispatch_interval"`
+	BatchSize			int		`json:"batch_size"`
+	MaxAttempts			int		`json:"max_attempts"`
+	BackoffBase			int		`json:"backoff_base"`
+	BackoffMax			int		`json:"backoff_max"`
+	Timeout				int		`json:"timeout"`
+	AllowHttp			bool	`json:"allow_http"`
+}
+
+type ConsumerConfig struct {
+	Consumer		string		`json:"consumer"`
+	Name			string		`json:"name"`
+	KafkaBrokers	[]string	`json:"kafka_brokers"`
+	KafkaTopic		string		`json:"kafka_topic"`
+	KafkaGroupID	string		`json:"kafka_group_id"`
+	FilePath		string		`json:"file_path"`
+	RetryInterval	int			`json:"retry_interval"`
+	MaxAttempts		int			`json:"max_attempts"`
+}
+
+type EventStoreConfig struct {
+	Mode				string	`json:"mode"`
+	SnapshotInterval	int		`json:"snapshot_interval"`
+}
+
+type MigrationConfig struct {
+	MigrateOnStartup	bool	`json:"migrate_on_startup"`
+	SchemaCheck			bool	`json:"schema_check"`
+}
+
+type ClientCertificate struct {
+	Subject			string		`json:"subject,omitempty"`
+	CommonName		string		`json:"common_name,omitempty"`
+	Organization	[]string	`json:"organization,omitempty"`
+	Issuer			string		`json:"issuer,omitempty"`
+	SerialNumber	string		`json:"serial_number,omitempty"`
+	DNSNames		[]string	`json:"dns_names,omitempty"`
+	NotAfter		time.Time	`json:"not_after,omitempty"`
+}
+
+type Principal struct {
+	Subject			string		`json:"subject,omitempty"`
+	TenantID		string		`json:"tenant_id,omitempty"`
+	ClientID		string		`json:"client_id,omitempty"`
+	Scopes			[]string	`json:"scopes,omitempty"`
+	Roles			[]string	`json:"roles,omitempty"`
+	Method			string		`json:"method,omitempty"`
+	TokenID			string		`json:"token_id,omitempty"`
 }
 
 type MessageRouter struct {
@@ -38,26 +156,61 @@ type MessageRouter struct {
 
 type Account struct {
 	ID				int			`json:"id,omitempty"`
-	AccountID		string		`json:"account_id,omitempty"`
-	PersonID		string  	`json:"person_id,omitempty"`
+	AccountID		string		`json:"account_id,omitempty" validate:"omitempty,max=50,account"`
+	PersonID		string  	`json:"person_id,omitempty" validate:"required,max=50,id"`
 	UserLastUpdate	*string  	`json:"user_last_update,omitempty"`
 	CreatedAt		time.Time 	`json:"created_at,omitempty"`
 	UpdatedAt		*time.Time 	`json:"updated_at,omitempty"`
-	TenantID		string  	`json:"tenant_id,omitempty"`
+	TenantID		string  	`json:"tenant_id,omitempty" validate:"required,max=50,id"`
+	ProductID		string  	`json:"product_id,omitempty" validate:"omitempty,max=50,id"`
+	Status			string		`json:"status,omitempty"`
+}
+
+// About the fields of an account that can be changed by a merge patch (nil means not informed)
+type AccountPatch struct {
+	AccountID		string		`json:"account_id,omitempty"`
+	TenantID		string		`json:"tenant_id,omitempty" validate:"omitempty,max=50,id"`
+	PersonID		*string		`json:"person_id,omitempty" validate:"omitempty,max=50,id"`
+	Status			*string		`json:"status,omitempty" validate:"omitempty,oneof=ACTIVE BLOCKED"`
+	UserLastUpdate	*string		`json:"-"`
+}
+
+type AccountProduct struct {
+	ID				int				`json:"id,omitempty"`
+	ProductID		string  		`json:"product_id,omitempty" validate:"required,max=50,id"`
+	Type			string  		`json:"type_product,omitempty" validate:"required,oneof=CHECKING SAVINGS WALLET"`
+	Description		string  		`json:"description,omitempty" validate:"omitempty,max=200"`
+	Currencies		[]string  		`json:"currencies,omitempty" validate:"required,min=1,currency"`
+	Overdraft		float64 		`json:"overdraft" validate:"min=0"`
+	InterestRate	*InterestRate	`json:"interest_rate,omitempty"`
+	FeeSchedule		*[]FeeSchedule	`json:"fee_schedule,omitempty"`
+	TenantID		string  		`json:"tenant_id,omitempty" validate:"required,max=50,id"`
+	CreatedAt		time.Time 		`json:"created_at,omitempty"`
+	UpdatedAt		*time.Time 		`json:"updated_at,omitempty"`
 }
 
 type AccountStatement struct {
 	ID				int			`json:"id,omitempty"`
 	FkAccountID		int			`json:"fk_account_id,omitempty"`
-	AccountID		string		`json:"account_id,omitempty"`
+	AccountID		string		`json:"account_id,omitempty" validate:"required,max=50,id"`
 	PersonID		string  	`json:"person_id,omitempty"`
-	Type			string  	`json:"type_charge,omitempty"`
+	Type			string  	`json:"type_charge,omitempty" validate:"required,oneof=CREDIT DEBIT"`
 	ChargedAt		time.Time 	`json:"charged_at,omitempty"`
-	Currency		string  	`json:"currency,omitempty"`
-	Amount			float64 	`json:"amount,omitempty"`
+	Currency		string  	`json:"currency,omitempty" validate:"required,currency"`
+	Amount			float64 	`json:"amount,omitempty" validate:"required,ne=0"`
 	TenantID		string  	`json:"tenant_id,omitempty"`
-	TransactionID	*string  	`json:"transaction_id,omitempty"`
-	Obs				string  	`json:"obs,omitempty"`
+	TransactionID	*string  	`json:"transaction_id,omitempty" validate:"omitempty,max=100"`
+	Obs				string  	`json:"obs,omitempty" validate:"omitempty,max=200"`
+}
+
+type Reconciliation struct {
+	AccountID		string		`json:"account_id,omitempty"`
+	Currency		string  	`json:"currency,omitempty"`
+	Balance			float64 	`json:"balance"`
+	StatementTotal	float64 	`json:"statement_total"`
+	Statements		int			`json:"statements"`
+	Difference		float64 	`json:"difference"`
+	Reconciled		bool		`json:"reconciled"`
 }
 
 type MovimentAccount struct {
@@ -72,8 +225,8 @@ type Transfer struct {
 	ID				int			`json:"id,omitempty"`
 	AccountFrom		AccountBalance	`json:"account_from,omitempty"`
 	AccountTo		AccountBalance	`json:"account_to,omitempty"`
-	Currency		string  	`json:"currency,omitempty"`
-	Amount			float64 	`json:"amount,omitempty"`
+	Currency		string  	`json:"currency,omitempty" validate:"required,currency"`
+	Amount			float64 	`json:"amount,omitempty" validate:"required,gt=0"`
 	TransferAt		time.Time 	`json:"transfer_at,omitempty"`
 	Type			string  	`json:"type_charge,omitempty"`
 	Status			string  	`json:"status,omitempty"`
@@ -81,7 +234,7 @@ type Transfer struct {
 
 type AccountBalance struct {
 	ID				int			`json:"id,omitempty"`
-	AccountID		string		`json:"account_id,omitempty"`
+	AccountID		string		`json:"account_id,omitempty" validate:"required,max=50,id"`
 	FkAccountID		int			`json:"fk_account_id,omitempty"`
 	Currency		string  	`json:"currency,omitempty"`
 	Amount			float64 	`json:"amount"`
@@ -92,4 +245,220 @@ type AccountBalance struct {
 	TenantID		string  	`json:"tenant_id,omitempty"`
 	CreatedAt		time.Time 	`json:"created_at,omitempty"`
 	UpdatedAt		*time.Time 	`json:"updated_at,omitempty"`
-}
\ No newline at end of file
+}
+type InterestRate struct {
+	ID				int			`json:"id,omitempty"`
+	ProductID		string		`json:"product_id,omitempty"`
+	Rate			float64 	`json:"rate"`
+	DayCount		string  	`json:"day_count,omitempty"`
+	TenantID		string  	`json:"tenant_id,omitempty"`
+}
+
+type InterestAccrual struct {
+	ID					int			`json:"id,omitempty"`
+	FkAccountBalanceID	int			`json:"fk_account_balance_id,omitempty"`
+	FkAccountID			int			`json:"fk_account_id,omitempty"`
+	AccountID			string		`json:"account_id,omitempty"`
+	Currency			string  	`json:"currency,omitempty"`
+	AccrualDate			time.Time 	`json:"accrual_date,omitempty"`
+	Balance				float64 	`json:"balance"`
+	Rate				float64 	`json:"rate"`
+	DayCount			string  	`json:"day_count,omitempty"`
+	Amount				float64 	`json:"amount"`
+	CapitalizedAt		*time.Time 	`json:"capitalized_at,omitempty"`
+	TenantID			string  	`json:"tenant_id,omitempty"`
+}
+
+type InterestRun struct {
+	AccrualDate		time.Time 	`json:"accrual_date,omitempty"`
+	Balances		int			`json:"balances"`
+	Accrued			int			`json:"accrued"`
+	Capitalized		int			`json:"capitalized"`
+	AmountPosted	float64 	`json:"amount_posted"`
+}
+
+type FeeTier struct {
+	UpTo			float64 	`json:"up_to"`
+	Amount			float64 	`json:"amount"`
+	Percentage		float64 	`json:"percentage"`
+}
+
+type FeeSchedule struct {
+	ID				int			`json:"id,omitempty"`
+	TenantID		string  	`json:"tenant_id,omitempty"`
+	ProductID		string  	`json:"product_id,omitempty"`
+	Operation		string  	`json:"operation,omitempty"`
+	Method			string  	`json:"method,omitempty"`
+	Currency		string  	`json:"currency,omitempty"`
+	Amount			float64 	`json:"amount"`
+	Percentage		float64 	`json:"percentage"`
+	MinFee			float64 	`json:"min_fee"`
+	MaxFee			float64 	`json:"max_fee"`
+	Tiers			[]FeeTier	`json:"tiers,omitempty"`
+}
+
+type MaintenanceBalance struct {
+	FkAccountBalanceID	int			`json:"fk_account_balance_id,omitempty"`
+	FkAccountID			int			`json:"fk_account_id,omitempty"`
+	AccountID			string  	`json:"account_id,omitempty"`
+	ProductID			string  	`json:"product_id,omitempty"`
+	Currency			string  	`json:"currency,omitempty"`
+	Balance				float64 	`json:"balance"`
+	TenantID			string  	`json:"tenant_id,omitempty"`
+}
+
+type MaintenanceRun struct {
+	Period			time.Time 	`json:"period,omitempty"`
+	Balances		int			`json:"balances"`
+	Charged			int			`json:"charged"`
+	Skipped			int			`json:"skipped"`
+	AmountCharged	float64 	`json:"amount_charged"`
+}
+
+type FeePreview struct {
+	TenantID		string  		`json:"tenant_id,omitempty" validate:"required,max=50,id"`
+	ProductID		string  		`json:"product_id,omitempty" validate:"omitempty,max=50,id"`
+	Operation		string  		`json:"operation,omitempty" validate:"required,oneof=POSTING TRANSFER MAINTENANCE"`
+	Currency		string  		`json:"currency,omitempty" validate:"required,currency"`
+	Amount			float64 		`json:"amount" validate:"required,ne=0"`
+	Fee				float64 		`json:"fee"`
+	FeeSchedule		*FeeSchedule	`json:"fee_schedule,omitempty"`
+}
+
+type AccountNumberFormat struct {
+	ID				int			`json:"id,omitempty"`
+	TenantID		string  	`json:"tenant_id,omitempty" validate:"required,max=50,id"`
+	Prefix			string  	`json:"prefix,omitempty" validate:"omitempty,max=10,alphanum"`
+	SequenceLength	int			`json:"sequence_length" validate:"required,min=1,max=18"`
+	CheckDigit		string  	`json:"check_digit,omitempty" validate:"omitempty,oneof=NONE LUHN MOD97"`
+	Iban			bool		`json:"iban"`
+	CountryCode		string  	`json:"country_code,omitempty" validate:"omitempty,len=2,alpha"`
+	LastSequence	int64		`json:"last_sequence"`
+	CreatedAt		time.Time 	`json:"created_at,omitempty"`
+	UpdatedAt		*time.Time 	`json:"updated_at,omitempty"`
+}
+
+type TenantRole struct {
+	ID				int			`json:"id,omitempty"`
+	TenantID		string  	`json:"tenant_id,omitempty" validate:"required,max=50,id"`
+	Subject			string  	`json:"subject,omitempty" validate:"required,max=100"`
+	Role			string  	`json:"role,omitempty" validate:"required,oneof=viewer operator admin"`
+	UserLastUpdate	*string  	`json:"user_last_update,omitempty"`
+	CreatedAt		time.Time 	`json:"created_at,omitempty"`
+	UpdatedAt		*time.Time 	`json:"updated_at,omitempty"`
+}
+
+type ApiKey struct {
+	ID				int			`json:"id,omitempty"`
+	KeyID			string  	`json:"key_id,omitempty"`
+	TenantID		string  	`json:"tenant_id,omitempty" validate:"required,max=50,id"`
+	Name			string  	`json:"name,omitempty" validate:"required,max=100"`
+	Scopes			[]string	`json:"scopes,omitempty" validate:"required,oneof=account:read account:write account:admin"`
+	Role			string  	`json:"role,omitempty" validate:"omitempty,oneof=viewer operator admin"`
+	Key				string  	`json:"key,omitempty"`
+	KeyHash			string  	`json:"-"`
+	Status			string  	`json:"status,omitempty"`
+	ExpiresAt		*time.Time 	`json:"expires_at,omitempty"`
+	LastUsedAt		*time.Time 	`json:"last_used_at,omitempty"`
+	UserLastUpdate	*string  	`json:"user_last_update,omitempty"`
+	CreatedAt		time.Time 	`json:"created_at,omitempty"`
+	UpdatedAt		*time.Time 	`json:"updated_at,omitempty"`
+}
+
+type OutboxEvent struct {
+	ID				int64		`json:"id,omitempty"`
+	AggregateID		string		`json:"aggregate_id,omitempty"`
+	TenantID		string		`json:"tenant_id,omitempty"`
+	EventType		string		`json:"event_type,omitempty"`
+	Payload			json.RawMessage	`json:"payload,omitempty"`
+	CreatedAt		time.Time	`json:"created_at,omitempty"`
+	PublishedAt		*time.Time	`json:"published_at,omitempty"`
+	Attempts		int			`json:"attempts,omitempty"`
+	LastError		*string		`json:"last_error,omitempty"`
+	ClaimedUntil	*time.Time	`json:"claimed_until,omitempty"`
+	ParkedAt		*time.Time	`json:"parked_at,omitempty"`
+}
+
+type WebhookSubscription struct {
+	ID				int			`json:"id,omitempty"`
+	SubscriptionID	string		`json:"subscription_id,omitempty"`
+	TenantID		string		`json:"tenant_id,omitempty" validate:"required,max=50,id"`
+	URL				string		`json:"url,omitempty" validate:"required,max=500,url"`
+	EventTypes		[]string	`json:"event_types,omitempty" validate:"omitempty,oneof=go-account.account.created.v1 go-account.account.updated.v1 go-account.account.closed.v1 go-account.balance.posted.v1 go-account.transfer.completed.v1"`
+	Secret			string		`json:"secret,omitempty"`
+	Status			string		`json:"status,omitempty"`
+	UserLastUpdate	*string		`json:"user_last_update,omitempty"`
+	CreatedAt		time.Time	`json:"created_at,omitempty"`
+	UpdatedAt		*time.Time	`json:"updated_at,omitempty"`
+}
+
+type WebhookDelivery struct {
+	ID				int64		`json:"id,omitempty"`
+	SubscriptionID	string		`json:"subscription_id,omitempty"`
+	OutboxEventID	int64		`json:"outbox_event_id,omitempty"`
+	EventType		string		`json:"event_type,omitempty"`
+	Payload			json.RawMessage	`json:"payload,omitempty"`
+	Status			string		`json:"status,omitempty"`
+	Attempts		int			`json:"attempts"`
+	NextAttemptAt	time.Time	`json:"next_attempt_at,omitempty"`
+	LastStatusCode	*int		`json:"last_status_code,omitempty"`
+	LastError		*string		`json:"last_error,omitempty"`
+	CreatedAt		time.Time	`json:"created_at,omitempty"`
+	DeliveredAt		*time.Time	`json:"delivered_at,omitempty"`
+	URL				string		`json:"-"`
+	Secret			string		`json:"-"`
+	AttemptList		*[]WebhookDeliveryAttempt	`json:"attempt_list,omitempty"`
+}
+
+type WebhookDeliveryAttempt struct {
+	ID				int64		`json:"id,omitempty"`
+	FkDeliveryID	int64		`json:"fk_delivery_id,omitempty"`
+	Attempt			int			`json:"attempt"`
+	AttemptedAt		time.Time	`json:"attempted_at"`
+	StatusCode		*int		`json:"status_code,omitempty"`
+	Error			*string		`json:"error,omitempty"`
+	DurationMs		int64		`json:"duration_ms"`
+}
+
+type InboundMessage struct {
+	Topic			string		`json:"topic,omitempty"`
+	Partition		int			`json:"partition"`
+	Offset			int64		`json:"offset"`
+	Key				string		`json:"key"`
+	Value			json.RawMessage	`json:"value"`
+}
+
+type ConsumedMessage struct {
+	Consumer		string		`json:"consumer"`
+	MessageKey		string		`json:"message_key"`
+	Topic			string		`json:"topic,omitempty"`
+	Partition		int			`json:"partition"`
+	Offset			int64		`json:"offset"`
+	Status			string		`json:"status"`
+	Error			*string		`json:"error,omitempty"`
+	CreatedAt		time.Time	`json:"created_at,omitempty"`
+}
+
+type StreamEvent struct {
+	ID				int64		`json:"id,omitempty"`
+	AggregateID		string		`json:"aggregate_id"`
+	TenantID		string		`json:"tenant_id"`
+	Version			int			`json:"version"`
+	EventType		string		`json:"event_type"`
+	Data			json.RawMessage	`json:"data"`
+	CreatedAt		time.Time	`json:"created_at,omitempty"`
+}
+
+type AccountSnapshot struct {
+	AggregateID		string		`json:"aggregate_id"`
+	TenantID		string		`json:"tenant_id"`
+	Version			int			`json:"version"`
+	State			json.RawMessage	`json:"state"`
+	CreatedAt		time.Time	`json:"created_at,omitempty"`
+}
+
+type SchemaMigration struct {
+	Version			int			`json:"version"`
+	Name			string		`json:"name"`
+	AppliedAt		*time.Time	`json:"applied_at,omitempty"`
+}
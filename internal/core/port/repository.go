@@ -0,0 +1,120 @@
+package port
+
+import (
+	"time"
+	"context"
+
+	"github.com/go-account/internal/core/model"
+
+	go_core_pg "github.com/eliezerraj/go-core/database/pg"
+)
+
+// About a transaction of the unit of work, it ends with Commit or Rollback (both release it)
+type Tx interface {
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+// About the unit of work, the writes that receive the same Tx are committed or rolled back together
+type UnitOfWork interface {
+	StartTx(ctx context.Context) (Tx, error)
+	Stat(ctx context.Context) (go_core_pg.PoolStats)
+}
+
+// About the persistence of the account and its balances
+type AccountRepository interface {
+	AddAccount(ctx context.Context, tx Tx, account *model.Account) (*model.Account, error)
+	GetAccount(ctx context.Context, account *model.Account) (*model.Account, error)
+	GetAccountForUpdate(ctx context.Context, tx Tx, account *model.Account) (*model.Account, error)
+	GetAccountId(ctx context.Context, account *model.Account) (*model.Account, error)
+	ListAccountPerPerson(ctx context.Context, account *model.Account) (*[]model.Account, error)
+	PatchAccount(ctx context.Context, tx Tx, accountPatch *model.AccountPatch) (*model.Account, error)
+	DeleteAccount(ctx context.Context, tx Tx, account *model.Account) (bool, error)
+	AddAccountBalance(ctx context.Context, tx Tx, accountBalance *model.AccountBalance) (*model.AccountBalance, error)
+	ListAccountBalance(ctx context.Context, account *model.Account) (*[]model.AccountBalance, error)
+	GetAccountBalanceForUpdate(ctx context.Context, tx Tx, accountBalance *model.AccountBalance) (*model.AccountBalance, error)
+	UpdateAccountBalanceAmount(ctx context.Context, tx Tx, accountBalance *model.AccountBalance) (int64, error)
+}
+
+// About the persistence of the postings, transfers, interest accruals and maintenance fees
+type PostingRepository interface {
+	AddAccountStatement(ctx context.Context, tx Tx, accountStatement *model.AccountStatement) (*model.AccountStatement, error)
+	AddAccountStatementOnce(ctx context.Context, tx Tx, accountStatement *model.AccountStatement) (bool, error)
+	AddTransfer(ctx context.Context, tx Tx, transfer *model.Transfer) (*model.Transfer, error)
+	ListInterestBearingBalance(ctx context.Context) (*[]model.InterestAccrual, error)
+	AddInterestAccrual(ctx context.Context, tx Tx, interestAccrual *model.InterestAccrual) (bool, error)
+	CapitalizeInterestAccrual(ctx context.Context, tx Tx, interestAccrual *model.InterestAccrual, until time.Time) ([]float64, error)
+	ListMaintenanceBalance(ctx context.Context) (*[]model.MaintenanceBalance, error)
+	ListAccountBalanceByAccountIDs(ctx context.Context, accountIDs []string) (map[string][]model.AccountBalance, error)
+	ListAccountStatementByAccountIDs(ctx context.Context, accountIDs []string, last int) (map[string][]model.AccountStatement, error)
+	ListTransferByAccountIDs(ctx context.Context, accountIDs []string, last int) (map[string][]model.Transfer, error)
+	ListAccountStatement(ctx context.Context, account *model.Account, from time.Time, to time.Time) (*[]model.AccountStatement, error)
+	ListAccountStatementTotal(ctx context.Context, account *model.Account) (*[]model.Reconciliation, error)
+}
+
+// About the persistence of the account products, interest rates, fee schedules and account number formats
+type ProductRepository interface {
+	AddAccountProduct(ctx context.Context, tx Tx, accountProduct *model.AccountProduct) (*model.AccountProduct, error)
+	GetAccountProduct(ctx context.Context, accountProduct *model.AccountProduct) (*model.AccountProduct, error)
+	ListAccountProduct(ctx context.Context, accountProduct *model.AccountProduct) (*[]model.AccountProduct, error)
+	AddInterestRate(ctx context.Context, tx Tx, interestRate *model.InterestRate) (*model.InterestRate, error)
+	GetInterestRate(ctx context.Context, interestRate *model.InterestRate) (*model.InterestRate, error)
+	AddFeeSchedule(ctx context.Context, tx Tx, feeSchedule *model.FeeSchedule) (*model.FeeSchedule, error)
+	DeleteFeeScheduleProduct(ctx context.Context, tx Tx, feeSchedule *model.FeeSchedule) (int64, error)
+	GetFeeSchedule(ctx context.Context, feeSchedule *model.FeeSchedule) (*model.FeeSchedule, error)
+	ListFeeScheduleProduct(ctx context.Context, feeSchedule *model.FeeSchedule) (*[]model.FeeSchedule, error)
+	AddAccountNumberFormat(ctx context.Context, tx Tx, accountNumberFormat *model.AccountNumberFormat) (*model.AccountNumberFormat, error)
+	GetAccountNumberFormat(ctx context.Context, accountNumberFormat *model.AccountNumberFormat) (*model.AccountNumberFormat, error)
+	NextAccountNumberSequence(ctx context.Context, tx Tx, accountNumberFormat *model.AccountNumberFormat) (int64, error)
+}
+
+// About the persistence of the roles of the tenants and the api keys
+type AccessRepository interface {
+	AddTenantRole(ctx context.Context, tx Tx, tenantRole *model.TenantRole) (*model.TenantRole, error)
+	GetTenantRole(ctx context.Context, tenantRole *model.TenantRole) (*model.TenantRole, error)
+	ListTenantRole(ctx context.Context, tenantRole *model.TenantRole) (*[]model.TenantRole, error)
+	AddApiKey(ctx context.Context, tx Tx, apiKey *model.ApiKey) (*model.ApiKey, error)
+	GetApiKey(ctx context.Context, apiKey *model.ApiKey) (*model.ApiKey, error)
+	ListApiKey(ctx context.Context, apiKey *model.ApiKey) (*[]model.ApiKey, error)
+	UpdateApiKey(ctx context.Context, tx Tx, apiKey *model.ApiKey) (int64, error)
+	UpdateApiKeyLastUsed(ctx context.Context, apiKey *model.ApiKey) (int64, error)
+}
+
+// About the persistence of the outbox events and the consumed messages
+type MessageRepository interface {
+	AddOutboxEvent(ctx context.Context, tx Tx, outboxEvent *model.OutboxEvent) (*model.OutboxEvent, error)
+	ClaimOutboxEvent(ctx context.Context, limit int, lease time.Duration) (*[]model.OutboxEvent, error)
+	ListPendingOutboxEvent(ctx context.Context, tx Tx, limit int) (*[]model.OutboxEvent, error)
+	MarkOutboxEventPublished(ctx context.Context, tx Tx, ids []int64) (int64, error)
+	MarkOutboxEventFailed(ctx context.Context, tx Tx, id int64, lastError string, maxAttempts int) (int64, error)
+	ReleaseOutboxEvent(ctx context.Context, tx Tx, ids []int64) (int64, error)
+	AddConsumedMessage(ctx context.Context, tx Tx, consumedMessage *model.ConsumedMessage) (bool, error)
+}
+
+// About the persistence of the webhook subscriptions and deliveries
+type WebhookRepository interface {
+	AddWebhookSubscription(ctx context.Context, tx Tx, webhookSubscription *model.WebhookSubscription) (*model.WebhookSubscription, error)
+	GetWebhookSubscription(ctx context.Context, webhookSubscription *model.WebhookSubscription) (*model.WebhookSubscription, error)
+	ListWebhookSubscription(ctx context.Context, webhookSubscription *model.WebhookSubscription) (*[]model.WebhookSubscription, error)
+	UpdateWebhookSubscription(ctx context.Context, tx Tx, webhookSubscription *model.WebhookSubscription) (int64, error)
+	AddWebhookDelivery(ctx context.Context, tx Tx, outboxEvent *model.OutboxEvent) (int64, error)
+	ClaimWebhookDelivery(ctx context.Context, limit int, lease time.Duration) (*[]model.WebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, tx Tx, webhookDelivery *model.WebhookDelivery) (int64, error)
+	CancelWebhookDelivery(ctx context.Context, tx Tx, webhookSubscription *model.WebhookSubscription) (int64, error)
+	AddWebhookDeliveryAttempt(ctx context.Context, tx Tx, webhookDeliveryAttempt *model.WebhookDeliveryAttempt) (*model.WebhookDeliveryAttempt, error)
+	ListWebhookDelivery(ctx context.Context, webhookSubscription *model.WebhookSubscription, status string, limit int) (*[]model.WebhookDelivery, error)
+	ListWebhookDeliveryAttemptByDeliveryIDs(ctx context.Context, deliveryIDs []int64) (map[int64][]model.WebhookDeliveryAttempt, error)
+	RetryWebhookDelivery(ctx context.Context, tx Tx, webhookSubscription *model.WebhookSubscription, deliveryID int64) (int64, error)
+}
+
+// About the persistence used by the WorkerService, implemented by the state tables (database.WorkerRepository),
+// the event store of the account aggregate (database.EventStoreRepository) and in memory (memory.MemoryRepository)
+type Repository interface {
+	UnitOfWork
+	AccountRepository
+	PostingRepository
+	ProductRepository
+	AccessRepository
+	MessageRepository
+	WebhookRepository
+}
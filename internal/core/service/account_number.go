@@ -0,0 +1,224 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"context"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
+	"github.com/go-account/internal/core/erro"
+)
+
+const (
+	CheckDigitNone	= "NONE"
+	CheckDigitLuhn	= "LUHN"
+	CheckDigitMod97	= "MOD97"
+)
+
+// About the luhn check digit of the digits of a value (non digits are ignored)
+func LuhnDigit(value string) int {
+	sum := 0
+	double := true
+	for i := len(value) - 1; i >= 0; i-- {
+		c := value[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d = d * 2
+			if d > 9 {
+				d = d - 9
+			}
+		}
+		sum = sum + d
+		double = !double
+	}
+	return (10 - sum % 10) % 10
+}
+
+// About the remainder by 97 of a value, letters are converted as A=10 ... Z=35 (ISO 7064)
+func mod97(value string) (int, error) {
+	rem := 0
+	for _, c := range value {
+		switch {
+		case c >= '0' && c <= '9':
+			rem = (rem * 10 + int(c - '0')) % 97
+		case c >= 'A' && c <= 'Z':
+			n := int(c - 'A') + 10
+			rem = (rem * 100 + n) % 97
+		default:
+			return 0, erro.ErrAccountNumber
+		}
+	}
+	return rem, nil
+}
+
+// About the two check digits ISO 7064 MOD 97-10 of a value
+func Mod97CheckDigits(value string) (string, error) {
+	rem, err := mod97(value + "00")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%02d", 98 - rem), nil
+}
+
+// About the IBAN (electronic format) of a BBAN
+func IbanFromBban(countryCode string, bban string) (string, error) {
+	rem, err := mod97(bban + countryCode + "00")
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%02d%s", countryCode, 98 - rem, bban), nil
+}
+
+// About remove the spaces and upper case an account number, so the printed IBAN format is accepted
+func NormalizeAccountNumber(accountID string) string {
+	return strings.ToUpper(strings.ReplaceAll(accountID, " ", ""))
+}
+
+// About generate an account number as prefix + sequence + check digit (+ IBAN)
+func GenerateAccountNumber(accountNumberFormat *model.AccountNumberFormat, sequence int64) (string, error) {
+	body := fmt.Sprintf("%s%0*d", NormalizeAccountNumber(accountNumberFormat.Prefix), accountNumberFormat.SequenceLength, sequence)
+
+	switch accountNumberFormat.CheckDigit {
+	case CheckDigitLuhn:
+		body = fmt.Sprintf("%s%d", body, LuhnDigit(body))
+	case CheckDigitMod97:
+		digits, err := Mod97CheckDigits(body)
+		if err != nil {
+			return "", err
+		}
+		body = body + digits
+	case CheckDigitNone, "":
+	default:
+		return "", erro.ErrBadRequest
+	}
+
+	if accountNumberFormat.Iban {
+		return IbanFromBban(accountNumberFormat.CountryCode, body)
+	}
+	return body, nil
+}
+
+// About check the check digits of an account number against the tenant format
+func ValidateAccountNumber(accountNumberFormat *model.AccountNumberFormat, accountID string) error {
+	value := NormalizeAccountNumber(accountID)
+
+	if accountNumberFormat.Iban {
+		if len(value) < 5 || !strings.HasPrefix(value, accountNumberFormat.CountryCode) {
+			return erro.ErrAccountNumber
+		}
+		rem, err := mod97(value[4:] + value[:4])
+		if err != nil || rem != 1 {
+			return erro.ErrAccountNumber
+		}
+		value = value[4:]
+	}
+
+	if !strings.HasPrefix(value, NormalizeAccountNumber(accountNumberFormat.Prefix)) {
+		return erro.ErrAccountNumber
+	}
+
+	switch accountNumberFormat.CheckDigit {
+	case CheckDigitLuhn:
+		if len(value) < 2 || fmt.Sprintf("%d", LuhnDigit(value[:len(value)-1])) != value[len(value)-1:] {
+			return erro.ErrAccountNumber
+		}
+	case CheckDigitMod97:
+		rem, err := mod97(value)
+		if err != nil || rem != 1 {
+			return erro.ErrAccountNumber
+		}
+	}
+
+	return nil
+}
+
+// About add (or replace) the account number format of a tenant
+func (s *WorkerService) AddAccountNumberFormat(ctx context.Context, accountNumberFormat *model.AccountNumberFormat) (*model.AccountNumberFormat, error){
+	childLogger.Info().Str("func","AddAccountNumberFormat").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("accountNumberFormat", accountNumberFormat).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.AddAccountNumberFormat")
+
+	// check the format generating a number
+	if accountNumberFormat.TenantID == "" || accountNumberFormat.SequenceLength <= 0 || (accountNumberFormat.Iban && len(accountNumberFormat.CountryCode) != 2) {
+		span.End()
+		return nil, erro.ErrBadRequest
+	}
+	accountNumberFormat.CountryCode = strings.ToUpper(accountNumberFormat.CountryCode)
+	if _, err := GenerateAccountNumber(accountNumberFormat, 1); err != nil {
+		span.End()
+		return nil, erro.ErrBadRequest
+	}
+
+	// Get the database connection
+	tx, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			tx.Commit(ctx)
+		}
+		span.End()
+	}()
+
+	res, err := s.workerRepository.AddAccountNumberFormat(ctx, tx, accountNumberFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// About get the account number format of a tenant
+func (s *WorkerService) GetAccountNumberFormat(ctx context.Context, accountNumberFormat *model.AccountNumberFormat) (*model.AccountNumberFormat, error){
+	childLogger.Info().Str("func","GetAccountNumberFormat").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("accountNumberFormat", accountNumberFormat).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.GetAccountNumberFormat")
+	defer span.End()
+
+	res, err := s.workerRepository.GetAccountNumberFormat(ctx, accountNumberFormat)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About generate the account number when it is not informed, otherwise check it against the tenant format
+func (s *WorkerService) setAccountNumber(ctx context.Context, tx port.Tx, account *model.Account) error {
+	res_format, err := s.workerRepository.GetAccountNumberFormat(ctx, &model.AccountNumberFormat{TenantID: account.TenantID})
+	if err == erro.ErrNotFound {
+		if account.AccountID == "" {
+			return &erro.ValidationError{Fields: []erro.FieldError{{	Field: "account_id",
+																		Rule: "required",
+																		Message: "is required (the tenant has no account number format)" }}}
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if account.AccountID != "" {
+		account.AccountID = NormalizeAccountNumber(account.AccountID)
+		return ValidateAccountNumber(res_format, account.AccountID)
+	}
+
+	sequence, err := s.workerRepository.NextAccountNumberSequence(ctx, tx, res_format)
+	if err != nil {
+		return err
+	}
+	account.AccountID, err = GenerateAccountNumber(res_format, sequence)
+
+	return err
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/model"
+)
+
+func Test_LuhnDigit(t *testing.T){
+	if got := LuhnDigit("7992739871"); got != 3 {
+		t.Errorf("luhn 7992739871: got %v want 3", got)
+	}
+}
+
+func Test_IbanFromBban(t *testing.T){
+	// known valid IBAN GB82 WEST 1234 5698 7654 32
+	got, err := IbanFromBban("GB", "WEST12345698765432")
+	if err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if got != "GB82WEST12345698765432" {
+		t.Errorf("got %v want GB82WEST12345698765432", got)
+	}
+}
+
+func Test_AccountNumber(t *testing.T){
+	formats := []model.AccountNumberFormat{
+		{Prefix: "ACC", SequenceLength: 6, CheckDigit: CheckDigitLuhn},
+		{Prefix: "0001", SequenceLength: 8, CheckDigit: CheckDigitMod97},
+		{Prefix: "0001", SequenceLength: 8, CheckDigit: CheckDigitMod97, Iban: true, CountryCode: "BR"},
+		{Prefix: "9", SequenceLength: 4, CheckDigit: CheckDigitNone},
+	}
+
+	for _, f := range formats {
+		accountID, err := GenerateAccountNumber(&f, 42)
+		if err != nil {
+			t.Fatalf("%+v: unexpected error %v", f, err)
+		}
+		if err := ValidateAccountNumber(&f, accountID); err != nil {
+			t.Errorf("%+v: generated %v must be valid: %v", f, accountID, err)
+		}
+		if f.CheckDigit == CheckDigitNone {
+			continue
+		}
+		// a typo on the sequence must be rejected
+		typo := []byte(accountID)
+		i := len(typo) - 4
+		if typo[i] == '9' {
+			typo[i] = '8'
+		} else {
+			typo[i] = typo[i] + 1
+		}
+		if err := ValidateAccountNumber(&f, string(typo)); err == nil {
+			t.Errorf("%+v: typo %v must be rejected", f, string(typo))
+		}
+	}
+}
+
+func Test_AddAccountNumberFormat(t *testing.T){
+	tests := []struct {
+		name	string
+		format	model.AccountNumberFormat
+		err		error
+	}{
+		{"luhn", model.AccountNumberFormat{TenantID: testTenant, Prefix: "ACC", SequenceLength: 6, CheckDigit: CheckDigitLuhn}, nil},
+		{"iban", model.AccountNumberFormat{TenantID: testTenant, Prefix: "0001", SequenceLength: 8, CheckDigit: CheckDigitMod97, Iban: true, CountryCode: "br"}, nil},
+		{"iban without country", model.AccountNumberFormat{TenantID: testTenant, SequenceLength: 8, Iban: true}, erro.ErrBadRequest},
+		{"without sequence", model.AccountNumberFormat{TenantID: testTenant, Prefix: "ACC"}, erro.ErrBadRequest},
+		{"without tenant", model.AccountNumberFormat{Prefix: "ACC", SequenceLength: 6}, erro.ErrBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := bypassContext()
+			workerService, _ := newMemoryService(t)
+
+			_, err := workerService.AddAccountNumberFormat(ctx, &tt.format)
+			if err != tt.err {
+				t.Fatalf("err %v want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+
+			res_format, err := workerService.GetAccountNumberFormat(ctx, &model.AccountNumberFormat{TenantID: testTenant})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the accounts without number get the next sequence
+			for sequence := int64(1); sequence <= 2; sequence++ {
+				res, err := workerService.AddAccount(ctx, &model.Account{PersonID: "P-1", ProductID: "CHECKING", TenantID: testTenant})
+				if err != nil {
+					t.Fatal(err)
+				}
+				want, _ := GenerateAccountNumber(res_format, sequence)
+				if res.AccountID != want {
+					t.Errorf("account number %s want %s", res.AccountID, want)
+				}
+			}
+
+			// an informed number must match the format
+			want, _ := GenerateAccountNumber(res_format, 99)
+			if _, err := workerService.AddAccount(ctx, &model.Account{AccountID: want, PersonID: "P-1", ProductID: "CHECKING", TenantID: testTenant}); err != nil {
+				t.Errorf("informed number %s err %v", want, err)
+			}
+			if _, err := workerService.AddAccount(ctx, &model.Account{AccountID: want + "0", PersonID: "P-1", ProductID: "CHECKING", TenantID: testTenant}); err != erro.ErrAccountNumber {
+				t.Errorf("invalid number err %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"time"
+	"context"
+	"crypto/subtle"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/auth"
+)
+
+const (
+	ApiKeyActive	= "ACTIVE"
+	ApiKeyRevoked	= "REVOKED"
+)
+
+// About create an api key, the key is returned only in this response
+func (s *WorkerService) AddApiKey(ctx context.Context, apiKey *model.ApiKey) (*model.ApiKey, error){
+	childLogger.Info().Str("func","AddApiKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("tenant_id", apiKey.TenantID).Str("name", apiKey.Name).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.AddApiKey")
+
+	if apiKey.TenantID == "" || len(apiKey.Scopes) == 0 {
+		span.End()
+		return nil, erro.ErrBadRequest
+	}
+	// the caller can not create a key with more scopes than its own
+	if principal, ok := auth.PrincipalFromContext(ctx); ok && !auth.HasScopes(principal, apiKey.Scopes...) {
+		span.End()
+		return nil, erro.ErrHTTPForbiden
+	}
+
+	keyID, key, err := auth.GenerateApiKey()
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+	apiKey.KeyID = keyID
+	apiKey.KeyHash = auth.HashApiKey(key)
+	apiKey.Status = ApiKeyActive
+
+	// Get the database connection
+	tx, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			tx.Commit(ctx)
+		}
+		span.End()
+	}()
+
+	res, err := s.workerRepository.AddApiKey(ctx, tx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	res.Key = key
+
+	return res, nil
+}
+
+// About rotate (new key) or revoke an api key
+func (s *WorkerService) updateApiKey(ctx context.Context, apiKey *model.ApiKey, rotate bool) (*model.ApiKey, error){
+	// Trace
+	span := tracerProvider.Span(ctx, "service.updateApiKey")
+
+	// Get api key (check if exists)
+	res, err := s.workerRepository.GetApiKey(ctx, &model.ApiKey{KeyID: apiKey.KeyID})
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+	if apiKey.TenantID != "" && res.TenantID != apiKey.TenantID {
+		span.End()
+		return nil, erro.ErrNotFound
+	}
+	if res.Status == ApiKeyRevoked {
+		span.End()
+		return nil, erro.ErrTransInvalid
+	}
+
+	key := ""
+	if rotate {
+		key, err = auth.GenerateApiKeySecret(res.KeyID)
+		if err != nil {
+			span.End()
+			return nil, err
+		}
+		res.KeyHash = auth.HashApiKey(key)
+	} else {
+		res.Status = ApiKeyRevoked
+	}
+	res.UserLastUpdate = apiKey.UserLastUpdate
+
+	// Get the database connection
+	tx, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			tx.Commit(ctx)
+		}
+		span.End()
+	}()
+
+	res_update, err := s.workerRepository.UpdateApiKey(ctx, tx, res)
+	if err != nil {
+		return nil, err
+	}
+	if res_update == 0 {
+		err = erro.ErrUpdate
+		return nil, err
+	}
+	res.Key = key
+
+	return res, nil
+}
+
+// About rotate an api key, the old key stops working and the new one is returned only in this response
+func (s *WorkerService) RotateApiKey(ctx context.Context, apiKey *model.ApiKey) (*model.ApiKey, error){
+	childLogger.Info().Str("func","RotateApiKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("key_id", apiKey.KeyID).Send()
+
+	return s.updateApiKey(ctx, apiKey, true)
+}
+
+// About revoke an api key
+func (s *WorkerService) RevokeApiKey(ctx context.Context, apiKey *model.ApiKey) (*model.ApiKey, error){
+	childLogger.Info().Str("func","RevokeApiKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("key_id", apiKey.KeyID).Send()
+
+	return s.updateApiKey(ctx, apiKey, false)
+}
+
+// About list all api keys of a tenant (without the hashes)
+func (s *WorkerService) ListApiKey(ctx context.Context, apiKey *model.ApiKey) (*[]model.ApiKey, error){
+	childLogger.Info().Str("func","ListApiKey").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("tenant_id", apiKey.TenantID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListApiKey")
+	defer span.End()
+
+	res, err := s.workerRepository.ListApiKey(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About verify an api key (hash, status and expiration) and record its last use
+func (s *WorkerService) VerifyApiKey(ctx context.Context, key string) (*model.ApiKey, error){
+	// Trace
+	span := tracerProvider.Span(ctx, "service.VerifyApiKey")
+	defer span.End()
+
+	keyID, ok := auth.ParseApiKey(key)
+	if !ok {
+		return nil, erro.ErrUnauthorized
+	}
+
+	// the tenant is not known before the key is found, the lookup is done over all the tenants
+	res, err := s.workerRepository.GetApiKey(auth.WithTenantBypass(ctx), &model.ApiKey{KeyID: keyID})
+	if err == erro.ErrNotFound {
+		return nil, erro.ErrUnauthorized
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(res.KeyHash), []byte(auth.HashApiKey(key))) != 1 {
+		return nil, erro.ErrUnauthorized
+	}
+	if res.Status != ApiKeyActive || (res.ExpiresAt != nil && time.Now().After(*res.ExpiresAt)) {
+		childLogger.Info().Str("key_id", res.KeyID).Str("status", res.Status).Msg("api key revoked or expired")
+		return nil, erro.ErrUnauthorized
+	}
+
+	// the last use is recorded without delaying the request
+	go func(apiKey model.ApiKey) {
+		ctxTouch, cancel := context.WithTimeout(auth.WithTenantBypass(context.Background()), 5 * time.Second)
+		defer cancel()
+		if _, err := s.workerRepository.UpdateApiKeyLastUsed(ctxTouch, &apiKey); err != nil {
+			childLogger.Error().Err(err).Str("key_id", apiKey.KeyID).Msg("error update api key last use")
+		}
+	}(*res)
+
+	res.KeyHash = ""
+	return res, nil
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"time"
+	"context"
+	"testing"
+
+	"github.com/go-account/internal/core/auth"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/model"
+)
+
+func Test_AddApiKey(t *testing.T){
+	tests := []struct {
+		name		string
+		ctx			context.Context
+		apiKey		model.ApiKey
+		err			error
+	}{
+		{"without principal", bypassContext(), model.ApiKey{TenantID: testTenant, Name: "batch", Scopes: []string{"account:read"}}, nil},
+		{"scopes of the principal", auth.WithPrincipal(bypassContext(), &model.Principal{Subject: "user-a", TenantID: testTenant, Scopes: []string{"account:read", "account:write"}}), model.ApiKey{TenantID: testTenant, Name: "batch", Scopes: []string{"account:write"}}, nil},
+		{"scopes over the principal", auth.WithPrincipal(bypassContext(), &model.Principal{Subject: "user-a", TenantID: testTenant, Scopes: []string{"account:read"}}), model.ApiKey{TenantID: testTenant, Name: "batch", Scopes: []string{"account:admin"}}, erro.ErrHTTPForbiden},
+		{"without scopes", bypassContext(), model.ApiKey{TenantID: testTenant, Name: "batch"}, erro.ErrBadRequest},
+		{"without tenant", bypassContext(), model.ApiKey{Name: "batch", Scopes: []string{"account:read"}}, erro.ErrBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workerService, _ := newMemoryService(t)
+
+			res, err := workerService.AddApiKey(tt.ctx, &tt.apiKey)
+			if err != tt.err {
+				t.Fatalf("err %v want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if res.Key == "" || res.KeyID == "" || res.Status != ApiKeyActive {
+				t.Fatalf("api key %+v", res)
+			}
+
+			res_verify, err := workerService.VerifyApiKey(bypassContext(), res.Key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res_verify.KeyID != res.KeyID || res_verify.KeyHash != "" {
+				t.Errorf("verify %+v", res_verify)
+			}
+		})
+	}
+}
+
+func Test_UpdateApiKey(t *testing.T){
+	ctx := bypassContext()
+	workerService, _ := newMemoryService(t)
+
+	res_add, err := workerService.AddApiKey(ctx, &model.ApiKey{TenantID: testTenant, Name: "batch", Scopes: []string{"account:read"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := res_add.Key
+
+	// rotate, the old key stops working
+	res_rotate, err := workerService.RotateApiKey(ctx, &model.ApiKey{KeyID: res_add.KeyID, TenantID: testTenant})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res_rotate.Key == "" || res_rotate.Key == key {
+		t.Fatalf("rotate %+v", res_rotate)
+	}
+	if _, err := workerService.VerifyApiKey(ctx, key); err != erro.ErrUnauthorized {
+		t.Errorf("old key err %v", err)
+	}
+	if _, err := workerService.VerifyApiKey(ctx, res_rotate.Key); err != nil {
+		t.Errorf("new key err %v", err)
+	}
+
+	tests := []struct {
+		name		string
+		ctx			context.Context
+		apiKey		model.ApiKey
+		err			error
+	}{
+		{"other tenant", ctx, model.ApiKey{KeyID: res_add.KeyID, TenantID: "tenant-2"}, erro.ErrNotFound},
+		{"other tenant principal", tenantContext("tenant-2"), model.ApiKey{KeyID: res_add.KeyID}, erro.ErrNotFound},
+		{"unknown", ctx, model.ApiKey{KeyID: "unknown", TenantID: testTenant}, erro.ErrNotFound},
+		{"revoke", ctx, model.ApiKey{KeyID: res_add.KeyID, TenantID: testTenant}, nil},
+		{"revoke twice", ctx, model.ApiKey{KeyID: res_add.KeyID, TenantID: testTenant}, erro.ErrTransInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := workerService.RevokeApiKey(tt.ctx, &tt.apiKey)
+			if err != tt.err {
+				t.Fatalf("err %v want %v", err, tt.err)
+			}
+			if err == nil && res.Status != ApiKeyRevoked {
+				t.Errorf("status %s", res.Status)
+			}
+		})
+	}
+
+	if _, err := workerService.VerifyApiKey(ctx, res_rotate.Key); err != erro.ErrUnauthorized {
+		t.Errorf("revoked key err %v", err)
+	}
+	if _, err := workerService.RotateApiKey(ctx, &model.ApiKey{KeyID: res_add.KeyID, TenantID: testTenant}); err != erro.ErrTransInvalid {
+		t.Errorf("rotate revoked err %v", err)
+	}
+}
+
+func Test_VerifyApiKey(t *testing.T){
+	ctx := bypassContext()
+	workerService, _ := newMemoryService(t)
+
+	expired := time.Now().Add(-time.Hour)
+	res_expired, err := workerService.AddApiKey(ctx, &model.ApiKey{TenantID: testTenant, Name: "expired", Scopes: []string{"account:read"}, ExpiresAt: &expired})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res_active, err := workerService.AddApiKey(ctx, &model.ApiKey{TenantID: testTenant, Name: "active", Scopes: []string{"account:read"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name	string
+		key		string
+		err		error
+	}{
+		{"active", res_active.Key, nil},
+		{"expired", res_expired.Key, erro.ErrUnauthorized},
+		{"wrong secret", res_active.Key + "x", erro.ErrUnauthorized},
+		{"malformed", "not-a-key", erro.ErrUnauthorized},
+		{"empty", "", erro.ErrUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := workerService.VerifyApiKey(ctx, tt.key); err != tt.err {
+				t.Errorf("err %v want %v", err, tt.err)
+			}
+		})
+	}
+
+	list_apiKey, err := workerService.ListApiKey(ctx, &model.ApiKey{TenantID: testTenant})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*list_apiKey) != 2 || (*list_apiKey)[0].Name != "active" {
+		t.Fatalf("list api key %+v", list_apiKey)
+	}
+	for _, apiKey := range *list_apiKey {
+		if apiKey.KeyHash != "" || apiKey.Key != "" {
+			t.Errorf("list exposes the key %+v", apiKey)
+		}
+	}
+}
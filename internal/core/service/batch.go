@@ -0,0 +1,53 @@
+package service
+
+import(
+	"context"
+
+	"github.com/go-account/internal/core/model"
+)
+
+// About the max of statements/transfers per account returned by the batch list
+const MaxBatchLast = 100
+
+func clampLast(last int) int {
+	if last <= 0 {
+		return 1
+	}
+	if last > MaxBatchLast {
+		return MaxBatchLast
+	}
+	return last
+}
+
+// About list the balances of many accounts (batch loaders), keyed by account_id
+func (s *WorkerService) ListAccountBalanceByAccountIDs(ctx context.Context, accountIDs []string) (map[string][]model.AccountBalance, error){
+	childLogger.Info().Str("func","ListAccountBalanceByAccountIDs").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Strs("accountIDs", accountIDs).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListAccountBalanceByAccountIDs")
+	defer span.End()
+
+	return s.workerRepository.ListAccountBalanceByAccountIDs(ctx, accountIDs)
+}
+
+// About list the last statements of many accounts (batch loaders), keyed by account_id
+func (s *WorkerService) ListAccountStatementByAccountIDs(ctx context.Context, accountIDs []string, last int) (map[string][]model.AccountStatement, error){
+	childLogger.Info().Str("func","ListAccountStatementByAccountIDs").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Strs("accountIDs", accountIDs).Int("last", last).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListAccountStatementByAccountIDs")
+	defer span.End()
+
+	return s.workerRepository.ListAccountStatementByAccountIDs(ctx, accountIDs, clampLast(last))
+}
+
+// About list the last transfers of many accounts (batch loaders), keyed by account_id
+func (s *WorkerService) ListTransferByAccountIDs(ctx context.Context, accountIDs []string, last int) (map[string][]model.Transfer, error){
+	childLogger.Info().Str("func","ListTransferByAccountIDs").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Strs("accountIDs", accountIDs).Int("last", last).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListTransferByAccountIDs")
+	defer span.End()
+
+	return s.workerRepository.ListTransferByAccountIDs(ctx, accountIDs, clampLast(last))
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"time"
+	"errors"
+	"context"
+	"encoding/json"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/auth"
+	"github.com/go-account/internal/core/validator"
+)
+
+const (
+	ConsumedApplied		= "APPLIED"
+	ConsumedRejected	= "REJECTED"
+)
+
+var errDuplicateMessage = errors.New("message already consumed")
+
+// About read the inbound messages, a message is committed (offset) only after it was handled
+type MessageConsumer interface {
+	Fetch(ctx context.Context) (*model.InboundMessage, error)
+	Commit(ctx context.Context, message *model.InboundMessage) error
+}
+
+// About decode a posting command, the value is an AccountStatement with the tenant of the account.
+// Without a transaction_id the message key is used, so the statement carries the idempotency key
+func decodePostingCommand(message *model.InboundMessage) (*model.AccountStatement, error) {
+	accountStatement := model.AccountStatement{}
+	if err := json.Unmarshal(message.Value, &accountStatement); err != nil {
+		return nil, erro.ErrUnmarshal
+	}
+	if accountStatement.TenantID == "" {
+		return nil, &erro.ValidationError{Fields: []erro.FieldError{{Field: "tenant_id", Rule: "required", Message: "is required"}}}
+	}
+	if err := validator.Validate(&accountStatement); err != nil {
+		return nil, err
+	}
+	if accountStatement.TransactionID == nil {
+		transactionID := message.Key
+		accountStatement.TransactionID = &transactionID
+	}
+	return &accountStatement, nil
+}
+
+// About the errors that do not change on a new attempt (the command is rejected), the others are retried
+func isPermanentError(err error) bool {
+	var validationError *erro.ValidationError
+	if errors.As(err, &validationError) {
+		return true
+	}
+	for _, permanent := range []error{	erro.ErrUnmarshal,
+										erro.ErrBadRequest,
+										erro.ErrNotFound,
+										erro.ErrTransInvalid,
+										erro.ErrInvalidAmount,
+										erro.ErrInsufficientFunds,
+										erro.ErrCurrency,
+										erro.ErrAccountBlocked,
+										erro.ErrProduct,
+										erro.ErrAccountNumber,
+										erro.ErrDayCount,
+										erro.ErrMediaType} {
+		if errors.Is(err, permanent) {
+			return true
+		}
+	}
+	return false
+}
+
+// About apply a posting command through the posting path, the message key is recorded in the transaction
+// of the posting so a message delivered again is not applied twice. A command that can not be applied is
+// recorded as rejected, a nil error means the message can be committed
+func (s *WorkerService) ConsumePosting(ctx context.Context, consumer string, message *model.InboundMessage) error{
+	childLogger.Info().Str("func","ConsumePosting").Str("consumer", consumer).Str("key", message.Key).Int64("offset", message.Offset).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ConsumePosting")
+	defer span.End()
+
+	if message.Key == "" {
+		childLogger.Error().Str("topic", message.Topic).Int64("offset", message.Offset).Msg("posting command without key skipped")
+		return nil
+	}
+
+	consumedMessage := newConsumedMessage(consumer, message)
+
+	accountStatement, err := decodePostingCommand(message)
+	if err != nil {
+		return s.rejectMessage(ctx, &consumedMessage, err)
+	}
+
+	// the posting is scoped to the tenant of the command
+	ctx = auth.WithPrincipal(ctx, &model.Principal{	Subject: consumer,
+													TenantID: accountStatement.TenantID,
+													Method: "consumer" })
+
+	_, err = s.addPosting(ctx, accountStatement, func(ctx context.Context, tx port.Tx) error {
+		inserted, err := s.workerRepository.AddConsumedMessage(ctx, tx, &consumedMessage)
+		if err != nil {
+			return err
+		}
+		if !inserted {
+			return errDuplicateMessage
+		}
+		return nil
+	})
+	if errors.Is(err, errDuplicateMessage) {
+		childLogger.Info().Str("key", message.Key).Msg("posting command already consumed")
+		return nil
+	}
+	if err != nil && isPermanentError(err) {
+		return s.rejectMessage(ctx, &consumedMessage, err)
+	}
+	return err
+}
+
+// About the record of a consumed message, applied unless it is rejected
+func newConsumedMessage(consumer string, message *model.InboundMessage) model.ConsumedMessage {
+	return model.ConsumedMessage{	Consumer: consumer,
+									MessageKey: message.Key,
+									Topic: message.Topic,
+									Partition: message.Partition,
+									Offset: message.Offset,
+									Status: ConsumedApplied }
+}
+
+// About record a command that can not be applied, so it is committed and not retried
+func (s *WorkerService) rejectMessage(ctx context.Context, consumedMessage *model.ConsumedMessage, errCommand error) error{
+	childLogger.Error().Err(errCommand).Str("key", consumedMessage.MessageKey).Msg("posting command rejected")
+
+	lastError := errCommand.Error()
+	consumedMessage.Status = ConsumedRejected
+	consumedMessage.Error = &lastError
+
+	// Get the database connection
+	tx, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.workerRepository.AddConsumedMessage(ctx, tx, consumedMessage)
+	if err != nil {
+		tx.Rollback(ctx)
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// About fetch, handle and commit the messages one by one. A failure of the handler is retried on the same
+// message after the retry interval, so the offset never passes a message that was not handled. After the max
+// attempts (0 retries forever) the message is given to reject (recorded as rejected) and then committed
+func consumeLoop(ctx context.Context,
+				consumer MessageConsumer,
+				handle func(ctx context.Context, message *model.InboundMessage) error,
+				reject func(ctx context.Context, message *model.InboundMessage, err error) error,
+				retryInterval time.Duration,
+				maxAttempts int) {
+	wait := func() bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(retryInterval):
+			return true
+		}
+	}
+
+	for ctx.Err() == nil {
+		message, err := consumer.Fetch(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				childLogger.Error().Err(err).Msg("error fetch message")
+				wait()
+			}
+			continue
+		}
+
+		for attempt := 1; ; attempt++ {
+			err = handle(ctx, message)
+			if err != nil && reject != nil && maxAttempts > 0 && attempt >= maxAttempts {
+				childLogger.Error().Err(err).Str("key", message.Key).Int("attempts", attempt).Msg("message rejected after the max attempts")
+				err = reject(ctx, message, err)
+			}
+			if err == nil {
+				break
+			}
+			childLogger.Error().Err(err).Str("key", message.Key).Msg("error handle message, retrying")
+			if !wait() {
+				return
+			}
+		}
+
+		for {
+			err = consumer.Commit(ctx, message)
+			if err == nil {
+				break
+			}
+			childLogger.Error().Err(err).Str("key", message.Key).Msg("error commit message, retrying")
+			if !wait() {
+				return
+			}
+		}
+	}
+}
+
+// About consume the posting commands until the context is done, a command that still fails after the
+// max attempts is recorded as rejected (dead letter in the consumed_message table)
+func (s *WorkerService) ConsumePostingJob(ctx context.Context, name string, consumer MessageConsumer, retryInterval time.Duration, maxAttempts int){
+	childLogger.Info().Str("func","ConsumePostingJob").Str("name", name).Interface("retryInterval", retryInterval).Int("maxAttempts", maxAttempts).Send()
+
+	consumeLoop(ctx, consumer, func(ctx context.Context, message *model.InboundMessage) error {
+		return s.ConsumePosting(ctx, name, message)
+	}, func(ctx context.Context, message *model.InboundMessage, err error) error {
+		consumedMessage := newConsumedMessage(name, message)
+		return s.rejectMessage(ctx, &consumedMessage, err)
+	}, retryInterval, maxAttempts)
+
+	childLogger.Info().Msg("stop posting consumer job !!!")
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"errors"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+	"fmt"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+)
+
+type fakeConsumer struct {
+	messages	[]*model.InboundMessage
+	next		int
+	log			[]string
+	cancel		context.CancelFunc
+}
+
+func (f *fakeConsumer) Fetch(ctx context.Context) (*model.InboundMessage, error) {
+	if f.next == len(f.messages) {
+		f.cancel()
+		return nil, ctx.Err()
+	}
+	f.next++
+	return f.messages[f.next - 1], nil
+}
+
+func (f *fakeConsumer) Commit(ctx context.Context, message *model.InboundMessage) error {
+	f.log = append(f.log, fmt.Sprintf("commit %s", message.Key))
+	return nil
+}
+
+// a failure is retried on the same message and the commit comes only after the handler succeeded
+func Test_consumeLoop(t *testing.T){
+	ctx, cancel := context.WithTimeout(bypassContext(), 5 * time.Second)
+	defer cancel()
+
+	consumer := &fakeConsumer{
+		messages: []*model.InboundMessage{{Key: "K1", Offset: 1}, {Key: "K2", Offset: 2}},
+		cancel: cancel,
+	}
+	failures := map[string]int{"K1": 2}
+
+	consumeLoop(ctx, consumer, func(ctx context.Context, message *model.InboundMessage) error {
+		consumer.log = append(consumer.log, fmt.Sprintf("handle %s", message.Key))
+		if failures[message.Key] > 0 {
+			failures[message.Key]--
+			return errors.New("database unavailable")
+		}
+		return nil
+	}, nil, time.Millisecond, 0)
+
+	want := []string{"handle K1", "handle K1", "handle K1", "commit K1", "handle K2", "commit K2"}
+	if !reflect.DeepEqual(consumer.log, want) {
+		t.Errorf("got %v want %v", consumer.log, want)
+	}
+}
+
+// after the max attempts the message is rejected and committed, the next one is handled
+func Test_consumeLoopMaxAttempts(t *testing.T){
+	ctx, cancel := context.WithTimeout(bypassContext(), 5 * time.Second)
+	defer cancel()
+
+	consumer := &fakeConsumer{
+		messages: []*model.InboundMessage{{Key: "K1", Offset: 1}, {Key: "K2", Offset: 2}},
+		cancel: cancel,
+	}
+
+	consumeLoop(ctx, consumer, func(ctx context.Context, message *model.InboundMessage) error {
+		consumer.log = append(consumer.log, fmt.Sprintf("handle %s", message.Key))
+		if message.Key == "K1" {
+			return errors.New("database unavailable")
+		}
+		return nil
+	}, func(ctx context.Context, message *model.InboundMessage, err error) error {
+		consumer.log = append(consumer.log, fmt.Sprintf("reject %s", message.Key))
+		return nil
+	}, time.Millisecond, 3)
+
+	want := []string{"handle K1", "handle K1", "handle K1", "reject K1", "commit K1", "handle K2", "commit K2"}
+	if !reflect.DeepEqual(consumer.log, want) {
+		t.Errorf("got %v want %v", consumer.log, want)
+	}
+}
+
+// the context done stops the retries without committing
+func Test_consumeLoopStop(t *testing.T){
+	ctx, cancel := context.WithCancel(bypassContext())
+	consumer := &fakeConsumer{messages: []*model.InboundMessage{{Key: "K1"}}, cancel: cancel}
+
+	consumeLoop(ctx, consumer, func(ctx context.Context, message *model.InboundMessage) error {
+		cancel()
+		return errors.New("database unavailable")
+	}, nil, time.Hour, 0)
+
+	if len(consumer.log) != 0 {
+		t.Errorf("unexpected commit %v", consumer.log)
+	}
+}
+
+func Test_decodePostingCommand(t *testing.T){
+	tests := []struct {
+		name		string
+		value		string
+		err			error
+		transaction	string
+	}{
+		{"ok", `{"account_id":"ACC-1","type_charge":"CREDIT","currency":"BRL","amount":10,"tenant_id":"T1"}`, nil, "K1"},
+		{"transaction id kept", `{"account_id":"ACC-1","type_charge":"DEBIT","currency":"BRL","amount":-10,"tenant_id":"T1","transaction_id":"TX-9"}`, nil, "TX-9"},
+		{"no tenant", `{"account_id":"ACC-1","type_charge":"CREDIT","currency":"BRL","amount":10}`, erro.ErrBadRequest, ""},
+		{"invalid", `{"account_id":"ACC-1","type_charge":"FEE","currency":"BRL","amount":10,"tenant_id":"T1"}`, erro.ErrBadRequest, ""},
+		{"not json", `CREDIT ACC-1 10`, erro.ErrUnmarshal, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accountStatement, err := decodePostingCommand(&model.InboundMessage{Key: "K1", Value: []byte(tt.value)})
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got %v want %v", err, tt.err)
+			}
+			if err != nil {
+				if !isPermanentError(err) {
+					t.Errorf("a decode error must be permanent")
+				}
+				return
+			}
+			if *accountStatement.TransactionID != tt.transaction {
+				t.Errorf("transaction_id %s want %s", *accountStatement.TransactionID, tt.transaction)
+			}
+		})
+	}
+}
+
+func Test_isPermanentError(t *testing.T){
+	if !isPermanentError(fmt.Errorf("posting: %w", erro.ErrInsufficientFunds)) {
+		t.Errorf("insufficient funds must be permanent")
+	}
+	for _, err := range []error{	&erro.ValidationError{Fields: []erro.FieldError{{Field: "amount", Rule: "gt"}}},
+									fmt.Errorf("number: %w", erro.ErrAccountNumber),
+									erro.ErrDayCount,
+									erro.ErrAccountBlocked,
+									erro.ErrMediaType} {
+		if !isPermanentError(err) {
+			t.Errorf("%v must be permanent", err)
+		}
+	}
+	if isPermanentError(errors.New("conn closed")) || isPermanentError(erro.ErrUpdate) {
+		t.Errorf("a database error must be retried")
+	}
+}
+
+// a message delivered again is not applied twice and a rejected command is not retried
+func Test_ConsumePosting(t *testing.T){
+	ctx := bypassContext()
+	workerService, _ := newMemoryService(t)
+	addTestAccount(t, workerService, "ACC-1", "P-1", "CHECKING")
+
+	tests := []struct {
+		name		string
+		message		model.InboundMessage
+		balance		float64
+	}{
+		{"credit", model.InboundMessage{Key: "K1", Offset: 1, Value: []byte(`{"account_id":"ACC-1","type_charge":"CREDIT","currency":"BRL","amount":10,"tenant_id":"tenant-1"}`)}, 10},
+		{"delivered again", model.InboundMessage{Key: "K1", Offset: 1, Value: []byte(`{"account_id":"ACC-1","type_charge":"CREDIT","currency":"BRL","amount":10,"tenant_id":"tenant-1"}`)}, 10},
+		{"debit", model.InboundMessage{Key: "K2", Offset: 2, Value: []byte(`{"account_id":"ACC-1","type_charge":"DEBIT","currency":"BRL","amount":-5,"tenant_id":"tenant-1"}`)}, 5},
+		{"insufficient funds rejected", model.InboundMessage{Key: "K3", Offset: 3, Value: []byte(`{"account_id":"ACC-1","type_charge":"DEBIT","currency":"BRL","amount":-500,"tenant_id":"tenant-1"}`)}, 5},
+		{"rejected key not applied", model.InboundMessage{Key: "K3", Offset: 4, Value: []byte(`{"account_id":"ACC-1","type_charge":"DEBIT","currency":"BRL","amount":-1,"tenant_id":"tenant-1"}`)}, 5},
+		{"other tenant rejected", model.InboundMessage{Key: "K4", Offset: 5, Value: []byte(`{"account_id":"ACC-1","type_charge":"CREDIT","currency":"BRL","amount":1,"tenant_id":"tenant-2"}`)}, 5},
+		{"not json rejected", model.InboundMessage{Key: "K5", Offset: 6, Value: []byte(`CREDIT ACC-1 1`)}, 5},
+		{"without key skipped", model.InboundMessage{Offset: 7, Value: []byte(`{"account_id":"ACC-1","type_charge":"CREDIT","currency":"BRL","amount":1,"tenant_id":"tenant-1"}`)}, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := workerService.ConsumePosting(ctx, "go-account-test", &tt.message); err != nil {
+				t.Fatalf("err %v, the message must be committed", err)
+			}
+			if got := balanceOf(t, workerService, "ACC-1", "BRL"); got != tt.balance {
+				t.Errorf("balance %v want %v", got, tt.balance)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+	"context"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/auth"
+	"github.com/go-account/internal/core/erro"
+)
+
+const (
+	FeeMethodFlat			= "FLAT"
+	FeeMethodPercentage		= "PERCENTAGE"
+	FeeMethodTiered			= "TIERED"
+	StatementFee			= "FEE"
+	OperationMaintenance	= "MAINTENANCE"
+)
+
+// About calculate the fee of an amount (percentages are expressed in %, tiers are ordered by up_to and 0 means no upper bound)
+func EvaluateFee(feeSchedule *model.FeeSchedule, amount float64) (float64, error){
+	base := math.Abs(amount)
+	fee := 0.0
+
+	switch feeSchedule.Method {
+	case FeeMethodFlat:
+		fee = feeSchedule.Amount
+	case FeeMethodPercentage:
+		fee = base * feeSchedule.Percentage / 100
+	case FeeMethodTiered:
+		for _, tier := range feeSchedule.Tiers {
+			if tier.UpTo == 0 || base <= tier.UpTo {
+				fee = tier.Amount + base * tier.Percentage / 100
+				break
+			}
+		}
+	default:
+		return 0, erro.ErrFeeMethod
+	}
+
+	if fee < feeSchedule.MinFee {
+		fee = feeSchedule.MinFee
+	}
+	if feeSchedule.MaxFee > 0 && fee > feeSchedule.MaxFee {
+		fee = feeSchedule.MaxFee
+	}
+
+	return roundAmount(fee, 2), nil
+}
+
+// About find the fee of an operation, no schedule means no fee
+func (s *WorkerService) PreviewFee(ctx context.Context, feePreview *model.FeePreview) (*model.FeePreview, error){
+	childLogger.Info().Str("func","PreviewFee").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("feePreview", feePreview).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.PreviewFee")
+	defer span.End()
+
+	feeSchedule := model.FeeSchedule{	TenantID: feePreview.TenantID,
+										ProductID: feePreview.ProductID,
+										Operation: feePreview.Operation,
+										Currency: feePreview.Currency }
+
+	res_feeSchedule, err := s.workerRepository.GetFeeSchedule(ctx, &feeSchedule)
+	if err == erro.ErrNotFound {
+		feePreview.Fee = 0
+		feePreview.FeeSchedule = nil
+		return feePreview, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fee, err := EvaluateFee(res_feeSchedule, feePreview.Amount)
+	if err != nil {
+		return nil, err
+	}
+	feePreview.Fee = fee
+	feePreview.FeeSchedule = res_feeSchedule
+
+	return feePreview, nil
+}
+
+// About charge the monthly maintenance fee of a month on every balance with a MAINTENANCE fee schedule,
+// the fee is evaluated over the current balance and running the same month again does not charge twice
+func (s *WorkerService) MaintenanceFee(ctx context.Context, period time.Time) (*model.MaintenanceRun, error){
+	childLogger.Info().Str("func","MaintenanceFee").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("period", period).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.MaintenanceFee")
+	defer span.End()
+
+	// the fee is charged once per calendar month
+	period = time.Date(period.Year(), period.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	// List the balances
+	list_maintenance, err := s.workerRepository.ListMaintenanceBalance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	maintenanceRun := model.MaintenanceRun{	Period: period,
+											Balances: len(*list_maintenance) }
+
+	for i := range *list_maintenance {
+		maintenanceBalance := (*list_maintenance)[i]
+
+		feePreview := model.FeePreview{	TenantID: maintenanceBalance.TenantID,
+										ProductID: maintenanceBalance.ProductID,
+										Operation: OperationMaintenance,
+										Currency: maintenanceBalance.Currency,
+										Amount: maintenanceBalance.Balance }
+		res_fee, err := s.PreviewFee(ctx, &feePreview)
+		if err != nil {
+			return nil, err
+		}
+		if res_fee.Fee <= 0 {
+			continue
+		}
+
+		charged, err := s.chargeMaintenanceFee(ctx, &maintenanceBalance, res_fee, period)
+		if err == erro.ErrInsufficientFunds {
+			childLogger.Error().Err(err).Int("fk_account_balance_id", maintenanceBalance.FkAccountBalanceID).Msg("maintenance fee skipped")
+			maintenanceRun.Skipped = maintenanceRun.Skipped + 1
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if charged {
+			maintenanceRun.Charged = maintenanceRun.Charged + 1
+			maintenanceRun.AmountCharged = roundAmount(maintenanceRun.AmountCharged + res_fee.Fee, 2)
+		}
+	}
+
+	return &maintenanceRun, nil
+}
+
+// About charge the maintenance fee of a month into a (locked) balance, unless the month was already charged.
+// The error of the commit is returned, so a month is counted as charged only when it is durable
+func (s *WorkerService) chargeMaintenanceFee(ctx context.Context,
+											maintenanceBalance *model.MaintenanceBalance,
+											feePreview *model.FeePreview,
+											period time.Time) (charged bool, err error){
+	childLogger.Info().Str("func","chargeMaintenanceFee").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("maintenanceBalance", maintenanceBalance).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.chargeMaintenanceFee")
+
+	account := model.Account{	ID: maintenanceBalance.FkAccountID,
+								AccountID: maintenanceBalance.AccountID,
+								ProductID: maintenanceBalance.ProductID,
+								TenantID: maintenanceBalance.TenantID }
+	res_product, err := s.getAccountProductRules(ctx, &account)
+	if err != nil {
+		span.End()
+		return false, err
+	}
+
+	// Get the database connection
+	tx, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		span.End()
+		return false, err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else if err = tx.Commit(ctx); err != nil {
+			charged = false
+		}
+		span.End()
+	}()
+
+	// Lock the balance, the fee is applied over its current amount
+	accountBalance := model.AccountBalance{	FkAccountID: maintenanceBalance.FkAccountID,
+											Currency: maintenanceBalance.Currency }
+	res_balance, err := s.workerRepository.GetAccountBalanceForUpdate(ctx, tx, &accountBalance)
+	if err != nil {
+		return false, err
+	}
+
+	// the transaction id of the month is unique per account (migration 0011), so a month charged by another run
+	// (or replica) is not charged again
+	transactionID := fmt.Sprintf("%s-%d-%s", OperationMaintenance, maintenanceBalance.FkAccountBalanceID, period.Format("2006-01"))
+
+	return s.postStatementOnce(ctx, tx, res_balance, feeStatement(feePreview, &transactionID), res_product.Overdraft)
+}
+
+// About charge the maintenance fee of the previous month on each tick, until the context is done
+func (s *WorkerService) MaintenanceFeeJob(ctx context.Context, interval time.Duration){
+	childLogger.Info().Str("func","MaintenanceFeeJob").Interface("interval", interval).Send()
+
+	// the job runs for all the tenants
+	ctx = auth.WithTenantBypass(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			childLogger.Info().Msg("stop maintenance fee job !!!")
+			return
+		case <-ticker.C:
+			now := time.Now().UTC()
+			res, err := s.MaintenanceFee(ctx, time.Date(now.Year(), now.Month() - 1, 1, 0, 0, 0, 0, time.UTC))
+			if err != nil {
+				childLogger.Error().Err(err).Msg("error maintenance fee job")
+				continue
+			}
+			childLogger.Info().Interface("maintenanceRun", res).Msg("maintenance fee job done")
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"time"
+	"context"
+	"testing"
+
+	"github.com/go-account/internal/core/model"
+)
+
+func Test_EvaluateFee(t *testing.T){
+	tiers := []model.FeeTier{	{UpTo: 100, Amount: 1},
+								{UpTo: 1000, Amount: 0.5, Percentage: 1},
+								{UpTo: 0, Percentage: 0.5} }
+
+	tests := []struct {
+		name		string
+		schedule	model.FeeSchedule
+		amount		float64
+		want		float64
+	}{
+		{"flat", model.FeeSchedule{Method: FeeMethodFlat, Amount: 2.5}, 10, 2.5},
+		{"percentage debit", model.FeeSchedule{Method: FeeMethodPercentage, Percentage: 1.5}, -200, 3},
+		{"percentage min", model.FeeSchedule{Method: FeeMethodPercentage, Percentage: 1, MinFee: 1}, 10, 1},
+		{"percentage max", model.FeeSchedule{Method: FeeMethodPercentage, Percentage: 1, MaxFee: 5}, 1000, 5},
+		{"tier 1", model.FeeSchedule{Method: FeeMethodTiered, Tiers: tiers}, 100, 1},
+		{"tier 2", model.FeeSchedule{Method: FeeMethodTiered, Tiers: tiers}, 500, 5.5},
+		{"tier open", model.FeeSchedule{Method: FeeMethodTiered, Tiers: tiers}, 5000, 25},
+	}
+
+	for _, tt := range tests {
+		got, err := EvaluateFee(&tt.schedule, tt.amount)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %v want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := EvaluateFee(&model.FeeSchedule{Method: "UNKNOWN"}, 10); err == nil {
+		t.Errorf("unknown method must fail")
+	}
+}
+
+func Test_MaintenanceFee(t *testing.T){
+	ctx := bypassContext()
+	workerService, repository := newMemoryService(t)
+	addTestAccount(t, workerService, "ACC-1", "P-1", "CHECKING")
+	addTestAccount(t, workerService, "ACC-2", "P-1", "SAVINGS")
+	addTestAccount(t, workerService, "ACC-3", "P-1", "SAVINGS")
+	for _, accountID := range []string{"ACC-1", "ACC-2"} {
+		if _, err := workerService.AddPosting(ctx, &model.AccountStatement{AccountID: accountID, Type: StatementCredit, Currency: "BRL", Amount: 100}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 3 for the tenant, the checking product has no fee, ACC-3 has no funds (savings has no overdraft)
+	list_fee := []model.FeeSchedule{
+		{TenantID: testTenant, Operation: OperationMaintenance, Method: FeeMethodFlat, Currency: "BRL", Amount: 3},
+		{TenantID: testTenant, ProductID: "CHECKING", Operation: OperationMaintenance, Method: FeeMethodFlat, Currency: "BRL", Amount: 0},
+	}
+	for _, feeSchedule := range list_fee {
+		if err := repository.PutFeeSchedule(ctx, feeSchedule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name		string
+		period		time.Time
+		run			model.MaintenanceRun
+		balance		float64
+	}{
+		{"month", time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC), model.MaintenanceRun{Balances: 3, Charged: 1, Skipped: 1, AmountCharged: 3}, 97},
+		{"same month again", time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC), model.MaintenanceRun{Balances: 3, Skipped: 1}, 97},
+		{"next month", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), model.MaintenanceRun{Balances: 3, Charged: 1, Skipped: 1, AmountCharged: 3}, 94},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := workerService.MaintenanceFee(ctx, tt.period)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.run.Period = time.Date(tt.period.Year(), tt.period.Month(), 1, 0, 0, 0, 0, time.UTC)
+			if *res != tt.run {
+				t.Errorf("run %+v want %+v", *res, tt.run)
+			}
+			if got := balanceOf(t, workerService, "ACC-2", "BRL"); got != tt.balance {
+				t.Errorf("balance %v want %v", got, tt.balance)
+			}
+			if got := balanceOf(t, workerService, "ACC-1", "BRL"); got != 100 {
+				t.Errorf("checking balance %v", got)
+			}
+		})
+	}
+
+	res_statement, err := repository.ListAccountStatementByAccountIDs(ctx, []string{"ACC-2"}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list_statement := res_statement["ACC-2"]; len(list_statement) != 3 || list_statement[0].Type != StatementFee ||
+		list_statement[0].TransactionID == nil || *list_statement[0].TransactionID != "FEE-MAINTENANCE-3-2026-02" {
+		t.Errorf("statements %+v", list_statement)
+	}
+
+	// a month already written by an other run (replica) is not charged again
+	tx, err := repository.StartTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transactionID := "FEE-MAINTENANCE-3-2026-03"
+	if _, err := repository.AddAccountStatement(ctx, tx, &model.AccountStatement{FkAccountID: res_statement["ACC-2"][0].FkAccountID, Type: StatementFee, Currency: "BRL", Amount: -3, TenantID: testTenant, TransactionID: &transactionID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	res, err := workerService.MaintenanceFee(ctx, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Charged != 0 || res.AmountCharged != 0 {
+		t.Errorf("run %+v", *res)
+	}
+	if got := balanceOf(t, workerService, "ACC-2", "BRL"); got != 94 {
+		t.Errorf("balance %v want 94", got)
+	}
+}
+
+func Test_MaintenanceFeeJob(t *testing.T){
+	workerService, repository := newMemoryService(t)
+	addTestAccount(t, workerService, "ACC-1", "P-1", "SAVINGS")
+	if _, err := workerService.AddPosting(bypassContext(), &model.AccountStatement{AccountID: "ACC-1", Type: StatementCredit, Currency: "BRL", Amount: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repository.PutFeeSchedule(bypassContext(), model.FeeSchedule{TenantID: testTenant, Operation: OperationMaintenance, Method: FeeMethodFlat, Currency: "BRL", Amount: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(bypassContext())
+	done := make(chan struct{})
+	go func() {
+		workerService.MaintenanceFeeJob(ctx, time.Millisecond)
+		close(done)
+	}()
+
+	// the previous month is charged once, whatever the number of ticks
+	deadline := time.Now().Add(5 * time.Second)
+	for balanceOf(t, workerService, "ACC-1", "BRL") == 100 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	<-done
+
+	if got := balanceOf(t, workerService, "ACC-1", "BRL"); got != 97 {
+		t.Errorf("balance %v want 97", got)
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"time"
+	"context"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/auth"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/event"
+)
+
+const (
+	DayCountACT365		= "ACT/365"
+	DayCount30360		= "30/360"
+	StatementInterest	= "INTEREST"
+)
+
+// About the fraction of a year between two dates for a day-count convention
+func DayCountFraction(dayCount string, from time.Time, to time.Time) (float64, error){
+	switch dayCount {
+	case DayCountACT365:
+		days := to.Sub(from).Hours() / 24
+		return math.Round(days) / 365, nil
+	case DayCount30360:
+		y1, m1, d1 := from.Date()
+		y2, m2, d2 := to.Date()
+		if d1 == 31 {
+			d1 = 30
+		}
+		if d2 == 31 && d1 == 30 {
+			d2 = 30
+		}
+		days := 360*(y2-y1) + 30*(int(m2)-int(m1)) + (d2-d1)
+		return float64(days) / 360, nil
+	default:
+		return 0, erro.ErrDayCount
+	}
+}
+
+// About round an amount to n decimal places
+func roundAmount(amount float64, places int) float64 {
+	pow := math.Pow(10, float64(places))
+	return math.Round(amount * pow) / pow
+}
+
+// About run the daily interest accrual and the monthly capitalization for a date
+func (s *WorkerService) InterestAccrual(ctx context.Context, accrualDate time.Time) (*model.InterestRun, error){
+	childLogger.Info().Str("func","InterestAccrual").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("accrualDate", accrualDate).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.InterestAccrual")
+	defer span.End()
+
+	// accruals are per calendar day
+	accrualDate = time.Date(accrualDate.Year(), accrualDate.Month(), accrualDate.Day(), 0, 0, 0, 0, time.UTC)
+	nextDate := accrualDate.AddDate(0, 0, 1)
+
+	// only closed months are capitalized, so the day after the month end posts the month
+	capitalizeUntil := time.Date(nextDate.Year(), nextDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	// List the balances
+	list_accrual, err := s.workerRepository.ListInterestBearingBalance(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	interestRun := model.InterestRun{	AccrualDate: accrualDate,
+										Balances: len(*list_accrual) }
+
+	for i := range *list_accrual {
+		interestAccrual := (*list_accrual)[i]
+		interestAccrual.AccrualDate = accrualDate
+
+		fraction, err := DayCountFraction(interestAccrual.DayCount, accrualDate, nextDate)
+		if err != nil {
+			childLogger.Error().Err(err).Int("fk_account_balance_id", interestAccrual.FkAccountBalanceID).Msg("balance skipped")
+			continue
+		}
+		interestAccrual.Amount = roundAmount(interestAccrual.Balance * interestAccrual.Rate * fraction, 6)
+
+		accrued, posted, err := s.accrueInterest(ctx, &interestAccrual, capitalizeUntil)
+		if err != nil {
+			return nil, err
+		}
+		if accrued {
+			interestRun.Accrued = interestRun.Accrued + 1
+		}
+		if posted > 0 {
+			interestRun.Capitalized = interestRun.Capitalized + 1
+			interestRun.AmountPosted = roundAmount(interestRun.AmountPosted + posted, 2)
+		}
+	}
+
+	return &interestRun, nil
+}
+
+// About accrue the interest of one balance and capitalize the closed months in a single transaction,
+// the error of the commit is returned, so nothing is counted as accrued or posted unless it is durable
+func (s *WorkerService) accrueInterest(ctx context.Context,
+										interestAccrual *model.InterestAccrual,
+										capitalizeUntil time.Time) (accrued bool, posted float64, err error){
+	childLogger.Info().Str("func","accrueInterest").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("interestAccrual", interestAccrual).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.accrueInterest")
+
+	// Get the database connection
+	tx, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		span.End()
+		return false, 0, err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else if err = tx.Commit(ctx); err != nil {
+			accrued, posted = false, 0
+		}
+		span.End()
+	}()
+
+	// Add the daily accrual
+	accrued, err = s.workerRepository.AddInterestAccrual(ctx, tx, interestAccrual)
+	if err != nil {
+		return false, 0, err
+	}
+
+	// Capitalize the pending accruals of the closed months
+	amounts, err := s.workerRepository.CapitalizeInterestAccrual(ctx, tx, interestAccrual, capitalizeUntil)
+	if err != nil {
+		return false, 0, err
+	}
+	total := 0.0
+	for _, amount := range amounts {
+		total = total + amount
+	}
+	total = roundAmount(total, 2)
+	if total <= 0 {
+		return accrued, 0, nil
+	}
+
+	// Post the interest
+	period := capitalizeUntil.AddDate(0, 0, -1)
+	transactionID := fmt.Sprintf("%s-%d-%s", StatementInterest, interestAccrual.FkAccountBalanceID, period.Format("2006-01"))
+
+	accountStatement := model.AccountStatement{	FkAccountID: interestAccrual.FkAccountID,
+												AccountID: interestAccrual.AccountID,
+												Type: StatementInterest,
+												Currency: interestAccrual.Currency,
+												Amount: total,
+												TenantID: interestAccrual.TenantID,
+												TransactionID: &transactionID,
+											}
+	_, err = s.workerRepository.AddAccountStatement(ctx, tx, &accountStatement)
+	if err != nil {
+		return false, 0, err
+	}
+
+	accountBalance := model.AccountBalance{	ID: interestAccrual.FkAccountBalanceID,
+											Amount: total,
+											TransactionID: &transactionID,
+										}
+	res_update, err := s.workerRepository.UpdateAccountBalanceAmount(ctx, tx, &accountBalance)
+	if err != nil {
+		return false, 0, err
+	}
+	if (res_update == 0) {
+		err = erro.ErrUpdate
+		return false, 0, err
+	}
+
+	err = s.addEvent(ctx, tx, event.TypeBalancePosted, interestAccrual.AccountID, interestAccrual.TenantID, event.NewBalancePosted(&accountStatement, nil))
+	if err != nil {
+		return false, 0, err
+	}
+
+	return accrued, total, nil
+}
+
+// About run the interest accrual for the previous day on each tick, until the context is done
+func (s *WorkerService) InterestAccrualJob(ctx context.Context, interval time.Duration){
+	childLogger.Info().Str("func","InterestAccrualJob").Interface("interval", interval).Send()
+
+	// the job runs for all the tenants
+	ctx = auth.WithTenantBypass(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			childLogger.Info().Msg("stop interest accrual job !!!")
+			return
+		case <-ticker.C:
+			res, err := s.InterestAccrual(ctx, time.Now().UTC().AddDate(0, 0, -1))
+			if err != nil {
+				childLogger.Error().Err(err).Msg("error interest accrual job")
+				continue
+			}
+			childLogger.Info().Interface("interestRun", res).Msg("interest accrual job done")
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-account/internal/core/model"
+)
+
+func Test_DayCountFraction(t *testing.T){
+	date := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		dayCount	string
+		from		time.Time
+		to			time.Time
+		want		float64
+	}{
+		{DayCountACT365, date(2024, 2, 28), date(2024, 2, 29), 1.0/365},
+		{DayCountACT365, date(2024, 1, 1), date(2025, 1, 1), 366.0/365},
+		{DayCount30360, date(2024, 1, 31), date(2024, 2, 1), 1.0/360},
+		{DayCount30360, date(2024, 1, 30), date(2024, 1, 31), 0},
+		{DayCount30360, date(2024, 1, 1), date(2024, 2, 1), 30.0/360},
+	}
+
+	for _, tt := range tests {
+		got, err := DayCountFraction(tt.dayCount, tt.from, tt.to)
+		if err != nil {
+			t.Fatalf("%s %v: unexpected error %v", tt.dayCount, tt.from, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s %v -> %v: got %v want %v", tt.dayCount, tt.from, tt.to, got, tt.want)
+		}
+	}
+
+	if _, err := DayCountFraction("ACT/ACT", date(2024, 1, 1), date(2024, 1, 2)); err == nil {
+		t.Errorf("unsupported day count must fail")
+	}
+}
+
+// 36500 at 10% ACT/365 accrues 10 a day, the day after the month end posts the month
+func Test_InterestAccrual(t *testing.T){
+	ctx := bypassContext()
+	workerService, repository := newMemoryService(t)
+	addTestAccount(t, workerService, "ACC-1", "P-1", "SAVINGS")
+	addTestAccount(t, workerService, "ACC-2", "P-1", "CHECKING")
+	for _, accountID := range []string{"ACC-1", "ACC-2"} {
+		if _, err := workerService.AddPosting(ctx, &model.AccountStatement{AccountID: accountID, Type: StatementCredit, Currency: "BRL", Amount: 36500}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name		string
+		date		time.Time
+		run			model.InterestRun
+		balance		float64
+	}{
+		{"accrual", time.Date(2026, 1, 30, 15, 0, 0, 0, time.UTC), model.InterestRun{Balances: 1, Accrued: 1}, 36500},
+		{"same day again", time.Date(2026, 1, 30, 0, 0, 0, 0, time.UTC), model.InterestRun{Balances: 1}, 36500},
+		{"month end", time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC), model.InterestRun{Balances: 1, Accrued: 1, Capitalized: 1, AmountPosted: 20}, 36520},
+		{"month end again", time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC), model.InterestRun{Balances: 1}, 36520},
+		{"next month", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), model.InterestRun{Balances: 1, Accrued: 1}, 36520},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := workerService.InterestAccrual(ctx, tt.date)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.run.AccrualDate = time.Date(tt.date.Year(), tt.date.Month(), tt.date.Day(), 0, 0, 0, 0, time.UTC)
+			if *res != tt.run {
+				t.Errorf("run %+v want %+v", *res, tt.run)
+			}
+			if got := balanceOf(t, workerService, "ACC-1", "BRL"); got != tt.balance {
+				t.Errorf("balance %v want %v", got, tt.balance)
+			}
+			if got := balanceOf(t, workerService, "ACC-2", "BRL"); got != 36500 {
+				t.Errorf("checking balance %v", got)
+			}
+		})
+	}
+
+	res_statement, err := repository.ListAccountStatementByAccountIDs(ctx, []string{"ACC-1"}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list_statement := res_statement["ACC-1"]; len(list_statement) != 2 || list_statement[0].Type != StatementInterest ||
+		list_statement[0].TransactionID == nil || *list_statement[0].TransactionID != "INTEREST-1-2026-01" {
+		t.Errorf("statements %+v", list_statement)
+	}
+}
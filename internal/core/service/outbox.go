@@ -0,0 +1,177 @@
+package service
+
+import (
+	"time"
+	"context"
+	"encoding/json"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/auth"
+	"github.com/go-account/internal/core/port"
+	"github.com/go-account/internal/core/event"
+)
+
+// About deliver an outbox event to the other services (kafka, file, memory)
+type EventPublisher interface {
+	Publish(ctx context.Context, outboxEvent model.OutboxEvent) error
+}
+
+// About write a domain event (CloudEvents envelope) into the outbox in the transaction of the mutation,
+// the subject and the aggregate is the account, the webhook deliveries are written with it
+func (s *WorkerService) addEvent(ctx context.Context,
+								tx port.Tx,
+								eventType string,
+								accountID string,
+								tenantID string,
+								data interface{}) error {
+	cloudEvent, err := event.NewCloudEvent(ctx, s.eventSource, eventType, accountID, tenantID, data)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(cloudEvent)
+	if err != nil {
+		return err
+	}
+
+	outboxEvent, err := s.workerRepository.AddOutboxEvent(ctx, tx, &model.OutboxEvent{	AggregateID: accountID,
+																						TenantID: tenantID,
+																						EventType: eventType,
+																						Payload: payload })
+	if err != nil {
+		return err
+	}
+
+	// the webhooks of the tenant receive the same event
+	_, err = s.workerRepository.AddWebhookDelivery(ctx, tx, outboxEvent)
+	return err
+}
+
+// About publish the events in order, after a failure the next events of the same account are
+// kept for the next run (ordering per account), it returns the published ids and the failures
+func publishBatch(ctx context.Context, publisher EventPublisher, list_event []model.OutboxEvent) ([]int64, map[int64]error) {
+	published := []int64{}
+	failed := map[int64]error{}
+	blocked := map[string]bool{}
+
+	for _, outboxEvent := range list_event {
+		if blocked[outboxEvent.AggregateID] {
+			continue
+		}
+		if err := publisher.Publish(ctx, outboxEvent); err != nil {
+			failed[outboxEvent.ID] = err
+			blocked[outboxEvent.AggregateID] = true
+			continue
+		}
+		published = append(published, outboxEvent.ID)
+	}
+
+	return published, failed
+}
+
+// About publish a batch of claimed events. The claim and the marks are short transactions, the publish runs
+// out of them (a slow broker holds no connection nor lock). The events are marked as published after the delivery,
+// so a failure delivers them again (at-least-once), after OUTBOX_MAX_ATTEMPTS failures an event is parked
+func (s *WorkerService) OutboxRelay(ctx context.Context, publisher EventPublisher, outboxConfig *model.OutboxConfig) (int, error){
+	childLogger.Debug().Str("func","OutboxRelay").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.OutboxRelay")
+
+	lease := time.Duration(outboxConfig.ClaimLease) * time.Second
+	list_event, err := s.workerRepository.ClaimOutboxEvent(ctx, outboxConfig.BatchSize, lease)
+	if err != nil || len(*list_event) == 0 {
+		span.End()
+		return 0, err
+	}
+
+	published, failed := publishBatch(ctx, publisher, *list_event)
+
+	// the events waiting behind a failure of the same account go back to pending
+	done := map[int64]bool{}
+	for _, id := range published {
+		done[id] = true
+	}
+	released := []int64{}
+	for _, outboxEvent := range *list_event {
+		if !done[outboxEvent.ID] && failed[outboxEvent.ID] == nil {
+			released = append(released, outboxEvent.ID)
+		}
+	}
+
+	// Get the database connection
+	tx, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		span.End()
+		return 0, err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			tx.Commit(ctx)
+		}
+		span.End()
+	}()
+
+	for _, outboxEvent := range *list_event {
+		errPublish := failed[outboxEvent.ID]
+		if errPublish == nil {
+			continue
+		}
+		if outboxConfig.MaxAttempts > 0 && outboxEvent.Attempts + 1 >= outboxConfig.MaxAttempts {
+			childLogger.Error().Err(errPublish).Int64("id", outboxEvent.ID).Str("aggregate_id", outboxEvent.AggregateID).Int("attempts", outboxEvent.Attempts + 1).Msg("outbox event parked")
+		} else {
+			childLogger.Error().Err(errPublish).Int64("id", outboxEvent.ID).Msg("error publish outbox event")
+		}
+		_, err = s.workerRepository.MarkOutboxEventFailed(ctx, tx, outboxEvent.ID, errPublish.Error(), outboxConfig.MaxAttempts)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if len(published) > 0 {
+		_, err = s.workerRepository.MarkOutboxEventPublished(ctx, tx, published)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if len(released) > 0 {
+		_, err = s.workerRepository.ReleaseOutboxEvent(ctx, tx, released)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(published), nil
+}
+
+// About relay the outbox on each tick until the context is done, a full batch is followed by the next one
+func (s *WorkerService) OutboxRelayJob(ctx context.Context, publisher EventPublisher, interval time.Duration, outboxConfig *model.OutboxConfig){
+	childLogger.Info().Str("func","OutboxRelayJob").Interface("interval", interval).Int("batchSize", outboxConfig.BatchSize).Int("maxAttempts", outboxConfig.MaxAttempts).Send()
+
+	// the job runs for all the tenants
+	ctx = auth.WithTenantBypass(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			childLogger.Info().Msg("stop outbox relay job !!!")
+			return
+		case <-ticker.C:
+			for {
+				count, err := s.OutboxRelay(ctx, publisher, outboxConfig)
+				if err != nil {
+					childLogger.Error().Err(err).Msg("error outbox relay job")
+					break
+				}
+				if count < outboxConfig.BatchSize || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
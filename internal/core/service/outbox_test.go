@@ -0,0 +1,147 @@
+package service
+
+import (
+	"time"
+	"errors"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-account/internal/core/model"
+)
+
+type fakePublisher struct {
+	fail		map[int64]bool
+	published	[]int64
+}
+
+func (f *fakePublisher) Publish(ctx context.Context, outboxEvent model.OutboxEvent) error {
+	if f.fail[outboxEvent.ID] {
+		return errors.New("broker unavailable")
+	}
+	f.published = append(f.published, outboxEvent.ID)
+	return nil
+}
+
+// after a failure the next events of the same account wait, the other accounts go on
+func Test_publishBatch(t *testing.T){
+	list_event := []model.OutboxEvent{
+		{ID: 1, AggregateID: "ACC-1"},
+		{ID: 2, AggregateID: "ACC-2"},
+		{ID: 3, AggregateID: "ACC-1"},
+		{ID: 4, AggregateID: "ACC-2"},
+		{ID: 5, AggregateID: "ACC-3"},
+	}
+	publisher := &fakePublisher{fail: map[int64]bool{2: true}}
+
+	published, failed := publishBatch(bypassContext(), publisher, list_event)
+
+	if want := []int64{1, 3, 5}; !reflect.DeepEqual(published, want) {
+		t.Errorf("published %v want %v", published, want)
+	}
+	if !reflect.DeepEqual(publisher.published, published) {
+		t.Errorf("delivered %v want %v", publisher.published, published)
+	}
+	if len(failed) != 1 || failed[2] == nil {
+		t.Errorf("failed %v want only the event 2", failed)
+	}
+}
+
+// a failed event stays pending with the next events of its account, a new run delivers them in order
+func Test_OutboxRelay(t *testing.T){
+	ctx := bypassContext()
+	workerService, repository := newMemoryService(t)
+	addTestAccount(t, workerService, "ACC-1", "P-1", "CHECKING")
+	if _, err := workerService.AddPosting(ctx, &model.AccountStatement{AccountID: "ACC-1", Type: StatementCredit, Currency: "BRL", Amount: 10}); err != nil {
+		t.Fatal(err)
+	}
+	addTestAccount(t, workerService, "ACC-2", "P-2", "CHECKING")
+
+	tests := []struct {
+		name		string
+		fail		map[int64]bool
+		batchSize	int
+		published	[]int64
+		pending		int
+	}{
+		{"first event fails", map[int64]bool{1: true}, 10, []int64{3}, 2},
+		{"batch size", nil, 1, []int64{1}, 1},
+		{"the rest", nil, 10, []int64{2}, 0},
+		{"nothing pending", nil, 10, []int64{}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &fakePublisher{fail: tt.fail, published: []int64{}}
+			count, err := workerService.OutboxRelay(ctx, publisher, &model.OutboxConfig{BatchSize: tt.batchSize, MaxAttempts: 10, ClaimLease: 60})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != len(tt.published) || !reflect.DeepEqual(publisher.published, tt.published) {
+				t.Errorf("published %v (%d) want %v", publisher.published, count, tt.published)
+			}
+			if got := len(pendingEvents(t, repository)); got != tt.pending {
+				t.Errorf("pending %d want %d", got, tt.pending)
+			}
+		})
+	}
+}
+
+// after the max attempts the event is parked, the next events of its account go on
+func Test_OutboxRelayParked(t *testing.T){
+	ctx := bypassContext()
+	workerService, repository := newMemoryService(t)
+	addTestAccount(t, workerService, "ACC-1", "P-1", "CHECKING")
+	if _, err := workerService.AddPosting(ctx, &model.AccountStatement{AccountID: "ACC-1", Type: StatementCredit, Currency: "BRL", Amount: 10}); err != nil {
+		t.Fatal(err)
+	}
+	outboxConfig := &model.OutboxConfig{BatchSize: 10, MaxAttempts: 2, ClaimLease: 60}
+
+	tests := []struct {
+		name		string
+		fail		map[int64]bool
+		published	[]int64
+		pending		int
+	}{
+		{"first attempt", map[int64]bool{1: true}, []int64{}, 2},
+		{"parked", map[int64]bool{1: true}, []int64{}, 1},
+		{"after the parked", map[int64]bool{1: true}, []int64{2}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &fakePublisher{fail: tt.fail, published: []int64{}}
+			count, err := workerService.OutboxRelay(ctx, publisher, outboxConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != len(tt.published) || !reflect.DeepEqual(publisher.published, tt.published) {
+				t.Errorf("published %v (%d) want %v", publisher.published, count, tt.published)
+			}
+			if got := len(pendingEvents(t, repository)); got != tt.pending {
+				t.Errorf("pending %d want %d", got, tt.pending)
+			}
+		})
+	}
+}
+
+// a claimed event is not claimed again until its lease expires, nor the next events of its account
+func Test_ClaimOutboxEvent(t *testing.T){
+	ctx := bypassContext()
+	workerService, repository := newMemoryService(t)
+	addTestAccount(t, workerService, "ACC-1", "P-1", "CHECKING")
+	addTestAccount(t, workerService, "ACC-2", "P-2", "CHECKING")
+
+	list_event, err := repository.ClaimOutboxEvent(ctx, 1, time.Minute)
+	if err != nil || len(*list_event) != 1 || (*list_event)[0].AggregateID != "ACC-1" {
+		t.Fatalf("claimed %+v err %v", list_event, err)
+	}
+	if _, err := workerService.AddPosting(ctx, &model.AccountStatement{AccountID: "ACC-1", Type: StatementCredit, Currency: "BRL", Amount: 10}); err != nil {
+		t.Fatal(err)
+	}
+
+	list_event, err = repository.ClaimOutboxEvent(ctx, 10, time.Minute)
+	if err != nil || len(*list_event) != 1 || (*list_event)[0].AggregateID != "ACC-2" {
+		t.Errorf("claimed %+v err %v", list_event, err)
+	}
+}
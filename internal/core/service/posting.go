@@ -0,0 +1,397 @@
+package service
+
+import (
+	"fmt"
+	"context"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/event"
+)
+
+const (
+	StatementCredit		= "CREDIT"
+	StatementDebit		= "DEBIT"
+	OperationPosting	= "POSTING"
+	OperationTransfer	= "TRANSFER"
+	TransferStatusDone	= "DONE"
+)
+
+// About write a statement and apply its amount into the (locked) balance, a debit can use the product overdraft
+func (s *WorkerService) postStatement(ctx context.Context,
+									tx port.Tx,
+									accountBalance *model.AccountBalance,
+									accountStatement *model.AccountStatement,
+									overdraft float64) error {
+	childLogger.Info().Str("func","postStatement").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("accountStatement", accountStatement).Send()
+
+	if accountStatement.Amount < 0 && accountBalance.Amount + accountStatement.Amount < -overdraft {
+		return erro.ErrInsufficientFunds
+	}
+
+	setStatementBalance(accountStatement, accountBalance)
+
+	_, err := s.workerRepository.AddAccountStatement(ctx, tx, accountStatement)
+	if err != nil {
+		return err
+	}
+
+	return s.applyStatement(ctx, tx, accountBalance, accountStatement)
+}
+
+// About write a statement unless the account already has one with its transaction id (maintenance fees),
+// it returns false when it was already written and then the balance is not changed
+func (s *WorkerService) postStatementOnce(ctx context.Context,
+										tx port.Tx,
+										accountBalance *model.AccountBalance,
+										accountStatement *model.AccountStatement,
+										overdraft float64) (bool, error) {
+	childLogger.Info().Str("func","postStatementOnce").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("accountStatement", accountStatement).Send()
+
+	setStatementBalance(accountStatement, accountBalance)
+
+	added, err := s.workerRepository.AddAccountStatementOnce(ctx, tx, accountStatement)
+	if err != nil || !added {
+		return false, err
+	}
+
+	// the funds are checked after the insert, so a written statement is not reported as insufficient funds
+	if accountStatement.Amount < 0 && accountBalance.Amount + accountStatement.Amount < -overdraft {
+		return false, erro.ErrInsufficientFunds
+	}
+
+	if err := s.applyStatement(ctx, tx, accountBalance, accountStatement); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// About the account, currency and tenant of a statement are the ones of its balance
+func setStatementBalance(accountStatement *model.AccountStatement, accountBalance *model.AccountBalance) {
+	accountStatement.FkAccountID = accountBalance.FkAccountID
+	accountStatement.AccountID = accountBalance.AccountID
+	accountStatement.Currency = accountBalance.Currency
+	accountStatement.TenantID = accountBalance.TenantID
+}
+
+// About apply the amount of a written statement into the (locked) balance and add its event
+func (s *WorkerService) applyStatement(ctx context.Context,
+										tx port.Tx,
+										accountBalance *model.AccountBalance,
+										accountStatement *model.AccountStatement) error {
+	balanceDelta := model.AccountBalance{	ID: accountBalance.ID,
+											Amount: accountStatement.Amount,
+											TransactionID: accountStatement.TransactionID }
+	res_update, err := s.workerRepository.UpdateAccountBalanceAmount(ctx, tx, &balanceDelta)
+	if err != nil {
+		return err
+	}
+	if (res_update == 0) {
+		return erro.ErrUpdate
+	}
+
+	accountBalance.Amount = roundAmount(accountBalance.Amount + accountStatement.Amount, 2)
+	accountBalance.UpdatedAt = balanceDelta.UpdatedAt
+
+	return s.addEvent(ctx, tx, event.TypeBalancePosted, accountBalance.AccountID, accountBalance.TenantID, event.NewBalancePosted(accountStatement, accountBalance))
+}
+
+// About charge the fee of an operation as a separated FEE statement
+func (s *WorkerService) postFee(ctx context.Context,
+								tx port.Tx,
+								accountBalance *model.AccountBalance,
+								feePreview *model.FeePreview,
+								transactionID *string,
+								overdraft float64) (*model.AccountStatement, error){
+	if feePreview.Fee <= 0 {
+		return nil, nil
+	}
+
+	accountStatement := feeStatement(feePreview, transactionID)
+
+	err := s.postStatement(ctx, tx, accountBalance, accountStatement, overdraft)
+	if err != nil {
+		return nil, err
+	}
+
+	return accountStatement, nil
+}
+
+// About the FEE statement of an operation, its transaction id is the one of the operation prefixed by FEE
+func feeStatement(feePreview *model.FeePreview, transactionID *string) *model.AccountStatement {
+	var feeTransactionID *string
+	if transactionID != nil {
+		id := fmt.Sprintf("%s-%s", StatementFee, *transactionID)
+		feeTransactionID = &id
+	}
+
+	return &model.AccountStatement{	Type: StatementFee,
+									Amount: -feePreview.Fee,
+									TransactionID: feeTransactionID,
+									Obs: feePreview.Operation }
+}
+
+// About post a credit or debit into an account balance, with its fee
+func (s *WorkerService) AddPosting(ctx context.Context, accountStatement *model.AccountStatement) (*model.MovimentAccount, error){
+	childLogger.Info().Str("func","AddPosting").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("accountStatement", accountStatement).Send()
+
+	return s.addPosting(ctx, accountStatement, nil)
+}
+
+// About the posting path, inTx (optional) runs first in the transaction of the posting and its error rolls it back.
+// The error of the commit is returned, so a nil error means the posting is durable
+func (s *WorkerService) addPosting(ctx context.Context,
+									accountStatement *model.AccountStatement,
+									inTx func(ctx context.Context, tx port.Tx) error) (res *model.MovimentAccount, err error){
+	// Trace
+	span := tracerProvider.Span(ctx, "service.AddPosting")
+
+	// Check the amount signal against the type
+	switch accountStatement.Type {
+	case StatementCredit:
+		if accountStatement.Amount <= 0 {
+			span.End()
+			return nil, erro.ErrInvalidAmount
+		}
+	case StatementDebit:
+		if accountStatement.Amount >= 0 {
+			span.End()
+			return nil, erro.ErrInvalidAmount
+		}
+	default:
+		span.End()
+		return nil, erro.ErrTransInvalid
+	}
+
+	// Get account (check if exists)
+	account := model.Account{AccountID: accountStatement.AccountID}
+	res_account, err := s.workerRepository.GetAccount(ctx, &account)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+	if res_account.Status == AccountBlocked {
+		span.End()
+		return nil, erro.ErrAccountBlocked
+	}
+
+	// Check the product rules
+	res_product, err := s.getAccountProductRules(ctx, res_account)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+	err = checkProductCurrency(res_product, accountStatement.Currency)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Get the fee before opening the transaction
+	feePreview := model.FeePreview{	TenantID: res_account.TenantID,
+									ProductID: res_account.ProductID,
+									Operation: OperationPosting,
+									Currency: accountStatement.Currency,
+									Amount: accountStatement.Amount }
+	res_fee, err := s.PreviewFee(ctx, &feePreview)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Get the database connection
+	tx, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else if err = tx.Commit(ctx); err != nil {
+			res = nil
+		}
+		span.End()
+	}()
+
+	if inTx != nil {
+		err = inTx(ctx, tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Lock the balance
+	accountBalance := model.AccountBalance{	FkAccountID: res_account.ID,
+											Currency: accountStatement.Currency }
+	res_balance, err := s.workerRepository.GetAccountBalanceForUpdate(ctx, tx, &accountBalance)
+	if err != nil {
+		return nil, err
+	}
+
+	// Post the amount and the fee
+	err = s.postStatement(ctx, tx, res_balance, accountStatement, res_product.Overdraft)
+	if err != nil {
+		return nil, err
+	}
+	res_statement_fee, err := s.postFee(ctx, tx, res_balance, res_fee, accountStatement.TransactionID, res_product.Overdraft)
+	if err != nil {
+		return nil, err
+	}
+
+	list_statement := []model.AccountStatement{*accountStatement}
+	if res_statement_fee != nil {
+		list_statement = append(list_statement, *res_statement_fee)
+	}
+
+	return &model.MovimentAccount{	AccountBalance: res_balance,
+									AccountStatement: &list_statement }, nil
+}
+
+// About transfer an amount between two account balances, the fee is charged from the debited account.
+// The error of the commit is returned, so a nil error means the transfer is durable
+func (s *WorkerService) Transfer(ctx context.Context, transfer *model.Transfer) (res *model.Transfer, err error){
+	childLogger.Info().Str("func","Transfer").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("transfer", transfer).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.Transfer")
+
+	if transfer.Amount <= 0 {
+		span.End()
+		return nil, erro.ErrInvalidAmount
+	}
+	if transfer.AccountFrom.AccountID == transfer.AccountTo.AccountID {
+		span.End()
+		return nil, erro.ErrTransInvalid
+	}
+
+	// Get accounts (check if exists)
+	res_account_from, err := s.workerRepository.GetAccount(ctx, &model.Account{AccountID: transfer.AccountFrom.AccountID})
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+	res_account_to, err := s.workerRepository.GetAccount(ctx, &model.Account{AccountID: transfer.AccountTo.AccountID})
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+	if res_account_from.Status == AccountBlocked || res_account_to.Status == AccountBlocked {
+		span.End()
+		return nil, erro.ErrAccountBlocked
+	}
+
+	// Check the product rules of both sides
+	res_product_from, err := s.getAccountProductRules(ctx, res_account_from)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+	res_product_to, err := s.getAccountProductRules(ctx, res_account_to)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+	if err = checkProductCurrency(res_product_from, transfer.Currency); err == nil {
+		err = checkProductCurrency(res_product_to, transfer.Currency)
+	}
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Get the fee before opening the transaction
+	feePreview := model.FeePreview{	TenantID: res_account_from.TenantID,
+									ProductID: res_account_from.ProductID,
+									Operation: OperationTransfer,
+									Currency: transfer.Currency,
+									Amount: transfer.Amount }
+	res_fee, err := s.PreviewFee(ctx, &feePreview)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Get the database connection
+	tx, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else if err = tx.Commit(ctx); err != nil {
+			res = nil
+		}
+		span.End()
+	}()
+
+	// Lock both balances, always in the same order to avoid deadlocks
+	balance_from := model.AccountBalance{FkAccountID: res_account_from.ID, Currency: transfer.Currency}
+	balance_to := model.AccountBalance{FkAccountID: res_account_to.ID, Currency: transfer.Currency}
+
+	var res_balance_from, res_balance_to *model.AccountBalance
+	if res_account_from.ID < res_account_to.ID {
+		res_balance_from, err = s.workerRepository.GetAccountBalanceForUpdate(ctx, tx, &balance_from)
+		if err != nil {
+			return nil, err
+		}
+		res_balance_to, err = s.workerRepository.GetAccountBalanceForUpdate(ctx, tx, &balance_to)
+	} else {
+		res_balance_to, err = s.workerRepository.GetAccountBalanceForUpdate(ctx, tx, &balance_to)
+		if err != nil {
+			return nil, err
+		}
+		res_balance_from, err = s.workerRepository.GetAccountBalanceForUpdate(ctx, tx, &balance_from)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the transfer
+	transfer.AccountFrom = *res_balance_from
+	transfer.AccountTo = *res_balance_to
+	transfer.Type = OperationTransfer
+	transfer.Status = TransferStatusDone
+	_, err = s.workerRepository.AddTransfer(ctx, tx, transfer)
+	if err != nil {
+		return nil, err
+	}
+	transactionID := fmt.Sprintf("%s-%d", OperationTransfer, transfer.ID)
+
+	// Post both sides and the fee
+	statement_debit := model.AccountStatement{	Type: StatementDebit,
+												Amount: -transfer.Amount,
+												TransactionID: &transactionID }
+	err = s.postStatement(ctx, tx, res_balance_from, &statement_debit, res_product_from.Overdraft)
+	if err != nil {
+		return nil, err
+	}
+	statement_credit := model.AccountStatement{	Type: StatementCredit,
+												Amount: transfer.Amount,
+												TransactionID: &transactionID }
+	err = s.postStatement(ctx, tx, res_balance_to, &statement_credit, res_product_to.Overdraft)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.postFee(ctx, tx, res_balance_from, res_fee, &transactionID, res_product_from.Overdraft)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer.AccountFrom = *res_balance_from
+	transfer.AccountTo = *res_balance_to
+
+	err = s.addEvent(ctx, tx, event.TypeTransferCompleted, transfer.AccountFrom.AccountID, transfer.AccountFrom.TenantID, event.NewTransferCompleted(transfer))
+	if err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
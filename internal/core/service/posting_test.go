@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/model"
+)
+
+// a flat fee of 1 per posting in BRL for the tenant and 2 per transfer for the checking product
+func putTestFees(t *testing.T, repository interface{ PutFeeSchedule(context.Context, model.FeeSchedule) error }) {
+	t.Helper()
+	list_fee := []model.FeeSchedule{
+		{TenantID: testTenant, Operation: OperationPosting, Method: FeeMethodFlat, Currency: "BRL", Amount: 1},
+		{TenantID: testTenant, Operation: OperationTransfer, Method: FeeMethodFlat, Currency: "BRL", Amount: 5},
+		{TenantID: testTenant, ProductID: "CHECKING", Operation: OperationTransfer, Method: FeeMethodFlat, Currency: "BRL", Amount: 2},
+	}
+	for _, feeSchedule := range list_fee {
+		if err := repository.PutFeeSchedule(bypassContext(), feeSchedule); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func Test_AddPosting(t *testing.T){
+	tests := []struct {
+		name		string
+		fee			bool
+		statement	model.AccountStatement
+		balance		float64
+		statements	int
+		err			error
+	}{
+		{"credit", false, model.AccountStatement{AccountID: "ACC-1", Type: StatementCredit, Currency: "BRL", Amount: 50}, 150, 2, nil},
+		{"debit", false, model.AccountStatement{AccountID: "ACC-1", Type: StatementDebit, Currency: "BRL", Amount: -30}, 70, 2, nil},
+		{"debit into the overdraft", false, model.AccountStatement{AccountID: "ACC-1", Type: StatementDebit, Currency: "BRL", Amount: -200}, -100, 2, nil},
+		{"debit over the overdraft", false, model.AccountStatement{AccountID: "ACC-1", Type: StatementDebit, Currency: "BRL", Amount: -200.01}, 100, 1, erro.ErrInsufficientFunds},
+		{"debit without overdraft", false, model.AccountStatement{AccountID: "ACC-2", Type: StatementDebit, Currency: "BRL", Amount: -0.01}, 0, 0, erro.ErrInsufficientFunds},
+		{"positive debit", false, model.AccountStatement{AccountID: "ACC-1", Type: StatementDebit, Currency: "BRL", Amount: 10}, 100, 1, erro.ErrInvalidAmount},
+		{"negative credit", false, model.AccountStatement{AccountID: "ACC-1", Type: StatementCredit, Currency: "BRL", Amount: -10}, 100, 1, erro.ErrInvalidAmount},
+		{"unknown type", false, model.AccountStatement{AccountID: "ACC-1", Type: "REFUND", Currency: "BRL", Amount: 10}, 100, 1, erro.ErrTransInvalid},
+		{"currency of the product", false, model.AccountStatement{AccountID: "ACC-1", Type: StatementCredit, Currency: "USD", Amount: 10}, 100, 2, nil},
+		{"currency out of the product", false, model.AccountStatement{AccountID: "ACC-2", Type: StatementCredit, Currency: "USD", Amount: 10}, 0, 0, erro.ErrCurrency},
+		{"unknown account", false, model.AccountStatement{AccountID: "ACC-9", Type: StatementCredit, Currency: "BRL", Amount: 10}, 100, 1, erro.ErrNotFound},
+		{"credit with fee", true, model.AccountStatement{AccountID: "ACC-1", Type: StatementCredit, Currency: "BRL", Amount: 50}, 149, 3, nil},
+		{"debit with fee", true, model.AccountStatement{AccountID: "ACC-1", Type: StatementDebit, Currency: "BRL", Amount: -199}, -100, 3, nil},
+		{"fee over the overdraft", true, model.AccountStatement{AccountID: "ACC-1", Type: StatementDebit, Currency: "BRL", Amount: -199.5}, 100, 1, erro.ErrInsufficientFunds},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := bypassContext()
+			workerService, repository := newMemoryService(t)
+			addTestAccount(t, workerService, "ACC-1", "P-1", "CHECKING")
+			addTestAccount(t, workerService, "ACC-2", "P-1", "SAVINGS")
+			if _, err := workerService.AddPosting(ctx, &model.AccountStatement{AccountID: "ACC-1", Type: StatementCredit, Currency: "BRL", Amount: 100}); err != nil {
+				t.Fatal(err)
+			}
+			if tt.fee {
+				putTestFees(t, repository)
+			}
+
+			res, err := workerService.AddPosting(ctx, &tt.statement)
+			if err != tt.err {
+				t.Fatalf("err %v want %v", err, tt.err)
+			}
+			if err == nil && (res.AccountBalance == nil || len(*res.AccountStatement) != tt.statements - 1) {
+				t.Errorf("moviment %+v", res)
+			}
+
+			currency := tt.statement.Currency
+			if tt.err == erro.ErrCurrency || tt.err == erro.ErrNotFound {
+				currency = "BRL"
+			}
+			accountID := tt.statement.AccountID
+			if tt.err == erro.ErrNotFound {
+				accountID = "ACC-1"
+			}
+			if got := balanceOf(t, workerService, accountID, currency); got != tt.balance && currency == "BRL" {
+				t.Errorf("balance %v want %v", got, tt.balance)
+			}
+
+			res_statement, err := repository.ListAccountStatementByAccountIDs(ctx, []string{accountID}, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(res_statement[accountID]); got != tt.statements {
+				t.Errorf("statements %d want %d", got, tt.statements)
+			}
+		})
+	}
+}
+
+func Test_Transfer(t *testing.T){
+	tests := []struct {
+		name		string
+		fee			bool
+		transfer	model.Transfer
+		from		float64
+		to			float64
+		err			error
+	}{
+		{"transfer", false, model.Transfer{AccountFrom: model.AccountBalance{AccountID: "ACC-1"}, AccountTo: model.AccountBalance{AccountID: "ACC-2"}, Currency: "BRL", Amount: 40}, 60, 40, nil},
+		{"transfer into the overdraft", false, model.Transfer{AccountFrom: model.AccountBalance{AccountID: "ACC-1"}, AccountTo: model.AccountBalance{AccountID: "ACC-2"}, Currency: "BRL", Amount: 200}, -100, 200, nil},
+		{"transfer to a lower id", false, model.Transfer{AccountFrom: model.AccountBalance{AccountID: "ACC-3"}, AccountTo: model.AccountBalance{AccountID: "ACC-1"}, Currency: "BRL", Amount: 10}, -10, 110, nil},
+		{"transfer with fee", true, model.Transfer{AccountFrom: model.AccountBalance{AccountID: "ACC-1"}, AccountTo: model.AccountBalance{AccountID: "ACC-2"}, Currency: "BRL", Amount: 40}, 58, 40, nil},
+		{"fee over the overdraft", true, model.Transfer{AccountFrom: model.AccountBalance{AccountID: "ACC-1"}, AccountTo: model.AccountBalance{AccountID: "ACC-2"}, Currency: "BRL", Amount: 199}, 100, 0, erro.ErrInsufficientFunds},
+		{"insufficient funds", false, model.Transfer{AccountFrom: model.AccountBalance{AccountID: "ACC-2"}, AccountTo: model.AccountBalance{AccountID: "ACC-1"}, Currency: "BRL", Amount: 1}, 0, 100, erro.ErrInsufficientFunds},
+		{"same account", false, model.Transfer{AccountFrom: model.AccountBalance{AccountID: "ACC-1"}, AccountTo: model.AccountBalance{AccountID: "ACC-1"}, Currency: "BRL", Amount: 1}, 100, 100, erro.ErrTransInvalid},
+		{"zero amount", false, model.Transfer{AccountFrom: model.AccountBalance{AccountID: "ACC-1"}, AccountTo: model.AccountBalance{AccountID: "ACC-2"}, Currency: "BRL", Amount: 0}, 100, 0, erro.ErrInvalidAmount},
+		{"currency out of the product", false, model.Transfer{AccountFrom: model.AccountBalance{AccountID: "ACC-1"}, AccountTo: model.AccountBalance{AccountID: "ACC-2"}, Currency: "USD", Amount: 1}, 100, 0, erro.ErrCurrency},
+		{"unknown account", false, model.Transfer{AccountFrom: model.AccountBalance{AccountID: "ACC-1"}, AccountTo: model.AccountBalance{AccountID: "ACC-9"}, Currency: "BRL", Amount: 1}, 100, 0, erro.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := bypassContext()
+			workerService, repository := newMemoryService(t)
+			addTestAccount(t, workerService, "ACC-1", "P-1", "CHECKING")
+			addTestAccount(t, workerService, "ACC-2", "P-2", "SAVINGS")
+			addTestAccount(t, workerService, "ACC-3", "P-3", "CHECKING")
+			if _, err := workerService.AddPosting(ctx, &model.AccountStatement{AccountID: "ACC-1", Type: StatementCredit, Currency: "BRL", Amount: 100}); err != nil {
+				t.Fatal(err)
+			}
+			if tt.fee {
+				putTestFees(t, repository)
+			}
+			from, to := tt.transfer.AccountFrom.AccountID, tt.transfer.AccountTo.AccountID
+
+			res, err := workerService.Transfer(ctx, &tt.transfer)
+			if err != tt.err {
+				t.Fatalf("err %v want %v", err, tt.err)
+			}
+			if err == nil && (res.ID == 0 || res.Status != TransferStatusDone) {
+				t.Errorf("transfer %+v", res)
+			}
+
+			if got := balanceOf(t, workerService, from, "BRL"); got != tt.from {
+				t.Errorf("balance from %v want %v", got, tt.from)
+			}
+			if to != "ACC-9" && to != from {
+				if got := balanceOf(t, workerService, to, "BRL"); got != tt.to {
+					t.Errorf("balance to %v want %v", got, tt.to)
+				}
+			}
+
+			res_transfer, err := repository.ListTransferByAccountIDs(ctx, []string{from}, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := len(res_transfer[from]), map[bool]int{true: 1, false: 0}[tt.err == nil]; got != want {
+				t.Errorf("transfers %d want %d", got, want)
+			}
+		})
+	}
+}
+
+func Test_AccountBlocked(t *testing.T){
+	ctx := bypassContext()
+	workerService, _ := newMemoryService(t)
+	addTestAccount(t, workerService, "ACC-1", "P-1", "CHECKING")
+	addTestAccount(t, workerService, "ACC-2", "P-2", "CHECKING")
+	if _, err := workerService.AddPosting(ctx, &model.AccountStatement{AccountID: "ACC-1", Type: StatementCredit, Currency: "BRL", Amount: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	setStatus := func(status string) {
+		t.Helper()
+		res, err := workerService.PatchAccount(ctx, &model.AccountPatch{AccountID: "ACC-2", Status: &status})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Status != status {
+			t.Fatalf("status %q want %q", res.Status, status)
+		}
+	}
+
+	setStatus(AccountBlocked)
+	if _, err := workerService.AddPosting(ctx, &model.AccountStatement{AccountID: "ACC-2", Type: StatementCredit, Currency: "BRL", Amount: 10}); err != erro.ErrAccountBlocked {
+		t.Errorf("posting err %v want %v", err, erro.ErrAccountBlocked)
+	}
+	for _, transfer := range []model.Transfer{
+		{AccountFrom: model.AccountBalance{AccountID: "ACC-1"}, AccountTo: model.AccountBalance{AccountID: "ACC-2"}, Currency: "BRL", Amount: 10},
+		{AccountFrom: model.AccountBalance{AccountID: "ACC-2"}, AccountTo: model.AccountBalance{AccountID: "ACC-1"}, Currency: "BRL", Amount: 10},
+	} {
+		if _, err := workerService.Transfer(ctx, &transfer); err != erro.ErrAccountBlocked {
+			t.Errorf("transfer %s to %s err %v want %v", transfer.AccountFrom.AccountID, transfer.AccountTo.AccountID, err, erro.ErrAccountBlocked)
+		}
+	}
+	if got := balanceOf(t, workerService, "ACC-1", "BRL"); got != 100 {
+		t.Errorf("balance %v want 100", got)
+	}
+
+	setStatus(AccountActive)
+	if _, err := workerService.AddPosting(ctx, &model.AccountStatement{AccountID: "ACC-2", Type: StatementCredit, Currency: "BRL", Amount: 10}); err != nil {
+		t.Errorf("posting after unblock err %v", err)
+	}
+}
+
+func Test_PreviewFee(t *testing.T){
+	workerService, repository := newMemoryService(t)
+	putTestFees(t, repository)
+
+	tests := []struct {
+		name		string
+		feePreview	model.FeePreview
+		fee			float64
+		schedule	bool
+	}{
+		{"tenant schedule", model.FeePreview{TenantID: testTenant, Operation: OperationPosting, Currency: "BRL", Amount: 10}, 1, true},
+		{"product schedule", model.FeePreview{TenantID: testTenant, ProductID: "CHECKING", Operation: OperationTransfer, Currency: "BRL", Amount: 10}, 2, true},
+		{"product without schedule", model.FeePreview{TenantID: testTenant, ProductID: "SAVINGS", Operation: OperationTransfer, Currency: "BRL", Amount: 10}, 5, true},
+		{"currency without schedule", model.FeePreview{TenantID: testTenant, Operation: OperationPosting, Currency: "USD", Amount: 10}, 0, false},
+		{"tenant without schedule", model.FeePreview{TenantID: "tenant-2", Operation: OperationPosting, Currency: "BRL", Amount: 10}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := workerService.PreviewFee(bypassContext(), &tt.feePreview)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Fee != tt.fee || (res.FeeSchedule != nil) != tt.schedule {
+				t.Errorf("fee %v schedule %+v, want %v", res.Fee, res.FeeSchedule, tt.fee)
+			}
+		})
+	}
+}
+
+func Test_ListByAccountIDs(t *testing.T){
+	ctx := bypassContext()
+	workerService, _ := newMemoryService(t)
+	addTestAccount(t, workerService, "ACC-1", "P-1", "CHECKING")
+	addTestAccount(t, workerService, "ACC-2", "P-2", "SAVINGS")
+	for _, amount := range []float64{10, 20, 30} {
+		if _, err := workerService.AddPosting(ctx, &model.AccountStatement{AccountID: "ACC-1", Type: StatementCredit, Currency: "BRL", Amount: amount}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := workerService.Transfer(ctx, &model.Transfer{AccountFrom: model.AccountBalance{AccountID: "ACC-1"}, AccountTo: model.AccountBalance{AccountID: "ACC-2"}, Currency: "BRL", Amount: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name		string
+		ctx			context.Context
+		accountIDs	[]string
+		balances	map[string]int
+		statements	map[string]int
+		transfers	map[string]int
+	}{
+		{"accounts", ctx, []string{"ACC-1", "ACC-2", "ACC-9"}, map[string]int{"ACC-1": 2, "ACC-2": 1}, map[string]int{"ACC-1": 2, "ACC-2": 1}, map[string]int{"ACC-1": 1, "ACC-2": 1}},
+		{"other tenant", tenantContext("tenant-2"), []string{"ACC-1", "ACC-2"}, map[string]int{}, map[string]int{}, map[string]int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res_balance, err := workerService.ListAccountBalanceByAccountIDs(tt.ctx, tt.accountIDs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res_statement, err := workerService.ListAccountStatementByAccountIDs(tt.ctx, tt.accountIDs, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res_transfer, err := workerService.ListTransferByAccountIDs(tt.ctx, tt.accountIDs, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, accountID := range tt.accountIDs {
+				if len(res_balance[accountID]) != tt.balances[accountID] {
+					t.Errorf("%s balances %d want %d", accountID, len(res_balance[accountID]), tt.balances[accountID])
+				}
+				if len(res_statement[accountID]) != tt.statements[accountID] {
+					t.Errorf("%s statements %d want %d", accountID, len(res_statement[accountID]), tt.statements[accountID])
+				}
+				if len(res_transfer[accountID]) != tt.transfers[accountID] {
+					t.Errorf("%s transfers %d want %d", accountID, len(res_transfer[accountID]), tt.transfers[accountID])
+				}
+			}
+			if list_statement := res_statement["ACC-1"]; len(list_statement) > 0 && list_statement[0].Amount != -5 {
+				t.Errorf("the last statement first %+v", list_statement)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"time"
+	"context"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+)
+
+const (
+	ProductChecking	= "CHECKING"
+	ProductSavings	= "SAVINGS"
+	ProductWallet	= "WALLET"
+)
+
+// About add (or replace) an account product with its interest rate and fee schedule in the same transaction.
+// An informed interest_rate replaces the rate of the product and an informed fee_schedule (even empty) replaces
+// the schedules bound to the product, an omitted one keeps what is there.
+// The error of the commit is returned, so a nil error means the product is durable
+func (s *WorkerService) AddAccountProduct(ctx context.Context, accountProduct *model.AccountProduct) (res *model.AccountProduct, err error){
+	childLogger.Info().Str("func","AddAccountProduct").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("accountProduct", accountProduct).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.AddAccountProduct")
+
+	switch accountProduct.Type {
+	case ProductChecking, ProductSavings, ProductWallet:
+	default:
+		span.End()
+		return nil, erro.ErrBadRequest
+	}
+	if accountProduct.ProductID == "" || accountProduct.TenantID == "" || len(accountProduct.Currencies) == 0 || accountProduct.Overdraft < 0 {
+		span.End()
+		return nil, erro.ErrBadRequest
+	}
+	if err := checkProductInterestRate(accountProduct); err != nil {
+		span.End()
+		return nil, err
+	}
+	if err := checkProductFeeSchedule(accountProduct); err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Get the database connection
+	tx, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else if err = tx.Commit(ctx); err != nil {
+			res = nil
+		}
+		span.End()
+	}()
+
+	interestRate, feeSchedule := accountProduct.InterestRate, accountProduct.FeeSchedule
+
+	res, err = s.workerRepository.AddAccountProduct(ctx, tx, accountProduct)
+	if err != nil {
+		return nil, err
+	}
+
+	if interestRate != nil {
+		res.InterestRate, err = s.workerRepository.AddInterestRate(ctx, tx, interestRate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if feeSchedule != nil {
+		_, err = s.workerRepository.DeleteFeeScheduleProduct(ctx, tx, &model.FeeSchedule{ProductID: res.ProductID, TenantID: res.TenantID})
+		if err != nil {
+			return nil, err
+		}
+		for i := range *feeSchedule {
+			_, err = s.workerRepository.AddFeeSchedule(ctx, tx, &(*feeSchedule)[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+		res.FeeSchedule = feeSchedule
+	}
+
+	return res, nil
+}
+
+// About check the interest rate of a product, it belongs to the product and its tenant
+func checkProductInterestRate(accountProduct *model.AccountProduct) error {
+	interestRate := accountProduct.InterestRate
+	if interestRate == nil {
+		return nil
+	}
+	if (interestRate.ProductID != "" && interestRate.ProductID != accountProduct.ProductID) ||
+		(interestRate.TenantID != "" && interestRate.TenantID != accountProduct.TenantID) {
+		return erro.ErrBadRequest
+	}
+	if _, err := DayCountFraction(interestRate.DayCount, time.Time{}, time.Time{}); err != nil || interestRate.Rate < 0 {
+		return erro.ErrBadRequest
+	}
+
+	interestRate.ProductID = accountProduct.ProductID
+	interestRate.TenantID = accountProduct.TenantID
+	return nil
+}
+
+// About check the fee schedule of a product: an operation and currency of the product once, a supported method
+// and no negative value, each schedule belongs to the product and its tenant
+func checkProductFeeSchedule(accountProduct *model.AccountProduct) error {
+	if accountProduct.FeeSchedule == nil {
+		return nil
+	}
+
+	operations := map[string]bool{}
+	for i := range *accountProduct.FeeSchedule {
+		feeSchedule := &(*accountProduct.FeeSchedule)[i]
+		if (feeSchedule.ProductID != "" && feeSchedule.ProductID != accountProduct.ProductID) ||
+			(feeSchedule.TenantID != "" && feeSchedule.TenantID != accountProduct.TenantID) {
+			return erro.ErrBadRequest
+		}
+		switch feeSchedule.Operation {
+		case OperationPosting, OperationTransfer, OperationMaintenance:
+		default:
+			return erro.ErrBadRequest
+		}
+		if checkProductCurrency(accountProduct, feeSchedule.Currency) != nil || operations[feeSchedule.Operation + "/" + feeSchedule.Currency] {
+			return erro.ErrBadRequest
+		}
+		operations[feeSchedule.Operation + "/" + feeSchedule.Currency] = true
+		if feeSchedule.Amount < 0 || feeSchedule.Percentage < 0 || feeSchedule.MinFee < 0 || feeSchedule.MaxFee < 0 {
+			return erro.ErrBadRequest
+		}
+		for _, tier := range feeSchedule.Tiers {
+			if tier.UpTo < 0 || tier.Amount < 0 || tier.Percentage < 0 {
+				return erro.ErrBadRequest
+			}
+		}
+		if _, err := EvaluateFee(feeSchedule, 0); err != nil {
+			return erro.ErrBadRequest
+		}
+
+		feeSchedule.ProductID = accountProduct.ProductID
+		feeSchedule.TenantID = accountProduct.TenantID
+	}
+	return nil
+}
+
+// About get an account product with its interest rate and fee schedule
+func (s *WorkerService) GetAccountProduct(ctx context.Context, accountProduct *model.AccountProduct) (*model.AccountProduct, error){
+	childLogger.Info().Str("func","GetAccountProduct").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("accountProduct", accountProduct).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.GetAccountProduct")
+	defer span.End()
+
+	res, err := s.workerRepository.GetAccountProduct(ctx, accountProduct)
+	if err == erro.ErrNotFound {
+		return nil, erro.ErrProduct
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the interest rate is optional (ex: checking and wallet)
+	res_interestRate, err := s.workerRepository.GetInterestRate(ctx, &model.InterestRate{ProductID: res.ProductID, TenantID: res.TenantID})
+	if err != nil && err != erro.ErrNotFound {
+		return nil, err
+	}
+	res.InterestRate = res_interestRate
+
+	res_feeSchedule, err := s.workerRepository.ListFeeScheduleProduct(ctx, &model.FeeSchedule{ProductID: res.ProductID, TenantID: res.TenantID})
+	if err != nil {
+		return nil, err
+	}
+	res.FeeSchedule = res_feeSchedule
+
+	return res, nil
+}
+
+// About list all account products of a tenant
+func (s *WorkerService) ListAccountProduct(ctx context.Context, accountProduct *model.AccountProduct) (*[]model.AccountProduct, error){
+	childLogger.Info().Str("func","ListAccountProduct").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("accountProduct", accountProduct).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListAccountProduct")
+	defer span.End()
+
+	res, err := s.workerRepository.ListAccountProduct(ctx, accountProduct)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About get the product rules of an account, accounts created before the product catalog have no rules
+func (s *WorkerService) getAccountProductRules(ctx context.Context, account *model.Account) (*model.AccountProduct, error){
+	if account.ProductID == "" {
+		return &model.AccountProduct{TenantID: account.TenantID}, nil
+	}
+	return s.workerRepository.GetAccountProduct(ctx, &model.AccountProduct{ProductID: account.ProductID, TenantID: account.TenantID})
+}
+
+// About check if a currency is allowed by the account product
+func checkProductCurrency(accountProduct *model.AccountProduct, currency string) error {
+	if accountProduct.ProductID == "" {
+		return nil
+	}
+	for _, c := range accountProduct.Currencies {
+		if c == currency {
+			return nil
+		}
+	}
+	return erro.ErrCurrency
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/model"
+)
+
+func Test_AddAccountProduct(t *testing.T){
+	tests := []struct {
+		name		string
+		product		model.AccountProduct
+		err			error
+	}{
+		{"checking", model.AccountProduct{ProductID: "CHECKING-2", Type: ProductChecking, Currencies: []string{"BRL"}, Overdraft: 50, TenantID: testTenant}, nil},
+		{"wallet", model.AccountProduct{ProductID: "WALLET", Type: ProductWallet, Currencies: []string{"BRL", "EUR"}, TenantID: testTenant}, nil},
+		{"replace", model.AccountProduct{ProductID: "SAVINGS", Type: ProductSavings, Currencies: []string{"BRL", "USD"}, TenantID: testTenant}, nil},
+		{"unknown type", model.AccountProduct{ProductID: "LOAN", Type: "LOAN", Currencies: []string{"BRL"}, TenantID: testTenant}, erro.ErrBadRequest},
+		{"without product id", model.AccountProduct{Type: ProductChecking, Currencies: []string{"BRL"}, TenantID: testTenant}, erro.ErrBadRequest},
+		{"without tenant", model.AccountProduct{ProductID: "WALLET", Type: ProductWallet, Currencies: []string{"BRL"}}, erro.ErrBadRequest},
+		{"without currencies", model.AccountProduct{ProductID: "WALLET", Type: ProductWallet, TenantID: testTenant}, erro.ErrBadRequest},
+		{"negative overdraft", model.AccountProduct{ProductID: "WALLET", Type: ProductWallet, Currencies: []string{"BRL"}, Overdraft: -1, TenantID: testTenant}, erro.ErrBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := bypassContext()
+			workerService, _ := newMemoryService(t)
+
+			_, err := workerService.AddAccountProduct(ctx, &tt.product)
+			if err != tt.err {
+				t.Fatalf("err %v want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+
+			res, err := workerService.GetAccountProduct(ctx, &model.AccountProduct{ProductID: tt.product.ProductID, TenantID: testTenant})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Type != tt.product.Type || len(res.Currencies) != len(tt.product.Currencies) || res.Overdraft != tt.product.Overdraft {
+				t.Errorf("product %+v want %+v", res, tt.product)
+			}
+		})
+	}
+}
+
+func Test_AddAccountProductRules(t *testing.T){
+	savings := func(interestRate *model.InterestRate, feeSchedule *[]model.FeeSchedule) model.AccountProduct {
+		return model.AccountProduct{ProductID: "SAVINGS", Type: ProductSavings, Currencies: []string{"BRL"}, InterestRate: interestRate, FeeSchedule: feeSchedule, TenantID: testTenant}
+	}
+	maintenance := model.FeeSchedule{Operation: OperationMaintenance, Method: FeeMethodFlat, Currency: "BRL", Amount: 3}
+
+	tests := []struct {
+		name		string
+		product		model.AccountProduct
+		rate		float64
+		fees		int
+		err			error
+	}{
+		{"omitted keeps the rate and the fees", savings(nil, nil), 0.1, 1, nil},
+		{"replace the rate", savings(&model.InterestRate{Rate: 0.12, DayCount: DayCount30360}, nil), 0.12, 1, nil},
+		{"replace the fees", savings(nil, &[]model.FeeSchedule{maintenance, {Operation: OperationPosting, Method: FeeMethodPercentage, Currency: "BRL", Percentage: 1}}), 0.1, 2, nil},
+		{"empty fees remove them", savings(nil, &[]model.FeeSchedule{}), 0.1, 0, nil},
+		{"unknown day count", savings(&model.InterestRate{Rate: 0.12, DayCount: "ACT/360"}, nil), 0.1, 1, erro.ErrBadRequest},
+		{"negative rate", savings(&model.InterestRate{Rate: -0.1, DayCount: DayCountACT365}, nil), 0.1, 1, erro.ErrBadRequest},
+		{"rate of an other product", savings(&model.InterestRate{ProductID: "CHECKING", Rate: 0.1, DayCount: DayCountACT365}, nil), 0.1, 1, erro.ErrBadRequest},
+		{"unknown operation", savings(nil, &[]model.FeeSchedule{{Operation: "LOAN", Method: FeeMethodFlat, Currency: "BRL", Amount: 1}}), 0.1, 1, erro.ErrBadRequest},
+		{"unknown method", savings(nil, &[]model.FeeSchedule{{Operation: OperationPosting, Method: "UNKNOWN", Currency: "BRL"}}), 0.1, 1, erro.ErrBadRequest},
+		{"currency out of the product", savings(nil, &[]model.FeeSchedule{{Operation: OperationPosting, Method: FeeMethodFlat, Currency: "USD", Amount: 1}}), 0.1, 1, erro.ErrBadRequest},
+		{"operation and currency twice", savings(nil, &[]model.FeeSchedule{maintenance, maintenance}), 0.1, 1, erro.ErrBadRequest},
+		{"negative amount", savings(nil, &[]model.FeeSchedule{{Operation: OperationPosting, Method: FeeMethodFlat, Currency: "BRL", Amount: -1}}), 0.1, 1, erro.ErrBadRequest},
+		{"fee of an other tenant", savings(nil, &[]model.FeeSchedule{{TenantID: "tenant-2", Operation: OperationPosting, Method: FeeMethodFlat, Currency: "BRL", Amount: 1}}), 0.1, 1, erro.ErrBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := bypassContext()
+			workerService, repository := newMemoryService(t)
+			if err := repository.PutFeeSchedule(ctx, model.FeeSchedule{TenantID: testTenant, ProductID: "SAVINGS", Operation: OperationMaintenance, Method: FeeMethodFlat, Currency: "BRL", Amount: 1}); err != nil {
+				t.Fatal(err)
+			}
+			// the schedule of the tenant is not replaced by the product
+			if err := repository.PutFeeSchedule(ctx, model.FeeSchedule{TenantID: testTenant, Operation: OperationTransfer, Method: FeeMethodFlat, Currency: "BRL", Amount: 5}); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := workerService.AddAccountProduct(ctx, &tt.product)
+			if err != tt.err {
+				t.Fatalf("err %v want %v", err, tt.err)
+			}
+
+			res, err := workerService.GetAccountProduct(ctx, &model.AccountProduct{ProductID: "SAVINGS", TenantID: testTenant})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.InterestRate == nil || res.InterestRate.Rate != tt.rate {
+				t.Errorf("interest rate %+v want %v", res.InterestRate, tt.rate)
+			}
+			if res.FeeSchedule == nil || len(*res.FeeSchedule) != tt.fees {
+				t.Errorf("fee schedule %+v want %d", res.FeeSchedule, tt.fees)
+			}
+			res_fee, err := workerService.PreviewFee(ctx, &model.FeePreview{TenantID: testTenant, ProductID: "SAVINGS", Operation: OperationTransfer, Currency: "BRL", Amount: 10})
+			if err != nil || res_fee.Fee != 5 {
+				t.Errorf("tenant fee %+v err %v", res_fee, err)
+			}
+		})
+	}
+}
+
+func Test_GetAccountProduct(t *testing.T){
+	workerService, repository := newMemoryService(t)
+	putTestFees(t, repository)
+
+	tests := []struct {
+		name		string
+		ctx			context.Context
+		product		model.AccountProduct
+		interest	bool
+		fees		int
+		err			error
+	}{
+		{"checking with fee", bypassContext(), model.AccountProduct{ProductID: "CHECKING", TenantID: testTenant}, false, 1, nil},
+		{"savings with interest", bypassContext(), model.AccountProduct{ProductID: "SAVINGS", TenantID: testTenant}, true, 0, nil},
+		{"unknown", bypassContext(), model.AccountProduct{ProductID: "LOAN", TenantID: testTenant}, false, 0, erro.ErrProduct},
+		{"other tenant", tenantContext("tenant-2"), model.AccountProduct{ProductID: "CHECKING", TenantID: testTenant}, false, 0, erro.ErrProduct},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := workerService.GetAccountProduct(tt.ctx, &tt.product)
+			if err != tt.err {
+				t.Fatalf("err %v want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if (res.InterestRate != nil) != tt.interest {
+				t.Errorf("interest rate %+v", res.InterestRate)
+			}
+			if res.FeeSchedule == nil || len(*res.FeeSchedule) != tt.fees {
+				t.Errorf("fee schedule %+v want %d", res.FeeSchedule, tt.fees)
+			}
+		})
+	}
+
+	list_product, err := workerService.ListAccountProduct(bypassContext(), &model.AccountProduct{TenantID: testTenant})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*list_product) != 2 || (*list_product)[0].ProductID != "CHECKING" {
+		t.Errorf("list product %+v", list_product)
+	}
+}
+
+func Test_TenantRole(t *testing.T){
+	ctx := bypassContext()
+	workerService, _ := newMemoryService(t)
+
+	tests := []struct {
+		name		string
+		tenantRole	model.TenantRole
+		err			error
+	}{
+		{"viewer", model.TenantRole{TenantID: testTenant, Subject: "user-b", Role: "viewer"}, nil},
+		{"admin", model.TenantRole{TenantID: testTenant, Subject: "user-a", Role: "admin"}, nil},
+		{"replace", model.TenantRole{TenantID: testTenant, Subject: "user-b", Role: "operator"}, nil},
+		{"unknown role", model.TenantRole{TenantID: testTenant, Subject: "user-c", Role: "root"}, erro.ErrBadRequest},
+		{"without subject", model.TenantRole{TenantID: testTenant, Role: "viewer"}, erro.ErrBadRequest},
+		{"without tenant", model.TenantRole{Subject: "user-c", Role: "viewer"}, erro.ErrBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := workerService.AddTenantRole(ctx, &tt.tenantRole)
+			if err != tt.err {
+				t.Fatalf("err %v want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			res, err := workerService.GetTenantRole(ctx, &model.TenantRole{TenantID: testTenant, Subject: tt.tenantRole.Subject})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Role != tt.tenantRole.Role {
+				t.Errorf("role %s want %s", res.Role, tt.tenantRole.Role)
+			}
+		})
+	}
+
+	list_role, err := workerService.ListTenantRole(ctx, &model.TenantRole{TenantID: testTenant})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*list_role) != 2 || (*list_role)[0].Subject != "user-a" || (*list_role)[1].Role != "operator" {
+		t.Errorf("list role %+v", list_role)
+	}
+	if _, err := workerService.GetTenantRole(tenantContext("tenant-2"), &model.TenantRole{TenantID: testTenant, Subject: "user-a"}); err != erro.ErrNotFound {
+		t.Errorf("other tenant err %v", err)
+	}
+}
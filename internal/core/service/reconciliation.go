@@ -0,0 +1,89 @@
+package service
+
+import (
+	"sort"
+	"time"
+	"context"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+)
+
+// About reconcile the balances of an account with the sum of its statements, per currency.
+// Every movement of a balance writes a statement (postings, transfers, fees and interest), so both must match
+func (s *WorkerService) ReconcileAccount(ctx context.Context, account *model.Account) (*[]model.Reconciliation, error){
+	childLogger.Info().Str("func","ReconcileAccount").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("account", account).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ReconcileAccount")
+	defer span.End()
+
+	// Get account (check if exists)
+	_, err := s.workerRepository.GetAccount(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	list_balance, err := s.workerRepository.ListAccountBalance(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	list_total, err := s.workerRepository.ListAccountStatementTotal(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	reconciliations := map[string]*model.Reconciliation{}
+	for _, accountBalance := range *list_balance {
+		reconciliations[accountBalance.Currency] = &model.Reconciliation{	AccountID: account.AccountID,
+																			Currency: accountBalance.Currency,
+																			Balance: accountBalance.Amount }
+	}
+	// a statement without a balance is reconciled against a zero balance
+	for _, total := range *list_total {
+		reconciliation, ok := reconciliations[total.Currency]
+		if !ok {
+			reconciliation = &model.Reconciliation{AccountID: account.AccountID, Currency: total.Currency}
+			reconciliations[total.Currency] = reconciliation
+		}
+		reconciliation.StatementTotal = roundAmount(total.StatementTotal, 2)
+		reconciliation.Statements = total.Statements
+	}
+
+	res_reconciliation_list := []model.Reconciliation{}
+	for _, reconciliation := range reconciliations {
+		reconciliation.Difference = roundAmount(reconciliation.Balance - reconciliation.StatementTotal, 2)
+		reconciliation.Reconciled = reconciliation.Difference == 0
+		if !reconciliation.Reconciled {
+			childLogger.Error().Str("account_id", account.AccountID).Str("currency", reconciliation.Currency).Float64("difference", reconciliation.Difference).Msg("account balance not reconciled")
+		}
+		res_reconciliation_list = append(res_reconciliation_list, *reconciliation)
+	}
+	sort.Slice(res_reconciliation_list, func(i, j int) bool { return res_reconciliation_list[i].Currency < res_reconciliation_list[j].Currency })
+
+	return &res_reconciliation_list, nil
+}
+
+// About list the statements of an account charged into a period [from, to) (exports), a zero to means up to now
+func (s *WorkerService) ListAccountStatement(ctx context.Context, account *model.Account, from time.Time, to time.Time) (*[]model.AccountStatement, error){
+	childLogger.Info().Str("func","ListAccountStatement").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("account", account).Time("from", from).Time("to", to).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListAccountStatement")
+	defer span.End()
+
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if !from.Before(to) {
+		return nil, erro.ErrBadRequest
+	}
+
+	// Get account (check if exists)
+	_, err := s.workerRepository.GetAccount(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.workerRepository.ListAccountStatement(ctx, account, from, to)
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"time"
+	"testing"
+
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/model"
+)
+
+func Test_ReconcileAccount(t *testing.T){
+	tests := []struct {
+		name		string
+		accountID	string
+		tamper		float64
+		want		[]model.Reconciliation
+		err			error
+	}{
+		{"postings, fees and transfers", "ACC-1", 0, []model.Reconciliation{
+			{AccountID: "ACC-1", Currency: "BRL", Balance: 57, StatementTotal: 57, Statements: 4, Reconciled: true},
+			{AccountID: "ACC-1", Currency: "USD", Balance: 0, Reconciled: true},
+		}, nil},
+		{"transfer received", "ACC-2", 0, []model.Reconciliation{
+			{AccountID: "ACC-2", Currency: "BRL", Balance: 40, StatementTotal: 40, Statements: 1, Reconciled: true},
+		}, nil},
+		{"balance changed without a statement", "ACC-1", 0.5, []model.Reconciliation{
+			{AccountID: "ACC-1", Currency: "BRL", Balance: 57.5, StatementTotal: 57, Statements: 4, Difference: 0.5},
+			{AccountID: "ACC-1", Currency: "USD", Balance: 0, Reconciled: true},
+		}, nil},
+		{"unknown account", "ACC-9", 0, nil, erro.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := bypassContext()
+			workerService, repository := newMemoryService(t)
+			addTestAccount(t, workerService, "ACC-1", "P-1", "CHECKING")
+			addTestAccount(t, workerService, "ACC-2", "P-1", "SAVINGS")
+			putTestFees(t, repository)
+
+			// 100 - 1 (fee) - 40 - 2 (fee)
+			if _, err := workerService.AddPosting(ctx, &model.AccountStatement{AccountID: "ACC-1", Type: StatementCredit, Currency: "BRL", Amount: 100}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := workerService.Transfer(ctx, &model.Transfer{AccountFrom: model.AccountBalance{AccountID: "ACC-1"}, AccountTo: model.AccountBalance{AccountID: "ACC-2"}, Currency: "BRL", Amount: 40}); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.tamper != 0 {
+				list_balance, err := workerService.ListAccountBalance(ctx, &model.Account{AccountID: tt.accountID})
+				if err != nil {
+					t.Fatal(err)
+				}
+				tx, err := repository.StartTx(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, accountBalance := range *list_balance {
+					if accountBalance.Currency == "BRL" {
+						if _, err := repository.UpdateAccountBalanceAmount(ctx, tx, &model.AccountBalance{ID: accountBalance.ID, Amount: tt.tamper}); err != nil {
+							t.Fatal(err)
+						}
+					}
+				}
+				if err := tx.Commit(ctx); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			res, err := workerService.ReconcileAccount(ctx, &model.Account{AccountID: tt.accountID})
+			if err != tt.err {
+				t.Fatalf("err %v want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if len(*res) != len(tt.want) {
+				t.Fatalf("reconciliations %+v want %+v", *res, tt.want)
+			}
+			for i, reconciliation := range *res {
+				if reconciliation != tt.want[i] {
+					t.Errorf("reconciliation %+v want %+v", reconciliation, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_ReconcileAccountTenant(t *testing.T){
+	workerService, _ := newMemoryService(t)
+	addTestAccount(t, workerService, "ACC-1", "P-1", "CHECKING")
+
+	if _, err := workerService.ReconcileAccount(tenantContext("tenant-2"), &model.Account{AccountID: "ACC-1"}); err != erro.ErrNotFound {
+		t.Errorf("err %v want %v", err, erro.ErrNotFound)
+	}
+	if _, err := workerService.ReconcileAccount(tenantContext(testTenant), &model.Account{AccountID: "ACC-1"}); err != nil {
+		t.Errorf("err %v", err)
+	}
+}
+
+func Test_ListAccountStatement(t *testing.T){
+	now := time.Now()
+
+	tests := []struct {
+		name		string
+		accountID	string
+		from		time.Time
+		to			time.Time
+		statements	int
+		err			error
+	}{
+		{"open period", "ACC-1", time.Time{}, time.Time{}, 3, nil},
+		{"period up to now", "ACC-1", now.Add(-time.Hour), now.Add(time.Hour), 3, nil},
+		{"period before the statements", "ACC-1", now.Add(-48 * time.Hour), now.Add(-24 * time.Hour), 0, nil},
+		{"period after the statements", "ACC-1", now.Add(time.Hour), now.Add(2 * time.Hour), 0, nil},
+		{"empty period", "ACC-1", now, now, 0, erro.ErrBadRequest},
+		{"inverted period", "ACC-1", now.Add(time.Hour), now, 0, erro.ErrBadRequest},
+		{"unknown account", "ACC-9", time.Time{}, time.Time{}, 0, erro.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := bypassContext()
+			workerService, _ := newMemoryService(t)
+			addTestAccount(t, workerService, "ACC-1", "P-1", "CHECKING")
+			addTestAccount(t, workerService, "ACC-2", "P-1", "CHECKING")
+
+			list_statement := []model.AccountStatement{
+				{AccountID: "ACC-1", Type: StatementCredit, Currency: "BRL", Amount: 100},
+				{AccountID: "ACC-1", Type: StatementDebit, Currency: "BRL", Amount: -30},
+				{AccountID: "ACC-1", Type: StatementCredit, Currency: "USD", Amount: 10},
+				{AccountID: "ACC-2", Type: StatementCredit, Currency: "BRL", Amount: 10},
+			}
+			for i := range list_statement {
+				if _, err := workerService.AddPosting(ctx, &list_statement[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			res, err := workerService.ListAccountStatement(ctx, &model.Account{AccountID: tt.accountID}, tt.from, tt.to)
+			if err != tt.err {
+				t.Fatalf("err %v want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if len(*res) != tt.statements {
+				t.Fatalf("statements %d want %d", len(*res), tt.statements)
+			}
+			for i, accountStatement := range *res {
+				if accountStatement.AccountID != tt.accountID {
+					t.Errorf("statement of the account %s", accountStatement.AccountID)
+				}
+				if i > 0 && accountStatement.ChargedAt.Before((*res)[i-1].ChargedAt) {
+					t.Errorf("statements out of order %+v", *res)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/auth"
+)
+
+// About add (or replace) the role of a subject into a tenant
+func (s *WorkerService) AddTenantRole(ctx context.Context, tenantRole *model.TenantRole) (*model.TenantRole, error){
+	childLogger.Info().Str("func","AddTenantRole").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("tenantRole", tenantRole).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.AddTenantRole")
+
+	if tenantRole.TenantID == "" || tenantRole.Subject == "" || !auth.IsRole(tenantRole.Role) {
+		span.End()
+		return nil, erro.ErrBadRequest
+	}
+
+	// Get the database connection
+	tx, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			tx.Commit(ctx)
+		}
+		span.End()
+	}()
+
+	res, err := s.workerRepository.AddTenantRole(ctx, tx, tenantRole)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// About get the role of a subject into a tenant
+func (s *WorkerService) GetTenantRole(ctx context.Context, tenantRole *model.TenantRole) (*model.TenantRole, error){
+	childLogger.Info().Str("func","GetTenantRole").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("tenantRole", tenantRole).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.GetTenantRole")
+	defer span.End()
+
+	res, err := s.workerRepository.GetTenantRole(ctx, tenantRole)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About list all roles of a tenant
+func (s *WorkerService) ListTenantRole(ctx context.Context, tenantRole *model.TenantRole) (*[]model.TenantRole, error){
+	childLogger.Info().Str("func","ListTenantRole").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("tenantRole", tenantRole).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListTenantRole")
+	defer span.End()
+
+	res, err := s.workerRepository.ListTenantRole(ctx, tenantRole)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
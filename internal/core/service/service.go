@@ -5,9 +5,10 @@ import(
 	"context"
 	"github.com/rs/zerolog/log"
 
-	"github.com/go-account/internal/adapter/database"
 	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/port"
 	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/event"
 
 	go_core_pg "github.com/eliezerraj/go-core/database/pg"
 	go_core_observ "github.com/eliezerraj/go-core/observability"
@@ -20,12 +21,20 @@ var (
 	apiService go_core_api.ApiService
 )
 
+// About the status of an account, a blocked account refuses the postings and the transfers
+const (
+	AccountActive	= "ACTIVE"
+	AccountBlocked	= "BLOCKED"
+)
+
 type WorkerService struct {
-	workerRepository *database.WorkerRepository
+	workerRepository port.Repository
+	eventSource		string
+	webhookAllowHttp	bool
 }
 
 // About new worker service
-func NewWorkerService(workerRepository *database.WorkerRepository) *WorkerService{
+func NewWorkerService(workerRepository port.Repository) *WorkerService{
 	childLogger.Info().Str("func","NewWorkerService").Send()
 
 	return &WorkerService{
@@ -33,6 +42,16 @@ func NewWorkerService(workerRepository *database.WorkerRepository) *WorkerServic
 	}
 }
 
+// About set the source of the domain events (pod name)
+func (s *WorkerService) SetEventSource(eventSource string) {
+	s.eventSource = eventSource
+}
+
+// About allow the plain http webhook urls (dev only), otherwise a subscription must be https
+func (s *WorkerService) SetWebhookAllowHttp(allowHttp bool) {
+	s.webhookAllowHttp = allowHttp
+}
+
 // About handle/convert http status code
 func (s *WorkerService) Stat(ctx context.Context) (go_core_pg.PoolStats){
 	childLogger.Info().Str("func","Stat").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Send()
@@ -46,13 +65,24 @@ func (s *WorkerService) AddAccount(ctx context.Context, account *model.Account)
 
 	// Trace
 	span := tracerProvider.Span(ctx, "service.AddAccount")
+
+	// Get the account product (check if exists)
+	if account.ProductID == "" {
+		span.End()
+		return nil, erro.ErrProduct
+	}
+	res_product, err := s.GetAccountProduct(ctx, &model.AccountProduct{ProductID: account.ProductID, TenantID: account.TenantID})
+	if err != nil {
+		span.End()
+		return nil, err
+	}
 	
 	// Get the database connection
-	tx, conn, err := s.workerRepository.DatabasePGServer.StartTx(ctx)
+	tx, err := s.workerRepository.StartTx(ctx)
 	if err != nil {
+		span.End()
 		return nil, err
 	}
-	defer s.workerRepository.DatabasePGServer.ReleaseTx(conn)
 	
 	// Handle the transaction
 	defer func() {
@@ -64,28 +94,63 @@ func (s *WorkerService) AddAccount(ctx context.Context, account *model.Account)
 		span.End()
 	}()
 
+	// Generate or check the account number
+	err = s.setAccountNumber(ctx, tx, account)
+	if err != nil {
+		return nil, err
+	}
+
 	// Add the account
+	account.Status = AccountActive
 	res, err := s.workerRepository.AddAccount(ctx, tx, account)
 	if err != nil {
 		return nil, err
 	}
 
+	// Open a balance for each currency allowed by the product
+	for _, currency := range res_product.Currencies {
+		accountBalance := model.AccountBalance{	FkAccountID: res.ID,
+												AccountID: res.AccountID,
+												Currency: currency,
+												TenantID: res.TenantID,
+												UserLastUpdate: res.UserLastUpdate }
+		_, err = s.workerRepository.AddAccountBalance(ctx, tx, &accountBalance)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.addEvent(ctx, tx, event.TypeAccountCreated, res.AccountID, res.TenantID, event.NewAccountCreated(res))
+	if err != nil {
+		return nil, err
+	}
+
 	return res, nil
 }
 
-// About update an account
+// About update an account (all the updatable fields)
 func (s *WorkerService) UpdateAccount(ctx context.Context, account *model.Account) (*model.Account, error){
 	childLogger.Info().Str("func","UpdateAccount").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("account", account).Send()
 
+	// the tenant of an account can not be changed, so it is not part of the patch
+	return s.PatchAccount(ctx, &model.AccountPatch{	AccountID: account.AccountID,
+													PersonID: &account.PersonID,
+													UserLastUpdate: account.UserLastUpdate })
+}
+
+// About update only the informed fields of an account and return the updated account
+func (s *WorkerService) PatchAccount(ctx context.Context, accountPatch *model.AccountPatch) (*model.Account, error){
+	childLogger.Info().Str("func","PatchAccount").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("accountPatch", accountPatch).Send()
+
 	// Trace
-	span := tracerProvider.Span(ctx, "service.UpdateAccount")
+	span := tracerProvider.Span(ctx, "service.PatchAccount")
 	
 	// Get the database connection
-	tx, conn, err := s.workerRepository.DatabasePGServer.StartTx(ctx)
+	tx, err := s.workerRepository.StartTx(ctx)
 	if err != nil {
+		span.End()
 		return nil, err
 	}
-	defer s.workerRepository.DatabasePGServer.ReleaseTx(conn)
 
 	// Handle the transaction
 	defer func() {
@@ -97,19 +162,33 @@ func (s *WorkerService) UpdateAccount(ctx context.Context, account *model.Accoun
 		span.End()
 	}()
 
-	// Get account (check if exists)
-	res, err := s.workerRepository.GetAccount(ctx, account)
+	// Get and lock the account (check if exists), the update applies over the current row
+	res, err := s.workerRepository.GetAccountForUpdate(ctx, tx, &model.Account{AccountID: accountPatch.AccountID})
 	if err != nil {
 		return nil, err
 	}
 
+	// the tenant of an account can not be changed
+	if accountPatch.TenantID != "" && accountPatch.TenantID != res.TenantID {
+		err = &erro.ValidationError{Fields: []erro.FieldError{{Field: "tenant_id", Rule: "readonly", Message: "can not be changed"}}}
+		return nil, err
+	}
+	accountPatch.TenantID = res.TenantID
+
+	// nothing informed, the account is unchanged
+	if accountPatch.PersonID == nil && accountPatch.Status == nil {
+		return res, nil
+	}
+
 	// Update the account
-	res_update, err := s.workerRepository.UpdateAccount(ctx, tx, account)
+	res, err = s.workerRepository.PatchAccount(ctx, tx, accountPatch)
 	if err != nil {
 		return nil, err
 	}
-	if (res_update == 0) {
-		return nil, erro.ErrUpdate
+
+	err = s.addEvent(ctx, tx, event.TypeAccountUpdated, res.AccountID, res.TenantID, event.NewAccountUpdated(res))
+	if err != nil {
+		return nil, err
 	}
 
 	return res, nil
@@ -120,17 +199,39 @@ func (s *WorkerService) DeleteAccount(ctx context.Context, account *model.Accoun
 	childLogger.Info().Str("func","DeleteAccount").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("account", account).Send()
 
 	// Trace
-	span := tracerProvider.Span(ctx, "service.UpdateAccount")
-	defer span.End()
+	span := tracerProvider.Span(ctx, "service.DeleteAccount")
 	
 	// Get account (check if exists)
 	res, err := s.workerRepository.GetAccount(ctx, account)
 	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Get the database connection
+	tx, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		span.End()
 		return nil, err
 	}
 
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			tx.Commit(ctx)
+		}
+		span.End()
+	}()
+
 	// Delete the account
-	_, err = s.workerRepository.DeleteAccount(ctx, account)
+	_, err = s.workerRepository.DeleteAccount(ctx, tx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.addEvent(ctx, tx, event.TypeAccountClosed, res.AccountID, res.TenantID, event.NewAccountClosed(res, time.Now().UTC()))
 	if err != nil {
 		return nil, err
 	}
@@ -187,4 +288,25 @@ func (s *WorkerService) ListAccountPerPerson(ctx context.Context, account *model
 		return nil, err
 	}
 	return res, nil
-}
\ No newline at end of file
+}
+// About list the balances (one per currency) of an account
+func (s *WorkerService) ListAccountBalance(ctx context.Context, account *model.Account) (*[]model.AccountBalance, error){
+	childLogger.Info().Str("func","ListAccountBalance").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Interface("account", account).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListAccountBalance")
+	defer span.End()
+
+	// Get account (check if exists)
+	_, err := s.workerRepository.GetAccount(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	// List the balances
+	res, err := s.workerRepository.ListAccountBalance(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -1,8 +1,318 @@
 package service
 
 import (
+	"errors"
+	"context"
 	"testing"
+
+	"github.com/go-account/internal/adapter/memory"
+	"github.com/go-account/internal/core/auth"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/model"
 )
 
+const testTenant = "tenant-1"
+
+// the service over a memory repository with a checking product (BRL and USD, overdraft 100)
+// and a savings product (BRL, no overdraft, 10% a year ACT/365)
+func newMemoryService(t *testing.T) (*WorkerService, *memory.MemoryRepository) {
+	t.Helper()
+	ctx := bypassContext()
+	repository := memory.NewMemoryRepository()
+	workerService := NewWorkerService(repository)
+	workerService.SetEventSource("go-account-test")
+
+	list_product := []model.AccountProduct{
+		{ProductID: "CHECKING", Type: ProductChecking, Currencies: []string{"BRL", "USD"}, Overdraft: 100, TenantID: testTenant},
+		{ProductID: "SAVINGS", Type: ProductSavings, Currencies: []string{"BRL"}, TenantID: testTenant},
+	}
+	for i := range list_product {
+		if _, err := workerService.AddAccountProduct(ctx, &list_product[i]); err != nil {
+			t.Fatalf("add product %s: %v", list_product[i].ProductID, err)
+		}
+	}
+	err := repository.PutInterestRate(ctx, model.InterestRate{ProductID: "SAVINGS", Rate: 0.1, DayCount: DayCountACT365, TenantID: testTenant})
+	if err != nil {
+		t.Fatalf("put interest rate: %v", err)
+	}
+	return workerService, repository
+}
+
+// add an account of the test tenant
+func addTestAccount(t *testing.T, workerService *WorkerService, accountID string, personID string, productID string) *model.Account {
+	t.Helper()
+	res, err := workerService.AddAccount(bypassContext(), &model.Account{	AccountID: accountID,
+																				PersonID: personID,
+																				ProductID: productID,
+																				TenantID: testTenant })
+	if err != nil {
+		t.Fatalf("add account %s: %v", accountID, err)
+	}
+	return res
+}
+
+// the amount of the balance of an account in a currency
+func balanceOf(t *testing.T, workerService *WorkerService, accountID string, currency string) float64 {
+	t.Helper()
+	list_balance, err := workerService.ListAccountBalance(bypassContext(), &model.Account{AccountID: accountID})
+	if err != nil {
+		t.Fatalf("list balance %s: %v", accountID, err)
+	}
+	for _, accountBalance := range *list_balance {
+		if accountBalance.Currency == currency {
+			return accountBalance.Amount
+		}
+	}
+	t.Fatalf("account %s has no %s balance", accountID, currency)
+	return 0
+}
+
+// the context of the jobs and of the requests with the authentication disabled (all the tenants)
+func bypassContext() context.Context {
+	return auth.WithTenantBypass(context.Background())
+}
+
+// the context of a principal of a tenant
+func tenantContext(tenantID string) context.Context {
+	return auth.WithPrincipal(context.Background(), &model.Principal{Subject: "user-test", TenantID: tenantID})
+}
+
+// the events written into the outbox (not published yet)
+func pendingEvents(t *testing.T, repository *memory.MemoryRepository) []model.OutboxEvent {
+	t.Helper()
+	ctx := bypassContext()
+	tx, err := repository.StartTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+	list_event, err := repository.ListPendingOutboxEvent(ctx, tx, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *list_event
+}
+
+func Test_AddAccount(t *testing.T){
+	tests := []struct {
+		name		string
+		account		model.Account
+		balances	int
+		err			error
+		validation	bool
+	}{
+		{"checking", model.Account{AccountID: "ACC-1", PersonID: "P-1", ProductID: "CHECKING", TenantID: testTenant}, 2, nil, false},
+		{"savings", model.Account{AccountID: "ACC-1", PersonID: "P-1", ProductID: "SAVINGS", TenantID: testTenant}, 1, nil, false},
+		{"duplicated", model.Account{AccountID: "ACC-0", PersonID: "P-1", ProductID: "CHECKING", TenantID: testTenant}, 0, memory.ErrDuplicateKey, false},
+		{"without product", model.Account{AccountID: "ACC-1", PersonID: "P-1", TenantID: testTenant}, 0, erro.ErrProduct, false},
+		{"unknown product", model.Account{AccountID: "ACC-1", PersonID: "P-1", ProductID: "UNKNOWN", TenantID: testTenant}, 0, erro.ErrProduct, false},
+		{"product of another tenant", model.Account{AccountID: "ACC-1", PersonID: "P-1", ProductID: "CHECKING", TenantID: "tenant-2"}, 0, erro.ErrProduct, false},
+		{"without number format", model.Account{PersonID: "P-1", ProductID: "CHECKING", TenantID: testTenant}, 0, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := bypassContext()
+			workerService, repository := newMemoryService(t)
+			addTestAccount(t, workerService, "ACC-0", "P-0", "CHECKING")
+
+			res, err := workerService.AddAccount(ctx, &tt.account)
+			if tt.validation {
+				var validationError *erro.ValidationError
+				if !errors.As(err, &validationError) {
+					t.Fatalf("err %v want a validation error", err)
+				}
+				return
+			}
+			if err != tt.err {
+				t.Fatalf("err %v want %v", err, tt.err)
+			}
+			if err != nil {
+				if len(pendingEvents(t, repository)) != 1 {
+					t.Errorf("the failed account wrote an event")
+				}
+				return
+			}
+
+			list_balance, err := workerService.ListAccountBalance(ctx, res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(*list_balance) != tt.balances {
+				t.Errorf("balances %d want %d", len(*list_balance), tt.balances)
+			}
+			if list_event := pendingEvents(t, repository); len(list_event) != 2 || list_event[1].AggregateID != res.AccountID {
+				t.Errorf("events %+v want the account created", list_event)
+			}
+		})
+	}
+}
+
+func Test_PatchAccount(t *testing.T){
+	personID := "P-2"
+
+	tests := []struct {
+		name		string
+		patch		model.AccountPatch
+		personID	string
+		events		int
+		err			error
+		validation	bool
+	}{
+		{"person", model.AccountPatch{AccountID: "ACC-1", PersonID: &personID}, "P-2", 2, nil, false},
+		{"same tenant", model.AccountPatch{AccountID: "ACC-1", TenantID: testTenant, PersonID: &personID}, "P-2", 2, nil, false},
+		{"nothing informed", model.AccountPatch{AccountID: "ACC-1"}, "P-1", 1, nil, false},
+		{"other tenant", model.AccountPatch{AccountID: "ACC-1", TenantID: "tenant-2", PersonID: &personID}, "P-1", 1, nil, true},
+		{"unknown account", model.AccountPatch{AccountID: "ACC-9", PersonID: &personID}, "P-1", 1, erro.ErrNotFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := bypassContext()
+			workerService, repository := newMemoryService(t)
+			addTestAccount(t, workerService, "ACC-1", "P-1", "CHECKING")
+
+			_, err := workerService.PatchAccount(ctx, &tt.patch)
+			var validationError *erro.ValidationError
+			if tt.validation != errors.As(err, &validationError) {
+				t.Fatalf("err %v validation %v", err, tt.validation)
+			}
+			if !tt.validation && err != tt.err {
+				t.Fatalf("err %v want %v", err, tt.err)
+			}
+
+			res, err := workerService.GetAccount(ctx, &model.Account{AccountID: "ACC-1"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.PersonID != tt.personID {
+				t.Errorf("person %s want %s", res.PersonID, tt.personID)
+			}
+			if got := len(pendingEvents(t, repository)); got != tt.events {
+				t.Errorf("events %d want %d", got, tt.events)
+			}
+		})
+	}
+}
+
+func Test_UpdateAccount(t *testing.T){
+	ctx := bypassContext()
+	workerService, _ := newMemoryService(t)
+	addTestAccount(t, workerService, "ACC-1", "P-1", "CHECKING")
+
+	res, err := workerService.UpdateAccount(ctx, &model.Account{AccountID: "ACC-1", PersonID: "P-3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.PersonID != "P-3" || res.TenantID != testTenant || res.UpdatedAt == nil {
+		t.Errorf("updated account %+v", res)
+	}
+	if _, err := workerService.UpdateAccount(ctx, &model.Account{AccountID: "ACC-9", PersonID: "P-3"}); err != erro.ErrNotFound {
+		t.Errorf("unknown account err %v want %v", err, erro.ErrNotFound)
+	}
+}
+
+func Test_DeleteAccount(t *testing.T){
+	tests := []struct {
+		name		string
+		ctx			context.Context
+		accountID	string
+		err			error
+	}{
+		{"account", bypassContext(), "ACC-1", nil},
+		{"same tenant", tenantContext(testTenant), "ACC-1", nil},
+		{"other tenant", tenantContext("tenant-2"), "ACC-1", erro.ErrNotFound},
+		{"unknown account", bypassContext(), "ACC-9", erro.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workerService, repository := newMemoryService(t)
+			addTestAccount(t, workerService, "ACC-1", "P-1", "CHECKING")
+
+			_, err := workerService.DeleteAccount(tt.ctx, &model.Account{AccountID: tt.accountID})
+			if err != tt.err {
+				t.Fatalf("err %v want %v", err, tt.err)
+			}
+
+			_, errGet := workerService.GetAccount(bypassContext(), &model.Account{AccountID: "ACC-1"})
+			if deleted := errGet == erro.ErrNotFound; deleted != (tt.err == nil) {
+				t.Errorf("account deleted %v, want %v", deleted, tt.err == nil)
+			}
+			if tt.err == nil {
+				list_event := pendingEvents(t, repository)
+				if len(list_event) != 2 || list_event[1].AggregateID != "ACC-1" {
+					t.Errorf("events %+v want the account closed", list_event)
+				}
+			}
+		})
+	}
+}
+
+func Test_GetAccount(t *testing.T){
+	workerService, _ := newMemoryService(t)
+	res_account := addTestAccount(t, workerService, "ACC-1", "P-1", "CHECKING")
+	addTestAccount(t, workerService, "ACC-2", "P-1", "SAVINGS")
+	addTestAccount(t, workerService, "ACC-3", "P-2", "CHECKING")
+
+	tests := []struct {
+		name		string
+		ctx			context.Context
+		account		model.Account
+		accounts	int
+		balances	int
+		err			error
+	}{
+		{"account", bypassContext(), model.Account{ID: res_account.ID, AccountID: "ACC-1", PersonID: "P-1"}, 2, 2, nil},
+		{"same tenant", tenantContext(testTenant), model.Account{ID: res_account.ID, AccountID: "ACC-1", PersonID: "P-1"}, 2, 2, nil},
+		{"other tenant", tenantContext("tenant-2"), model.Account{ID: res_account.ID, AccountID: "ACC-1", PersonID: "P-1"}, 0, 0, erro.ErrNotFound},
+		{"unknown account", bypassContext(), model.Account{ID: 99, AccountID: "ACC-9", PersonID: "P-9"}, 0, 0, erro.ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := workerService.GetAccount(tt.ctx, &model.Account{AccountID: tt.account.AccountID})
+			if err != tt.err {
+				t.Fatalf("GetAccount err %v want %v", err, tt.err)
+			}
+			if err == nil && (res.ID != tt.account.ID || res.PersonID != tt.account.PersonID || res.TenantID != testTenant) {
+				t.Errorf("GetAccount %+v", res)
+			}
+
+			res, err = workerService.GetAccountId(tt.ctx, &model.Account{ID: tt.account.ID})
+			if err != tt.err {
+				t.Fatalf("GetAccountId err %v want %v", err, tt.err)
+			}
+			if err == nil && res.AccountID != tt.account.AccountID {
+				t.Errorf("GetAccountId %+v", res)
+			}
+
+			list_account, err := workerService.ListAccountPerPerson(tt.ctx, &model.Account{PersonID: tt.account.PersonID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(*list_account) != tt.accounts {
+				t.Errorf("ListAccountPerPerson %d want %d", len(*list_account), tt.accounts)
+			}
+
+			list_balance, err := workerService.ListAccountBalance(tt.ctx, &model.Account{AccountID: tt.account.AccountID})
+			if err != tt.err {
+				t.Fatalf("ListAccountBalance err %v want %v", err, tt.err)
+			}
+			if err == nil && len(*list_balance) != tt.balances {
+				t.Errorf("ListAccountBalance %d want %d", len(*list_balance), tt.balances)
+			}
+		})
+	}
+}
+
+func Test_Stat(t *testing.T){
+	workerService, _ := newMemoryService(t)
+
+	if res := workerService.Stat(bypassContext()); res.TotalConns != 0 {
+		t.Errorf("memory repository has no pool %+v", res)
+	}
+}
+
 func Test_Account(t *testing.T){
 }
\ No newline at end of file
@@ -0,0 +1,440 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"context"
+	"strconv"
+	"strings"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/auth"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/event"
+
+	"github.com/google/uuid"
+)
+
+const (
+	WebhookActive		= "ACTIVE"
+	WebhookDisabled		= "DISABLED"
+
+	DeliveryPending		= "PENDING"
+	DeliveryDelivered	= "DELIVERED"
+	DeliveryDead		= "DEAD"
+	DeliveryCanceled	= "CANCELED"
+
+	// the last deliveries listed of a subscription
+	MaxWebhookDeliveryList = 100
+)
+
+// About post a payload to a webhook endpoint, it returns the http status code
+type WebhookSender interface {
+	Send(ctx context.Context, url string, header map[string]string, body []byte) (int, error)
+}
+
+// About the signature of a payload, hex HMAC-SHA256 of "<timestamp>.<body>" with the secret of the subscription
+// (the timestamp is signed to allow the receiver to reject replays)
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// About the wait before the next attempt, base * 2^(attempts-1) up to the max
+func webhookBackoff(attempts int, base time.Duration, max time.Duration) time.Duration {
+	backoff := base
+	for i := 1; i < attempts && backoff < max; i++ {
+		backoff = backoff * 2
+	}
+	if backoff > max {
+		return max
+	}
+	return backoff
+}
+
+// About apply the result of an attempt into the delivery: 2xx is delivered, otherwise it waits
+// the backoff or is dead after the max attempts. It returns the attempt for the history
+func applyWebhookResult(webhookDelivery *model.WebhookDelivery,
+						statusCode int,
+						errSend error,
+						attemptedAt time.Time,
+						duration time.Duration,
+						webhookConfig *model.WebhookConfig) model.WebhookDeliveryAttempt {
+	webhookDelivery.Attempts++
+	webhookDeliveryAttempt := model.WebhookDeliveryAttempt{	FkDeliveryID: webhookDelivery.ID,
+															Attempt: webhookDelivery.Attempts,
+															AttemptedAt: attemptedAt,
+															DurationMs: duration.Milliseconds() }
+	webhookDelivery.LastStatusCode = nil
+	webhookDelivery.LastError = nil
+	if statusCode > 0 {
+		webhookDelivery.LastStatusCode = &statusCode
+		webhookDeliveryAttempt.StatusCode = &statusCode
+	}
+	if errSend == nil && (statusCode < 200 || statusCode > 299) {
+		errSend = fmt.Errorf("unexpected status code %d", statusCode)
+	}
+
+	if errSend == nil {
+		webhookDelivery.Status = DeliveryDelivered
+		webhookDelivery.DeliveredAt = &attemptedAt
+		webhookDelivery.NextAttemptAt = attemptedAt
+		return webhookDeliveryAttempt
+	}
+
+	lastError := errSend.Error()
+	webhookDelivery.LastError = &lastError
+	webhookDeliveryAttempt.Error = &lastError
+	webhookDelivery.NextAttemptAt = attemptedAt.Add(webhookBackoff(webhookDelivery.Attempts,
+																	time.Duration(webhookConfig.BackoffBase) * time.Second,
+																	time.Duration(webhookConfig.BackoffMax) * time.Second))
+	webhookDelivery.Status = DeliveryPending
+	if webhookDelivery.Attempts >= webhookConfig.MaxAttempts {
+		webhookDelivery.Status = DeliveryDead
+	}
+	return webhookDeliveryAttempt
+}
+
+// About post a delivery with the signature headers
+func deliverWebhook(ctx context.Context, sender WebhookSender, webhookDelivery *model.WebhookDelivery, webhookConfig *model.WebhookConfig) model.WebhookDeliveryAttempt {
+	attemptedAt := time.Now()
+	header := map[string]string{
+		"Content-Type":			event.ContentTypeEvent,
+		"Webhook-Id":			strconv.FormatInt(webhookDelivery.ID, 10),
+		"Webhook-Timestamp":	strconv.FormatInt(attemptedAt.Unix(), 10),
+		"Webhook-Signature":	SignWebhook(webhookDelivery.Secret, attemptedAt.Unix(), webhookDelivery.Payload),
+	}
+
+	ctxSend, cancel := context.WithTimeout(ctx, time.Duration(webhookConfig.Timeout) * time.Second)
+	defer cancel()
+
+	statusCode, err := sender.Send(ctxSend, webhookDelivery.URL, header, webhookDelivery.Payload)
+	return applyWebhookResult(webhookDelivery, statusCode, err, attemptedAt, time.Since(attemptedAt), webhookConfig)
+}
+
+// About generate the secret of a subscription (signs the payloads)
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// About create a webhook subscription, the secret is returned only in this response
+func (s *WorkerService) AddWebhookSubscription(ctx context.Context, webhookSubscription *model.WebhookSubscription) (*model.WebhookSubscription, error){
+	childLogger.Info().Str("func","AddWebhookSubscription").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("tenant_id", webhookSubscription.TenantID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.AddWebhookSubscription")
+
+	// the plain http is only allowed in dev
+	if !s.webhookAllowHttp && !strings.HasPrefix(strings.ToLower(webhookSubscription.URL), "https://") {
+		span.End()
+		return nil, &erro.ValidationError{Fields: []erro.FieldError{{Field: "url", Rule: "https", Message: "must be an https url"}}}
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+	webhookSubscription.SubscriptionID = uuid.New().String()
+	webhookSubscription.Secret = secret
+	webhookSubscription.Status = WebhookActive
+
+	// Get the database connection
+	tx, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			tx.Commit(ctx)
+		}
+		span.End()
+	}()
+
+	res, err := s.workerRepository.AddWebhookSubscription(ctx, tx, webhookSubscription)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// About get a webhook subscription of the tenant
+func (s *WorkerService) GetWebhookSubscription(ctx context.Context, webhookSubscription *model.WebhookSubscription) (*model.WebhookSubscription, error){
+	childLogger.Info().Str("func","GetWebhookSubscription").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("subscription_id", webhookSubscription.SubscriptionID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.GetWebhookSubscription")
+	defer span.End()
+
+	res, err := s.workerRepository.GetWebhookSubscription(ctx, webhookSubscription)
+	if err != nil {
+		return nil, err
+	}
+	if webhookSubscription.TenantID != "" && res.TenantID != webhookSubscription.TenantID {
+		return nil, erro.ErrNotFound
+	}
+	return res, nil
+}
+
+// About list all webhook subscriptions of a tenant (without the secrets)
+func (s *WorkerService) ListWebhookSubscription(ctx context.Context, webhookSubscription *model.WebhookSubscription) (*[]model.WebhookSubscription, error){
+	childLogger.Info().Str("func","ListWebhookSubscription").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("tenant_id", webhookSubscription.TenantID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListWebhookSubscription")
+	defer span.End()
+
+	res, err := s.workerRepository.ListWebhookSubscription(ctx, webhookSubscription)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// About disable a webhook subscription, its pending deliveries are canceled
+func (s *WorkerService) DisableWebhookSubscription(ctx context.Context, webhookSubscription *model.WebhookSubscription) (*model.WebhookSubscription, error){
+	childLogger.Info().Str("func","DisableWebhookSubscription").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("subscription_id", webhookSubscription.SubscriptionID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.DisableWebhookSubscription")
+
+	res, err := s.GetWebhookSubscription(ctx, webhookSubscription)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+	if res.Status == WebhookDisabled {
+		span.End()
+		return nil, erro.ErrTransInvalid
+	}
+	res.Status = WebhookDisabled
+	res.UserLastUpdate = webhookSubscription.UserLastUpdate
+
+	// Get the database connection
+	tx, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		span.End()
+		return nil, err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			tx.Commit(ctx)
+		}
+		span.End()
+	}()
+
+	res_update, err := s.workerRepository.UpdateWebhookSubscription(ctx, tx, res)
+	if err != nil {
+		return nil, err
+	}
+	if res_update == 0 {
+		err = erro.ErrUpdate
+		return nil, err
+	}
+	_, err = s.workerRepository.CancelWebhookDelivery(ctx, tx, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// About list the last deliveries of a subscription with the history of the attempts
+func (s *WorkerService) ListWebhookDelivery(ctx context.Context, webhookSubscription *model.WebhookSubscription, status string) (*[]model.WebhookDelivery, error){
+	childLogger.Info().Str("func","ListWebhookDelivery").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("subscription_id", webhookSubscription.SubscriptionID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.ListWebhookDelivery")
+	defer span.End()
+
+	res_subscription, err := s.GetWebhookSubscription(ctx, webhookSubscription)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.workerRepository.ListWebhookDelivery(ctx, res_subscription, status, MaxWebhookDeliveryList)
+	if err != nil {
+		return nil, err
+	}
+	if len(*res) == 0 {
+		return res, nil
+	}
+
+	deliveryIDs := make([]int64, 0, len(*res))
+	for _, webhookDelivery := range *res {
+		deliveryIDs = append(deliveryIDs, webhookDelivery.ID)
+	}
+	attempts, err := s.workerRepository.ListWebhookDeliveryAttemptByDeliveryIDs(ctx, deliveryIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range *res {
+		list_attempt := attempts[(*res)[i].ID]
+		if list_attempt == nil {
+			list_attempt = []model.WebhookDeliveryAttempt{}
+		}
+		(*res)[i].AttemptList = &list_attempt
+	}
+
+	return res, nil
+}
+
+// About send a dead delivery back to the queue (dead-letter redrive)
+func (s *WorkerService) RetryWebhookDelivery(ctx context.Context, webhookSubscription *model.WebhookSubscription, deliveryID int64) error{
+	childLogger.Info().Str("func","RetryWebhookDelivery").Interface("trace-resquest-id", ctx.Value("trace-request-id")).Str("subscription_id", webhookSubscription.SubscriptionID).Int64("delivery_id", deliveryID).Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.RetryWebhookDelivery")
+
+	res_subscription, err := s.GetWebhookSubscription(ctx, webhookSubscription)
+	if err != nil {
+		span.End()
+		return err
+	}
+	if res_subscription.Status != WebhookActive {
+		span.End()
+		return erro.ErrTransInvalid
+	}
+
+	// Get the database connection
+	tx, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		span.End()
+		return err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			tx.Commit(ctx)
+		}
+		span.End()
+	}()
+
+	// only a dead delivery of the subscription can be retried
+	res_update, err := s.workerRepository.RetryWebhookDelivery(ctx, tx, res_subscription, deliveryID)
+	if err != nil {
+		return err
+	}
+	if res_update == 0 {
+		err = erro.ErrTransInvalid
+		return err
+	}
+
+	return nil
+}
+
+// About deliver a batch of due webhook deliveries concurrently and record the results,
+// the claim leases the deliveries so the replicas do not send them twice at the same time
+func (s *WorkerService) WebhookDispatch(ctx context.Context, sender WebhookSender, webhookConfig *model.WebhookConfig) (int, error){
+	childLogger.Debug().Str("func","WebhookDispatch").Send()
+
+	// Trace
+	span := tracerProvider.Span(ctx, "service.WebhookDispatch")
+
+	lease := 2 * time.Duration(webhookConfig.Timeout) * time.Second
+	list_delivery, err := s.workerRepository.ClaimWebhookDelivery(ctx, webhookConfig.BatchSize, lease)
+	if err != nil || len(*list_delivery) == 0 {
+		span.End()
+		return 0, err
+	}
+
+	list_attempt := make([]model.WebhookDeliveryAttempt, len(*list_delivery))
+	var wg sync.WaitGroup
+	for i := range *list_delivery {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			list_attempt[i] = deliverWebhook(ctx, sender, &(*list_delivery)[i], webhookConfig)
+		}(i)
+	}
+	wg.Wait()
+
+	// Get the database connection
+	tx, err := s.workerRepository.StartTx(ctx)
+	if err != nil {
+		span.End()
+		return 0, err
+	}
+
+	// Handle the transaction
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		} else {
+			tx.Commit(ctx)
+		}
+		span.End()
+	}()
+
+	for i := range *list_delivery {
+		webhookDelivery := &(*list_delivery)[i]
+		if webhookDelivery.Status == DeliveryDead {
+			childLogger.Error().Int64("id", webhookDelivery.ID).Str("subscription_id", webhookDelivery.SubscriptionID).Int("attempts", webhookDelivery.Attempts).Msg("webhook delivery dead")
+		}
+		_, err = s.workerRepository.UpdateWebhookDelivery(ctx, tx, webhookDelivery)
+		if err != nil {
+			return 0, err
+		}
+		_, err = s.workerRepository.AddWebhookDeliveryAttempt(ctx, tx, &list_attempt[i])
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(*list_delivery), nil
+}
+
+// About dispatch the webhooks on each tick until the context is done, a full batch is followed by the next one
+func (s *WorkerService) WebhookDispatchJob(ctx context.Context, sender WebhookSender, interval time.Duration, webhookConfig *model.WebhookConfig){
+	childLogger.Info().Str("func","WebhookDispatchJob").Interface("interval", interval).Int("batchSize", webhookConfig.BatchSize).Send()
+
+	// the job runs for all the tenants
+	ctx = auth.WithTenantBypass(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			childLogger.Info().Msg("stop webhook dispatch job !!!")
+			return
+		case <-ticker.C:
+			for {
+				count, err := s.WebhookDispatch(ctx, sender, webhookConfig)
+				if err != nil {
+					childLogger.Error().Err(err).Msg("error webhook dispatch job")
+					break
+				}
+				if count < webhookConfig.BatchSize || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
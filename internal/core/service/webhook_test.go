@@ -0,0 +1,274 @@
+package service
+
+import (
+	"errors"
+	"context"
+	"testing"
+	"time"
+	"strconv"
+	"strings"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/event"
+)
+
+type fakeSender struct {
+	statusCode	int
+	err			error
+	header		map[string]string
+}
+
+func (f *fakeSender) Send(ctx context.Context, url string, header map[string]string, body []byte) (int, error) {
+	f.header = header
+	return f.statusCode, f.err
+}
+
+func Test_SignWebhook(t *testing.T){
+	// echo -n '1700000000.{"a":1}' | openssl dgst -sha256 -hmac whsec_test
+	want := "t=1700000000,v1=38877139021993b830af32feea6e18a8da83eb2f6e49ee50bd9e4cf4ca4d3789"
+	got := SignWebhook("whsec_test", 1700000000, []byte(`{"a":1}`))
+	if got != want {
+		t.Fatalf("signature %s want %s", got, want)
+	}
+	if got == SignWebhook("whsec_other", 1700000000, []byte(`{"a":1}`)) || got == SignWebhook("whsec_test", 1700000001, []byte(`{"a":1}`)) {
+		t.Errorf("the secret and the timestamp must change the signature")
+	}
+}
+
+func Test_webhookBackoff(t *testing.T){
+	tests := []struct {
+		attempts	int
+		want		time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, 60 * time.Second},
+		{4, 240 * time.Second},
+		{8, 3600 * time.Second},
+		{50, 3600 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts, 30 * time.Second, time.Hour); got != tt.want {
+			t.Errorf("attempts %d got %v want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func Test_applyWebhookResult(t *testing.T){
+	webhookConfig := &model.WebhookConfig{MaxAttempts: 3, BackoffBase: 10, BackoffMax: 3600}
+	now := time.Now()
+
+	tests := []struct {
+		name		string
+		attempts	int
+		statusCode	int
+		err			error
+		status		string
+		next		time.Duration
+	}{
+		{"delivered", 0, 204, nil, DeliveryDelivered, 0},
+		{"server error", 0, 500, nil, DeliveryPending, 10 * time.Second},
+		{"network error", 1, 0, errors.New("connection refused"), DeliveryPending, 20 * time.Second},
+		{"redirect is not delivered", 1, 302, nil, DeliveryPending, 20 * time.Second},
+		{"dead letter", 2, 503, nil, DeliveryDead, 40 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			webhookDelivery := &model.WebhookDelivery{ID: 7, Attempts: tt.attempts}
+			attempt := applyWebhookResult(webhookDelivery, tt.statusCode, tt.err, now, time.Millisecond, webhookConfig)
+
+			if webhookDelivery.Status != tt.status {
+				t.Errorf("status %s want %s", webhookDelivery.Status, tt.status)
+			}
+			if webhookDelivery.Attempts != tt.attempts + 1 || attempt.Attempt != tt.attempts + 1 || attempt.FkDeliveryID != 7 {
+				t.Errorf("unexpected attempt %+v", attempt)
+			}
+			if got := webhookDelivery.NextAttemptAt.Sub(now); got != tt.next {
+				t.Errorf("next attempt in %v want %v", got, tt.next)
+			}
+			if tt.status == DeliveryDelivered && (webhookDelivery.DeliveredAt == nil || webhookDelivery.LastError != nil) {
+				t.Errorf("delivered without the date or with an error")
+			}
+			if tt.status != DeliveryDelivered && (attempt.Error == nil || webhookDelivery.LastError == nil) {
+				t.Errorf("the error is not recorded")
+			}
+		})
+	}
+}
+
+func Test_deliverWebhook(t *testing.T){
+	sender := &fakeSender{statusCode: 200}
+	webhookDelivery := &model.WebhookDelivery{ID: 9, Secret: "whsec_test", Payload: []byte(`{"id":"1"}`)}
+
+	deliverWebhook(bypassContext(), sender, webhookDelivery, &model.WebhookConfig{MaxAttempts: 1, Timeout: 1})
+
+	if webhookDelivery.Status != DeliveryDelivered {
+		t.Fatalf("status %s", webhookDelivery.Status)
+	}
+	if sender.header["Webhook-Id"] != "9" || sender.header["Content-Type"] != "application/cloudevents+json" {
+		t.Errorf("unexpected headers %v", sender.header)
+	}
+	ts, _ := strconv.ParseInt(sender.header["Webhook-Timestamp"], 10, 64)
+	if sender.header["Webhook-Signature"] != SignWebhook("whsec_test", ts, webhookDelivery.Payload) {
+		t.Errorf("the signature does not match the timestamp and the body")
+	}
+}
+
+func Test_WebhookSubscription(t *testing.T){
+	ctx := bypassContext()
+	workerService, _ := newMemoryService(t)
+
+	res, err := workerService.AddWebhookSubscription(ctx, &model.WebhookSubscription{TenantID: testTenant, URL: "https://hook.example.com/a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.SubscriptionID == "" || res.Status != WebhookActive || !strings.HasPrefix(res.Secret, "whsec_") {
+		t.Fatalf("subscription %+v", res)
+	}
+
+	// the plain http only in dev
+	if _, err := workerService.AddWebhookSubscription(ctx, &model.WebhookSubscription{TenantID: testTenant, URL: "http://hook.example.com/a"}); !errors.Is(err, erro.ErrBadRequest) {
+		t.Errorf("http subscription err %v", err)
+	}
+	addTestAccount(t, workerService, "ACC-1", "P-1", "CHECKING")
+
+	tests := []struct {
+		name			string
+		ctx				context.Context
+		subscription	model.WebhookSubscription
+		err				error
+	}{
+		{"other tenant", ctx, model.WebhookSubscription{SubscriptionID: res.SubscriptionID, TenantID: "tenant-2"}, erro.ErrNotFound},
+		{"other tenant principal", tenantContext("tenant-2"), model.WebhookSubscription{SubscriptionID: res.SubscriptionID}, erro.ErrNotFound},
+		{"unknown", ctx, model.WebhookSubscription{SubscriptionID: "unknown", TenantID: testTenant}, erro.ErrNotFound},
+		{"disable", ctx, model.WebhookSubscription{SubscriptionID: res.SubscriptionID, TenantID: testTenant}, nil},
+		{"disable twice", ctx, model.WebhookSubscription{SubscriptionID: res.SubscriptionID, TenantID: testTenant}, erro.ErrTransInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res_disable, err := workerService.DisableWebhookSubscription(tt.ctx, &tt.subscription)
+			if err != tt.err {
+				t.Fatalf("err %v want %v", err, tt.err)
+			}
+			if err == nil && (res_disable.Status != WebhookDisabled || res_disable.Secret != "") {
+				t.Errorf("subscription %+v", res_disable)
+			}
+		})
+	}
+
+	// the pending delivery of the account created was canceled
+	list_delivery, err := workerService.ListWebhookDelivery(ctx, &model.WebhookSubscription{SubscriptionID: res.SubscriptionID, TenantID: testTenant}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*list_delivery) != 1 || (*list_delivery)[0].Status != DeliveryCanceled {
+		t.Errorf("deliveries %+v", list_delivery)
+	}
+	list_subscription, err := workerService.ListWebhookSubscription(ctx, &model.WebhookSubscription{TenantID: testTenant})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*list_subscription) != 1 || (*list_subscription)[0].Secret != "" {
+		t.Errorf("subscriptions %+v", list_subscription)
+	}
+}
+
+// a delivery is dead after the max attempts, the redrive sends it again
+func Test_WebhookDispatch(t *testing.T){
+	ctx := bypassContext()
+	workerService, _ := newMemoryService(t)
+	webhookConfig := &model.WebhookConfig{BatchSize: 10, MaxAttempts: 2, BackoffBase: 0, BackoffMax: 0, Timeout: 1}
+
+	res_all, err := workerService.AddWebhookSubscription(ctx, &model.WebhookSubscription{TenantID: testTenant, URL: "https://hook.example.com/all"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res_posted, err := workerService.AddWebhookSubscription(ctx, &model.WebhookSubscription{TenantID: testTenant, URL: "https://hook.example.com/posted", EventTypes: []string{event.TypeBalancePosted}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addTestAccount(t, workerService, "ACC-1", "P-1", "CHECKING")
+	if _, err := workerService.AddPosting(ctx, &model.AccountStatement{AccountID: "ACC-1", Type: StatementCredit, Currency: "BRL", Amount: 10}); err != nil {
+		t.Fatal(err)
+	}
+	subscriptionAll := &model.WebhookSubscription{SubscriptionID: res_all.SubscriptionID, TenantID: testTenant}
+	subscriptionPosted := &model.WebhookSubscription{SubscriptionID: res_posted.SubscriptionID, TenantID: testTenant}
+
+	tests := []struct {
+		name		string
+		sender		*fakeSender
+		dispatched	int
+		status		string
+	}{
+		{"first attempt fails", &fakeSender{statusCode: 500}, 3, DeliveryPending},
+		{"dead", &fakeSender{err: errors.New("connection refused")}, 3, DeliveryDead},
+		{"nothing due", &fakeSender{statusCode: 200}, 0, DeliveryDead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, err := workerService.WebhookDispatch(ctx, tt.sender, webhookConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != tt.dispatched {
+				t.Errorf("dispatched %d want %d", count, tt.dispatched)
+			}
+			list_delivery, err := workerService.ListWebhookDelivery(ctx, subscriptionAll, tt.status)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(*list_delivery) != 2 {
+				t.Errorf("deliveries %s %d want 2", tt.status, len(*list_delivery))
+			}
+		})
+	}
+
+	// only the posted event goes to the filtered subscription, with the history of the attempts
+	list_delivery, err := workerService.ListWebhookDelivery(ctx, subscriptionPosted, DeliveryDead)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*list_delivery) != 1 || (*list_delivery)[0].EventType != event.TypeBalancePosted || len(*(*list_delivery)[0].AttemptList) != 2 {
+		t.Fatalf("deliveries %+v", list_delivery)
+	}
+	deliveryID := (*list_delivery)[0].ID
+
+	retries := []struct {
+		name			string
+		subscription	*model.WebhookSubscription
+		deliveryID		int64
+		err				error
+	}{
+		{"delivery of other subscription", subscriptionAll, deliveryID, erro.ErrTransInvalid},
+		{"redrive", subscriptionPosted, deliveryID, nil},
+		{"not dead", subscriptionPosted, deliveryID, erro.ErrTransInvalid},
+	}
+	for _, tt := range retries {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := workerService.RetryWebhookDelivery(ctx, tt.subscription, tt.deliveryID); err != tt.err {
+				t.Errorf("err %v want %v", err, tt.err)
+			}
+		})
+	}
+
+	sender := &fakeSender{statusCode: 204}
+	if count, err := workerService.WebhookDispatch(ctx, sender, webhookConfig); err != nil || count != 1 {
+		t.Fatalf("redrive dispatched %d err %v", count, err)
+	}
+	list_delivery, err = workerService.ListWebhookDelivery(ctx, subscriptionPosted, DeliveryDelivered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*list_delivery) != 1 || (*list_delivery)[0].DeliveredAt == nil || (*list_delivery)[0].Attempts != 1 {
+		t.Errorf("delivered %+v", list_delivery)
+	}
+
+	if _, err := workerService.DisableWebhookSubscription(ctx, subscriptionAll); err != nil {
+		t.Fatal(err)
+	}
+	if err := workerService.RetryWebhookDelivery(ctx, subscriptionAll, 1); err != erro.ErrTransInvalid {
+		t.Errorf("retry of a disabled subscription err %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package validator
+
+import (
+	"net"
+	"strings"
+)
+
+// About the ranges that are not public besides the loopback, private, link-local and multicast ones
+// (carrier grade nat, benchmarking, documentation and the reserved blocks)
+var reservedNetworks = func() []*net.IPNet {
+	cidrs := []string{	"0.0.0.0/8",
+						"100.64.0.0/10",
+						"192.0.0.0/24",
+						"192.0.2.0/24",
+						"198.18.0.0/15",
+						"198.51.100.0/24",
+						"203.0.113.0/24",
+						"240.0.0.0/4",
+						"64:ff9b::/96",
+						"2001:db8::/32" }
+
+	res := []*net.IPNet{}
+	for _, cidr := range cidrs {
+		_, network, _ := net.ParseCIDR(cidr)
+		res = append(res, network)
+	}
+	return res
+}()
+
+// About check if an ip is public, the loopback, private, link-local (the cloud metadata 169.254.169.254 included)
+// and reserved addresses are not (a webhook can not reach the internal network)
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+	for _, network := range reservedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// About check if a host name can be public, a literal ip must be public and the local names are refused.
+// A name is resolved only when it is dialed, where each resolved ip is checked again
+func IsPublicHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".local") || strings.HasSuffix(host, ".internal") {
+		return false
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return IsPublicIP(ip)
+	}
+	return true
+}
@@ -0,0 +1,254 @@
+package validator
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-account/internal/core/erro"
+)
+
+// About the tag read from the payload structs, ex: `validate:"required,max=50,id"`
+//
+// rules:
+//   required		the value can not be the zero value (empty string, 0, empty slice, nil)
+//   omitempty		the others rules are skipped when the value is the zero value
+//   min=n, max=n	numbers: value limits / strings and slices: length limits
+//   len=n			strings and slices: exact length
+//   gt=n			numbers: value must be greater than n
+//   ne=n			numbers: value must be different from n
+//   oneof=a b c	strings: one of the listed values
+//   id				strings: letters, digits, '.', '-' and '_'
+//   account		strings: same as id plus spaces (printed IBAN format)
+//   alpha			strings: letters only
+//   alphanum		strings: letters and digits only
+//   currency		strings: ISO 4217 currency code
+//   url			strings: absolute http or https url with a public host (not loopback, private or link-local)
+//
+// string rules applied on a slice are checked on each element, nested structs are always validated.
+const tagName = "validate"
+
+// About validate a payload, it returns nil or an *erro.ValidationError listing each failing field
+func Validate(payload interface{}) error {
+	fields := []erro.FieldError{}
+
+	v := reflect.ValueOf(payload)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return &erro.ValidationError{Fields: []erro.FieldError{{Field: "body", Rule: "required", Message: "is required"}}}
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct {
+		validateStruct(v, "", &fields)
+	}
+
+	if len(fields) > 0 {
+		return &erro.ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+func validateStruct(v reflect.Value, prefix string, fields *[]erro.FieldError) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		name := prefix + jsonName(sf)
+
+		if tag := sf.Tag.Get(tagName); tag != "" && tag != "-" {
+			validateField(fv, name, tag, fields)
+		}
+
+		// nested payloads
+		switch {
+		case fv.Kind() == reflect.Struct && fv.Type().PkgPath() != "time":
+			validateStruct(fv, name + ".", fields)
+		case fv.Kind() == reflect.Ptr && !fv.IsNil() && fv.Elem().Kind() == reflect.Struct && fv.Elem().Type().PkgPath() != "time":
+			validateStruct(fv.Elem(), name + ".", fields)
+		}
+	}
+}
+
+func jsonName(sf reflect.StructField) string {
+	name := strings.Split(sf.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+	return name
+}
+
+func validateField(fv reflect.Value, name string, tag string, fields *[]erro.FieldError) {
+	rules := strings.Split(tag, ",")
+
+	for fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			for _, rule := range rules {
+				if rule == "required" {
+					addError(fields, name, "required", "is required")
+				}
+			}
+			return
+		}
+		fv = fv.Elem()
+	}
+
+	if fv.IsZero() {
+		for _, rule := range rules {
+			if rule == "required" {
+				addError(fields, name, "required", "is required")
+				return
+			}
+			if rule == "omitempty" {
+				return
+			}
+		}
+	}
+
+	for _, rule := range rules {
+		key, param, _ := strings.Cut(rule, "=")
+		if key == "required" || key == "omitempty" {
+			continue
+		}
+		if msg := checkRule(fv, key, param); msg != "" {
+			addError(fields, name, key, msg)
+			return
+		}
+	}
+}
+
+func addError(fields *[]erro.FieldError, name string, rule string, msg string) {
+	*fields = append(*fields, erro.FieldError{Field: name, Rule: rule, Message: msg})
+}
+
+func checkRule(fv reflect.Value, key string, param string) string {
+	switch fv.Kind() {
+	case reflect.String:
+		return checkString(fv.String(), key, param)
+	case reflect.Slice, reflect.Array:
+		switch key {
+		case "min", "max", "len":
+			return checkLength(fv.Len(), key, param)
+		}
+		for i := 0; i < fv.Len(); i++ {
+			ev := fv.Index(i)
+			if ev.Kind() != reflect.String {
+				continue
+			}
+			if msg := checkString(ev.String(), key, param); msg != "" {
+				return fmt.Sprintf("[%d] %s", i, msg)
+			}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return checkNumber(float64(fv.Int()), key, param)
+	case reflect.Float32, reflect.Float64:
+		return checkNumber(fv.Float(), key, param)
+	}
+	return ""
+}
+
+func checkLength(n int, key string, param string) string {
+	limit, err := strconv.Atoi(param)
+	if err != nil {
+		return ""
+	}
+	switch key {
+	case "min":
+		if n < limit {
+			return fmt.Sprintf("must have at least %d characters/items", limit)
+		}
+	case "max":
+		if n > limit {
+			return fmt.Sprintf("must have at most %d characters/items", limit)
+		}
+	case "len":
+		if n != limit {
+			return fmt.Sprintf("must have exactly %d characters/items", limit)
+		}
+	}
+	return ""
+}
+
+func checkString(s string, key string, param string) string {
+	switch key {
+	case "min", "max", "len":
+		return checkLength(utf8.RuneCountInString(s), key, param)
+	case "oneof":
+		for _, opt := range strings.Fields(param) {
+			if s == opt {
+				return ""
+			}
+		}
+		return "must be one of [" + param + "]"
+	case "id":
+		for _, r := range s {
+			if !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_') {
+				return "must have only letters, digits, '.', '-' or '_'"
+			}
+		}
+	case "account":
+		for _, r := range s {
+			if !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '-' || r == '_' || r == ' ') {
+				return "must have only letters, digits, spaces, '.', '-' or '_'"
+			}
+		}
+	case "alpha":
+		for _, r := range s {
+			if !unicode.IsLetter(r) {
+				return "must have only letters"
+			}
+		}
+	case "alphanum":
+		for _, r := range s {
+			if !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				return "must have only letters and digits"
+			}
+		}
+	case "currency":
+		if !IsCurrency(s) {
+			return "must be an ISO 4217 currency code"
+		}
+	case "url":
+		u, err := url.Parse(s)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "must be an absolute http or https url"
+		}
+		if !IsPublicHost(u.Hostname()) {
+			return "must not be a loopback, private or link-local address"
+		}
+	}
+	return ""
+}
+
+func checkNumber(n float64, key string, param string) string {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return ""
+	}
+	switch key {
+	case "min":
+		if n < limit {
+			return fmt.Sprintf("must be greater than or equal to %v", limit)
+		}
+	case "max":
+		if n > limit {
+			return fmt.Sprintf("must be less than or equal to %v", limit)
+		}
+	case "gt":
+		if n <= limit {
+			return fmt.Sprintf("must be greater than %v", limit)
+		}
+	case "ne":
+		if n == limit {
+			return fmt.Sprintf("must be different from %v", limit)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,91 @@
+package validator
+
+import (
+	"net"
+	"errors"
+	"testing"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+)
+
+func fieldsOf(t *testing.T, err error) map[string]string {
+	t.Helper()
+	res := map[string]string{}
+	if err == nil {
+		return res
+	}
+	var validationError *erro.ValidationError
+	if !errors.As(err, &validationError) {
+		t.Fatalf("error %v is not a ValidationError", err)
+	}
+	if !errors.Is(err, erro.ErrBadRequest) {
+		t.Errorf("ValidationError must wrap ErrBadRequest")
+	}
+	for _, f := range validationError.Fields {
+		res[f.Field] = f.Rule
+	}
+	return res
+}
+
+func Test_Validate(t *testing.T){
+	tests := []struct {
+		name	string
+		payload	interface{}
+		want	map[string]string
+	}{
+		{"account ok", &model.Account{AccountID: "ACC-1.1", PersonID: "P-1", TenantID: "TENANT-1"}, map[string]string{}},
+		{"account empty", &model.Account{}, map[string]string{"person_id": "required", "tenant_id": "required"}},
+		{"account bad id", &model.Account{AccountID: "ACC/1", PersonID: "P 1", TenantID: "T"}, map[string]string{"account_id": "account", "person_id": "id"}},
+		{"posting", &model.AccountStatement{AccountID: "ACC-1", Type: "FEE", Currency: "XXX"}, map[string]string{"type_charge": "oneof", "currency": "currency", "amount": "required"}},
+		{"transfer", &model.Transfer{AccountFrom: model.AccountBalance{AccountID: "ACC-1"}, Currency: "BRL", Amount: -1}, map[string]string{"account_to.account_id": "required", "amount": "gt"}},
+		{"product", &model.AccountProduct{ProductID: "P1", Type: "SAVINGS", Currencies: []string{"BRL", "usd"}, Overdraft: -1, TenantID: "T"}, map[string]string{"currencies": "currency", "overdraft": "min"}},
+		{"webhook", &model.WebhookSubscription{TenantID: "T", URL: "ftp://hooks.example.com", EventTypes: []string{"go-account.account.created.v1", "account.deleted"}}, map[string]string{"url": "url", "event_types": "oneof"}},
+		{"webhook no host", &model.WebhookSubscription{TenantID: "T", URL: "https:///path"}, map[string]string{"url": "url"}},
+		{"webhook loopback", &model.WebhookSubscription{TenantID: "T", URL: "http://127.0.0.1:8080/hook"}, map[string]string{"url": "url"}},
+		{"webhook metadata", &model.WebhookSubscription{TenantID: "T", URL: "http://169.254.169.254/latest/meta-data"}, map[string]string{"url": "url"}},
+		{"webhook localhost", &model.WebhookSubscription{TenantID: "T", URL: "https://localhost/hook"}, map[string]string{"url": "url"}},
+		{"webhook ok", &model.WebhookSubscription{TenantID: "T", URL: "https://hooks.example.com/a"}, map[string]string{}},
+		{"number format", &model.AccountNumberFormat{TenantID: "T", SequenceLength: 20, CountryCode: "BRA"}, map[string]string{"sequence_length": "max", "country_code": "len"}},
+	}
+
+	for _, tt := range tests {
+		got := fieldsOf(t, Validate(tt.payload))
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v want %v", tt.name, got, tt.want)
+			continue
+		}
+		for field, rule := range tt.want {
+			if got[field] != rule {
+				t.Errorf("%s: field %s got rule %q want %q", tt.name, field, got[field], rule)
+			}
+		}
+	}
+}
+
+func Test_IsPublicIP(t *testing.T){
+	tests := []struct {
+		ip		string
+		want	bool
+	}{
+		{"8.8.8.8", true},
+		{"2606:4700::1111", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fd00:ec2::254", false},
+		{"fe80::1", false},
+		{"0.0.0.0", false},
+		{"100.64.0.1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsPublicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("%s: got %v want %v", tt.ip, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package configuration
+
+import(
+	"os"
+	"strings"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-account/internal/core/model"
+)
+
+// About get all consumer env var
+func GetConsumerEnv() model.ConsumerConfig {
+	childLogger.Info().Str("func","GetConsumerEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var consumerConfig	model.ConsumerConfig
+
+	consumerConfig.Name = "go-account.posting"
+	consumerConfig.KafkaTopic = "account.postings"
+	consumerConfig.KafkaGroupID = "go-account"
+	consumerConfig.FilePath = "postings.jsonl"
+	consumerConfig.RetryInterval = 5
+	consumerConfig.MaxAttempts = 20
+
+	// empty disable the consumer
+	if os.Getenv("EVENT_CONSUMER") !=  "" {
+		consumerConfig.Consumer = os.Getenv("EVENT_CONSUMER")
+	}
+	if os.Getenv("CONSUMER_NAME") !=  "" {
+		consumerConfig.Name = os.Getenv("CONSUMER_NAME")
+	}
+	if os.Getenv("KAFKA_BROKERS") !=  "" {
+		consumerConfig.KafkaBrokers = strings.Split(os.Getenv("KAFKA_BROKERS"), ",")
+	}
+	if os.Getenv("POSTING_TOPIC") !=  "" {
+		consumerConfig.KafkaTopic = os.Getenv("POSTING_TOPIC")
+	}
+	if os.Getenv("KAFKA_GROUP_ID") !=  "" {
+		consumerConfig.KafkaGroupID = os.Getenv("KAFKA_GROUP_ID")
+	}
+	if os.Getenv("POSTING_FILE_PATH") !=  "" {
+		consumerConfig.FilePath = os.Getenv("POSTING_FILE_PATH")
+	}
+	if os.Getenv("CONSUMER_RETRY_INTERVAL") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("CONSUMER_RETRY_INTERVAL"))
+		consumerConfig.RetryInterval = intVar
+	}
+	// 0 retries forever (the partition waits)
+	if os.Getenv("CONSUMER_MAX_ATTEMPTS") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("CONSUMER_MAX_ATTEMPTS"))
+		consumerConfig.MaxAttempts = intVar
+	}
+
+	return consumerConfig
+}
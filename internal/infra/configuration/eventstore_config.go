@@ -0,0 +1,36 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-account/internal/core/model"
+)
+
+// About get the persistence mode of the account aggregate
+func GetEventStoreEnv() model.EventStoreConfig {
+	childLogger.Info().Str("func","GetEventStoreEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var eventStoreConfig	model.EventStoreConfig
+
+	eventStoreConfig.Mode = "state"
+	eventStoreConfig.SnapshotInterval = 50
+
+	// state or eventstore
+	if os.Getenv("ACCOUNT_PERSISTENCE") !=  "" {
+		eventStoreConfig.Mode = os.Getenv("ACCOUNT_PERSISTENCE")
+	}
+	// 0 disable the snapshots
+	if os.Getenv("SNAPSHOT_INTERVAL") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("SNAPSHOT_INTERVAL"))
+		eventStoreConfig.SnapshotInterval = intVar
+	}
+
+	return eventStoreConfig
+}
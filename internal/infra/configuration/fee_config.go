@@ -0,0 +1,29 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-account/internal/core/model"
+)
+
+// About get all fee job env var
+func GetFeeJobEnv() model.FeeJob {
+	childLogger.Info().Str("func","GetFeeJobEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var feeJob	model.FeeJob
+
+	// 0 disable the job, the maintenance fee still can be triggered by /maintenanceFee/{month}
+	if os.Getenv("FEE_JOB_INTERVAL") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("FEE_JOB_INTERVAL"))
+		feeJob.Interval = intVar
+	}
+
+	return feeJob
+}
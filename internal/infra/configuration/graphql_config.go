@@ -0,0 +1,35 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-account/internal/core/model"
+)
+
+// About get all graphql env var
+func GetGraphQLEnv() model.GraphQLConfig {
+	childLogger.Info().Str("func","GetGraphQLEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var graphQLConfig	model.GraphQLConfig
+
+	graphQLConfig.MaxDepth = 5
+	graphQLConfig.MaxCost = 1000
+
+	if os.Getenv("GRAPHQL_MAX_DEPTH") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("GRAPHQL_MAX_DEPTH"))
+		graphQLConfig.MaxDepth = intVar
+	}
+	if os.Getenv("GRAPHQL_MAX_COST") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("GRAPHQL_MAX_COST"))
+		graphQLConfig.MaxCost = intVar
+	}
+
+	return graphQLConfig
+}
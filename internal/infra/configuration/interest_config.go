@@ -0,0 +1,29 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-account/internal/core/model"
+)
+
+// About get all interest job env var
+func GetInterestJobEnv() model.InterestJob {
+	childLogger.Info().Str("func","GetInterestJobEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var interestJob	model.InterestJob
+
+	// 0 disable the job, the accrual still can be triggered by /interestAccrual/{date}
+	if os.Getenv("INTEREST_JOB_INTERVAL") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("INTEREST_JOB_INTERVAL"))
+		interestJob.Interval = intVar
+	}
+
+	return interestJob
+}
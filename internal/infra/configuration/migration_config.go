@@ -0,0 +1,33 @@
+package configuration
+
+import(
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/go-account/internal/core/model"
+)
+
+// About get the schema migration env var
+func GetMigrationEnv() model.MigrationConfig {
+	childLogger.Info().Str("func","GetMigrationEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var migrationConfig	model.MigrationConfig
+
+	migrationConfig.SchemaCheck = true
+
+	// apply the pending migrations before starting (the replicas wait on the advisory lock)
+	if os.Getenv("MIGRATE_ON_STARTUP") == "true" {
+		migrationConfig.MigrateOnStartup = true
+	}
+	// false starts even when the schema is behind the service
+	if os.Getenv("SCHEMA_CHECK") == "false" {
+		migrationConfig.SchemaCheck = false
+	}
+
+	return migrationConfig
+}
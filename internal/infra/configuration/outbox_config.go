@@ -0,0 +1,61 @@
+package configuration
+
+import(
+	"os"
+	"strings"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-account/internal/core/model"
+)
+
+// About get all outbox env var
+func GetOutboxEnv() model.OutboxConfig {
+	childLogger.Info().Str("func","GetOutboxEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var outboxConfig	model.OutboxConfig
+
+	outboxConfig.BatchSize = 100
+	outboxConfig.MaxAttempts = 10
+	outboxConfig.ClaimLease = 60
+	outboxConfig.KafkaTopic = "account.events"
+	outboxConfig.FilePath = "outbox_events.jsonl"
+
+	// 0 disable the relay, the events stay in the outbox
+	if os.Getenv("OUTBOX_RELAY_INTERVAL") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("OUTBOX_RELAY_INTERVAL"))
+		outboxConfig.RelayInterval = intVar
+	}
+	if os.Getenv("OUTBOX_BATCH_SIZE") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("OUTBOX_BATCH_SIZE"))
+		outboxConfig.BatchSize = intVar
+	}
+	// 0 never park an event, it is retried forever
+	if os.Getenv("OUTBOX_MAX_ATTEMPTS") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("OUTBOX_MAX_ATTEMPTS"))
+		outboxConfig.MaxAttempts = intVar
+	}
+	if os.Getenv("OUTBOX_CLAIM_LEASE") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("OUTBOX_CLAIM_LEASE"))
+		outboxConfig.ClaimLease = intVar
+	}
+	if os.Getenv("EVENT_PUBLISHER") !=  "" {
+		outboxConfig.Publisher = os.Getenv("EVENT_PUBLISHER")
+	}
+	if os.Getenv("KAFKA_BROKERS") !=  "" {
+		outboxConfig.KafkaBrokers = strings.Split(os.Getenv("KAFKA_BROKERS"), ",")
+	}
+	if os.Getenv("KAFKA_TOPIC") !=  "" {
+		outboxConfig.KafkaTopic = os.Getenv("KAFKA_TOPIC")
+	}
+	if os.Getenv("EVENT_FILE_PATH") !=  "" {
+		outboxConfig.FilePath = os.Getenv("EVENT_FILE_PATH")
+	}
+
+	return outboxConfig
+}
@@ -81,6 +81,12 @@ func GetInfoPod() (	model.InfoPod, model.Server) {
 		server.Port = intVar
 	}
 
+	// the grpc server is started only when the port is informed
+	if os.Getenv("GRPC_PORT") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("GRPC_PORT"))
+		server.GrpcPort = intVar
+	}
+
 	server.ReadTimeout = 60
 	server.WriteTimeout = 60
 	server.IdleTimeout = 60
@@ -0,0 +1,58 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-account/internal/core/model"
+)
+
+// About get all rate limit env var
+func GetRateLimitEnv() model.RateLimitConfig {
+	childLogger.Info().Str("func","GetRateLimitEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var rateLimitConfig	model.RateLimitConfig
+
+	rateLimitConfig.ReadRate = 50
+	rateLimitConfig.ReadBurst = 100
+	rateLimitConfig.WriteRate = 10
+	rateLimitConfig.WriteBurst = 20
+	rateLimitConfig.Store = "memory"
+	rateLimitConfig.FailOpen = true
+
+	if os.Getenv("RATE_LIMIT_ENABLED") == "true" {
+		rateLimitConfig.Enabled = true
+	} else {
+		rateLimitConfig.Enabled = false
+	}
+	if os.Getenv("RATE_LIMIT_READ_RATE") !=  "" {
+		floatVar, _ := strconv.ParseFloat(os.Getenv("RATE_LIMIT_READ_RATE"), 64)
+		rateLimitConfig.ReadRate = floatVar
+	}
+	if os.Getenv("RATE_LIMIT_READ_BURST") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("RATE_LIMIT_READ_BURST"))
+		rateLimitConfig.ReadBurst = intVar
+	}
+	if os.Getenv("RATE_LIMIT_WRITE_RATE") !=  "" {
+		floatVar, _ := strconv.ParseFloat(os.Getenv("RATE_LIMIT_WRITE_RATE"), 64)
+		rateLimitConfig.WriteRate = floatVar
+	}
+	if os.Getenv("RATE_LIMIT_WRITE_BURST") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("RATE_LIMIT_WRITE_BURST"))
+		rateLimitConfig.WriteBurst = intVar
+	}
+	if os.Getenv("RATE_LIMIT_STORE") !=  "" {
+		rateLimitConfig.Store = os.Getenv("RATE_LIMIT_STORE")
+	}
+	if os.Getenv("RATE_LIMIT_FAIL_OPEN") == "false" {
+		rateLimitConfig.FailOpen = false
+	}
+
+	return rateLimitConfig
+}
@@ -0,0 +1,61 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/go-account/internal/core/model"
+)
+
+// About get all security env var
+func GetSecurityEnv() model.SecurityConfig {
+	childLogger.Info().Str("func","GetSecurityEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var securityConfig	model.SecurityConfig
+
+	securityConfig.JwksRefresh = 300
+	securityConfig.DefaultRole = "viewer"
+	securityConfig.RoleCacheTTL = 60
+
+	if os.Getenv("AUTH_DISABLED") == "true" {
+		securityConfig.AuthDisabled = true
+	} else {
+		securityConfig.AuthDisabled = false
+	}
+	if os.Getenv("JWKS_URL") !=  "" {
+		securityConfig.JwksUrl = os.Getenv("JWKS_URL")
+	}
+	if os.Getenv("JWKS_FILE") !=  "" {
+		securityConfig.JwksFile = os.Getenv("JWKS_FILE")
+	}
+	if os.Getenv("JWKS_REFRESH") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("JWKS_REFRESH"))
+		securityConfig.JwksRefresh = intVar
+	}
+	if os.Getenv("JWT_ISSUER") !=  "" {
+		securityConfig.Issuer = os.Getenv("JWT_ISSUER")
+	}
+	if os.Getenv("JWT_AUDIENCE") !=  "" {
+		securityConfig.Audience = os.Getenv("JWT_AUDIENCE")
+	}
+
+	if os.Getenv("RBAC_DEFAULT_ROLE") !=  "" {
+		securityConfig.DefaultRole = os.Getenv("RBAC_DEFAULT_ROLE")
+	}
+	if os.Getenv("RBAC_CACHE_TTL") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("RBAC_CACHE_TTL"))
+		securityConfig.RoleCacheTTL = intVar
+	}
+	if os.Getenv("MTLS_CERT_SCOPES") !=  "" {
+		securityConfig.CertScopes = strings.Split(os.Getenv("MTLS_CERT_SCOPES"), ",")
+	}
+
+	return securityConfig
+}
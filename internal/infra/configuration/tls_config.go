@@ -0,0 +1,43 @@
+package configuration
+
+import(
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/go-account/internal/core/model"
+)
+
+// About get all tls env var
+func GetTlsEnv() model.TlsConfig {
+	childLogger.Info().Str("func","GetTlsEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var tlsConfig	model.TlsConfig
+
+	tlsConfig.ClientAuth = "none"
+
+	if os.Getenv("TLS") == "true" {
+		tlsConfig.Enabled = true
+	} else {
+		tlsConfig.Enabled = false
+	}
+	if os.Getenv("TLS_CERT_FILE") !=  "" {
+		tlsConfig.CertFile = os.Getenv("TLS_CERT_FILE")
+	}
+	if os.Getenv("TLS_KEY_FILE") !=  "" {
+		tlsConfig.KeyFile = os.Getenv("TLS_KEY_FILE")
+	}
+	if os.Getenv("TLS_CA_FILE") !=  "" {
+		tlsConfig.CaFile = os.Getenv("TLS_CA_FILE")
+		tlsConfig.ClientAuth = "require"
+	}
+	if os.Getenv("TLS_CLIENT_AUTH") !=  "" {
+		tlsConfig.ClientAuth = os.Getenv("TLS_CLIENT_AUTH")
+	}
+
+	return tlsConfig
+}
@@ -0,0 +1,58 @@
+package configuration
+
+import(
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+	"github.com/go-account/internal/core/model"
+)
+
+// About get all webhook env var
+func GetWebhookEnv() model.WebhookConfig {
+	childLogger.Info().Str("func","GetWebhookEnv").Send()
+
+	err := godotenv.Load(".env")
+	if err != nil {
+		childLogger.Info().Err(err).Send()
+	}
+
+	var webhookConfig	model.WebhookConfig
+
+	webhookConfig.BatchSize = 20
+	webhookConfig.MaxAttempts = 8
+	webhookConfig.BackoffBase = 30
+	webhookConfig.BackoffMax = 3600
+	webhookConfig.Timeout = 10
+
+	// the plain http urls are only allowed in dev
+	webhookConfig.AllowHttp = os.Getenv("ENV") == "dev"
+
+	// 0 disable the dispatch, the deliveries stay pending
+	if os.Getenv("WEBHOOK_DISPATCH_INTERVAL") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("WEBHOOK_DISPATCH_INTERVAL"))
+		webhookConfig.DispatchInterval = intVar
+	}
+	if os.Getenv("WEBHOOK_BATCH_SIZE") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("WEBHOOK_BATCH_SIZE"))
+		webhookConfig.BatchSize = intVar
+	}
+	if os.Getenv("WEBHOOK_MAX_ATTEMPTS") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS"))
+		webhookConfig.MaxAttempts = intVar
+	}
+	if os.Getenv("WEBHOOK_BACKOFF_BASE") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("WEBHOOK_BACKOFF_BASE"))
+		webhookConfig.BackoffBase = intVar
+	}
+	if os.Getenv("WEBHOOK_BACKOFF_MAX") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("WEBHOOK_BACKOFF_MAX"))
+		webhookConfig.BackoffMax = intVar
+	}
+	if os.Getenv("WEBHOOK_TIMEOUT") !=  "" {
+		intVar, _ := strconv.Atoi(os.Getenv("WEBHOOK_TIMEOUT"))
+		webhookConfig.Timeout = intVar
+	}
+
+	return webhookConfig
+}
@@ -0,0 +1,53 @@
+package event
+
+import (
+	"sync"
+	"context"
+
+	"github.com/go-account/internal/core/model"
+)
+
+// About a consumer fed by a channel (tests), the committed offsets are kept in memory
+type ChannelConsumer struct {
+	messages	chan *model.InboundMessage
+	mu			sync.Mutex
+	committed	[]int64
+}
+
+func NewChannelConsumer(size int) *ChannelConsumer {
+	return &ChannelConsumer{messages: make(chan *model.InboundMessage, size)}
+}
+
+// About put a message to be consumed
+func (c *ChannelConsumer) Send(message *model.InboundMessage) {
+	c.messages <- message
+}
+
+func (c *ChannelConsumer) Fetch(ctx context.Context) (*model.InboundMessage, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case message := <-c.messages:
+		return message, nil
+	}
+}
+
+func (c *ChannelConsumer) Commit(ctx context.Context, message *model.InboundMessage) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.committed = append(c.committed, message.Offset)
+	return nil
+}
+
+// About the committed offsets, in the commit order
+func (c *ChannelConsumer) Committed() []int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]int64{}, c.committed...)
+}
+
+func (c *ChannelConsumer) Close() error {
+	return nil
+}
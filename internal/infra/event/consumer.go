@@ -0,0 +1,37 @@
+package event
+
+import (
+	"fmt"
+	"context"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/service"
+)
+
+// About the consumers of the inbound messages (posting commands)
+const (
+	ConsumerKafka	= "kafka"
+	ConsumerFile	= "file"
+	ConsumerChannel	= "channel"
+)
+
+// About a consumer that can release its resources
+type Consumer interface {
+	service.MessageConsumer
+	Close() error
+}
+
+// About create the consumer of the config
+func NewConsumer(ctx context.Context, consumerConfig *model.ConsumerConfig) (Consumer, error) {
+	childLogger.Info().Str("func","NewConsumer").Str("consumer", consumerConfig.Consumer).Send()
+
+	switch consumerConfig.Consumer {
+	case ConsumerKafka:
+		return NewKafkaConsumer(consumerConfig.KafkaBrokers, consumerConfig.KafkaTopic, consumerConfig.KafkaGroupID)
+	case ConsumerFile:
+		return NewFileConsumer(consumerConfig.FilePath)
+	case ConsumerChannel:
+		return NewChannelConsumer(100), nil
+	}
+	return nil, fmt.Errorf("event consumer %q not supported", consumerConfig.Consumer)
+}
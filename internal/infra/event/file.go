@@ -0,0 +1,43 @@
+package event
+
+import (
+	"os"
+	"sync"
+	"context"
+	"encoding/json"
+
+	"github.com/go-account/internal/core/model"
+)
+
+// About a publisher that appends the events into a file, one json per line
+type FilePublisher struct {
+	mu		sync.Mutex
+	file	*os.File
+}
+
+func NewFilePublisher(path string) (*FilePublisher, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &FilePublisher{file: file}, nil
+}
+
+func (f *FilePublisher) Publish(ctx context.Context, outboxEvent model.OutboxEvent) error {
+	data, err := json.Marshal(outboxEvent)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return f.file.Sync()
+}
+
+func (f *FilePublisher) Close() error {
+	return f.file.Close()
+}
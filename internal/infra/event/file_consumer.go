@@ -0,0 +1,95 @@
+package event
+
+import (
+	"io"
+	"os"
+	"time"
+	"bytes"
+	"bufio"
+	"errors"
+	"context"
+	"strconv"
+	"encoding/json"
+
+	"github.com/go-account/internal/core/model"
+)
+
+// About the wait for new lines at the end of the file
+const filePollInterval = time.Second
+
+// About a consumer of a file with one message per line {"key": "...", "value": {...}} (local runs).
+// The offset is the line number, the committed offset is kept in <path>.offset so a restart goes on from it
+type FileConsumer struct {
+	file		*os.File
+	reader		*bufio.Reader
+	offsetPath	string
+	offset		int64
+	partial		[]byte
+}
+
+func NewFileConsumer(path string) (*FileConsumer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &FileConsumer{	file: file,
+						reader: bufio.NewReader(file),
+						offsetPath: path + ".offset" }
+
+	// skip the committed lines
+	committed := int64(0)
+	if data, err := os.ReadFile(f.offsetPath); err == nil {
+		committed, _ = strconv.ParseInt(string(bytes.TrimSpace(data)), 10, 64)
+	}
+	for f.offset < committed {
+		if _, err := f.reader.ReadBytes('\n'); err != nil {
+			break
+		}
+		f.offset++
+	}
+
+	return f, nil
+}
+
+func (f *FileConsumer) Fetch(ctx context.Context) (*model.InboundMessage, error) {
+	for {
+		line, err := f.reader.ReadBytes('\n')
+		f.partial = append(f.partial, line...)
+		if errors.Is(err, io.EOF) {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(filePollInterval):
+				continue
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line = bytes.TrimSpace(f.partial)
+		f.partial = nil
+		f.offset++
+		if len(line) == 0 {
+			continue
+		}
+
+		message := model.InboundMessage{}
+		if err := json.Unmarshal(line, &message); err != nil {
+			// the line is handed as the value, the command is rejected by the handler
+			message = model.InboundMessage{Value: line}
+		}
+		message.Topic = f.file.Name()
+		message.Offset = f.offset
+		return &message, nil
+	}
+}
+
+func (f *FileConsumer) Commit(ctx context.Context, message *model.InboundMessage) error {
+	return os.WriteFile(f.offsetPath, []byte(strconv.FormatInt(message.Offset, 10)), 0644)
+}
+
+func (f *FileConsumer) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,55 @@
+package event
+
+import (
+	"os"
+	"context"
+	"testing"
+	"path/filepath"
+
+	"github.com/go-account/internal/core/model"
+)
+
+// a restart goes on from the committed offset
+func TestFileConsumer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "postings.jsonl")
+	lines := `{"key":"K1","value":{"account_id":"ACC-1","amount":10}}
+{"key":"K2","value":{"account_id":"ACC-2","amount":20}}
+`
+	if err := os.WriteFile(path, []byte(lines), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	consumer, err := NewConsumer(context.Background(), &model.ConsumerConfig{Consumer: ConsumerFile, FilePath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := consumer.Fetch(context.Background())
+	if err != nil || message.Key != "K1" || message.Offset != 1 || string(message.Value) != `{"account_id":"ACC-1","amount":10}` {
+		t.Fatalf("unexpected message %+v %v", message, err)
+	}
+	if err := consumer.Commit(context.Background(), message); err != nil {
+		t.Fatal(err)
+	}
+	// read but not committed
+	if message, err = consumer.Fetch(context.Background()); err != nil || message.Key != "K2" {
+		t.Fatalf("unexpected message %+v %v", message, err)
+	}
+	consumer.Close()
+
+	consumer, err = NewFileConsumer(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer consumer.Close()
+	message, err = consumer.Fetch(context.Background())
+	if err != nil || message.Key != "K2" || message.Offset != 2 {
+		t.Fatalf("the uncommitted message is not read again %+v %v", message, err)
+	}
+
+	// the end of the file waits for new lines until the context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := consumer.Fetch(ctx); err != context.Canceled {
+		t.Errorf("got %v want context canceled", err)
+	}
+}
@@ -0,0 +1,59 @@
+package event
+
+import (
+	"os"
+	"bufio"
+	"context"
+	"testing"
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/go-account/internal/core/model"
+)
+
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	publisher, err := NewPublisher(context.Background(), &model.OutboxConfig{Publisher: PublisherFile, FilePath: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, eventType := range []string{"account.created", "balance.posted"} {
+		outboxEvent := model.OutboxEvent{	ID: int64(i + 1),
+											AggregateID: "ACC-1",
+											EventType: eventType,
+											Payload: json.RawMessage(`{"account_id":"ACC-1"}`) }
+		if err := publisher.Publish(context.Background(), outboxEvent); err != nil {
+			t.Fatal(err)
+		}
+	}
+	publisher.Close()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	got := []model.OutboxEvent{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		outboxEvent := model.OutboxEvent{}
+		if err := json.Unmarshal(scanner.Bytes(), &outboxEvent); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, outboxEvent)
+	}
+	if len(got) != 2 || got[0].EventType != "account.created" || got[1].ID != 2 || string(got[1].Payload) != `{"account_id":"ACC-1"}` {
+		t.Errorf("unexpected events %+v", got)
+	}
+}
+
+func TestNewPublisherNotSupported(t *testing.T) {
+	if _, err := NewPublisher(context.Background(), &model.OutboxConfig{Publisher: "sqs"}); err == nil {
+		t.Errorf("an unknown publisher must fail")
+	}
+	if _, err := NewPublisher(context.Background(), &model.OutboxConfig{Publisher: PublisherKafka}); err == nil {
+		t.Errorf("kafka without brokers must fail")
+	}
+}
@@ -0,0 +1,52 @@
+package event
+
+import (
+	"time"
+	"errors"
+	"context"
+	"strconv"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/event"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// About a publisher into a kafka topic, the value is the CloudEvents envelope (structured mode) and the key
+// is the account so the events of an account go to the same partition (ordering per account)
+type KafkaPublisher struct {
+	writer	*kafka.Writer
+}
+
+func NewKafkaPublisher(brokers []string, topic string) (*KafkaPublisher, error) {
+	if len(brokers) == 0 || topic == "" {
+		return nil, errors.New("kafka brokers and topic are required")
+	}
+
+	return &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:			kafka.TCP(brokers...),
+			Topic:			topic,
+			Balancer:		&kafka.Hash{},
+			RequiredAcks:	kafka.RequireAll,
+			BatchSize:		1,
+			WriteTimeout:	10 * time.Second,
+		},
+	}, nil
+}
+
+func (k *KafkaPublisher) Publish(ctx context.Context, outboxEvent model.OutboxEvent) error {
+	return k.writer.WriteMessages(ctx, kafka.Message{
+		Key:	[]byte(outboxEvent.AggregateID),
+		Value:	outboxEvent.Payload,
+		Time:	outboxEvent.CreatedAt,
+		Headers: []kafka.Header{
+			{Key: "content-type", Value: []byte(event.ContentTypeEvent)},
+			{Key: "outbox_id", Value: []byte(strconv.FormatInt(outboxEvent.ID, 10))},
+		},
+	})
+}
+
+func (k *KafkaPublisher) Close() error {
+	return k.writer.Close()
+}
@@ -0,0 +1,55 @@
+package event
+
+import (
+	"errors"
+	"context"
+
+	"github.com/go-account/internal/core/model"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// About a consumer of a kafka topic in a consumer group, the offsets are committed explicitly
+// (after the message was handled), a restart reads again the messages not committed
+type KafkaConsumer struct {
+	reader	*kafka.Reader
+}
+
+func NewKafkaConsumer(brokers []string, topic string, groupID string) (*KafkaConsumer, error) {
+	if len(brokers) == 0 || topic == "" || groupID == "" {
+		return nil, errors.New("kafka brokers, topic and group id are required")
+	}
+
+	return &KafkaConsumer{
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers:		brokers,
+			Topic:			topic,
+			GroupID:		groupID,
+			MinBytes:		1,
+			MaxBytes:		10e6,
+			StartOffset:	kafka.FirstOffset,
+		}),
+	}, nil
+}
+
+func (k *KafkaConsumer) Fetch(ctx context.Context) (*model.InboundMessage, error) {
+	message, err := k.reader.FetchMessage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &model.InboundMessage{	Topic: message.Topic,
+									Partition: message.Partition,
+									Offset: message.Offset,
+									Key: string(message.Key),
+									Value: message.Value }, nil
+}
+
+func (k *KafkaConsumer) Commit(ctx context.Context, message *model.InboundMessage) error {
+	return k.reader.CommitMessages(ctx, kafka.Message{	Topic: message.Topic,
+														Partition: message.Partition,
+														Offset: message.Offset })
+}
+
+func (k *KafkaConsumer) Close() error {
+	return k.reader.Close()
+}
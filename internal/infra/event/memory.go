@@ -0,0 +1,44 @@
+package event
+
+import (
+	"sync"
+	"context"
+
+	"github.com/go-account/internal/core/model"
+)
+
+// About a publisher that keeps the events in memory (tests), Fail can simulate a delivery error
+type MemoryPublisher struct {
+	mu		sync.Mutex
+	events	[]model.OutboxEvent
+	Fail	func(outboxEvent model.OutboxEvent) error
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (m *MemoryPublisher) Publish(ctx context.Context, outboxEvent model.OutboxEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Fail != nil {
+		if err := m.Fail(outboxEvent); err != nil {
+			return err
+		}
+	}
+	m.events = append(m.events, outboxEvent)
+	return nil
+}
+
+// About the published events, in the delivery order
+func (m *MemoryPublisher) Events() []model.OutboxEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]model.OutboxEvent{}, m.events...)
+}
+
+func (m *MemoryPublisher) Close() error {
+	return nil
+}
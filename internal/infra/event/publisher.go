@@ -0,0 +1,40 @@
+package event
+
+import (
+	"fmt"
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/service"
+)
+
+var childLogger = log.With().Str("component","go-account").Str("package","internal.infra.event").Logger()
+
+// About the publishers of the outbox events
+const (
+	PublisherKafka	= "kafka"
+	PublisherFile	= "file"
+	PublisherMemory	= "memory"
+)
+
+// About a publisher that can release its resources
+type Publisher interface {
+	service.EventPublisher
+	Close() error
+}
+
+// About create the publisher of the config
+func NewPublisher(ctx context.Context, outboxConfig *model.OutboxConfig) (Publisher, error) {
+	childLogger.Info().Str("func","NewPublisher").Str("publisher", outboxConfig.Publisher).Send()
+
+	switch outboxConfig.Publisher {
+	case PublisherKafka:
+		return NewKafkaPublisher(outboxConfig.KafkaBrokers, outboxConfig.KafkaTopic)
+	case PublisherFile:
+		return NewFilePublisher(outboxConfig.FilePath)
+	case PublisherMemory:
+		return NewMemoryPublisher(), nil
+	}
+	return nil, fmt.Errorf("event publisher %q not supported", outboxConfig.Publisher)
+}
@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+	"context"
+)
+
+// About the time of a bucket with no use to be removed
+const idleBucket = 10 * time.Minute
+
+type bucket struct {
+	tokens		float64
+	updatedAt	time.Time
+}
+
+// About the token buckets kept in memory, each replica has its own buckets
+type MemoryStore struct {
+	mutex		sync.Mutex
+	buckets		map[string]*bucket
+	sweepAt		time.Time
+	now			func() time.Time
+}
+
+// About create a memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{	buckets: map[string]*bucket{},
+							sweepAt: time.Now().Add(idleBucket),
+							now: time.Now }
+}
+
+// About take a token of the bucket, the bucket is refilled by rate tokens per second up to burst
+func (m *MemoryStore) Take(ctx context.Context, key string, rate float64, burst int) (Result, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(burst), updatedAt: now}
+		m.buckets[key] = b
+	}
+
+	b.tokens = b.tokens + now.Sub(b.updatedAt).Seconds() * rate
+	if b.tokens > float64(burst) {
+		b.tokens = float64(burst)
+	}
+	b.updatedAt = now
+
+	if b.tokens < 1 {
+		return Result{Allowed: false, Remaining: b.tokens}, nil
+	}
+	b.tokens = b.tokens - 1
+	return Result{Allowed: true, Remaining: b.tokens}, nil
+}
+
+// About remove the idle buckets (they would be full anyway)
+func (m *MemoryStore) sweep(now time.Time) {
+	if now.Before(m.sweepAt) {
+		return
+	}
+	for key, b := range m.buckets {
+		if now.Sub(b.updatedAt) > idleBucket {
+			delete(m.buckets, key)
+		}
+	}
+	m.sweepAt = now.Add(idleBucket)
+}
@@ -0,0 +1,198 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"context"
+	"strconv"
+	"net"
+	"net/http"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/auth"
+
+	"github.com/eliezerraj/go-core/coreJson"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// About the route classes, each one has its own rate and burst
+const (
+	ClassRead	= "read"
+	ClassWrite	= "write"
+)
+
+var (
+	childLogger = log.With().Str("component","go-account").Str("package","internal.infra.ratelimit").Logger()
+	core_json coreJson.CoreJson
+)
+
+// About the result of taking a token of a bucket
+type Result struct {
+	Allowed		bool
+	Remaining	float64
+}
+
+// About the token buckets storage (memory per replica or postgres shared by the replicas)
+type Store interface {
+	Take(ctx context.Context, key string, rate float64, burst int) (Result, error)
+}
+
+// About adapt a function to the Store interface
+type StoreFunc func(ctx context.Context, key string, rate float64, burst int) (Result, error)
+
+func (f StoreFunc) Take(ctx context.Context, key string, rate float64, burst int) (Result, error) {
+	return f(ctx, key, rate, burst)
+}
+
+type limit struct {
+	rate	float64
+	burst	int
+}
+
+type RateLimiter struct {
+	enabled		bool
+	failOpen	bool
+	store		Store
+	limits		map[string]limit
+	storeErrors	metric.Int64Counter
+}
+
+// About create the rate limiter, a nil store uses the memory store
+func NewRateLimiter(rateLimitConfig *model.RateLimitConfig, store Store) *RateLimiter {
+	childLogger.Info().Str("func","NewRateLimiter").Interface("rateLimitConfig", rateLimitConfig).Send()
+
+	if store == nil {
+		store = NewMemoryStore()
+	}
+
+	// the errors of the store, exported by the meter provider of the process
+	storeErrors, err := otel.Meter("go-account").Int64Counter("rate_limit.store.errors",
+																metric.WithDescription("errors of the rate limit store"))
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error create rate limit store errors counter")
+	}
+
+	return &RateLimiter{
+		enabled: rateLimitConfig.Enabled,
+		failOpen: rateLimitConfig.FailOpen,
+		store: store,
+		storeErrors: storeErrors,
+		limits: map[string]limit{	ClassRead: {rate: rateLimitConfig.ReadRate, burst: rateLimitConfig.ReadBurst},
+									ClassWrite: {rate: rateLimitConfig.WriteRate, burst: rateLimitConfig.WriteBurst} },
+	}
+}
+
+// About the bucket key of a request: the api key, otherwise the tenant, otherwise the client ip (auth disabled)
+func clientKey(ctx context.Context, remoteAddr string) string {
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		if principal.Method == "api_key" {
+			return "apikey:" + principal.TokenID
+		}
+		if principal.TenantID != "" {
+			return "tenant:" + principal.TenantID
+		}
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	return "ip:" + host
+}
+
+// About take a token of the bucket, a store error is counted and then the request goes on without
+// the RateLimit headers (nil result, fail open) or it is refused with erro.ErrRateLimitStore (fail closed)
+func (r *RateLimiter) take(ctx context.Context, class string, key string, l limit) (*Result, error) {
+	res, err := r.store.Take(ctx, key, l.rate, l.burst)
+	if err == nil {
+		return &res, nil
+	}
+
+	childLogger.Error().Err(err).Str("key", key).Bool("fail_open", r.failOpen).Msg("error rate limit store")
+	if r.storeErrors != nil {
+		r.storeErrors.Add(ctx, 1, metric.WithAttributes(attribute.String("class", class), attribute.Bool("fail_open", r.failOpen)))
+	}
+	if r.failOpen {
+		return nil, nil
+	}
+	return nil, erro.ErrRateLimitStore
+}
+
+// About limit the requests of a route class, it must run after the authentication (the key comes from the principal)
+func (r *RateLimiter) Limit(class string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		l, ok := r.limits[class]
+		if !r.enabled || !ok || l.rate <= 0 || l.burst <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			key := class + "|" + clientKey(req.Context(), req.RemoteAddr)
+
+			res, err := r.take(req.Context(), class, key, l)
+			if err != nil {
+				trace_id := fmt.Sprintf("%v", req.Context().Value("trace-request-id"))
+				var core_apiError coreJson.APIError
+				core_apiError = core_apiError.NewAPIError(err, trace_id, http.StatusServiceUnavailable)
+				core_json.WriteJSON(rw, http.StatusServiceUnavailable, core_apiError)
+				return
+			}
+			if res == nil {
+				next.ServeHTTP(rw, req)
+				return
+			}
+
+			// RateLimit headers (IETF draft), reset is the time to refill the bucket
+			remaining := int(math.Floor(res.Remaining))
+			if remaining < 0 {
+				remaining = 0
+			}
+			reset := int(math.Ceil((float64(l.burst) - res.Remaining) / l.rate))
+			rw.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", l.burst, int(math.Ceil(float64(l.burst) / l.rate))))
+			rw.Header().Set("RateLimit-Limit", strconv.Itoa(l.burst))
+			rw.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+			rw.Header().Set("RateLimit-Reset", strconv.Itoa(reset))
+
+			if !res.Allowed {
+				retryAfter := int(math.Ceil((1 - res.Remaining) / l.rate))
+				if retryAfter < 1 {
+					retryAfter = 1
+				}
+				rw.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+
+				childLogger.Warn().Str("key", key).Str("path", req.URL.Path).Msg("rate limit exceeded")
+
+				trace_id := fmt.Sprintf("%v", req.Context().Value("trace-request-id"))
+				var core_apiError coreJson.APIError
+				core_apiError = core_apiError.NewAPIError(erro.ErrRateLimit, trace_id, http.StatusTooManyRequests)
+				core_json.WriteJSON(rw, http.StatusTooManyRequests, core_apiError)
+				return
+			}
+
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
+
+// About limit a call of a route class outside of the http middleware (grpc), it must run after the authentication.
+// It returns erro.ErrRateLimit when the limit is exceeded and erro.ErrRateLimitStore when the store fails closed
+func (r *RateLimiter) Allow(ctx context.Context, class string, remoteAddr string) error {
+	l, ok := r.limits[class]
+	if !r.enabled || !ok || l.rate <= 0 || l.burst <= 0 {
+		return nil
+	}
+
+	key := class + "|" + clientKey(ctx, remoteAddr)
+	res, err := r.take(ctx, class, key, l)
+	if err != nil {
+		return err
+	}
+	if res != nil && !res.Allowed {
+		childLogger.Warn().Str("key", key).Msg("rate limit exceeded")
+		return erro.ErrRateLimit
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"time"
+	"errors"
+	"testing"
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/auth"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	// burst of 3, then empty
+	for i := 0; i < 3; i++ {
+		if res, _ := store.Take(context.Background(), "k", 2, 3); !res.Allowed {
+			t.Fatalf("take %d must be allowed", i)
+		}
+	}
+	if res, _ := store.Take(context.Background(), "k", 2, 3); res.Allowed {
+		t.Fatalf("take over the burst must be denied")
+	}
+
+	// 2 tokens per second, after 500ms one token is back
+	now = now.Add(500 * time.Millisecond)
+	if res, _ := store.Take(context.Background(), "k", 2, 3); !res.Allowed {
+		t.Errorf("take after the refill must be allowed")
+	}
+
+	// the refill is limited to the burst
+	now = now.Add(time.Hour)
+	res, _ := store.Take(context.Background(), "k", 2, 3)
+	if res.Remaining != 2 {
+		t.Errorf("remaining %v after a long idle, want 2", res.Remaining)
+	}
+
+	// other keys have their own bucket
+	if res, _ := store.Take(context.Background(), "other", 2, 3); !res.Allowed || res.Remaining != 2 {
+		t.Errorf("other key must have a full bucket, got %+v", res)
+	}
+}
+
+func TestLimit(t *testing.T) {
+	rateLimiter := NewRateLimiter(&model.RateLimitConfig{Enabled: true, ReadRate: 1, ReadBurst: 2, WriteRate: 1, WriteBurst: 1}, nil)
+	handler := rateLimiter.Limit(ClassRead)(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	request := func(tenantID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/get/ACC-1", nil)
+		req = req.WithContext(auth.WithPrincipal(req.Context(), &model.Principal{Subject: "user-1", TenantID: tenantID}))
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+		return rw
+	}
+
+	for i := 0; i < 2; i++ {
+		if rw := request("TENANT-1"); rw.Code != http.StatusOK {
+			t.Fatalf("request %d status %d, want 200", i, rw.Code)
+		}
+	}
+
+	rw := request("TENANT-1")
+	if rw.Code != http.StatusTooManyRequests {
+		t.Fatalf("status %d, want 429", rw.Code)
+	}
+	if rw.Header().Get("Retry-After") != "1" || rw.Header().Get("RateLimit-Remaining") != "0" || rw.Header().Get("RateLimit-Limit") != "2" {
+		t.Errorf("unexpected headers %v", rw.Header())
+	}
+
+	// the buckets are per tenant
+	if rw := request("TENANT-2"); rw.Code != http.StatusOK {
+		t.Errorf("other tenant status %d, want 200", rw.Code)
+	}
+}
+
+func TestLimitDisabled(t *testing.T) {
+	rateLimiter := NewRateLimiter(&model.RateLimitConfig{Enabled: false, ReadRate: 1, ReadBurst: 1}, nil)
+	handler := rateLimiter.Limit(ClassRead)(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	for i := 0; i < 5; i++ {
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/get/ACC-1", nil))
+		if rw.Code != http.StatusOK {
+			t.Fatalf("status %d, want 200", rw.Code)
+		}
+	}
+}
+
+// a store error lets the request go without the headers (fail open) or refuses it with 503 (fail closed)
+func TestLimitStoreError(t *testing.T) {
+	store := StoreFunc(func(ctx context.Context, key string, rate float64, burst int) (Result, error) {
+		return Result{}, errors.New("conn closed")
+	})
+
+	tests := []struct {
+		name		string
+		failOpen	bool
+		status		int
+	}{
+		{"fail open", true, http.StatusOK},
+		{"fail closed", false, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		rateLimiter := NewRateLimiter(&model.RateLimitConfig{Enabled: true, ReadRate: 1, ReadBurst: 1, FailOpen: tt.failOpen}, store)
+		handler := rateLimiter.Limit(ClassRead)(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusOK)
+		}))
+
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/get/ACC-1", nil))
+		if rw.Code != tt.status || rw.Header().Get("RateLimit-Limit") != "" {
+			t.Errorf("%s: status %d headers %v, want %d without headers", tt.name, rw.Code, rw.Header(), tt.status)
+		}
+	}
+}
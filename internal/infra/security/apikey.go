@@ -0,0 +1,56 @@
+package security
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/auth"
+)
+
+// About the header of the api key (machine clients without OAuth)
+const ApiKeyHeader = "X-API-Key"
+
+// About verify an api key and return it (erro.ErrUnauthorized when it is invalid, revoked or expired)
+type ApiKeyVerifier func(ctx context.Context, key string) (*model.ApiKey, error)
+
+// About set the verifier of the api keys
+func (a *AuthMiddleware) SetApiKeyVerifier(apiKeyVerifier ApiKeyVerifier) {
+	a.apiKeyVerifier = apiKeyVerifier
+}
+
+// About the principal of an api key, the same type produced by a JWT
+func apiKeyPrincipal(apiKey *model.ApiKey) *model.Principal {
+	principal := model.Principal{	Subject: "apikey:" + apiKey.KeyID,
+									TenantID: apiKey.TenantID,
+									ClientID: apiKey.Name,
+									Scopes: apiKey.Scopes,
+									Method: "api_key",
+									TokenID: apiKey.KeyID }
+	if apiKey.Role != "" {
+		principal.Roles = []string{apiKey.Role}
+	}
+	return &principal
+}
+
+// About authenticate the requests with an api key, the principal is put into the context so the
+// Authorize and RequireRole middlewares work the same way for JWT and api keys
+func (a *AuthMiddleware) ApiKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		key := req.Header.Get(ApiKeyHeader)
+		if a.disabled || a.apiKeyVerifier == nil || key == "" {
+			next.ServeHTTP(rw, req)
+			return
+		}
+
+		apiKey, err := a.apiKeyVerifier(req.Context(), key)
+		if err != nil {
+			childLogger.Info().Err(err).Str("path", req.URL.Path).Msg("api key rejected")
+			writeError(rw, req, erro.ErrUnauthorized, http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(rw, req.WithContext(auth.WithPrincipal(req.Context(), apiKeyPrincipal(apiKey))))
+	})
+}
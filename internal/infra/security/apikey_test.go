@@ -0,0 +1,49 @@
+package security
+
+import (
+	"testing"
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+)
+
+func TestApiKeyMiddleware(t *testing.T) {
+	authMiddleware := &AuthMiddleware{}
+	authMiddleware.SetApiKeyVerifier(func(ctx context.Context, key string) (*model.ApiKey, error) {
+		if key != "ak_1_valid" {
+			return nil, erro.ErrUnauthorized
+		}
+		return &model.ApiKey{KeyID: "1", TenantID: "TENANT-1", Name: "partner", Scopes: []string{ScopeRead}}, nil
+	})
+
+	handler := authMiddleware.ApiKey(authMiddleware.Authorize(ScopeRead)(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})))
+	handlerWrite := authMiddleware.ApiKey(authMiddleware.Authorize(ScopeWrite)(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})))
+
+	tests := []struct {
+		name	string
+		handler	http.Handler
+		key		string
+		status	int
+	}{
+		{"valid key", handler, "ak_1_valid", http.StatusOK},
+		{"invalid key", handler, "ak_1_invalid", http.StatusUnauthorized},
+		{"scope not granted to the key", handlerWrite, "ak_1_valid", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/get/ACC-1", nil)
+		req.Header.Set(ApiKeyHeader, tt.key)
+		rw := httptest.NewRecorder()
+		tt.handler.ServeHTTP(rw, req)
+		if rw.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, rw.Code, tt.status)
+		}
+	}
+}
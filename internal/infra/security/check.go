@@ -0,0 +1,59 @@
+package security
+
+import (
+	"context"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/auth"
+)
+
+// About authenticate and authorize a call outside of the http middlewares (grpc): the api key (when informed)
+// or the token, then the tenant, the scopes and the role. It returns erro.ErrUnauthorized or erro.ErrHTTPForbiden,
+// the principal is nil when the authentication is disabled
+func (a *AuthMiddleware) Check(ctx context.Context, authorization string, key string, required string, scopes ...string) (*model.Principal, error) {
+	if a.disabled {
+		return nil, nil
+	}
+
+	var principal *model.Principal
+	if key != "" && a.apiKeyVerifier != nil {
+		apiKey, err := a.apiKeyVerifier(ctx, key)
+		if err != nil {
+			childLogger.Info().Err(err).Msg("api key rejected")
+			return nil, erro.ErrUnauthorized
+		}
+		principal = apiKeyPrincipal(apiKey)
+	} else {
+		var err error
+		principal, err = a.jwtVerifier.Verify(ctx, authorization)
+		if err != nil {
+			return nil, erro.ErrUnauthorized
+		}
+	}
+
+	if principal.TenantID == "" {
+		childLogger.Info().Str("subject", principal.Subject).Msg("principal without tenant")
+		return nil, erro.ErrHTTPForbiden
+	}
+
+	if !auth.HasScopes(principal, scopes...) {
+		childLogger.Info().Str("subject", principal.Subject).Strs("required", scopes).Strs("scopes", principal.Scopes).Msg("scope denied")
+		return nil, erro.ErrHTTPForbiden
+	}
+
+	role, err := a.role(ctx, principal)
+	if err != nil {
+		childLogger.Error().Err(err).Str("subject", principal.Subject).Str("tenant_id", principal.TenantID).Msg("error get tenant role")
+	}
+	if err != nil || !auth.RoleGrants(role, required) {
+		childLogger.Warn().	Str("subject", principal.Subject).
+							Str("tenant_id", principal.TenantID).
+							Str("role", role).
+							Str("required", required).
+							Msg("access denied by role")
+		return nil, erro.ErrHTTPForbiden
+	}
+
+	return principal, nil
+}
@@ -0,0 +1,195 @@
+package security
+
+import (
+	"os"
+	"fmt"
+	"sync"
+	"time"
+	"errors"
+	"context"
+	"net/http"
+	"math/big"
+	"crypto/rsa"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/json"
+	"encoding/base64"
+)
+
+type jsonWebKey struct {
+	Kty		string	`json:"kty"`
+	Kid		string	`json:"kid"`
+	Use		string	`json:"use,omitempty"`
+	Alg		string	`json:"alg,omitempty"`
+	N		string	`json:"n,omitempty"`
+	E		string	`json:"e,omitempty"`
+	Crv		string	`json:"crv,omitempty"`
+	X		string	`json:"x,omitempty"`
+	Y		string	`json:"y,omitempty"`
+}
+
+type jsonWebKeySet struct {
+	Keys	[]jsonWebKey `json:"keys"`
+}
+
+// About the public keys used to verify the tokens, loaded from a JWKS url or a local file
+type KeySet struct {
+	jwksUrl		string
+	jwksFile	string
+	refresh		time.Duration
+	mutex		sync.RWMutex
+	keys		map[string]interface{}
+	loadedAt	time.Time
+}
+
+// About create a key set and load the keys
+func NewKeySet(ctx context.Context, jwksUrl string, jwksFile string, refresh time.Duration) (*KeySet, error){
+	childLogger.Info().Str("func","NewKeySet").Str("jwksUrl", jwksUrl).Str("jwksFile", jwksFile).Send()
+
+	if jwksUrl == "" && jwksFile == "" {
+		return nil, errors.New("jwks url or file must be informed")
+	}
+
+	keySet := KeySet{	jwksUrl: jwksUrl,
+						jwksFile: jwksFile,
+						refresh: refresh }
+
+	if err := keySet.load(ctx); err != nil {
+		return nil, err
+	}
+	return &keySet, nil
+}
+
+// About get a key by kid, an unknown kid forces a reload (key rotation) respecting the refresh interval
+func (k *KeySet) Key(ctx context.Context, kid string) (interface{}, error) {
+	k.mutex.RLock()
+	key, ok := k.keys[kid]
+	loadedAt := k.loadedAt
+	k.mutex.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	if time.Since(loadedAt) >= k.refresh {
+		if err := k.load(ctx); err != nil {
+			childLogger.Error().Err(err).Msg("error reload jwks")
+		}
+		k.mutex.RLock()
+		key, ok = k.keys[kid]
+		k.mutex.RUnlock()
+		if ok {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("key %s not found in jwks", kid)
+}
+
+func (k *KeySet) load(ctx context.Context) error {
+	var data []byte
+	var err error
+
+	if k.jwksFile != "" {
+		data, err = os.ReadFile(k.jwksFile)
+		if err != nil {
+			return err
+		}
+	} else {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.jwksUrl, nil)
+		if err != nil {
+			return err
+		}
+		client := http.Client{Timeout: 10 * time.Second}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("jwks url returned %d", resp.StatusCode)
+		}
+		var set jsonWebKeySet
+		if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+			return err
+		}
+		return k.setKeys(set)
+	}
+
+	var set jsonWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return err
+	}
+	return k.setKeys(set)
+}
+
+func (k *KeySet) setKeys(set jsonWebKeySet) error {
+	keys := map[string]interface{}{}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			childLogger.Error().Err(err).Str("kid", jwk.Kid).Msg("jwks key skipped")
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return errors.New("jwks has no usable key")
+	}
+
+	k.mutex.Lock()
+	k.keys = keys
+	k.loadedAt = time.Now()
+	k.mutex.Unlock()
+
+	childLogger.Info().Int("keys", len(keys)).Msg("jwks loaded")
+	return nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func (j jsonWebKey) publicKey() (interface{}, error) {
+	switch j.Kty {
+	case "RSA":
+		n, err := decodeBigInt(j.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(j.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch j.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("curve %s not supported", j.Crv)
+		}
+		x, err := decodeBigInt(j.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(j.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("key type %s not supported", j.Kty)
+	}
+}
@@ -0,0 +1,77 @@
+package security
+
+import (
+	"strings"
+	"context"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Scope		string		`json:"scope,omitempty"`
+	Scp			[]string	`json:"scp,omitempty"`
+	TenantID	string		`json:"tenant_id,omitempty"`
+	ClientID	string		`json:"client_id,omitempty"`
+	Roles		[]string	`json:"roles,omitempty"`
+}
+
+// About verify the JWT and build the principal
+type JwtVerifier struct {
+	keySet		*KeySet
+	parser		*jwt.Parser
+}
+
+// About create a jwt verifier
+func NewJwtVerifier(keySet *KeySet, issuer string, audience string) *JwtVerifier {
+	childLogger.Info().Str("func","NewJwtVerifier").Send()
+
+	options := []jwt.ParserOption{	jwt.WithValidMethods([]string{"RS256","RS384","RS512","ES256","ES384","ES512","PS256"}),
+									jwt.WithExpirationRequired() }
+	if issuer != "" {
+		options = append(options, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		options = append(options, jwt.WithAudience(audience))
+	}
+
+	return &JwtVerifier{	keySet: keySet,
+							parser: jwt.NewParser(options...) }
+}
+
+// About verify a token (with or without the Bearer prefix)
+func (j *JwtVerifier) Verify(ctx context.Context, token string) (*model.Principal, error){
+	token = strings.TrimSpace(token)
+	if len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
+		token = strings.TrimSpace(token[7:])
+	}
+	if token == "" {
+		return nil, erro.ErrUnauthorized
+	}
+
+	claims := tokenClaims{}
+	_, err := j.parser.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return j.keySet.Key(ctx, kid)
+	})
+	if err != nil {
+		childLogger.Info().Err(err).Msg("token rejected")
+		return nil, erro.ErrUnauthorized
+	}
+
+	scopes := claims.Scp
+	if claims.Scope != "" {
+		scopes = append(scopes, strings.Fields(claims.Scope)...)
+	}
+
+	return &model.Principal{	Subject: claims.Subject,
+								TenantID: claims.TenantID,
+								ClientID: claims.ClientID,
+								Scopes: scopes,
+								Roles: claims.Roles,
+								Method: "jwt",
+								TokenID: claims.ID }, nil
+}
@@ -0,0 +1,104 @@
+package security
+
+import (
+	"os"
+	"time"
+	"testing"
+	"context"
+	"math/big"
+	"net/http"
+	"crypto/rand"
+	"crypto/rsa"
+	"path/filepath"
+	"encoding/json"
+	"encoding/base64"
+	"net/http/httptest"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func newTestVerifier(t *testing.T) (*rsa.PrivateKey, *AuthMiddleware) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	set := jsonWebKeySet{Keys: []jsonWebKey{{	Kty: "RSA",
+												Kid: "kid-1",
+												Use: "sig",
+												N: base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+												E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()) }}}
+	data, _ := json.Marshal(set)
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	keySet, err := NewKeySet(context.Background(), "", file, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key, &AuthMiddleware{jwtVerifier: NewJwtVerifier(keySet, "issuer-1", "go-account")}
+}
+
+func signTestToken(t *testing.T, key *rsa.PrivateKey, scope string, exp time.Time) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"sub": "user-1",
+		"iss": "issuer-1",
+		"aud": "go-account",
+		"exp": exp.Unix(),
+		"scope": scope,
+		"tenant_id": "TENANT-1",
+	})
+	token.Header["kid"] = "kid-1"
+
+	res, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func TestAuthorize(t *testing.T) {
+	key, authMiddleware := newTestVerifier(t)
+	handler := authMiddleware.Authorize(ScopeWrite)(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name	string
+		token	string
+		status	int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"invalid token", "Bearer abc.def.ghi", http.StatusUnauthorized},
+		{"expired", "Bearer " + signTestToken(t, key, ScopeWrite, time.Now().Add(-time.Minute)), http.StatusUnauthorized},
+		{"missing scope", "Bearer " + signTestToken(t, key, ScopeRead, time.Now().Add(time.Minute)), http.StatusForbidden},
+		{"ok", "Bearer " + signTestToken(t, key, ScopeRead + " " + ScopeWrite, time.Now().Add(time.Minute)), http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/add", nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", tt.token)
+		}
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+		if rw.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, rw.Code, tt.status)
+		}
+	}
+}
+
+func TestVerifyPrincipal(t *testing.T) {
+	key, authMiddleware := newTestVerifier(t)
+
+	principal, err := authMiddleware.jwtVerifier.Verify(context.Background(), signTestToken(t, key, ScopeRead, time.Now().Add(time.Minute)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal.Subject != "user-1" || principal.TenantID != "TENANT-1" || len(principal.Scopes) != 1 {
+		t.Errorf("unexpected principal %+v", principal)
+	}
+}
@@ -0,0 +1,142 @@
+package security
+
+import (
+	"fmt"
+	"time"
+	"context"
+	"net/http"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/auth"
+
+	"github.com/eliezerraj/go-core/coreJson"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	ScopeRead	= "account:read"
+	ScopeWrite	= "account:write"
+	ScopeAdmin	= "account:admin"
+)
+
+var (
+	childLogger = log.With().Str("component","go-account").Str("package","internal.infra.security").Logger()
+	core_json coreJson.CoreJson
+)
+
+type AuthMiddleware struct {
+	jwtVerifier		*JwtVerifier
+	disabled		bool
+	defaultRole		string
+	roleResolver	RoleResolver
+	roleCache		*roleCache
+	apiKeyVerifier	ApiKeyVerifier
+	certScopes		[]string
+}
+
+// About create the authentication middleware from the security config
+func NewAuthMiddleware(ctx context.Context, securityConfig *model.SecurityConfig) (*AuthMiddleware, error){
+	childLogger.Info().Str("func","NewAuthMiddleware").Interface("securityConfig", securityConfig).Send()
+
+	if securityConfig.AuthDisabled {
+		childLogger.Warn().Msg("authentication DISABLED, all routes are open and see all the tenants !!!")
+		return &AuthMiddleware{disabled: true}, nil
+	}
+
+	keySet, err := NewKeySet(ctx,
+							securityConfig.JwksUrl,
+							securityConfig.JwksFile,
+							time.Duration(securityConfig.JwksRefresh) * time.Second)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthMiddleware{
+		jwtVerifier: NewJwtVerifier(keySet, securityConfig.Issuer, securityConfig.Audience),
+		defaultRole: securityConfig.DefaultRole,
+		roleCache: newRoleCache(time.Duration(securityConfig.RoleCacheTTL) * time.Second),
+		certScopes: securityConfig.CertScopes,
+	}, nil
+}
+
+// About write an error in the same format of the api ErrorHandler
+func writeError(rw http.ResponseWriter, req *http.Request, err error, statusCode int) {
+	trace_id := fmt.Sprintf("%v", req.Context().Value("trace-request-id"))
+
+	var core_apiError coreJson.APIError
+	core_apiError = core_apiError.NewAPIError(err, trace_id, statusCode)
+
+	core_json.WriteJSON(rw, statusCode, core_apiError)
+}
+
+// About the principal of the verified client certificate (mTLS) of a service, the organization of the
+// certificate is the tenant and the scopes are the ones of MTLS_CERT_SCOPES (without them the certificate does not authenticate).
+// The role comes from tenant_role (subject cert:<common name>) as for the tokens
+func (a *AuthMiddleware) certificatePrincipal(ctx context.Context) (*model.Principal, bool) {
+	clientCertificate, ok := auth.ClientCertificateFromContext(ctx)
+	if !ok || len(a.certScopes) == 0 || clientCertificate.CommonName == "" {
+		return nil, false
+	}
+
+	principal := model.Principal{	Subject: "cert:" + clientCertificate.CommonName,
+									ClientID: clientCertificate.Subject,
+									Scopes: a.certScopes,
+									Method: "mtls",
+									TokenID: clientCertificate.SerialNumber }
+	if len(clientCertificate.Organization) > 0 {
+		principal.TenantID = clientCertificate.Organization[0]
+	}
+	return &principal, true
+}
+
+// About get the principal of the request (from the context, the token or the client certificate), it writes the 401/403 when it fails.
+// A token has precedence over the client certificate
+func (a *AuthMiddleware) authenticate(rw http.ResponseWriter, req *http.Request) (*model.Principal, bool) {
+	principal, ok := auth.PrincipalFromContext(req.Context())
+	if !ok && req.Header.Get("Authorization") == "" {
+		principal, ok = a.certificatePrincipal(req.Context())
+	}
+	if !ok {
+		var err error
+		principal, err = a.jwtVerifier.Verify(req.Context(), req.Header.Get("Authorization"))
+		if err != nil {
+			writeError(rw, req, erro.ErrUnauthorized, http.StatusUnauthorized)
+			return nil, false
+		}
+	}
+
+	// the tenant isolation is derived from the principal, so a token without tenant is refused
+	if principal.TenantID == "" {
+		childLogger.Info().Str("subject", principal.Subject).Str("path", req.URL.Path).Msg("principal without tenant")
+		writeError(rw, req, erro.ErrHTTPForbiden, http.StatusForbidden)
+		return nil, false
+	}
+
+	return principal, true
+}
+
+// About authenticate the request (401) and check the route scopes (403)
+func (a *AuthMiddleware) Authorize(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if a.disabled {
+				next.ServeHTTP(rw, req.WithContext(auth.WithTenantBypass(req.Context())))
+				return
+			}
+
+			principal, ok := a.authenticate(rw, req)
+			if !ok {
+				return
+			}
+
+			if !auth.HasScopes(principal, scopes...) {
+				childLogger.Info().Str("subject", principal.Subject).Strs("required", scopes).Strs("scopes", principal.Scopes).Str("path", req.URL.Path).Msg("scope denied")
+				writeError(rw, req, erro.ErrHTTPForbiden, http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(rw, req.WithContext(auth.WithPrincipal(req.Context(), principal)))
+		})
+	}
+}
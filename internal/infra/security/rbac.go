@@ -0,0 +1,125 @@
+package security
+
+import (
+	"sync"
+	"time"
+	"context"
+	"net/http"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/auth"
+)
+
+// About get the role of a subject configured into a tenant ("" when there is no role configured)
+type RoleResolver func(ctx context.Context, tenantID string, subject string) (string, error)
+
+type roleCacheEntry struct {
+	role		string
+	expiresAt	time.Time
+}
+
+// About a small ttl cache of the tenant roles, so each request does not hit the database
+type roleCache struct {
+	ttl			time.Duration
+	mutex		sync.Mutex
+	entries		map[string]roleCacheEntry
+}
+
+func newRoleCache(ttl time.Duration) *roleCache {
+	return &roleCache{	ttl: ttl,
+						entries: map[string]roleCacheEntry{} }
+}
+
+func (c *roleCache) get(key string) (string, bool) {
+	if c.ttl <= 0 {
+		return "", false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return "", false
+	}
+	return entry.role, true
+}
+
+func (c *roleCache) set(key string, role string) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.entries[key] = roleCacheEntry{role: role, expiresAt: time.Now().Add(c.ttl)}
+}
+
+// About set the resolver of the roles configured per tenant
+func (a *AuthMiddleware) SetRoleResolver(roleResolver RoleResolver) {
+	a.roleResolver = roleResolver
+}
+
+// About the role of the principal: the role configured into the tenant, otherwise the highest role
+// of the token (roles claim), otherwise the default role
+func (a *AuthMiddleware) role(ctx context.Context, principal *model.Principal) (string, error) {
+	key := principal.TenantID + "|" + principal.Subject
+
+	role, ok := a.roleCache.get(key)
+	if !ok {
+		if a.roleResolver != nil {
+			var err error
+			role, err = a.roleResolver(ctx, principal.TenantID, principal.Subject)
+			if err != nil {
+				return "", err
+			}
+		}
+		a.roleCache.set(key, role)
+	}
+
+	if role != "" {
+		return role, nil
+	}
+	if role = auth.HighestRole(principal.Roles...); role != "" {
+		return role, nil
+	}
+	return a.defaultRole, nil
+}
+
+// About check the role of the principal into its tenant (403), the denied attempts are logged
+func (a *AuthMiddleware) RequireRole(required string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			if a.disabled {
+				next.ServeHTTP(rw, req.WithContext(auth.WithTenantBypass(req.Context())))
+				return
+			}
+
+			principal, ok := a.authenticate(rw, req)
+			if !ok {
+				return
+			}
+
+			role, err := a.role(req.Context(), principal)
+			if err != nil {
+				childLogger.Error().Err(err).Str("subject", principal.Subject).Str("tenant_id", principal.TenantID).Msg("error get tenant role")
+			}
+
+			if err != nil || !auth.RoleGrants(role, required) {
+				childLogger.Warn().	Interface("trace-resquest-id", req.Context().Value("trace-request-id")).
+									Str("subject", principal.Subject).
+									Str("tenant_id", principal.TenantID).
+									Str("role", role).
+									Str("required", required).
+									Str("method", req.Method).
+									Str("path", req.URL.Path).
+									Msg("access denied by role")
+				writeError(rw, req, erro.ErrHTTPForbiden, http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(rw, req.WithContext(auth.WithPrincipal(req.Context(), principal)))
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package security
+
+import (
+	"time"
+	"errors"
+	"testing"
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/auth"
+)
+
+func TestRequireRole(t *testing.T) {
+	authMiddleware := &AuthMiddleware{	defaultRole: auth.RoleOperator,
+										roleCache: newRoleCache(time.Minute) }
+	authMiddleware.SetRoleResolver(func(ctx context.Context, tenantID string, subject string) (string, error) {
+		switch subject {
+		case "admin-1":
+			return auth.RoleAdmin, nil
+		case "viewer-1":
+			return auth.RoleViewer, nil
+		case "error-1":
+			return "", errors.New("database down")
+		}
+		return "", nil
+	})
+
+	handler := authMiddleware.RequireRole(auth.RoleAdmin)(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name		string
+		principal	*model.Principal
+		status		int
+	}{
+		{"tenant admin", &model.Principal{Subject: "admin-1", TenantID: "TENANT-1"}, http.StatusOK},
+		{"tenant viewer", &model.Principal{Subject: "viewer-1", TenantID: "TENANT-1"}, http.StatusForbidden},
+		{"tenant role overrides token roles", &model.Principal{Subject: "viewer-1", TenantID: "TENANT-1", Roles: []string{auth.RoleAdmin}}, http.StatusForbidden},
+		{"token roles", &model.Principal{Subject: "user-1", TenantID: "TENANT-1", Roles: []string{auth.RoleViewer, auth.RoleAdmin}}, http.StatusOK},
+		{"default role", &model.Principal{Subject: "user-2", TenantID: "TENANT-1"}, http.StatusForbidden},
+		{"resolver error", &model.Principal{Subject: "error-1", TenantID: "TENANT-1"}, http.StatusForbidden},
+		{"without tenant", &model.Principal{Subject: "admin-1"}, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/stat", nil)
+		req = req.WithContext(auth.WithPrincipal(req.Context(), tt.principal))
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+		if rw.Code != tt.status {
+			t.Errorf("%s: status %d, want %d", tt.name, rw.Code, tt.status)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package security
+
+import (
+	"os"
+	"fmt"
+	"sync"
+	"errors"
+	"net/http"
+	"crypto/tls"
+	"crypto/x509"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/auth"
+)
+
+const (
+	ClientAuthNone		= "none"
+	ClientAuthRequest	= "request"
+	ClientAuthRequire	= "require"
+)
+
+// About the server certificate and the client CA (mTLS), both can be reloaded (SIGHUP) without restart
+type TlsReloader struct {
+	tlsConfig		*model.TlsConfig
+	clientAuth		tls.ClientAuthType
+	mutex			sync.RWMutex
+	certificate		*tls.Certificate
+	clientCAs		*x509.CertPool
+}
+
+// About create the tls reloader and load the certificates
+func NewTlsReloader(tlsConfig *model.TlsConfig) (*TlsReloader, error){
+	childLogger.Info().Str("func","NewTlsReloader").Interface("tlsConfig", tlsConfig).Send()
+
+	if tlsConfig.CertFile == "" || tlsConfig.KeyFile == "" {
+		return nil, errors.New("tls cert file and key file must be informed")
+	}
+
+	tlsReloader := TlsReloader{tlsConfig: tlsConfig}
+
+	switch tlsConfig.ClientAuth {
+	case ClientAuthNone, "":
+		tlsReloader.clientAuth = tls.NoClientCert
+	case ClientAuthRequest:
+		tlsReloader.clientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		tlsReloader.clientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("tls client auth %s not supported", tlsConfig.ClientAuth)
+	}
+	if tlsReloader.clientAuth != tls.NoClientCert && tlsConfig.CaFile == "" {
+		return nil, errors.New("tls ca file must be informed for client authentication (mTLS)")
+	}
+
+	if err := tlsReloader.Reload(); err != nil {
+		return nil, err
+	}
+	return &tlsReloader, nil
+}
+
+// About read again the certificate, key and CA files, the current ones are kept when it fails
+func (t *TlsReloader) Reload() error {
+	childLogger.Info().Str("func","Reload").Send()
+
+	certificate, err := tls.LoadX509KeyPair(t.tlsConfig.CertFile, t.tlsConfig.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	var clientCAs *x509.CertPool
+	if t.clientAuth != tls.NoClientCert {
+		data, err := os.ReadFile(t.tlsConfig.CaFile)
+		if err != nil {
+			return err
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(data) {
+			return errors.New("tls ca file has no valid certificate")
+		}
+	}
+
+	t.mutex.Lock()
+	t.certificate = &certificate
+	t.clientCAs = clientCAs
+	t.mutex.Unlock()
+
+	childLogger.Info().Str("cert_file", t.tlsConfig.CertFile).Str("client_auth", t.tlsConfig.ClientAuth).Msg("tls certificates loaded")
+	return nil
+}
+
+// About the tls config of the http server, each handshake uses the current certificate and CA
+func (t *TlsReloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			t.mutex.RLock()
+			defer t.mutex.RUnlock()
+			return t.certificate, nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			t.mutex.RLock()
+			defer t.mutex.RUnlock()
+			return &tls.Config{	MinVersion: tls.VersionTLS12,
+								NextProtos: []string{"h2", "http/1.1"},
+								Certificates: []tls.Certificate{*t.certificate},
+								ClientCAs: t.clientCAs,
+								ClientAuth: t.clientAuth }, nil
+		},
+	}
+}
+
+// About put the verified client certificate (mTLS) into the request context
+func ClientCertificateMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+			next.ServeHTTP(rw, req)
+			return
+		}
+
+		cert := req.TLS.VerifiedChains[0][0]
+		clientCertificate := model.ClientCertificate{	Subject: cert.Subject.String(),
+														CommonName: cert.Subject.CommonName,
+														Organization: cert.Subject.Organization,
+														Issuer: cert.Issuer.String(),
+														SerialNumber: cert.SerialNumber.String(),
+														DNSNames: cert.DNSNames,
+														NotAfter: cert.NotAfter }
+
+		next.ServeHTTP(rw, req.WithContext(auth.WithClientCertificate(req.Context(), &clientCertificate)))
+	})
+}
@@ -0,0 +1,196 @@
+package security
+
+import (
+	"os"
+	"time"
+	"testing"
+	"math/big"
+	"net/http"
+	"crypto/tls"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"path/filepath"
+	"net"
+	"io"
+	"net/http/httptest"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/auth"
+)
+
+type testCert struct {
+	cert	*x509.Certificate
+	key		*ecdsa.PrivateKey
+	certPEM	[]byte
+	keyPEM	[]byte
+}
+
+func newTestCert(t *testing.T, cn string, serial int64, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := x509.Certificate{	SerialNumber: big.NewInt(serial),
+									Subject: pkix.Name{CommonName: cn, Organization: []string{"go-account"}},
+									NotBefore: time.Now().Add(-time.Hour),
+									NotAfter: time.Now().Add(time.Hour),
+									DNSNames: []string{"localhost"},
+									IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+									ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth} }
+	parentCert, parentKey := &template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, parentCert, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+
+	return &testCert{	cert: cert,
+						key: key,
+						certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+						keyPEM: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}) }
+}
+
+func writeTestFile(t *testing.T, file string, data []byte) {
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTlsReloaderMTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", 1, nil)
+	server := newTestCert(t, "server", 2, ca)
+	client := newTestCert(t, "service-a", 3, ca)
+
+	tlsConfig := model.TlsConfig{	Enabled: true,
+									CertFile: filepath.Join(dir, "server.crt"),
+									KeyFile: filepath.Join(dir, "server.key"),
+									CaFile: filepath.Join(dir, "ca.crt"),
+									ClientAuth: ClientAuthRequire }
+	writeTestFile(t, tlsConfig.CertFile, server.certPEM)
+	writeTestFile(t, tlsConfig.KeyFile, server.keyPEM)
+	writeTestFile(t, tlsConfig.CaFile, ca.certPEM)
+
+	tlsReloader, err := NewTlsReloader(&tlsConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewUnstartedServer(ClientCertificateMiddleware(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		clientCertificate, ok := auth.ClientCertificateFromContext(req.Context())
+		if !ok {
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		io.WriteString(rw, clientCertificate.CommonName)
+	})))
+	srv.TLS = tlsReloader.TLSConfig()
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	clientPair, _ := tls.X509KeyPair(client.certPEM, client.keyPEM)
+
+	newClient := func(certificates []tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certificates}}}
+	}
+
+	// with the client certificate the subject is exposed to the handler
+	resp, err := newClient([]tls.Certificate{clientPair}).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "service-a" {
+		t.Errorf("client certificate subject %q, want service-a", body)
+	}
+
+	// without the client certificate the handshake fails
+	if _, err := newClient(nil).Get(srv.URL); err == nil {
+		t.Errorf("request without client certificate must fail")
+	}
+
+	// the reload swaps the server certificate for the new connections
+	renewed := newTestCert(t, "server", 4, ca)
+	writeTestFile(t, tlsConfig.CertFile, renewed.certPEM)
+	writeTestFile(t, tlsConfig.KeyFile, renewed.keyPEM)
+	if err := tlsReloader.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err = newClient([]tls.Certificate{clientPair}).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if serial := resp.TLS.PeerCertificates[0].SerialNumber.Int64(); serial != 4 {
+		t.Errorf("server certificate serial %d after reload, want 4", serial)
+	}
+}
+
+func TestTlsReloaderConfig(t *testing.T) {
+	if _, err := NewTlsReloader(&model.TlsConfig{Enabled: true}); err == nil {
+		t.Errorf("missing cert file must fail")
+	}
+	if _, err := NewTlsReloader(&model.TlsConfig{Enabled: true, CertFile: "a", KeyFile: "b", ClientAuth: ClientAuthRequire}); err == nil {
+		t.Errorf("client auth without ca file must fail")
+	}
+}
+
+// a verified client certificate authenticates a service when MTLS_CERT_SCOPES is set, a token has precedence
+func TestAuthorizeClientCertificate(t *testing.T) {
+	_, authMiddleware := newTestVerifier(t)
+	handler := authMiddleware.Authorize(ScopeRead)(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		principal, _ := auth.PrincipalFromContext(req.Context())
+		io.WriteString(rw, principal.Subject + " " + principal.TenantID)
+	}))
+
+	certificate := &model.ClientCertificate{Subject: "CN=service-a,O=TENANT-1", CommonName: "service-a", Organization: []string{"TENANT-1"}}
+
+	tests := []struct {
+		name		string
+		scopes		[]string
+		certificate	*model.ClientCertificate
+		token		string
+		status		int
+		body		string
+	}{
+		{"certificate without scopes configured", nil, certificate, "", http.StatusUnauthorized, ""},
+		{"no certificate", []string{ScopeRead}, nil, "", http.StatusUnauthorized, ""},
+		{"certificate without organization", []string{ScopeRead}, &model.ClientCertificate{CommonName: "service-a"}, "", http.StatusForbidden, ""},
+		{"missing scope", []string{ScopeWrite}, certificate, "", http.StatusForbidden, ""},
+		{"token has precedence", []string{ScopeRead}, certificate, "Bearer abc.def.ghi", http.StatusUnauthorized, ""},
+		{"ok", []string{ScopeRead}, certificate, "", http.StatusOK, "cert:service-a TENANT-1"},
+	}
+
+	for _, tt := range tests {
+		authMiddleware.certScopes = tt.scopes
+		req := httptest.NewRequest(http.MethodGet, "/get", nil)
+		if tt.certificate != nil {
+			req = req.WithContext(auth.WithClientCertificate(req.Context(), tt.certificate))
+		}
+		if tt.token != "" {
+			req.Header.Set("Authorization", tt.token)
+		}
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, req)
+		if rw.Code != tt.status || (tt.body != "" && rw.Body.String() != tt.body) {
+			t.Errorf("%s: status %d %q, want %d %q", tt.name, rw.Code, rw.Body.String(), tt.status, tt.body)
+		}
+	}
+}
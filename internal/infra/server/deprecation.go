@@ -0,0 +1,26 @@
+package server
+
+import (
+	"strings"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// About mark a legacy route as deprecated and point to its successor (the path variables are copied into the successor),
+// an empty successor (the legacy route has not the variables of it) has no Link
+func deprecated(successor string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("Deprecation", "true")
+			if successor != "" {
+				link := successor
+				for key, value := range mux.Vars(req) {
+					link = strings.ReplaceAll(link, "{" + key + "}", value)
+				}
+				rw.Header().Set("Link", "<" + link + ">; rel=\"successor-version\"")
+			}
+			next.ServeHTTP(rw, req)
+		})
+	}
+}
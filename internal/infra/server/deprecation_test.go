@@ -0,0 +1,49 @@
+package server
+
+import (
+	"testing"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gorilla/mux"
+)
+
+func TestDeprecated(t *testing.T) {
+	router := mux.NewRouter()
+	legacy := router.Methods(http.MethodGet).Subrouter()
+	legacy.HandleFunc("/get/{id}", func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+	legacy.Use(deprecated("/v1/accounts/{id}"))
+
+	legacyBody := router.Methods(http.MethodPost).Subrouter()
+	legacyBody.HandleFunc("/delete", func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+	legacyBody.Use(deprecated(""))
+
+	tests := []struct {
+		name	string
+		method	string
+		target	string
+		link	string
+	}{
+		{"path variable", http.MethodGet, "/get/ACC-1", `</v1/accounts/ACC-1>; rel="successor-version"`},
+		{"without successor", http.MethodPost, "/delete", ""},
+	}
+
+	for _, tt := range tests {
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, httptest.NewRequest(tt.method, tt.target, nil))
+
+		if rw.Code != http.StatusOK {
+			t.Fatalf("%s: status %d", tt.name, rw.Code)
+		}
+		if rw.Header().Get("Deprecation") != "true" {
+			t.Errorf("%s: missing Deprecation header", tt.name)
+		}
+		if got := rw.Header().Get("Link"); got != tt.link {
+			t.Errorf("%s: Link %q, want %q", tt.name, got, tt.link)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"net"
+	"errors"
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/erro"
+	"github.com/go-account/internal/core/auth"
+	"github.com/go-account/internal/infra/security"
+	"github.com/go-account/internal/infra/ratelimit"
+	grpc_adapter "github.com/go-account/internal/adapter/grpc"
+	"github.com/go-account/internal/adapter/grpc/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/credentials"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+)
+
+// About the scope, the role and the rate limit class required by a grpc method (the same of the equivalent http route)
+type grpcRule struct {
+	scope	string
+	role	string
+	class	string
+}
+
+var grpcRules = map[string]grpcRule{
+	pb.AccountService_AddAccount_FullMethodName:			{scope: security.ScopeWrite, role: auth.RoleOperator, class: ratelimit.ClassWrite},
+	pb.AccountService_GetAccount_FullMethodName:			{scope: security.ScopeRead, role: auth.RoleViewer, class: ratelimit.ClassRead},
+	pb.AccountService_UpdateAccount_FullMethodName:			{scope: security.ScopeWrite, role: auth.RoleOperator, class: ratelimit.ClassWrite},
+	pb.AccountService_DeleteAccount_FullMethodName:			{scope: security.ScopeWrite, role: auth.RoleAdmin, class: ratelimit.ClassWrite},
+	pb.AccountService_ListAccountPerPerson_FullMethodName:	{scope: security.ScopeRead, role: auth.RoleViewer, class: ratelimit.ClassRead},
+	pb.AccountService_ListAccountBalance_FullMethodName:	{scope: security.ScopeRead, role: auth.RoleViewer, class: ratelimit.ClassRead},
+	pb.AccountService_AddPosting_FullMethodName:			{scope: security.ScopeWrite, role: auth.RoleOperator, class: ratelimit.ClassWrite},
+	pb.AccountService_Transfer_FullMethodName:				{scope: security.ScopeWrite, role: auth.RoleOperator, class: ratelimit.ClassWrite},
+}
+
+type GrpcServer struct {
+	httpServer	*model.Server
+}
+
+// About create new grpc server
+func NewGrpcAppServer(httpServer *model.Server) GrpcServer {
+	childLogger.Info().Str("func","NewGrpcAppServer").Send()
+
+	return GrpcServer{httpServer: httpServer }
+}
+
+func firstMetadata(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// About authenticate and authorize each call with the rules of the method, an unknown method is denied
+func authInterceptor(authMiddleware *security.AuthMiddleware) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		// the same request id of the http middleware
+		if requestID := firstMetadata(md, "x-request-id"); requestID != "" {
+			ctx = context.WithValue(ctx, "trace-request-id", requestID)
+		}
+
+		rule, ok := grpcRules[info.FullMethod]
+		if !ok {
+			return nil, status.Error(codes.PermissionDenied, erro.ErrHTTPForbiden.Error())
+		}
+
+		principal, err := authMiddleware.Check(	ctx,
+												firstMetadata(md, "authorization"),
+												firstMetadata(md, strings.ToLower(security.ApiKeyHeader)),
+												rule.role,
+												rule.scope)
+		if errors.Is(err, erro.ErrUnauthorized) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if err != nil {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		if principal != nil {
+			ctx = auth.WithPrincipal(ctx, principal)
+		} else {
+			// authentication disabled (dev), the same as the http middlewares
+			ctx = auth.WithTenantBypass(ctx)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// About limit each call with the class of the method, it runs after the authInterceptor (the key comes from the principal)
+func rateLimitInterceptor(rateLimiter *ratelimit.RateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		remoteAddr := ""
+		if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+			remoteAddr = p.Addr.String()
+		}
+
+		err := rateLimiter.Allow(ctx, grpcRules[info.FullMethod].class, remoteAddr)
+		if errors.Is(err, erro.ErrRateLimit) {
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		}
+		if err != nil {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// About start grpc server, it stops (graceful) when the ctx is done
+func (g GrpcServer) StartGrpcAppServer(	ctx context.Context,
+										accountServer *grpc_adapter.AccountServer,
+										authMiddleware *security.AuthMiddleware,
+										tlsReloader *security.TlsReloader,
+										rateLimiter *ratelimit.RateLimiter) {
+	childLogger.Info().Str("func","StartGrpcAppServer").Send()
+
+	listener, err := net.Listen("tcp", ":" + strconv.Itoa(g.httpServer.GrpcPort))
+	if err != nil {
+		childLogger.Error().Err(err).Msg("error listen grpc port")
+		return
+	}
+
+	// the trace context is propagated the same way of the http server
+	options := []grpc.ServerOption{	grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithPropagators(propagation.TraceContext{}))),
+									grpc.ChainUnaryInterceptor(authInterceptor(authMiddleware), rateLimitInterceptor(rateLimiter)) }
+	if tlsReloader != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsReloader.TLSConfig())))
+	}
+
+	grpcServer := grpc.NewServer(options...)
+	pb.RegisterAccountServiceServer(grpcServer, accountServer)
+
+	go func() {
+		<-ctx.Done()
+		childLogger.Info().Msg("stopping grpc server !!!")
+		grpcServer.GracefulStop()
+	}()
+
+	childLogger.Info().Str("Grpc Port", strconv.Itoa(g.httpServer.GrpcPort)).Bool("tls", tlsReloader != nil).Send()
+
+	if err := grpcServer.Serve(listener); err != nil {
+		childLogger.Info().Err(err).Msg("canceling grpc server !!!")
+	}
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"testing"
+	"context"
+
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/infra/security"
+	"github.com/go-account/internal/infra/ratelimit"
+	"github.com/go-account/internal/adapter/grpc/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuthInterceptor(t *testing.T) {
+	authMiddleware, err := security.NewAuthMiddleware(context.Background(), &model.SecurityConfig{AuthDisabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	interceptor := authInterceptor(authMiddleware)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	res, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: pb.AccountService_GetAccount_FullMethodName}, handler)
+	if err != nil || res != "ok" {
+		t.Errorf("known method: res %v err %v", res, err)
+	}
+
+	_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/account.v1.AccountService/Unknown"}, handler)
+	if status.Code(err) != codes.PermissionDenied {
+		t.Errorf("unknown method: err %v, want PermissionDenied", err)
+	}
+
+	// every method of the service has a rule
+	for _, method := range pb.AccountService_ServiceDesc.Methods {
+		if _, ok := grpcRules["/" + pb.AccountService_ServiceDesc.ServiceName + "/" + method.MethodName]; !ok {
+			t.Errorf("method %s has no rule", method.MethodName)
+		}
+	}
+}
+
+// the calls are limited per class of the method, the same buckets of the http routes
+func TestRateLimitInterceptor(t *testing.T) {
+	rateLimiter := ratelimit.NewRateLimiter(&model.RateLimitConfig{Enabled: true, ReadRate: 1, ReadBurst: 1, WriteRate: 1, WriteBurst: 1}, nil)
+	interceptor := rateLimitInterceptor(rateLimiter)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	tests := []struct {
+		name	string
+		method	string
+		code	codes.Code
+	}{
+		{"read", pb.AccountService_GetAccount_FullMethodName, codes.OK},
+		{"read exceeded", pb.AccountService_ListAccountBalance_FullMethodName, codes.ResourceExhausted},
+		{"write has its own bucket", pb.AccountService_AddPosting_FullMethodName, codes.OK},
+		{"write exceeded", pb.AccountService_Transfer_FullMethodName, codes.ResourceExhausted},
+	}
+
+	for _, tt := range tests {
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+		if status.Code(err) != tt.code {
+			t.Errorf("%s: err %v, want %v", tt.name, err, tt.code)
+		}
+	}
+
+	// every method has a rate limit class
+	for method, rule := range grpcRules {
+		if rule.class != ratelimit.ClassRead && rule.class != ratelimit.ClassWrite {
+			t.Errorf("method %s has no rate limit class", method)
+		}
+	}
+}
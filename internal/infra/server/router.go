@@ -0,0 +1,306 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-account/internal/adapter/api"
+	"github.com/go-account/internal/adapter/graph"
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/core/auth"
+	"github.com/go-account/internal/infra/security"
+	"github.com/go-account/internal/infra/ratelimit"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+)
+
+// About the public view of the application server for / and /info, the configs (database, security,
+// brokers ...) are not exposed on these open routes
+type appInfo struct {
+	InfoPod		*model.InfoPod	`json:"info_pod"`
+	Server		*model.Server	`json:"server"`
+}
+
+func newAppInfo(appServer *model.AppServer) appInfo {
+	return appInfo{	InfoPod: appServer.InfoPod,
+					Server: appServer.Server }
+}
+
+// About create the router with all routes, every route must be described in the OpenAPI document (api/openapi.json)
+func NewRouter(	httpRouters *api.HttpRouters,
+				graphServer *graph.GraphServer,
+				authMiddleware *security.AuthMiddleware,
+				rateLimiter *ratelimit.RateLimiter,
+				appServer *model.AppServer) *mux.Router {
+	childLogger.Info().Str("func","NewRouter").Send()
+
+	myRouter := mux.NewRouter().StrictSlash(true)
+	myRouter.Use(core_middleware.MiddleWareHandlerHeader)
+	myRouter.Use(authMiddleware.ApiKey)
+	myRouter.Use(security.ClientCertificateMiddleware)
+
+	myRouter.HandleFunc("/", func(rw http.ResponseWriter, req *http.Request) {
+		childLogger.Info().Str("HandleFunc","/").Send()
+		
+		json.NewEncoder(rw).Encode(newAppInfo(appServer))
+	})
+
+	openApi := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	openApi.HandleFunc("/openapi.json", httpRouters.OpenApi)
+
+	health := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+    health.HandleFunc("/health", httpRouters.Health)
+
+	live := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+    live.HandleFunc("/live", httpRouters.Live)
+
+	header := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	header.HandleFunc("/header", api.MiddleWareErrorHandler(httpRouters.Header))	
+    header.Use(otelmux.Middleware("go-account"))
+	header.Use(authMiddleware.Authorize(security.ScopeAdmin))
+	header.Use(authMiddleware.RequireRole(auth.RoleAdmin))
+
+	wk_ctx := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+    wk_ctx.HandleFunc("/context", httpRouters.Context)
+	wk_ctx.Use(authMiddleware.Authorize(security.ScopeAdmin))
+	wk_ctx.Use(authMiddleware.RequireRole(auth.RoleAdmin))
+	
+	stat := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+    stat.HandleFunc("/stat", httpRouters.Stat)
+	stat.Use(authMiddleware.Authorize(security.ScopeAdmin))
+	stat.Use(authMiddleware.RequireRole(auth.RoleAdmin))
+	
+	myRouter.HandleFunc("/info", func(rw http.ResponseWriter, req *http.Request) {
+		childLogger.Info().Str("HandleFunc","/info").Send()
+
+		rw.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(rw).Encode(newAppInfo(appServer))
+	})
+	
+	// v1 account resources
+	v1AddAccount := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	v1AddAccount.HandleFunc("/v1/accounts", api.MiddleWareErrorHandler(httpRouters.AddAccount))		
+	v1AddAccount.Use(otelmux.Middleware("go-account"))
+	v1AddAccount.Use(authMiddleware.Authorize(security.ScopeWrite))
+	v1AddAccount.Use(authMiddleware.RequireRole(auth.RoleOperator))
+	v1AddAccount.Use(rateLimiter.Limit(ratelimit.ClassWrite))
+
+	v1GetAccount := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	v1GetAccount.HandleFunc("/v1/accounts/{id}", api.MiddleWareErrorHandler(httpRouters.GetAccount))		
+	v1GetAccount.HandleFunc("/v1/accounts/pk/{id}", api.MiddleWareErrorHandler(httpRouters.GetAccountId))		
+	v1GetAccount.HandleFunc("/v1/persons/{id}/accounts", api.MiddleWareErrorHandler(httpRouters.ListAccountPerPerson))		
+	v1GetAccount.HandleFunc("/v1/accounts/{id}/reconciliation", api.MiddleWareErrorHandler(httpRouters.ReconcileAccount))		
+	v1GetAccount.HandleFunc("/v1/accounts/{id}/statements", api.MiddleWareErrorHandler(httpRouters.ListAccountStatement))		
+	v1GetAccount.Use(otelmux.Middleware("go-account"))
+	v1GetAccount.Use(authMiddleware.Authorize(security.ScopeRead))
+	v1GetAccount.Use(authMiddleware.RequireRole(auth.RoleViewer))
+	v1GetAccount.Use(rateLimiter.Limit(ratelimit.ClassRead))
+
+	v1UpdateAccount := myRouter.Methods(http.MethodPatch, http.MethodOptions).Subrouter()
+	v1UpdateAccount.HandleFunc("/v1/accounts/{id}", api.MiddleWareErrorHandler(httpRouters.PatchAccount))		
+	v1UpdateAccount.Use(otelmux.Middleware("go-account"))
+	v1UpdateAccount.Use(authMiddleware.Authorize(security.ScopeWrite))
+	v1UpdateAccount.Use(authMiddleware.RequireRole(auth.RoleOperator))
+	v1UpdateAccount.Use(rateLimiter.Limit(ratelimit.ClassWrite))
+
+	v1DeleteAccount := myRouter.Methods(http.MethodDelete, http.MethodOptions).Subrouter()
+	v1DeleteAccount.HandleFunc("/v1/accounts/{id}", api.MiddleWareErrorHandler(httpRouters.DeleteAccount))		
+	v1DeleteAccount.Use(otelmux.Middleware("go-account"))
+	v1DeleteAccount.Use(authMiddleware.Authorize(security.ScopeWrite))
+	v1DeleteAccount.Use(authMiddleware.RequireRole(auth.RoleAdmin))
+	v1DeleteAccount.Use(rateLimiter.Limit(ratelimit.ClassWrite))
+
+	// graphql over the accounts, balances, statements and transfers
+	graphQuery := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	graphQuery.HandleFunc("/graphql", graphServer.Query)
+	graphQuery.Use(otelmux.Middleware("go-account"))
+	graphQuery.Use(authMiddleware.Authorize(security.ScopeRead))
+	graphQuery.Use(authMiddleware.RequireRole(auth.RoleViewer))
+	graphQuery.Use(rateLimiter.Limit(ratelimit.ClassRead))
+
+	// webhook subscriptions of the tenant and their deliveries
+	v1AddWebhook := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	v1AddWebhook.HandleFunc("/v1/webhooks", api.MiddleWareErrorHandler(httpRouters.AddWebhookSubscription))		
+	v1AddWebhook.HandleFunc("/v1/webhooks/{id}/deliveries/{delivery_id}/retry", api.MiddleWareErrorHandler(httpRouters.RetryWebhookDelivery))		
+	v1AddWebhook.Use(otelmux.Middleware("go-account"))
+	v1AddWebhook.Use(authMiddleware.Authorize(security.ScopeAdmin))
+	v1AddWebhook.Use(authMiddleware.RequireRole(auth.RoleAdmin))
+	v1AddWebhook.Use(rateLimiter.Limit(ratelimit.ClassWrite))
+
+	v1GetWebhook := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	v1GetWebhook.HandleFunc("/v1/webhooks", api.MiddleWareErrorHandler(httpRouters.ListWebhookSubscription))		
+	v1GetWebhook.HandleFunc("/v1/webhooks/{id}", api.MiddleWareErrorHandler(httpRouters.GetWebhookSubscription))		
+	v1GetWebhook.HandleFunc("/v1/webhooks/{id}/deliveries", api.MiddleWareErrorHandler(httpRouters.ListWebhookDelivery))		
+	v1GetWebhook.Use(otelmux.Middleware("go-account"))
+	v1GetWebhook.Use(authMiddleware.Authorize(security.ScopeAdmin))
+	v1GetWebhook.Use(authMiddleware.RequireRole(auth.RoleAdmin))
+	v1GetWebhook.Use(rateLimiter.Limit(ratelimit.ClassRead))
+
+	v1DeleteWebhook := myRouter.Methods(http.MethodDelete, http.MethodOptions).Subrouter()
+	v1DeleteWebhook.HandleFunc("/v1/webhooks/{id}", api.MiddleWareErrorHandler(httpRouters.DisableWebhookSubscription))		
+	v1DeleteWebhook.Use(otelmux.Middleware("go-account"))
+	v1DeleteWebhook.Use(authMiddleware.Authorize(security.ScopeAdmin))
+	v1DeleteWebhook.Use(authMiddleware.RequireRole(auth.RoleAdmin))
+	v1DeleteWebhook.Use(rateLimiter.Limit(ratelimit.ClassWrite))
+
+	// legacy routes, deprecated in favor of the v1 resources
+	addAccount := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	addAccount.HandleFunc("/add", api.MiddleWareErrorHandler(httpRouters.AddAccount))		
+	addAccount.Use(otelmux.Middleware("go-account"))
+	addAccount.Use(deprecated("/v1/accounts"))
+	addAccount.Use(authMiddleware.Authorize(security.ScopeWrite))
+	addAccount.Use(authMiddleware.RequireRole(auth.RoleOperator))
+	addAccount.Use(rateLimiter.Limit(ratelimit.ClassWrite))
+
+	getAccount := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	getAccount.HandleFunc("/get/{id}", api.MiddleWareErrorHandler(httpRouters.GetAccount))		
+	getAccount.Use(otelmux.Middleware("go-account"))
+	getAccount.Use(deprecated("/v1/accounts/{id}"))
+	getAccount.Use(authMiddleware.Authorize(security.ScopeRead))
+	getAccount.Use(authMiddleware.RequireRole(auth.RoleViewer))
+	getAccount.Use(rateLimiter.Limit(ratelimit.ClassRead))
+
+	getAccountId := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	getAccountId.HandleFunc("/getId/{id}", api.MiddleWareErrorHandler(httpRouters.GetAccountId))		
+	getAccountId.Use(otelmux.Middleware("go-account"))
+	getAccountId.Use(deprecated("/v1/accounts/pk/{id}"))
+	getAccountId.Use(authMiddleware.Authorize(security.ScopeRead))
+	getAccountId.Use(authMiddleware.RequireRole(auth.RoleViewer))
+	getAccountId.Use(rateLimiter.Limit(ratelimit.ClassRead))
+
+	deleteAccount := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	deleteAccount.HandleFunc("/delete/{id}", api.MiddleWareErrorHandler(httpRouters.DeleteAccount))		
+	deleteAccount.Use(otelmux.Middleware("go-account"))
+	deleteAccount.Use(deprecated("/v1/accounts/{id}"))
+	deleteAccount.Use(authMiddleware.Authorize(security.ScopeWrite))
+	deleteAccount.Use(authMiddleware.RequireRole(auth.RoleAdmin))
+	deleteAccount.Use(rateLimiter.Limit(ratelimit.ClassWrite))
+
+	// the account_id of /delete comes in the body, so there is no successor link to fill
+	deleteAccountBody := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	deleteAccountBody.HandleFunc("/delete", api.MiddleWareErrorHandler(httpRouters.DeleteAccount))		
+	deleteAccountBody.Use(otelmux.Middleware("go-account"))
+	deleteAccountBody.Use(deprecated(""))
+	deleteAccountBody.Use(authMiddleware.Authorize(security.ScopeWrite))
+	deleteAccountBody.Use(authMiddleware.RequireRole(auth.RoleAdmin))
+	deleteAccountBody.Use(rateLimiter.Limit(ratelimit.ClassWrite))
+
+	updateAccount := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	updateAccount.HandleFunc("/update/{id}", api.MiddleWareErrorHandler(httpRouters.UpdateAccount))		
+	updateAccount.Use(otelmux.Middleware("go-account"))
+	updateAccount.Use(deprecated("/v1/accounts/{id}"))
+	updateAccount.Use(authMiddleware.Authorize(security.ScopeWrite))
+	updateAccount.Use(authMiddleware.RequireRole(auth.RoleOperator))
+	updateAccount.Use(rateLimiter.Limit(ratelimit.ClassWrite))
+
+	listAccountPerPerson := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	listAccountPerPerson.HandleFunc("/list/{id}", api.MiddleWareErrorHandler(httpRouters.ListAccountPerPerson))		
+	listAccountPerPerson.Use(otelmux.Middleware("go-account"))
+	listAccountPerPerson.Use(deprecated("/v1/persons/{id}/accounts"))
+	listAccountPerPerson.Use(authMiddleware.Authorize(security.ScopeRead))
+	listAccountPerPerson.Use(authMiddleware.RequireRole(auth.RoleViewer))
+	listAccountPerPerson.Use(rateLimiter.Limit(ratelimit.ClassRead))
+
+	interestAccrual := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	interestAccrual.HandleFunc("/interestAccrual/{date}", api.MiddleWareErrorHandler(httpRouters.InterestAccrual))		
+	interestAccrual.Use(otelmux.Middleware("go-account"))
+	interestAccrual.Use(authMiddleware.Authorize(security.ScopeAdmin))
+	interestAccrual.Use(authMiddleware.RequireRole(auth.RoleAdmin))
+	interestAccrual.Use(rateLimiter.Limit(ratelimit.ClassWrite))
+
+	maintenanceFee := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	maintenanceFee.HandleFunc("/maintenanceFee/{month}", api.MiddleWareErrorHandler(httpRouters.MaintenanceFee))		
+	maintenanceFee.Use(otelmux.Middleware("go-account"))
+	maintenanceFee.Use(authMiddleware.Authorize(security.ScopeAdmin))
+	maintenanceFee.Use(authMiddleware.RequireRole(auth.RoleAdmin))
+	maintenanceFee.Use(rateLimiter.Limit(ratelimit.ClassWrite))
+
+	addPosting := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	addPosting.HandleFunc("/posting", api.MiddleWareErrorHandler(httpRouters.AddPosting))		
+	addPosting.Use(otelmux.Middleware("go-account"))
+	addPosting.Use(authMiddleware.Authorize(security.ScopeWrite))
+	addPosting.Use(authMiddleware.RequireRole(auth.RoleOperator))
+	addPosting.Use(rateLimiter.Limit(ratelimit.ClassWrite))
+
+	transfer := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	transfer.HandleFunc("/transfer", api.MiddleWareErrorHandler(httpRouters.Transfer))		
+	transfer.Use(otelmux.Middleware("go-account"))
+	transfer.Use(authMiddleware.Authorize(security.ScopeWrite))
+	transfer.Use(authMiddleware.RequireRole(auth.RoleOperator))
+	transfer.Use(rateLimiter.Limit(ratelimit.ClassWrite))
+
+	previewFee := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	previewFee.HandleFunc("/feePreview", api.MiddleWareErrorHandler(httpRouters.PreviewFee))		
+	previewFee.Use(otelmux.Middleware("go-account"))
+	previewFee.Use(authMiddleware.Authorize(security.ScopeRead))
+	previewFee.Use(authMiddleware.RequireRole(auth.RoleViewer))
+	previewFee.Use(rateLimiter.Limit(ratelimit.ClassRead))
+
+	addAccountProduct := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	addAccountProduct.HandleFunc("/product", api.MiddleWareErrorHandler(httpRouters.AddAccountProduct))		
+	addAccountProduct.Use(otelmux.Middleware("go-account"))
+	addAccountProduct.Use(authMiddleware.Authorize(security.ScopeAdmin))
+	addAccountProduct.Use(authMiddleware.RequireRole(auth.RoleAdmin))
+	addAccountProduct.Use(rateLimiter.Limit(ratelimit.ClassWrite))
+
+	getAccountProduct := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	getAccountProduct.HandleFunc("/product/{tenant}/{id}", api.MiddleWareErrorHandler(httpRouters.GetAccountProduct))		
+	getAccountProduct.Use(otelmux.Middleware("go-account"))
+	getAccountProduct.Use(authMiddleware.Authorize(security.ScopeRead))
+	getAccountProduct.Use(authMiddleware.RequireRole(auth.RoleViewer))
+	getAccountProduct.Use(rateLimiter.Limit(ratelimit.ClassRead))
+
+	listAccountProduct := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	listAccountProduct.HandleFunc("/listProduct/{id}", api.MiddleWareErrorHandler(httpRouters.ListAccountProduct))		
+	listAccountProduct.Use(otelmux.Middleware("go-account"))
+	listAccountProduct.Use(authMiddleware.Authorize(security.ScopeRead))
+	listAccountProduct.Use(authMiddleware.RequireRole(auth.RoleViewer))
+	listAccountProduct.Use(rateLimiter.Limit(ratelimit.ClassRead))
+
+	addAccountNumberFormat := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	addAccountNumberFormat.HandleFunc("/accountNumberFormat", api.MiddleWareErrorHandler(httpRouters.AddAccountNumberFormat))		
+	addAccountNumberFormat.Use(otelmux.Middleware("go-account"))
+	addAccountNumberFormat.Use(authMiddleware.Authorize(security.ScopeAdmin))
+	addAccountNumberFormat.Use(authMiddleware.RequireRole(auth.RoleAdmin))
+	addAccountNumberFormat.Use(rateLimiter.Limit(ratelimit.ClassWrite))
+
+	getAccountNumberFormat := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	getAccountNumberFormat.HandleFunc("/accountNumberFormat/{id}", api.MiddleWareErrorHandler(httpRouters.GetAccountNumberFormat))		
+	getAccountNumberFormat.Use(otelmux.Middleware("go-account"))
+	getAccountNumberFormat.Use(authMiddleware.Authorize(security.ScopeRead))
+	getAccountNumberFormat.Use(authMiddleware.RequireRole(auth.RoleViewer))
+	getAccountNumberFormat.Use(rateLimiter.Limit(ratelimit.ClassRead))
+
+	addTenantRole := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	addTenantRole.HandleFunc("/tenantRole", api.MiddleWareErrorHandler(httpRouters.AddTenantRole))		
+	addTenantRole.Use(otelmux.Middleware("go-account"))
+	addTenantRole.Use(authMiddleware.Authorize(security.ScopeAdmin))
+	addTenantRole.Use(authMiddleware.RequireRole(auth.RoleAdmin))
+	addTenantRole.Use(rateLimiter.Limit(ratelimit.ClassWrite))
+
+	listTenantRole := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	listTenantRole.HandleFunc("/tenantRole/{id}", api.MiddleWareErrorHandler(httpRouters.ListTenantRole))		
+	listTenantRole.Use(otelmux.Middleware("go-account"))
+	listTenantRole.Use(authMiddleware.Authorize(security.ScopeAdmin))
+	listTenantRole.Use(authMiddleware.RequireRole(auth.RoleAdmin))
+	listTenantRole.Use(rateLimiter.Limit(ratelimit.ClassRead))
+
+	addApiKey := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
+	addApiKey.HandleFunc("/apiKey", api.MiddleWareErrorHandler(httpRouters.AddApiKey))		
+	addApiKey.HandleFunc("/apiKey/{id}/rotate", api.MiddleWareErrorHandler(httpRouters.RotateApiKey))		
+	addApiKey.HandleFunc("/apiKey/{id}/revoke", api.MiddleWareErrorHandler(httpRouters.RevokeApiKey))		
+	addApiKey.Use(otelmux.Middleware("go-account"))
+	addApiKey.Use(authMiddleware.Authorize(security.ScopeAdmin))
+	addApiKey.Use(authMiddleware.RequireRole(auth.RoleAdmin))
+	addApiKey.Use(rateLimiter.Limit(ratelimit.ClassWrite))
+
+	listApiKey := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
+	listApiKey.HandleFunc("/listApiKey/{id}", api.MiddleWareErrorHandler(httpRouters.ListApiKey))		
+	listApiKey.Use(otelmux.Middleware("go-account"))
+	listApiKey.Use(authMiddleware.Authorize(security.ScopeAdmin))
+	listApiKey.Use(authMiddleware.RequireRole(auth.RoleAdmin))
+	listApiKey.Use(rateLimiter.Limit(ratelimit.ClassRead))
+
+	return myRouter
+}
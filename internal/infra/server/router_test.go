@@ -0,0 +1,114 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"context"
+	"net/http"
+	"encoding/json"
+	"net/http/httptest"
+
+	"github.com/go-account/internal/adapter/api"
+	"github.com/go-account/internal/adapter/graph"
+	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/infra/security"
+	"github.com/go-account/internal/infra/ratelimit"
+
+	"github.com/gorilla/mux"
+)
+
+func newTestRouter(t *testing.T) *mux.Router {
+	authMiddleware, err := security.NewAuthMiddleware(context.Background(), &model.SecurityConfig{AuthDisabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpRouters := api.NewHttpRouters(nil, 1)
+	graphServer, err := graph.NewGraphServer(nil, 1, &model.GraphQLConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewRouter(&httpRouters, graphServer, authMiddleware, ratelimit.NewRateLimiter(&model.RateLimitConfig{}, nil), &model.AppServer{})
+}
+
+// every registered route (path and method) must be described in the OpenAPI document and vice versa
+func TestOpenApiCoversRoutes(t *testing.T) {
+	router := newTestRouter(t)
+
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	if rw.Code != http.StatusOK {
+		t.Fatalf("GET /openapi.json status %d", rw.Code)
+	}
+
+	spec := struct {
+		Paths	map[string]map[string]json.RawMessage `json:"paths"`
+	}{}
+	if err := json.Unmarshal(rw.Body.Bytes(), &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	registered := map[string]bool{}
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			return nil // subrouters without path
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			methods = []string{http.MethodGet} // routes without method are documented as get
+		}
+		for _, method := range methods {
+			if method == http.MethodOptions {
+				continue
+			}
+			method = strings.ToLower(method)
+			registered[method + " " + path] = true
+			if _, ok := spec.Paths[path][method]; !ok {
+				t.Errorf("route %s %s is missing in openapi.json", strings.ToUpper(method), path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			if !registered[method + " " + path] {
+				t.Errorf("openapi.json describes %s %s that is not registered", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
+
+// / and /info are open, so they show the pod and not the configs
+func TestInfoHidesConfig(t *testing.T) {
+	authMiddleware, err := security.NewAuthMiddleware(context.Background(), &model.SecurityConfig{AuthDisabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpRouters := api.NewHttpRouters(nil, 1)
+	graphServer, err := graph.NewGraphServer(nil, 1, &model.GraphQLConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	appServer := model.AppServer{	InfoPod: &model.InfoPod{PodName: "go-account"},
+									Server: &model.Server{Port: 5000},
+									SecurityConfig: &model.SecurityConfig{JwksUrl: "https://issuer.internal/jwks.json", DefaultRole: "viewer"},
+									OutboxConfig: &model.OutboxConfig{KafkaBrokers: []string{"broker.internal:9092"}} }
+	router := NewRouter(&httpRouters, graphServer, authMiddleware, ratelimit.NewRateLimiter(&model.RateLimitConfig{}, nil), &appServer)
+
+	for _, path := range []string{"/", "/info"} {
+		rw := httptest.NewRecorder()
+		router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, path, nil))
+		body := rw.Body.String()
+		if rw.Code != http.StatusOK || !strings.Contains(body, `"pod_name":"go-account"`) {
+			t.Errorf("GET %s status %d body %s", path, rw.Code, body)
+		}
+		if strings.Contains(body, "issuer.internal") || strings.Contains(body, "broker.internal") || strings.Contains(body, "security") {
+			t.Errorf("GET %s exposes the configs: %s", path, body)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package server
 
 import (
 	"time"
-	"encoding/json"
 	"net/http"
 	"strconv"
 	"os"
@@ -11,10 +10,12 @@ import (
 	"context"
 
 	"github.com/go-account/internal/adapter/api"
+	"github.com/go-account/internal/adapter/graph"
 	"github.com/go-account/internal/core/model"
+	"github.com/go-account/internal/infra/security"
+	"github.com/go-account/internal/infra/ratelimit"
 	go_core_observ "github.com/eliezerraj/go-core/observability"  
 
-	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
 
 	"github.com/eliezerraj/go-core/middleware"
@@ -23,7 +24,6 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	//"go.opentelemetry.io/contrib/propagators/aws/xray"
 	"go.opentelemetry.io/otel/propagation"
-	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 )
 
 var (
@@ -48,6 +48,10 @@ func NewHttpAppServer(httpServer *model.Server) HttpServer {
 // About start http server
 func (h HttpServer) StartHttpAppServer(	ctx context.Context, 
 										httpRouters *api.HttpRouters,
+										graphServer *graph.GraphServer,
+										authMiddleware *security.AuthMiddleware,
+										tlsReloader *security.TlsReloader,
+										rateLimiter *ratelimit.RateLimiter,
 										appServer *model.AppServer) {
 	childLogger.Info().Str("func","StartHttpAppServer").Send()
 			
@@ -80,62 +84,8 @@ func (h HttpServer) StartHttpAppServer(	ctx context.Context,
 	}()
 
 	// Router
-	myRouter := mux.NewRouter().StrictSlash(true)
-	myRouter.Use(core_middleware.MiddleWareHandlerHeader)
+	myRouter := NewRouter(httpRouters, graphServer, authMiddleware, rateLimiter, appServer)
 
-	myRouter.HandleFunc("/", func(rw http.ResponseWriter, req *http.Request) {
-		childLogger.Info().Str("HandleFunc","/").Send()
-		
-		json.NewEncoder(rw).Encode(appServer)
-	})
-
-	health := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
-    health.HandleFunc("/health", httpRouters.Health)
-
-	live := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
-    live.HandleFunc("/live", httpRouters.Live)
-
-	header := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
-	header.HandleFunc("/header", core_middleware.MiddleWareErrorHandler(httpRouters.Header))	
-    header.Use(otelmux.Middleware("go-account"))
-
-	wk_ctx := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
-    wk_ctx.HandleFunc("/context", httpRouters.Context)
-	
-	stat := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
-    stat.HandleFunc("/stat", httpRouters.Stat)
-	
-	myRouter.HandleFunc("/info", func(rw http.ResponseWriter, req *http.Request) {
-		childLogger.Info().Str("HandleFunc","/info").Send()
-
-		rw.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(rw).Encode(appServer)
-	})
-	
-	addAccount := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
-	addAccount.HandleFunc("/add", core_middleware.MiddleWareErrorHandler(httpRouters.AddAccount))		
-	addAccount.Use(otelmux.Middleware("go-account"))
-
-	getAccount := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
-	getAccount.HandleFunc("/get/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.GetAccount))		
-	getAccount.Use(otelmux.Middleware("go-account"))
-
-	getAccountId := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
-	getAccountId.HandleFunc("/getId/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.GetAccountId))		
-	getAccountId.Use(otelmux.Middleware("go-account"))
-
-	deleteAccount := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
-	deleteAccount.HandleFunc("/delete", core_middleware.MiddleWareErrorHandler(httpRouters.DeleteAccount))		
-	deleteAccount.Use(otelmux.Middleware("go-account"))
-
-	updateAccount := myRouter.Methods(http.MethodPost, http.MethodOptions).Subrouter()
-	updateAccount.HandleFunc("/update/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.UpdateAccount))		
-	updateAccount.Use(otelmux.Middleware("go-account"))
-
-	listAccountPerPerson := myRouter.Methods(http.MethodGet, http.MethodOptions).Subrouter()
-	listAccountPerPerson.HandleFunc("/list/{id}", core_middleware.MiddleWareErrorHandler(httpRouters.ListAccountPerPerson))		
-	listAccountPerPerson.Use(otelmux.Middleware("go-account"))
-		
 	// start http server
 	srv := http.Server{
 		Addr:         ":" +  strconv.Itoa(h.httpServer.Port),      	
@@ -145,10 +95,16 @@ func (h HttpServer) StartHttpAppServer(	ctx context.Context,
 		IdleTimeout:  time.Duration(h.httpServer.IdleTimeout) * time.Second, 
 	}
 
-	childLogger.Info().Str("Service Port", strconv.Itoa(h.httpServer.Port)).Send()
+	childLogger.Info().Str("Service Port", strconv.Itoa(h.httpServer.Port)).Bool("tls", tlsReloader != nil).Send()
 
 	go func() {
-		err := srv.ListenAndServe()
+		var err error
+		if tlsReloader != nil {
+			srv.TLSConfig = tlsReloader.TLSConfig()
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
 		if err != nil {
 			childLogger.Info().Err(err).Msg("canceling http mux server !!!")
 		}
@@ -158,21 +114,31 @@ func (h HttpServer) StartHttpAppServer(	ctx context.Context,
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM)
 
+loop:
 	for {
 		sig := <-ch
 
 		switch sig {
 		case syscall.SIGHUP:
 			childLogger.Info().Msg("Received SIGHUP: reloading configuration...")
+			if tlsReloader != nil {
+				if err := tlsReloader.Reload(); err != nil {
+					childLogger.Error().Err(err).Msg("error reload tls certificates, the current ones are kept")
+				}
+			}
 		case syscall.SIGINT, syscall.SIGTERM:
 			childLogger.Info().Msg("Received SIGINT/SIGTERM termination signal. Exiting")
-			return
+			break loop
 		default:
 			childLogger.Info().Interface("Received signal:", sig).Send()
 		}
 	}
 
-	if err := srv.Shutdown(ctx); err != nil && err != http.ErrServerClosed {
+	// the main context is already cancelled by the signal, so the shutdown has its own timeout
+	ctxShutdown, cancel := context.WithTimeout(context.Background(), time.Duration(h.httpServer.WriteTimeout) * time.Second)
+	defer cancel()
+
+	if err := srv.Shutdown(ctxShutdown); err != nil && err != http.ErrServerClosed {
 		childLogger.Error().Err(err).Msg("warning dirty shutdown !!!")
 		return
 	}
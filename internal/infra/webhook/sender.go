@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"io"
+	"net"
+	"time"
+	"bytes"
+	"errors"
+	"context"
+	"syscall"
+	"net/http"
+	"net/url"
+
+	"github.com/rs/zerolog/log"
+	"github.com/go-account/internal/core/validator"
+)
+
+var childLogger = log.With().Str("component","go-account").Str("package","internal.infra.webhook").Logger()
+
+// About the max bytes of a response body read (the body is discarded, only the status matters)
+const maxResponseBody = 64 * 1024
+
+// About the errors of the url or of the address of a webhook (SSRF), they are not retried with success
+var (
+	ErrInsecureUrl		= errors.New("webhook url must be https")
+	ErrNotPublicAddress	= errors.New("webhook address is not public")
+)
+
+// About post the webhook payloads over http, the redirects are not followed (a 3xx is a failure)
+type HttpSender struct {
+	client		*http.Client
+	allowHttp	bool
+}
+
+// About create the http sender, the timeout of each post comes from the context. Each address is checked
+// after the dns resolution (the loopback, private, link-local and metadata ips are refused) and no proxy is used.
+// The plain http is only allowed in dev (allowHttp)
+func NewHttpSender(allowHttp bool) *HttpSender {
+	childLogger.Info().Str("func","NewHttpSender").Bool("allowHttp", allowHttp).Send()
+
+	return newHttpSender(allowHttp, validator.IsPublicIP)
+}
+
+func newHttpSender(allowHttp bool, allowIP func(ip net.IP) bool) *HttpSender {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !allowIP(net.ParseIP(host)) {
+				return ErrNotPublicAddress
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &HttpSender{
+		client: &http.Client{
+			Transport: transport,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		allowHttp: allowHttp,
+	}
+}
+
+// About post the body with the headers and return the status code
+func (h *HttpSender) Send(ctx context.Context, rawUrl string, header map[string]string, body []byte) (int, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return 0, err
+	}
+	if u.Scheme != "https" && !h.allowHttp {
+		return 0, ErrInsecureUrl
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawUrl, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("User-Agent", "go-account-webhook")
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, maxResponseBody))
+
+	return res.StatusCode, nil
+}
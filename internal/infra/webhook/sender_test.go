@@ -0,0 +1,77 @@
+package webhook
+
+import (
+	"io"
+	"net"
+	"errors"
+	"context"
+	"strings"
+	"testing"
+	"net/http"
+	"net/http/httptest"
+)
+
+// the test servers listen on the loopback
+func newTestSender() *HttpSender {
+	return newHttpSender(true, func(ip net.IP) bool { return true })
+}
+
+func TestSend(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		got = req
+		body, _ = io.ReadAll(req.Body)
+		rw.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	statusCode, err := newTestSender().Send(context.Background(), srv.URL, map[string]string{"Webhook-Id": "1"}, []byte(`{"a":1}`))
+	if err != nil || statusCode != http.StatusAccepted {
+		t.Fatalf("got %d %v", statusCode, err)
+	}
+	if got.Method != http.MethodPost || got.Header.Get("Webhook-Id") != "1" || string(body) != `{"a":1}` {
+		t.Errorf("unexpected request %s %v %s", got.Method, got.Header, body)
+	}
+}
+
+// a redirect is returned as the status, it is not followed
+func TestSendRedirect(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		http.Redirect(rw, req, "/other", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	statusCode, err := newTestSender().Send(context.Background(), srv.URL, nil, nil)
+	if err != nil || statusCode != http.StatusFound {
+		t.Fatalf("got %d %v", statusCode, err)
+	}
+}
+
+// the loopback is refused after the resolution, a plain http url only in dev
+func TestSendRefused(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		t.Error("the request must not arrive")
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name	string
+		sender	*HttpSender
+		url		string
+		err		error
+	}{
+		{"loopback", NewHttpSender(true), srv.URL, ErrNotPublicAddress},
+		{"localhost name", NewHttpSender(true), strings.Replace(srv.URL, "127.0.0.1", "localhost", 1), ErrNotPublicAddress},
+		{"plain http", NewHttpSender(false), "http://hooks.example.com/a", ErrInsecureUrl},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.sender.Send(context.Background(), tt.url, nil, nil)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("got %v want %v", err, tt.err)
+			}
+		})
+	}
+}